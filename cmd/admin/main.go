@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"shop/internal/repository"
+	"shop/pkg/config"
+	"shop/pkg/database"
+	"shop/pkg/logger"
+
+	"github.com/joho/godotenv"
+	log "github.com/sirupsen/logrus"
+)
+
+const usage = `usage: admin <command> [arguments]
+
+commands:
+  migrate up          apply all pending migrations
+  migrate down [n]    roll back the last n migrations (default 1)
+  migrate status      list migrations and when they were applied
+  migrate version     print the current schema version
+  seed <file>...      upsert users, merch and balances from YAML or JSON fixtures
+  role <user> <role>  set the role of a user: user or admin
+  audit verify        check the hash chain of the audit log
+  config              print the effective configuration with secrets redacted
+`
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("error loading .env file")
+	}
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load("admin", nil)
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if err = logger.Init(cfg.Log); err != nil {
+		log.Fatalf("failed to initialize logger: %v", err)
+	}
+
+	switch os.Args[1] {
+	case "migrate":
+		err = migrate(context.Background(), cfg, os.Args[2:])
+	case "seed":
+		err = seed(context.Background(), cfg, os.Args[2:])
+	case "role":
+		err = role(context.Background(), cfg, os.Args[2:])
+	case "audit":
+		err = verifyAudit(context.Background(), cfg, os.Args[2:])
+	case "config":
+		fmt.Print(cfg.Dump())
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func migrate(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("migrate: missing subcommand\n%s", usage)
+	}
+
+	db := database.InitializeDBPostgres(cfg.DB)
+	migrator, err := db.Migrator()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("migrate down: invalid number of steps %q", args[1])
+			}
+		}
+		return migrator.Down(ctx, steps)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	case "version":
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("current: %d, latest: %d\n", version, migrator.Latest())
+		return nil
+	default:
+		return fmt.Errorf("migrate: unknown subcommand %q\n%s", args[0], usage)
+	}
+}
+
+func seed(ctx context.Context, cfg *config.Config, files []string) error {
+	if len(files) == 0 {
+		return fmt.Errorf("seed: no fixture files given\n%s", usage)
+	}
+
+	db := database.InitializeDBPostgres(cfg.DB)
+	if err := db.CheckSchema(ctx); err != nil {
+		return err
+	}
+
+	options := database.SeedOptions{
+		Production:      cfg.IsProduction(),
+		StartingBalance: cfg.Users.StartingBalance,
+		BcryptCost:      cfg.Auth.BcryptCost,
+		Adjuster:        repository.NewRepository(db.GetDB()),
+	}
+	for _, file := range files {
+		fixtures, err := database.LoadFixtures(file)
+		if err != nil {
+			return err
+		}
+		if err = db.Seed(ctx, fixtures, options); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+func role(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("role: expected a username and a role\n%s", usage)
+	}
+
+	db := database.InitializeDBPostgres(cfg.DB)
+	if err := db.CheckSchema(ctx); err != nil {
+		return err
+	}
+	return db.SetRole(ctx, args[0], args[1])
+}
+
+func verifyAudit(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "verify" {
+		return fmt.Errorf("audit: unknown subcommand\n%s", usage)
+	}
+
+	db := database.InitializeDBPostgres(cfg.DB)
+	count, err := db.VerifyAudit(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("audit log intact, %d entries\n", count)
+	return nil
+}
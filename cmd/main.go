@@ -1,38 +1,136 @@
 package main
 
 import (
-	"net/http"
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"shop/internal/controller"
 	"shop/internal/repository"
 	"shop/internal/usecase"
+	"shop/pkg/audit"
+	"shop/pkg/blob"
+	"shop/pkg/config"
 	"shop/pkg/database"
+	"shop/pkg/health"
+	"shop/pkg/jobs"
 	"shop/pkg/logger"
+	"shop/pkg/metrics"
+	"shop/pkg/notify"
+	"shop/pkg/server"
+	"shop/pkg/tracing"
 
 	"github.com/joho/godotenv"
 	log "github.com/sirupsen/logrus"
 )
 
 func main() {
+	// JSON output from the first line, the configured level and format
+	// are applied as soon as the configuration is loaded.
+	_ = logger.Init(config.Default().Log)
 	if err := godotenv.Load(); err != nil {
 		log.Println("error loading .env file")
 	}
 
-	port := os.Getenv("HTTP_PORT")
-	db := database.InitializeDBPostgres(3, 10)
-	db.Seed()
-	logger.InitLogger()
+	cfg, err := config.Load(os.Args[0], os.Args[1:])
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if err = logger.Init(cfg.Log); err != nil {
+		log.Fatalf("failed to initialize logger: %v", err)
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %v", err)
+	}
+
+	db := database.InitializeDBPostgres(cfg.DB)
+	if err := db.CheckSchema(context.Background()); err != nil {
+		log.Fatalf("refusing to start: %v, run `admin migrate up` first", err)
+	}
+
+	sqlDB, err := db.GetDB().DB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	migrator, err := db.Migrator()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = db.GetDB().Use(metrics.GormPlugin{}); err != nil {
+		log.Fatal(err)
+	}
+	if err = db.GetDB().Use(tracing.GormPlugin{}); err != nil {
+		log.Fatal(err)
+	}
+	if err = metrics.RegisterDBStats(sqlDB, cfg.DB.Name); err != nil {
+		log.Fatal(err)
+	}
+
+	checker := health.NewChecker(cfg.Health.CheckTimeout)
+	checker.AddReadinessCheck("database", health.DatabaseCheck(sqlDB))
+	checker.AddReadinessCheck("migrations", health.MigrationsCheck(migrator))
+	checker.AddReadinessCheck("pool", health.PoolCheck(sqlDB, cfg.Health.PoolSaturation))
 
+	blobs, err := blob.New(cfg.Blobs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	notifier, err := notify.New(cfg.Notifier)
+	if err != nil {
+		log.Fatal(err)
+	}
 	repository := repository.NewRepository(db.GetDB())
-	usecase := usecase.NewUsecase(repository)
-	handlers := controller.NewHandler(usecase)
+	usecase := usecase.NewUsecase(repository, blobs, notifier, cfg)
+	handlers := controller.NewHandler(usecase, cfg.Auth, checker)
 	router := handlers.Handle()
 
-	err := http.ListenAndServe(":"+port, router)
-	if err != nil {
-		log.Fatalf("connection failed: %s\n", err.Error())
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	runner := jobs.NewRunner(cfg.Scheduler.Interval)
+	runner.Add("allowances", usecase.RunDueAllowances)
+	runner.Add("coin expiry", usecase.ExpireCoins)
+	runner.Add("coin request expiry", usecase.ExpireCoinRequests)
+	runner.Add("scheduled transfers", usecase.RunScheduledTransfers)
+	runner.Add("purchase approvals", usecase.EscalatePurchaseApprovals)
+	runner.Add("hold expiry", usecase.ExpireHolds)
+	runner.Add("wishlist alerts", usecase.SendWishlistAlerts)
+	if cfg.Scheduler.Enabled {
+		go runner.Run(audit.WithActor(ctx, audit.Actor{Username: "system:scheduler"}))
 	}
 
-	log.Infof("server is running on port %s\n", port)
+	metricsHTTP := cfg.HTTP
+	metricsHTTP.Port = cfg.HTTP.MetricsPort
+	metricsHTTP.DrainDelay = 0
+	go func() {
+		if err := server.New(metricsHTTP, metrics.Handler()).Run(ctx); err != nil {
+			log.Errorf("metrics server stopped with error: %v", err)
+			stop()
+		}
+	}()
+
+	srv := server.New(cfg.HTTP, router)
+	srv.OnDrain(checker.SetDraining)
+	if err = srv.Run(ctx); err != nil {
+		log.Errorf("server stopped with error: %v", err)
+	}
+	// The server may stop on its own, e.g. when the port is taken, the jobs
+	// and the metrics server only stop once ctx is cancelled.
+	stop()
+	if cfg.Scheduler.Enabled {
+		// Jobs write to the database, let the current tick finish first.
+		<-runner.Done()
+	}
+
+	if err = db.Close(); err != nil {
+		log.Errorf("failed to close database: %v", err)
+	}
+	log.Info("database connection closed")
+
+	if err = shutdownTracing(context.Background()); err != nil {
+		log.Errorf("failed to flush traces: %v", err)
+	}
 }
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// BalanceAdjustment is a credit (positive amount) or debit (negative amount)
+// of a user's balance made by an admin, outside of transfers and purchases.
+type BalanceAdjustment struct {
+	GUID      string    `json:"guid" gorm:"column:guid;primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	Username  string    `json:"username" gorm:"column:username;not null"`
+	Amount    float64   `json:"amount" gorm:"column:amount;type:decimal(20,8);not null"`
+	Reason    string    `json:"reason" gorm:"column:reason;not null"`
+	Actor     string    `json:"actor" gorm:"column:actor;not null"`
+	BatchID   string    `json:"batch_id,omitempty" gorm:"column:batch_id"`
+}
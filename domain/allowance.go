@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+const (
+	RunTriggerSchedule = "schedule"
+	RunTriggerManual   = "manual"
+)
+
+// AllowancePolicy pays Amount to every active user matching Role and
+// Department (empty means any) on the cron Schedule.
+type AllowancePolicy struct {
+	ID         int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Name       string    `json:"name" gorm:"column:name;not null;unique"`
+	Schedule   string    `json:"schedule" gorm:"column:schedule;not null"`
+	Amount     float64   `json:"amount" gorm:"column:amount;type:decimal(20,8);not null"`
+	Role       string    `json:"role" gorm:"column:role"`
+	Department string    `json:"department" gorm:"column:department"`
+	Paused     bool      `json:"paused" gorm:"column:paused;not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+type AllowanceRun struct {
+	GUID         string    `json:"guid" gorm:"column:guid;primaryKey"`
+	PolicyID     int64     `json:"policy_id" gorm:"column:policy_id;not null"`
+	ScheduledFor time.Time `json:"scheduled_for" gorm:"column:scheduled_for;not null"`
+	Trigger      string    `json:"trigger" gorm:"column:trigger;not null"`
+	Actor        string    `json:"actor" gorm:"column:actor;not null"`
+	UsersPaid    int       `json:"users_paid" gorm:"column:users_paid;not null"`
+	Total        float64   `json:"total" gorm:"column:total;type:decimal(20,8);not null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+type AllowancePreview struct {
+	Policy     AllowancePolicy      `json:"policy"`
+	NextRun    *time.Time           `json:"next_run"`
+	Recipients []AllowanceRecipient `json:"recipients"`
+	Total      float64              `json:"total"`
+}
+
+type AllowanceRecipient struct {
+	Username string  `json:"username"`
+	Amount   float64 `json:"amount"`
+}
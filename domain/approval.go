@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+	ApprovalExpired  = "expired"
+)
+
+// PurchaseApproval is a purchase of an expensive item waiting for the buyer's
+// manager. The price, after the discount of its promo codes, is held until
+// the approval is resolved. An approval not handled by DueAt is escalated to
+// admins, Approver is then empty, and it expires when admins do not handle it
+// in time either.
+type PurchaseApproval struct {
+	ID           int64      `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Username     string     `json:"username" gorm:"column:username;not null"`
+	RecipientID  string     `json:"recipient_id" gorm:"column:recipient_id;not null"`
+	GiftMessage  string     `json:"gift_message,omitempty" gorm:"column:gift_message"`
+	MerchName    string     `json:"merch_name" gorm:"column:merch_name;not null"`
+	Price        float64    `json:"price" gorm:"column:price;type:decimal(20,8);not null"`
+	Discount     float64    `json:"discount,omitempty" gorm:"column:discount;type:decimal(20,8);not null"`
+	PromoCodes   []string   `json:"promo_codes,omitempty" gorm:"column:promo_codes;serializer:json"`
+	Approver     string     `json:"approver,omitempty" gorm:"column:approver;not null"`
+	Escalated    bool       `json:"escalated" gorm:"column:escalated;not null"`
+	HoldID       int64      `json:"hold_id" gorm:"column:hold_id;not null"`
+	Status       string     `json:"status" gorm:"column:status;not null"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	DueAt        time.Time  `json:"due_at" gorm:"column:due_at;not null"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty" gorm:"column:resolved_at"`
+	ResolvedBy   string     `json:"resolved_by,omitempty" gorm:"column:resolved_by"`
+	PurchaseGUID string     `json:"purchase_guid,omitempty" gorm:"column:purchase_guid"`
+}
+
+// PurchasePolicy decides which purchases need an approval and how long
+// each approver has to handle it.
+type PurchasePolicy struct {
+	ApprovalThreshold float64
+	ApprovalTimeout   time.Duration
+}
+
+// NeedsApproval reports whether an item of the price needs an approval, a
+// zero threshold disables approvals.
+func (p PurchasePolicy) NeedsApproval(price float64) bool {
+	return p.ApprovalThreshold > 0 && price >= p.ApprovalThreshold
+}
+
+// PendingApprovals are the open approvals assigned to a user and the ones
+// waiting for their own purchases.
+type PendingApprovals struct {
+	Assigned  []PurchaseApproval `json:"assigned"`
+	Requested []PurchaseApproval `json:"requested"`
+}
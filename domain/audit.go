@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+const (
+	AuditLogin             = "auth.login"
+	AuditLoginFailed       = "auth.login_failed"
+	AuditRegistration      = "auth.register"
+	AuditRoleChange        = "user.role_change"
+	AuditUserUpdate        = "user.update"
+	AuditAllowancePolicy   = "allowance.policy"
+	AuditTransfer          = "coins.transfer"
+	AuditCoinRequest       = "coins.request"
+	AuditScheduledTransfer = "coins.scheduled_transfer"
+	AuditPurchase          = "merch.purchase"
+	AuditPurchaseApproval  = "merch.purchase_approval"
+	AuditPromoCode         = "merch.promo_code"
+	AuditWallet            = "wallet.change"
+	AuditWalletContribute  = "wallet.contribute"
+	AuditWalletPurchase    = "wallet.purchase"
+	AuditBalanceAdjustment = "balance.adjust"
+	AuditHold              = "balance.hold"
+	AuditPriceChange       = "merch.price_change"
+	AuditMerch             = "merch.change"
+	AuditMerchImage        = "merch.image"
+	AuditCategory          = "merch.category"
+)
+
+// AuditEntry is a row of the append-only audit log. Before and After hold
+// JSON snapshots of the changed values, Hash chains the entry to the
+// previous one so that edited or deleted rows can be detected.
+type AuditEntry struct {
+	ID        int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;not null"`
+	Action    string    `json:"action" gorm:"column:action;not null"`
+	Actor     string    `json:"actor" gorm:"column:actor;not null"`
+	Subject   string    `json:"subject" gorm:"column:subject"`
+	IP        string    `json:"ip" gorm:"column:ip"`
+	UserAgent string    `json:"user_agent" gorm:"column:user_agent"`
+	RequestID string    `json:"request_id" gorm:"column:request_id"`
+	Before    string    `json:"before,omitempty" gorm:"column:before"`
+	After     string    `json:"after,omitempty" gorm:"column:after"`
+	PrevHash  string    `json:"prev_hash" gorm:"column:prev_hash;not null"`
+	Hash      string    `json:"hash" gorm:"column:hash;not null;unique"`
+}
+
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
+
+type AuditFilter struct {
+	Actor   string
+	Subject string
+	Action  string
+	From    time.Time
+	To      time.Time
+	Limit   int
+	Offset  int
+}
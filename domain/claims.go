@@ -5,5 +5,6 @@ import "github.com/dgrijalva/jwt-go"
 type Claims struct {
 	IP       string `json:"ip"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
 	jwt.StandardClaims
 }
@@ -0,0 +1,55 @@
+package domain
+
+import "errors"
+
+// Errors are matched with errors.Is, they may be wrapped with details.
+var (
+	ErrInvalidPassword   = errors.New("invalid password")
+	ErrInsufficientMoney = errors.New("insufficient money")
+	ErrNoSuchUser        = errors.New("no such user")
+	ErrNoMerch           = errors.New("no merch found")
+	ErrMerchExists       = errors.New("merch already exists")
+	ErrOutOfStock        = errors.New("merch is out of stock")
+	ErrNoMerchImage      = errors.New("no merch image found")
+	ErrImageTooLarge     = errors.New("image is too large")
+	ErrNoCategory        = errors.New("no category found")
+	ErrCategoryExists    = errors.New("category already exists")
+	ErrCategoryInUse     = errors.New("category still has merch")
+
+	ErrAllowanceAlreadyPaid = errors.New("allowance already paid for this occurrence")
+	ErrNoAllowancePolicy    = errors.New("no allowance policy found")
+	ErrInvalidSchedule      = errors.New("invalid schedule")
+
+	ErrNoCoinRequest      = errors.New("no coin request found")
+	ErrCoinRequestClosed  = errors.New("coin request is no longer pending")
+	ErrCoinRequestExpired = errors.New("coin request has expired")
+
+	ErrNoScheduledTransfer     = errors.New("no scheduled transfer found")
+	ErrScheduledTransferClosed = errors.New("scheduled transfer is no longer pending")
+
+	ErrNoHold             = errors.New("no hold found")
+	ErrHoldClosed         = errors.New("hold is no longer active")
+	ErrHoldExpired        = errors.New("hold has expired")
+	ErrCaptureExceedsHold = errors.New("capture exceeds the held amount")
+
+	ErrNoApproval      = errors.New("no purchase approval found")
+	ErrApprovalClosed  = errors.New("purchase approval is no longer pending")
+	ErrApprovalExpired = errors.New("purchase approval has expired")
+
+	ErrNotInWishlist = errors.New("item is not in the wishlist")
+
+	ErrNoPromoCode            = errors.New("no promo code found")
+	ErrPromoCodeExists        = errors.New("promo code already exists")
+	ErrPromoCodeNotApplicable = errors.New("promo code cannot be used")
+	ErrPromoCodeUsedUp        = errors.New("promo code has been used up")
+	ErrPromoCodesNotStackable = errors.New("promo code cannot be combined with other codes")
+
+	ErrNoWallet             = errors.New("no wallet found")
+	ErrNotWalletOwner       = errors.New("only wallet owners can do this")
+	ErrLastWalletOwner      = errors.New("wallet must keep at least one owner")
+	ErrNoWalletPurchase     = errors.New("no wallet purchase found")
+	ErrWalletPurchaseClosed = errors.New("wallet purchase is no longer pending")
+
+	// ErrTransferLimit matches every *TransferLimitError.
+	ErrTransferLimit = errors.New("transfer limit exceeded")
+)
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+const (
+	HoldActive   = "active"
+	HoldCaptured = "captured"
+	HoldReleased = "released"
+	HoldExpired  = "expired"
+)
+
+// Hold reserves Amount of a user's balance without moving it. While the hold
+// is active the coins are not available for transfers and purchases. Capturing
+// it debits up to Amount and releases the rest, the debit is the adjustment
+// or, for a hold of an approved purchase, the purchase.
+type Hold struct {
+	ID             int64      `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Username       string     `json:"username" gorm:"column:username;not null"`
+	Amount         float64    `json:"amount" gorm:"column:amount;type:decimal(20,8);not null"`
+	Captured       float64    `json:"captured,omitempty" gorm:"column:captured;type:decimal(20,8);not null"`
+	Reason         string     `json:"reason" gorm:"column:reason;not null"`
+	Status         string     `json:"status" gorm:"column:status;not null"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	ExpiresAt      time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty" gorm:"column:resolved_at"`
+	AdjustmentGUID *string    `json:"adjustment_guid,omitempty" gorm:"column:adjustment_guid;default:null"`
+	PurchaseGUID   *string    `json:"purchase_guid,omitempty" gorm:"column:purchase_guid;default:null"`
+}
+
+// Balance is a user's balance split into the held and available parts,
+// with the active holds.
+type Balance struct {
+	Balance   float64 `json:"balance"`
+	Held      float64 `json:"held"`
+	Available float64 `json:"available"`
+	Holds     []Hold  `json:"holds"`
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Codes of TransferLimitError, returned to the client as is.
+const (
+	LimitTransferAmount  = "transfer_amount_limit"
+	LimitDailyAmount     = "daily_amount_limit"
+	LimitDailyCount      = "daily_count_limit"
+	LimitDailyRecipients = "daily_recipients_limit"
+)
+
+// TransferLimits caps what a user can send. Zero values mean no limit,
+// daily limits are counted per UTC day.
+type TransferLimits struct {
+	MaxAmount       float64
+	DailyAmount     float64
+	DailyCount      int
+	DailyRecipients int
+}
+
+// TransferPolicy holds the default limits and the ones of roles that override them.
+type TransferPolicy struct {
+	Default TransferLimits
+	Roles   map[string]TransferLimits
+}
+
+func (p TransferPolicy) For(role string) TransferLimits {
+	if limits, ok := p.Roles[role]; ok {
+		return limits
+	}
+	return p.Default
+}
+
+// TransferStats sums up what a user has sent during the current day.
+type TransferStats struct {
+	Amount     float64
+	Count      int
+	Recipients []string
+}
+
+// Add counts a transfer made after the stats were collected.
+func (s *TransferStats) Add(receiver string, amount float64) {
+	s.Amount += amount
+	s.Count++
+	if !slices.Contains(s.Recipients, receiver) {
+		s.Recipients = append(s.Recipients, receiver)
+	}
+}
+
+// TransferLimitError tells which limit a transfer exceeds and how much of it is left.
+type TransferLimitError struct {
+	Code      string     `json:"code"`
+	Limit     float64    `json:"limit"`
+	Remaining float64    `json:"remaining"`
+	ResetsAt  *time.Time `json:"resets_at,omitempty"`
+}
+
+func (e *TransferLimitError) Error() string {
+	return fmt.Sprintf("transfer limit exceeded: %s", e.Code)
+}
+
+func (e *TransferLimitError) Is(target error) bool {
+	return target == ErrTransferLimit
+}
+
+// TransferDay returns the UTC day now belongs to, daily limits are reset at its end.
+func TransferDay(now time.Time) (start, end time.Time) {
+	start = now.UTC().Truncate(24 * time.Hour)
+	return start, start.Add(24 * time.Hour)
+}
+
+// Check returns a *TransferLimitError if sending amount to receiver, on top
+// of what was sent today, exceeds one of the limits.
+func (l TransferLimits) Check(stats TransferStats, receiver string, amount float64, resetsAt time.Time) error {
+	if l.MaxAmount > 0 && amount > l.MaxAmount {
+		return &TransferLimitError{Code: LimitTransferAmount, Limit: l.MaxAmount, Remaining: l.MaxAmount}
+	}
+
+	exceeded := func(code string, limit, used float64) error {
+		return &TransferLimitError{Code: code, Limit: limit, Remaining: max(limit-used, 0), ResetsAt: &resetsAt}
+	}
+	if l.DailyAmount > 0 && stats.Amount+amount > l.DailyAmount {
+		return exceeded(LimitDailyAmount, l.DailyAmount, stats.Amount)
+	}
+	if l.DailyCount > 0 && stats.Count+1 > l.DailyCount {
+		return exceeded(LimitDailyCount, float64(l.DailyCount), float64(stats.Count))
+	}
+	newRecipient := !slices.Contains(stats.Recipients, receiver)
+	if l.DailyRecipients > 0 && newRecipient && len(stats.Recipients)+1 > l.DailyRecipients {
+		return exceeded(LimitDailyRecipients, float64(l.DailyRecipients), float64(len(stats.Recipients)))
+	}
+	return nil
+}
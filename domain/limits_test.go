@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferLimits_Check(t *testing.T) {
+	limits := TransferLimits{MaxAmount: 100, DailyAmount: 150, DailyCount: 3, DailyRecipients: 2}
+	resetsAt := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name      string
+		stats     TransferStats
+		receiver  string
+		amount    float64
+		code      string
+		remaining float64
+	}{
+		{name: "WithinLimits", stats: TransferStats{Amount: 50, Count: 1, Recipients: []string{"user2"}}, receiver: "user3", amount: 100},
+		{name: "TooLarge", receiver: "user2", amount: 101, code: LimitTransferAmount, remaining: 100},
+		{name: "DailyAmount", stats: TransferStats{Amount: 120, Count: 1, Recipients: []string{"user2"}}, receiver: "user2", amount: 40, code: LimitDailyAmount, remaining: 30},
+		{name: "DailyCount", stats: TransferStats{Amount: 3, Count: 3, Recipients: []string{"user2"}}, receiver: "user2", amount: 1, code: LimitDailyCount},
+		{name: "DailyRecipients", stats: TransferStats{Amount: 2, Count: 2, Recipients: []string{"user2", "user3"}}, receiver: "user4", amount: 1, code: LimitDailyRecipients},
+		{name: "KnownRecipient", stats: TransferStats{Amount: 2, Count: 2, Recipients: []string{"user2", "user3"}}, receiver: "user3", amount: 1},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			err := limits.Check(test.stats, test.receiver, test.amount, resetsAt)
+			if test.code == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var limitErr *TransferLimitError
+			if assert.ErrorAs(t, err, &limitErr) {
+				assert.Equal(t, test.code, limitErr.Code)
+				assert.Equal(t, test.remaining, limitErr.Remaining)
+			}
+			assert.ErrorIs(t, err, ErrTransferLimit)
+		})
+	}
+}
+
+func TestTransferPolicy_For(t *testing.T) {
+	policy := TransferPolicy{
+		Default: TransferLimits{MaxAmount: 100},
+		Roles:   map[string]TransferLimits{RoleAdmin: {}},
+	}
+	assert.Equal(t, TransferLimits{MaxAmount: 100}, policy.For(RoleUser))
+	assert.Equal(t, TransferLimits{}, policy.For(RoleAdmin))
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+const (
+	LotSourceRegistration = "registration"
+	LotSourceTransfer     = "transfer"
+	LotSourceAdjustment   = "adjustment"
+	LotSourceSeed         = "seed"
+)
+
+// CoinLot is a portion of a user's balance granted at the same time. Spending
+// consumes the oldest lots first, coins received in a transfer keep the date
+// they were originally granted at, so passing them around does not renew them.
+type CoinLot struct {
+	ID        int64     `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	Username  string    `json:"-" gorm:"column:username;not null"`
+	Amount    float64   `json:"amount" gorm:"column:amount;type:decimal(20,8);not null"`
+	Remaining float64   `json:"remaining" gorm:"column:remaining;type:decimal(20,8);not null"`
+	GrantedAt time.Time `json:"granted_at" gorm:"column:granted_at;not null"`
+	Source    string    `json:"source" gorm:"column:source;not null"`
+}
+
+// CoinExpiration is the amount of a user's coins that expires at ExpiresAt.
+type CoinExpiration struct {
+	Amount    float64   `json:"amount"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -1,6 +1,86 @@
 package domain
 
+import "time"
+
+// Merch is an item of the catalog. Category is the name of its category,
+// empty when it has none, tags are free-form and lower-case. Stock is the
+// number of items left, nil when the stock is not tracked.
 type Merch struct {
-	Name  string  `json:"name" gorm:"column:name;not null;primaryKey"`
-	Price float64 `json:"price" gorm:"column:price;type:decimal(20,8);not null"`
+	Name        string       `json:"name" gorm:"column:name;not null;primaryKey"`
+	Price       float64      `json:"price" gorm:"column:price;type:decimal(20,8);not null"`
+	Stock       *int         `json:"stock,omitempty" gorm:"column:stock"`
+	Description string       `json:"description,omitempty" gorm:"column:description;not null;default:''"`
+	Category    *string      `json:"category,omitempty" gorm:"column:category"`
+	Tags        []string     `json:"tags,omitempty" gorm:"column:tags;serializer:json"`
+	Images      []MerchImage `json:"images,omitempty" gorm:"foreignKey:MerchName;references:Name"`
+}
+
+// InStock reports whether the item can be bought, items whose stock is not
+// tracked never run out.
+func (m *Merch) InStock() bool {
+	return m.Stock == nil || *m.Stock > 0
+}
+
+// MerchUpdate holds the changes made to an item, nil fields are left
+// unchanged. An empty category removes the item from its category and a
+// negative stock stops tracking its stock.
+type MerchUpdate struct {
+	Price       *float64
+	Stock       *int
+	Description *string
+	Category    *string
+	Tags        *[]string
+}
+
+// MerchFilter narrows the catalog to a category and to items with a tag,
+// empty values match every item.
+type MerchFilter struct {
+	Category string
+	Tag      string
+}
+
+// Category groups merch in the catalog, Name is the slug used in URLs and
+// filters, Title the name shown to users.
+type Category struct {
+	Name  string `json:"name" gorm:"column:name;primaryKey"`
+	Title string `json:"title" gorm:"column:title;not null"`
+}
+
+// MerchImage is an uploaded picture of an item, the file itself is kept in
+// the blob store under Key.
+type MerchImage struct {
+	ID          int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	MerchName   string    `json:"-" gorm:"column:merch_name;not null"`
+	Key         string    `json:"-" gorm:"column:key;not null"`
+	ContentType string    `json:"content_type" gorm:"column:content_type;not null"`
+	Width       int       `json:"width" gorm:"column:width;not null"`
+	Height      int       `json:"height" gorm:"column:height;not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// MerchPrice is the price of an item from EffectiveFrom until the next
+// price of the item takes effect, EffectiveTo is empty for the current one.
+type MerchPrice struct {
+	ID            int64      `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	MerchName     string     `json:"-" gorm:"column:merch_name;not null"`
+	Price         float64    `json:"price" gorm:"column:price;type:decimal(20,8);not null"`
+	EffectiveFrom time.Time  `json:"effective_from" gorm:"column:effective_from;not null"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty" gorm:"-"`
+}
+
+// PriceTimeline is the current price of an item with its prices over time,
+// oldest first.
+type PriceTimeline struct {
+	Item   string       `json:"item"`
+	Price  float64      `json:"price"`
+	Prices []MerchPrice `json:"prices"`
+}
+
+// NewPriceTimeline builds the timeline of the item from its prices sorted
+// by EffectiveFrom, each price ends when the next one takes effect.
+func NewPriceTimeline(merch Merch, prices []MerchPrice) *PriceTimeline {
+	for i := 0; i+1 < len(prices); i++ {
+		prices[i].EffectiveTo = &prices[i+1].EffectiveFrom
+	}
+	return &PriceTimeline{Item: merch.Name, Price: merch.Price, Prices: prices}
 }
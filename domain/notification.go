@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+const (
+	NotificationGift      = "gift"
+	NotificationApproval  = "approval"
+	NotificationRestock   = "restock"
+	NotificationPriceDrop = "price_drop"
+)
+
+// Notification is a message in a user's inbox. Reference points to the
+// object it is about, such as the GUID of a gift purchase.
+type Notification struct {
+	ID        int64      `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Username  string     `json:"-" gorm:"column:username;not null"`
+	Kind      string     `json:"kind" gorm:"column:kind;not null"`
+	Message   string     `json:"message" gorm:"column:message;not null"`
+	Reference string     `json:"reference,omitempty" gorm:"column:reference"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	ReadAt    *time.Time `json:"read_at,omitempty" gorm:"column:read_at"`
+}
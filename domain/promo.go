@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// PromoCode discounts purchases of Items, or of any item when Items is
+// empty, from StartsAt until EndsAt. MaxUses caps the redemptions of all
+// users and MaxUsesPerUser the ones of each buyer, zero means no cap. Only
+// stackable codes can be combined with other codes in one purchase.
+type PromoCode struct {
+	Code           string     `json:"code" gorm:"column:code;primaryKey"`
+	Kind           string     `json:"kind" gorm:"column:kind;not null"`
+	Amount         float64    `json:"amount" gorm:"column:amount;type:decimal(20,8);not null"`
+	Items          []string   `json:"items,omitempty" gorm:"column:items;serializer:json"`
+	StartsAt       *time.Time `json:"starts_at,omitempty" gorm:"column:starts_at"`
+	EndsAt         *time.Time `json:"ends_at,omitempty" gorm:"column:ends_at"`
+	MaxUses        int        `json:"max_uses,omitempty" gorm:"column:max_uses;not null"`
+	MaxUsesPerUser int        `json:"max_uses_per_user,omitempty" gorm:"column:max_uses_per_user;not null"`
+	Stackable      bool       `json:"stackable" gorm:"column:stackable;not null"`
+	Active         bool       `json:"active" gorm:"column:active;not null"`
+	CreatedBy      string     `json:"created_by" gorm:"column:created_by;not null"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	Uses           int        `json:"uses" gorm:"column:uses;->;-:migration"`
+}
+
+// Check reports why the code cannot discount the item at now, nil if it can.
+// Usage caps are checked separately, they depend on the redemptions made.
+func (p *PromoCode) Check(item string, now time.Time) error {
+	switch {
+	case !p.Active:
+		return fmt.Errorf("%w: %s is disabled", ErrPromoCodeNotApplicable, p.Code)
+	case p.StartsAt != nil && now.Before(*p.StartsAt):
+		return fmt.Errorf("%w: %s is not valid yet", ErrPromoCodeNotApplicable, p.Code)
+	case p.EndsAt != nil && !now.Before(*p.EndsAt):
+		return fmt.Errorf("%w: %s has ended", ErrPromoCodeNotApplicable, p.Code)
+	case len(p.Items) > 0 && !slices.Contains(p.Items, item):
+		return fmt.Errorf("%w: %s does not apply to %s", ErrPromoCodeNotApplicable, p.Code, item)
+	}
+	return nil
+}
+
+// ApplyPromoCodes discounts the price with the codes and returns the final
+// price with the discount of each code. Percentages apply first, each to the
+// price left by the previous ones, then fixed amounts, and the price never
+// goes below zero. A code that is not stackable must be used alone.
+func ApplyPromoCodes(codes []PromoCode, price float64) (float64, []float64, error) {
+	if len(codes) > 1 {
+		for _, code := range codes {
+			if !code.Stackable {
+				return 0, nil, fmt.Errorf("%w: %s", ErrPromoCodesNotStackable, code.Code)
+			}
+		}
+	}
+
+	discounts := make([]float64, len(codes))
+	for _, kind := range []string{DiscountPercent, DiscountFixed} {
+		for i, code := range codes {
+			if code.Kind != kind {
+				continue
+			}
+			discount := code.Amount
+			if kind == DiscountPercent {
+				discount = price * code.Amount / 100
+			}
+			discounts[i] = min(discount, price)
+			price -= discounts[i]
+		}
+	}
+	return price, discounts, nil
+}
+
+// PromoRedemption is the use of a code by a purchase, or by a purchase
+// waiting for approval, which releases the redemption when it is not
+// approved.
+type PromoRedemption struct {
+	ID           int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Code         string    `json:"code" gorm:"column:code;not null"`
+	Username     string    `json:"username" gorm:"column:username;not null"`
+	Discount     float64   `json:"discount" gorm:"column:discount;type:decimal(20,8);not null"`
+	PurchaseGUID string    `json:"purchase_guid,omitempty" gorm:"column:purchase_guid;not null"`
+	ApprovalID   *int64    `json:"approval_id,omitempty" gorm:"column:approval_id"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromoCode_Check(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	yesterday, tomorrow := now.AddDate(0, 0, -1), now.AddDate(0, 0, 1)
+
+	testTable := []struct {
+		name  string
+		promo PromoCode
+		valid bool
+	}{
+		{name: "AnyItem", promo: PromoCode{Code: "ANY", Active: true}, valid: true},
+		{name: "Disabled", promo: PromoCode{Code: "OFF"}},
+		{name: "InWindow", promo: PromoCode{Code: "WEEK", Active: true, StartsAt: &yesterday, EndsAt: &tomorrow}, valid: true},
+		{name: "NotStarted", promo: PromoCode{Code: "SOON", Active: true, StartsAt: &tomorrow}},
+		{name: "Ended", promo: PromoCode{Code: "OVER", Active: true, EndsAt: &now}},
+		{name: "EligibleItem", promo: PromoCode{Code: "HOODY", Active: true, Items: []string{"hoody", "cup"}}, valid: true},
+		{name: "OtherItem", promo: PromoCode{Code: "PEN", Active: true, Items: []string{"pen"}}},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.promo.Check("hoody", now)
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrPromoCodeNotApplicable)
+			}
+		})
+	}
+}
+
+func TestApplyPromoCodes(t *testing.T) {
+	percent := func(code string, amount float64) PromoCode {
+		return PromoCode{Code: code, Kind: DiscountPercent, Amount: amount, Stackable: true}
+	}
+	fixed := func(code string, amount float64) PromoCode {
+		return PromoCode{Code: code, Kind: DiscountFixed, Amount: amount, Stackable: true}
+	}
+
+	testTable := []struct {
+		name      string
+		codes     []PromoCode
+		price     float64
+		discounts []float64
+	}{
+		{name: "None", codes: nil, price: 300, discounts: []float64{}},
+		{name: "Percent", codes: []PromoCode{percent("A", 20)}, price: 240, discounts: []float64{60}},
+		{name: "Fixed", codes: []PromoCode{fixed("A", 50)}, price: 250, discounts: []float64{50}},
+		{name: "PercentBeforeFixed", codes: []PromoCode{fixed("A", 50), percent("B", 20)}, price: 190, discounts: []float64{50, 60}},
+		{name: "PercentsCompound", codes: []PromoCode{percent("A", 50), percent("B", 50)}, price: 75, discounts: []float64{150, 75}},
+		{name: "NotBelowZero", codes: []PromoCode{fixed("A", 200), fixed("B", 200)}, price: 0, discounts: []float64{200, 100}},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			price, discounts, err := ApplyPromoCodes(test.codes, 300)
+			assert.NoError(t, err)
+			assert.Equal(t, test.price, price)
+			assert.Equal(t, test.discounts, discounts)
+		})
+	}
+
+	solo := PromoCode{Code: "SOLO", Kind: DiscountFixed, Amount: 10}
+	_, _, err := ApplyPromoCodes([]PromoCode{solo}, 300)
+	assert.NoError(t, err)
+	_, _, err = ApplyPromoCodes([]PromoCode{percent("A", 20), solo}, 300)
+	assert.ErrorIs(t, err, ErrPromoCodesNotStackable)
+}
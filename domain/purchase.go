@@ -2,11 +2,36 @@ package domain
 
 import "time"
 
+// Purchase is paid by UserID and delivered to RecipientID, who is the buyer
+// unless the item was bought as a gift. Purchases paid from a team wallet
+// have WalletID set, UserID is then the member who proposed it. UnitPrice
+// is the price of the item at buy time and Total what was paid after the
+// Discount of the PromoCodes used.
 type Purchase struct {
-	GUID      string    `json:"guid" gorm:"column:guid;primaryKey;default:gen_random_uuid()"`
-	UserID    string    `json:"user_id" gorm:"column:user_id;not null;index:idx_user_merch"`
-	User      User      `json:"-" gorm:"foreignKey:UserID;references:Username"`
-	MerchName string    `json:"merch_name" gorm:"column:merch_name;not null;index:idx_user_merch"`
-	Merch     Merch     `json:"-" gorm:"foreignKey:MerchName;references:name"`
-	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	GUID        string    `json:"guid" gorm:"column:guid;primaryKey;default:gen_random_uuid()"`
+	UserID      string    `json:"user_id" gorm:"column:user_id;not null;index:idx_user_merch"`
+	User        User      `json:"-" gorm:"foreignKey:UserID;references:Username"`
+	RecipientID string    `json:"recipient_id,omitempty" gorm:"column:recipient_id;not null"`
+	GiftMessage string    `json:"gift_message,omitempty" gorm:"column:gift_message"`
+	WalletID    *int64    `json:"wallet_id,omitempty" gorm:"column:wallet_id"`
+	MerchName   string    `json:"merch_name" gorm:"column:merch_name;not null;index:idx_user_merch"`
+	Merch       Merch     `json:"-" gorm:"foreignKey:MerchName;references:name"`
+	UnitPrice   float64   `json:"unit_price" gorm:"column:unit_price;type:decimal(20,8);not null"`
+	Total       float64   `json:"total" gorm:"column:total;type:decimal(20,8);not null"`
+	Discount    float64   `json:"discount,omitempty" gorm:"column:discount;type:decimal(20,8);not null"`
+	PromoCodes  []string  `json:"promo_codes,omitempty" gorm:"column:promo_codes;serializer:json"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// Gift names who receives a purchase made for someone else. The zero
+// value means the buyer keeps the item.
+type Gift struct {
+	Recipient string
+	Message   string
+}
+
+// InventoryItem counts the items of one kind a user has received.
+type InventoryItem struct {
+	Type     string `json:"type" gorm:"column:type"`
+	Quantity int    `json:"quantity" gorm:"column:quantity"`
 }
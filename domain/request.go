@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+const (
+	RequestPending   = "pending"
+	RequestAccepted  = "accepted"
+	RequestDeclined  = "declined"
+	RequestCancelled = "cancelled"
+	RequestExpired   = "expired"
+)
+
+// CoinRequest asks Payer to send Amount to Requester. It stays pending until
+// the payer accepts or declines it, the requester cancels it or it expires.
+type CoinRequest struct {
+	ID              int64      `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Requester       string     `json:"requester" gorm:"column:requester;not null"`
+	Payer           string     `json:"payer" gorm:"column:payer;not null"`
+	Amount          float64    `json:"amount" gorm:"column:amount;type:decimal(20,8);not null"`
+	Message         string     `json:"message,omitempty" gorm:"column:message"`
+	Status          string     `json:"status" gorm:"column:status;not null"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	ExpiresAt       time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty" gorm:"column:resolved_at"`
+	TransactionGUID *string    `json:"transaction_guid,omitempty" gorm:"column:transaction_guid;default:null"`
+}
+
+// PendingRequests are the open requests a user has received and sent.
+type PendingRequests struct {
+	Incoming []CoinRequest `json:"incoming"`
+	Outgoing []CoinRequest `json:"outgoing"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+const (
+	ScheduledPending   = "pending"
+	ScheduledExecuted  = "executed"
+	ScheduledFailed    = "failed"
+	ScheduledCancelled = "cancelled"
+)
+
+// ScheduledTransfer is a transfer the sender has set up to be made at
+// ExecuteAt. A transfer that does not pass the usual checks at that time is
+// marked failed with the reason and not retried.
+type ScheduledTransfer struct {
+	ID               int64   `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	SenderUsername   string  `json:"sender_username" gorm:"column:sender_username;not null"`
+	ReceiverUsername string  `json:"receiver_username" gorm:"column:receiver_username;not null"`
+	Amount           float64 `json:"amount" gorm:"column:amount;type:decimal(20,8);not null"`
+	TransferNote     `gorm:"embedded"`
+	Status           string     `json:"status" gorm:"column:status;not null"`
+	ExecuteAt        time.Time  `json:"execute_at" gorm:"column:execute_at;not null"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty" gorm:"column:resolved_at"`
+	TransactionGUID  *string    `json:"transaction_guid,omitempty" gorm:"column:transaction_guid;default:null"`
+	FailureReason    string     `json:"failure_reason,omitempty" gorm:"column:failure_reason"`
+}
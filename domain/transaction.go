@@ -1,9 +1,13 @@
 package domain
 
 import (
+	"slices"
 	"time"
 )
 
+// Categories a transfer can be tagged with.
+var KudosCategories = []string{"teamwork", "help", "mentoring", "innovation", "leadership", "thanks"}
+
 type Transaction struct {
 	GUID             string    `json:"guid" gorm:"column:guid;primaryKey"`
 	CreatedAt        time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
@@ -12,4 +16,32 @@ type Transaction struct {
 	SenderUsername   string    `json:"sender_username" gorm:"column:sender_username;not null"`
 	Sender           User      `json:"-" gorm:"foreignKey:SenderUsername;references:username"`
 	MoneyAmount      float64   `json:"money_amount" gorm:"column:money_amount;type:decimal(20,8);not null"`
+	BatchID          string    `json:"batch_id,omitempty" gorm:"column:batch_id"`
+	TransferNote     `gorm:"embedded"`
+}
+
+// TransferBatch groups the transfers a sender made in one request.
+type TransferBatch struct {
+	ID           string        `json:"batch_id"`
+	Total        float64       `json:"total"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// TransferNote is the optional message sent along with coins. Private
+// transfers are left out of the kudos feed.
+type TransferNote struct {
+	Message  string `json:"message,omitempty" gorm:"column:message"`
+	Category string `json:"category,omitempty" gorm:"column:category"`
+	Private  bool   `json:"private,omitempty" gorm:"column:private"`
+}
+
+func IsKudosCategory(category string) bool {
+	return slices.Contains(KudosCategories, category)
+}
+
+// FeedFilter selects public transfers older than Before, newest first.
+type FeedFilter struct {
+	Category string
+	Before   time.Time
+	Limit    int
 }
@@ -4,10 +4,25 @@ import (
 	"time"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	Username    string    `gorm:"column:username;primaryKey"`
 	Password    string    `json:"-" gorm:"column:password;not null"`
-	Balance     float64   `json:"balance" gorm:"column:balance;type:decimal(20,8);default:1000"`
+	Balance     float64   `json:"balance" gorm:"column:balance;type:decimal(20,8)"`
+	Held        float64   `json:"held" gorm:"column:held;type:decimal(20,8);not null;default:0"`
+	Role        string    `json:"role" gorm:"column:role;not null"`
+	Department  string    `json:"department" gorm:"column:department;not null"`
+	Manager     string    `json:"manager,omitempty" gorm:"column:manager;not null"`
+	Active      bool      `json:"active" gorm:"column:active;not null"`
 	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 	AccessToken string    `json:"-" gorm:"-"`
 }
+
+// Available is the part of the balance that is not reserved by active holds.
+func (u *User) Available() float64 {
+	return u.Balance - u.Held
+}
@@ -0,0 +1,69 @@
+package domain
+
+import "time"
+
+const (
+	WalletRoleOwner  = "owner"
+	WalletRoleMember = "member"
+
+	WalletPurchasePending   = "pending"
+	WalletPurchaseCompleted = "completed"
+	WalletPurchaseRejected  = "rejected"
+)
+
+// Wallet is a team's pooled balance. Members contribute coins from their own
+// balance, purchases from the wallet are proposed by a member and made once
+// an owner approves them, or once Quorum members have approved them when a
+// quorum is set.
+type Wallet struct {
+	ID        int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Name      string    `json:"name" gorm:"column:name;not null"`
+	Balance   float64   `json:"balance" gorm:"column:balance;type:decimal(20,8);not null"`
+	Quorum    int       `json:"quorum" gorm:"column:quorum;not null"`
+	CreatedBy string    `json:"created_by" gorm:"column:created_by;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+type WalletMember struct {
+	WalletID int64     `json:"-" gorm:"column:wallet_id;primaryKey"`
+	Username string    `json:"username" gorm:"column:username;primaryKey"`
+	Role     string    `json:"role" gorm:"column:role;not null"`
+	JoinedAt time.Time `json:"joined_at" gorm:"column:joined_at;autoCreateTime"`
+}
+
+type WalletContribution struct {
+	GUID      string    `json:"guid" gorm:"column:guid;primaryKey;default:gen_random_uuid()"`
+	WalletID  int64     `json:"-" gorm:"column:wallet_id;not null"`
+	Username  string    `json:"username" gorm:"column:username;not null"`
+	Amount    float64   `json:"amount" gorm:"column:amount;type:decimal(20,8);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// WalletPurchase is a purchase proposed from a wallet. Approvals lists the
+// members who approved it, the proposer included.
+type WalletPurchase struct {
+	ID           int64      `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	WalletID     int64      `json:"-" gorm:"column:wallet_id;not null"`
+	MerchName    string     `json:"merch_name" gorm:"column:merch_name;not null"`
+	Price        float64    `json:"price" gorm:"column:price;type:decimal(20,8);not null"`
+	ProposedBy   string     `json:"proposed_by" gorm:"column:proposed_by;not null"`
+	Status       string     `json:"status" gorm:"column:status;not null"`
+	PurchaseGUID string     `json:"purchase_guid,omitempty" gorm:"column:purchase_guid"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty" gorm:"column:resolved_at"`
+	Approvals    []string   `json:"approvals" gorm:"-"`
+}
+
+type WalletApproval struct {
+	WalletPurchaseID int64     `gorm:"column:wallet_purchase_id;primaryKey"`
+	Username         string    `gorm:"column:username;primaryKey"`
+	CreatedAt        time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+// WalletInfo is the wallet's state and history, like /api/info is for a user.
+type WalletInfo struct {
+	Wallet        Wallet               `json:"wallet"`
+	Members       []WalletMember       `json:"members"`
+	Contributions []WalletContribution `json:"contributions"`
+	Purchases     []WalletPurchase     `json:"purchases"`
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// WishlistItem is an item a user saved, with the alerts they subscribed to.
+// SeenPrice and SeenInStock are the state of the item when the user was last
+// alerted, or when they subscribed, so that each change is alerted once.
+type WishlistItem struct {
+	Username        string    `json:"-" gorm:"column:username;primaryKey"`
+	MerchName       string    `json:"item" gorm:"column:merch_name;primaryKey"`
+	NotifyRestock   bool      `json:"notify_restock" gorm:"column:notify_restock;not null"`
+	NotifyPriceDrop bool      `json:"notify_price_drop" gorm:"column:notify_price_drop;not null"`
+	SeenPrice       float64   `json:"-" gorm:"column:seen_price;type:decimal(20,8);not null"`
+	SeenInStock     bool      `json:"-" gorm:"column:seen_in_stock;not null"`
+	CreatedAt       time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	Merch           Merch     `json:"-" gorm:"foreignKey:MerchName;references:Name"`
+}
+
+// Alert returns the notification due for the item, nil if none is, and
+// marks the current price and stock of the item as seen. A restock that
+// comes with a lower price is alerted once, as a restock.
+func (w *WishlistItem) Alert() *Notification {
+	restocked := w.NotifyRestock && !w.SeenInStock && w.Merch.InStock()
+	dropped := w.NotifyPriceDrop && w.Merch.Price < w.SeenPrice
+
+	var notification *Notification
+	switch {
+	case restocked:
+		notification = &Notification{
+			Kind:    NotificationRestock,
+			Message: fmt.Sprintf("%s is back in stock for %v coins", w.MerchName, w.Merch.Price),
+		}
+	case dropped:
+		notification = &Notification{
+			Kind:    NotificationPriceDrop,
+			Message: fmt.Sprintf("%s now costs %v coins instead of %v", w.MerchName, w.Merch.Price, w.SeenPrice),
+		}
+	}
+	if notification != nil {
+		notification.Username = w.Username
+		notification.Reference = w.MerchName
+	}
+
+	w.SeenPrice = w.Merch.Price
+	w.SeenInStock = w.Merch.InStock()
+	return notification
+}
+
+// Wishlist is what a user saved and how many coins they lack to buy it.
+// CoinsToGo of an entry compares its price with the available balance
+// alone, the CoinsToGo of the wishlist is what buying every item would take.
+type Wishlist struct {
+	Available float64         `json:"available"`
+	CoinsToGo float64         `json:"coins_to_go"`
+	Items     []WishlistEntry `json:"items"`
+}
+
+type WishlistEntry struct {
+	Item            string    `json:"item"`
+	Price           float64   `json:"price"`
+	InStock         bool      `json:"in_stock"`
+	CoinsToGo       float64   `json:"coins_to_go"`
+	Affordable      bool      `json:"affordable"`
+	NotifyRestock   bool      `json:"notify_restock"`
+	NotifyPriceDrop bool      `json:"notify_price_drop"`
+	AddedAt         time.Time `json:"added_at"`
+}
+
+// NewWishlist computes the coins to go of the items, whose merch is loaded,
+// from the available balance. An item is affordable when it is in stock and
+// costs no more than the available balance.
+func NewWishlist(available float64, items []WishlistItem) *Wishlist {
+	wishlist := &Wishlist{Available: available, Items: []WishlistEntry{}}
+	total := 0.0
+	for _, item := range items {
+		coinsToGo := max(item.Merch.Price-available, 0)
+		wishlist.Items = append(wishlist.Items, WishlistEntry{
+			Item:            item.MerchName,
+			Price:           item.Merch.Price,
+			InStock:         item.Merch.InStock(),
+			CoinsToGo:       coinsToGo,
+			Affordable:      coinsToGo == 0 && item.Merch.InStock(),
+			NotifyRestock:   item.NotifyRestock,
+			NotifyPriceDrop: item.NotifyPriceDrop,
+			AddedAt:         item.CreatedAt,
+		})
+		total += item.Merch.Price
+	}
+	wishlist.CoinsToGo = max(total-available, 0)
+	return wishlist
+}
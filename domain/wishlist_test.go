@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWishlistItem_Alert(t *testing.T) {
+	zero, five := 0, 5
+
+	testTable := []struct {
+		name     string
+		item     WishlistItem
+		expected string
+	}{
+		{name: "Restocked", item: WishlistItem{NotifyRestock: true, SeenPrice: 300, Merch: Merch{Price: 300, Stock: &five}},
+			expected: "hoody is back in stock for 300 coins"},
+		{name: "RestockedUntracked", item: WishlistItem{NotifyRestock: true, SeenPrice: 300, Merch: Merch{Price: 300}},
+			expected: "hoody is back in stock for 300 coins"},
+		{name: "StillOutOfStock", item: WishlistItem{NotifyRestock: true, SeenPrice: 300, Merch: Merch{Price: 300, Stock: &zero}}},
+		{name: "RestockNotWanted", item: WishlistItem{SeenPrice: 300, Merch: Merch{Price: 300, Stock: &five}}},
+		{name: "PriceDropped", item: WishlistItem{NotifyPriceDrop: true, SeenPrice: 300, SeenInStock: true, Merch: Merch{Price: 250}},
+			expected: "hoody now costs 250 coins instead of 300"},
+		{name: "PriceRose", item: WishlistItem{NotifyPriceDrop: true, SeenPrice: 300, SeenInStock: true, Merch: Merch{Price: 350}}},
+		{name: "RestockedCheaper", item: WishlistItem{NotifyRestock: true, NotifyPriceDrop: true, SeenPrice: 300, Merch: Merch{Price: 250, Stock: &five}},
+			expected: "hoody is back in stock for 250 coins"},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			item := test.item
+			item.Username, item.MerchName = "user1", "hoody"
+			notification := item.Alert()
+			if test.expected == "" {
+				assert.Nil(t, notification)
+			} else if assert.NotNil(t, notification) {
+				assert.Equal(t, test.expected, notification.Message)
+				assert.Equal(t, "user1", notification.Username)
+				assert.Equal(t, "hoody", notification.Reference)
+			}
+			assert.Equal(t, item.Merch.Price, item.SeenPrice)
+			assert.Equal(t, item.Merch.InStock(), item.SeenInStock)
+			assert.Nil(t, item.Alert(), "a change is alerted once")
+		})
+	}
+}
+
+func TestNewWishlist(t *testing.T) {
+	zero := 0
+	wishlist := NewWishlist(400, []WishlistItem{
+		{MerchName: "hoody", Merch: Merch{Price: 300}},
+		{MerchName: "pink-hoody", NotifyPriceDrop: true, Merch: Merch{Price: 500}},
+		{MerchName: "cup", Merch: Merch{Price: 20, Stock: &zero}},
+	})
+
+	assert.Equal(t, 420.0, wishlist.CoinsToGo)
+	assert.Equal(t, []float64{0, 100, 0}, []float64{wishlist.Items[0].CoinsToGo, wishlist.Items[1].CoinsToGo, wishlist.Items[2].CoinsToGo})
+	assert.True(t, wishlist.Items[0].Affordable)
+	assert.False(t, wishlist.Items[1].Affordable)
+	assert.False(t, wishlist.Items[2].Affordable, "items out of stock cannot be bought")
+	assert.True(t, wishlist.Items[1].NotifyPriceDrop)
+
+	assert.Equal(t, 0.0, NewWishlist(1000, nil).CoinsToGo)
+	assert.Empty(t, NewWishlist(1000, nil).Items)
+}
@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"shop/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+
+	maxReasonLength   = 500
+	maxIssuanceRows   = 10000
+	maxIssuanceUpload = 1 << 20
+)
+
+func (h *Handler) AuditLogHandler(c *gin.Context) {
+	filter := domain.AuditFilter{
+		Actor:   c.Query("actor"),
+		Subject: c.Query("subject"),
+		Action:  c.Query("action"),
+		Limit:   defaultAuditLimit,
+	}
+
+	var err error
+	for param, value := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := c.Query(param); raw != "" {
+			if *value, err = time.Parse(time.RFC3339, raw); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + ", expected RFC 3339 time"})
+				return
+			}
+		}
+	}
+	for param, value := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if raw := c.Query(param); raw != "" {
+			if *value, err = strconv.Atoi(raw); err != nil || *value < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+				return
+			}
+		}
+	}
+	if filter.Limit == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	filter.Limit = min(filter.Limit, maxAuditLimit)
+
+	entries, err := h.service.GetAuditLog(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+func (h *Handler) CreditHandler(c *gin.Context) {
+	h.adjustBalance(c, 1)
+}
+
+func (h *Handler) DebitHandler(c *gin.Context) {
+	h.adjustBalance(c, -1)
+}
+
+func (h *Handler) adjustBalance(c *gin.Context, sign float64) {
+	var req struct {
+		Amount float64 `json:"amount"`
+		Reason string  `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	reason, err := validateAdjustment(req.Amount, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adjustment, err := h.service.AdjustBalance(c.Request.Context(), c.Param("username"), sign*req.Amount, reason)
+	if err != nil {
+		adjustmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, adjustment)
+}
+
+// IssuanceHandler credits the users listed in a CSV file with the columns
+// username, amount and reason. The file is sent as the "file" field of a
+// multipart form or as a text/csv body, a header row is optional.
+func (h *Handler) IssuanceHandler(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxIssuanceUpload)
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file"})
+			return
+		}
+		opened, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file"})
+			return
+		}
+		defer opened.Close()
+		body = opened
+	}
+
+	adjustments, err := parseIssuance(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adjustments, err = h.service.IssueCoins(c.Request.Context(), adjustments)
+	if err != nil {
+		adjustmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"adjustments": adjustments})
+}
+
+func parseIssuance(r io.Reader) ([]domain.BalanceAdjustment, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	var adjustments []domain.BalanceAdjustment
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+		if line == 1 && strings.EqualFold(record[0], "username") {
+			continue
+		}
+
+		amount, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid amount %q", line, record[1])
+		}
+		reason, err := validateAdjustment(amount, record[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if record[0] == "" {
+			return nil, fmt.Errorf("line %d: missing username", line)
+		}
+		adjustments = append(adjustments, domain.BalanceAdjustment{Username: record[0], Amount: amount, Reason: reason})
+		if len(adjustments) > maxIssuanceRows {
+			return nil, fmt.Errorf("at most %d rows can be issued at once", maxIssuanceRows)
+		}
+	}
+	if len(adjustments) == 0 {
+		return nil, errors.New("no rows to issue")
+	}
+	return adjustments, nil
+}
+
+func validateAdjustment(amount float64, reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	switch {
+	case amount <= 0:
+		return "", errors.New("amount must be positive")
+	case reason == "":
+		return "", errors.New("reason is required")
+	case len(reason) > maxReasonLength:
+		return "", fmt.Errorf("reason must be at most %d characters", maxReasonLength)
+	}
+	return reason, nil
+}
+
+func adjustmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNoSuchUser):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInsufficientMoney):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"shop/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) UpdateUserHandler(c *gin.Context) {
+	var req struct {
+		Department *string `json:"department"`
+		Manager    *string `json:"manager"`
+		Active     *bool   `json:"active"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Department == nil && req.Manager == nil && req.Active == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.Department != nil {
+		department := strings.TrimSpace(*req.Department)
+		req.Department = &department
+	}
+	if req.Manager != nil && *req.Manager == c.Param("username") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A user cannot be their own manager"})
+		return
+	}
+
+	user, err := h.service.UpdateProfile(c.Request.Context(), c.Param("username"), req.Department, req.Manager, req.Active)
+	if err != nil {
+		adjustmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"username": user.Username, "department": user.Department, "manager": user.Manager, "active": user.Active})
+}
+
+func (h *Handler) CreateAllowanceHandler(c *gin.Context) {
+	var req struct {
+		Name       string  `json:"name"`
+		Schedule   string  `json:"schedule"`
+		Amount     float64 `json:"amount"`
+		Role       string  `json:"role"`
+		Department string  `json:"department"`
+		Paused     bool    `json:"paused"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	switch {
+	case strings.TrimSpace(req.Name) == "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	case req.Amount <= 0:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be positive"})
+		return
+	case req.Role != "" && req.Role != domain.RoleUser && req.Role != domain.RoleAdmin:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be user or admin"})
+		return
+	}
+
+	policy, err := h.service.CreateAllowancePolicy(c.Request.Context(), &domain.AllowancePolicy{
+		Name:       req.Name,
+		Schedule:   req.Schedule,
+		Amount:     req.Amount,
+		Role:       req.Role,
+		Department: strings.TrimSpace(req.Department),
+		Paused:     req.Paused,
+	})
+	if err != nil {
+		allowanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, policy)
+}
+
+func (h *Handler) ListAllowancesHandler(c *gin.Context) {
+	policies, err := h.service.ListAllowancePolicies(c.Request.Context())
+	if err != nil {
+		allowanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policies": policies})
+}
+
+func (h *Handler) PreviewAllowanceHandler(c *gin.Context) {
+	id, ok := policyID(c)
+	if !ok {
+		return
+	}
+	preview, err := h.service.PreviewAllowance(c.Request.Context(), id)
+	if err != nil {
+		allowanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+func (h *Handler) PauseAllowanceHandler(c *gin.Context) {
+	h.setAllowancePaused(c, true)
+}
+
+func (h *Handler) ResumeAllowanceHandler(c *gin.Context) {
+	h.setAllowancePaused(c, false)
+}
+
+func (h *Handler) setAllowancePaused(c *gin.Context, paused bool) {
+	id, ok := policyID(c)
+	if !ok {
+		return
+	}
+	policy, err := h.service.SetAllowancePaused(c.Request.Context(), id, paused)
+	if err != nil {
+		allowanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *Handler) RunAllowanceHandler(c *gin.Context) {
+	id, ok := policyID(c)
+	if !ok {
+		return
+	}
+	run, err := h.service.RunAllowance(c.Request.Context(), id)
+	if err != nil {
+		allowanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+func (h *Handler) AllowanceRunsHandler(c *gin.Context) {
+	id, ok := policyID(c)
+	if !ok {
+		return
+	}
+	runs, err := h.service.ListAllowanceRuns(c.Request.Context(), id)
+	if err != nil {
+		allowanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+func policyID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func allowanceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNoAllowancePolicy):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrAllowanceAlreadyPaid):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		adjustmentError(c, err)
+	}
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"shop/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PurchaseApprovalsHandler lists the pending approvals assigned to the user
+// and the user's own purchases waiting for approval.
+func (h *Handler) PurchaseApprovalsHandler(c *gin.Context) {
+	username, admin := approver(c)
+	approvals, err := h.service.ListPurchaseApprovals(c.Request.Context(), username, admin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, approvals)
+}
+
+// ApprovePurchaseHandler buys the item with the coins held for it.
+func (h *Handler) ApprovePurchaseHandler(c *gin.Context) {
+	id, ok := approvalID(c)
+	if !ok {
+		return
+	}
+	username, admin := approver(c)
+	approval, err := h.service.ApprovePurchase(c.Request.Context(), id, username, admin)
+	if err != nil {
+		approvalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, approval)
+}
+
+// RejectPurchaseHandler releases the coins held for the item.
+func (h *Handler) RejectPurchaseHandler(c *gin.Context) {
+	id, ok := approvalID(c)
+	if !ok {
+		return
+	}
+	username, admin := approver(c)
+	approval, err := h.service.RejectPurchase(c.Request.Context(), id, username, admin)
+	if err != nil {
+		approvalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, approval)
+}
+
+// approver returns the user and whether they can handle any approval.
+func approver(c *gin.Context) (string, bool) {
+	return c.MustGet("username").(string), c.GetString("role") == domain.RoleAdmin
+}
+
+func approvalID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid approval id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func approvalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNoApproval):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrApprovalClosed), errors.Is(err, domain.ErrApprovalExpired), errors.Is(err, domain.ErrOutOfStock):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		transferError(c, err)
+	}
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"shop/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxBatchTransfers = 100
+
+// SendCoinBatchHandler sends coins to many users at once. All transfers are
+// made in one transaction and share the message, either all of them succeed
+// or none.
+func (h *Handler) SendCoinBatchHandler(c *gin.Context) {
+	var req struct {
+		Transfers []struct {
+			ReceiverUsername string  `json:"receiver_username"`
+			Amount           float64 `json:"amount"`
+		} `json:"transfers"`
+		Message  string `json:"message"`
+		Category string `json:"category"`
+		Private  bool   `json:"private"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if len(req.Transfers) == 0 || len(req.Transfers) > maxBatchTransfers {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("transfers must have 1 to %d entries", maxBatchTransfers)})
+		return
+	}
+	note, err := validateNote(req.Message, req.Category, req.Private)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	seen := make(map[string]bool, len(req.Transfers))
+	transfers := make([]domain.Transaction, 0, len(req.Transfers))
+	for i, transfer := range req.Transfers {
+		if transfer.ReceiverUsername == "" || transfer.Amount <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("transfer %d: missing or invalid fields", i+1)})
+			return
+		}
+		if seen[transfer.ReceiverUsername] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("transfer %d: duplicate receiver %s", i+1, transfer.ReceiverUsername)})
+			return
+		}
+		seen[transfer.ReceiverUsername] = true
+		transfers = append(transfers, domain.Transaction{
+			ReceiverUsername: transfer.ReceiverUsername,
+			MoneyAmount:      transfer.Amount,
+			TransferNote:     note,
+		})
+	}
+
+	senderUsername := c.MustGet("username").(string)
+
+	batch, err := h.service.CreateTransactionBatch(c.Request.Context(), senderUsername, transfers)
+	if err != nil {
+		transferError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, batch)
+}
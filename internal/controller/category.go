@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"shop/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) ListCategoriesHandler(c *gin.Context) {
+	categories, err := h.service.ListCategories(c.Request.Context())
+	if err != nil {
+		categoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"categories": categories})
+}
+
+func (h *Handler) CreateCategoryHandler(c *gin.Context) {
+	var req struct {
+		Name  string `json:"name"`
+		Title string `json:"title"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if !isSlug(req.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be lower-case letters, digits and dashes"})
+		return
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+
+	category, err := h.service.CreateCategory(c.Request.Context(), &domain.Category{Name: req.Name, Title: title})
+	if err != nil {
+		categoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *Handler) UpdateCategoryHandler(c *gin.Context) {
+	var req struct {
+		Title string `json:"title"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+
+	category, err := h.service.UpdateCategory(c.Request.Context(), &domain.Category{Name: c.Param("name"), Title: title})
+	if err != nil {
+		categoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *Handler) DeleteCategoryHandler(c *gin.Context) {
+	if err := h.service.DeleteCategory(c.Request.Context(), c.Param("name")); err != nil {
+		categoryError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// isSlug reports whether the category name can be used as is in URLs.
+func isSlug(name string) bool {
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-") {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func categoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNoCategory):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCategoryExists), errors.Is(err, domain.ErrCategoryInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
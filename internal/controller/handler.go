@@ -1,30 +1,117 @@
 package controller
 
 import (
+	"errors"
+	"io"
 	"net/http"
-	"time"
 
+	"shop/domain"
 	"shop/internal/controller/middleware"
 	"shop/internal/usecase"
+	"shop/pkg/config"
+	"shop/pkg/health"
+	"shop/pkg/metrics"
+	"shop/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
 	service usecase.Usecase
+	auth    config.Auth
+	jwt     middleware.JWT
+	health  *health.Checker
 }
 
-func NewHandler(service usecase.Usecase) *Handler {
-	return &Handler{service: service}
+func NewHandler(service usecase.Usecase, auth config.Auth, checker *health.Checker) *Handler {
+	return &Handler{
+		service: service,
+		auth:    auth,
+		jwt:     middleware.JWT{SecretKey: auth.SecretKey, TokenTTL: auth.TokenTTL},
+		health:  checker,
+	}
 }
 
 func (h *Handler) Handle() http.Handler {
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.Recovery(), middleware.RequestID(), tracing.Middleware(), middleware.AccessLog(), metrics.Middleware())
+
+	router.GET("/healthz", h.LivenessHandler)
+	router.GET("/readyz", h.ReadinessHandler)
 
 	router.POST("/api/auth", h.AuthHandler)
-	router.GET("/api/info", middleware.AuthMiddleware(), h.InfoHandler)
-	router.POST("/api/sendCoin", middleware.AuthMiddleware(), h.SendCoinHandler)
-	router.POST("/api/buy/:item", middleware.AuthMiddleware(), h.BuyItemHandler)
+	router.GET("/api/info", middleware.AuthMiddleware(h.jwt), h.InfoHandler)
+	router.POST("/api/sendCoin", middleware.AuthMiddleware(h.jwt), h.SendCoinHandler)
+	router.POST("/api/sendCoin/batch", middleware.AuthMiddleware(h.jwt), h.SendCoinBatchHandler)
+	router.GET("/api/sendCoin/scheduled", middleware.AuthMiddleware(h.jwt), h.ScheduledTransfersHandler)
+	router.POST("/api/sendCoin/scheduled", middleware.AuthMiddleware(h.jwt), h.ScheduleTransferHandler)
+	router.POST("/api/sendCoin/scheduled/:id/cancel", middleware.AuthMiddleware(h.jwt), h.CancelScheduledTransferHandler)
+	router.POST("/api/buy/:item", middleware.AuthMiddleware(h.jwt), h.BuyItemHandler)
+	router.GET("/api/merch", middleware.AuthMiddleware(h.jwt), h.ListMerchHandler)
+	router.GET("/api/merch/:item/prices", middleware.AuthMiddleware(h.jwt), h.PriceTimelineHandler)
+	router.GET("/api/merch/:item/images/:id/thumbnail", middleware.AuthMiddleware(h.jwt), h.ThumbnailHandler)
+	router.GET("/api/categories", middleware.AuthMiddleware(h.jwt), h.ListCategoriesHandler)
+	router.GET("/api/kudos", middleware.AuthMiddleware(h.jwt), h.KudosFeedHandler)
+
+	router.GET("/api/notifications", middleware.AuthMiddleware(h.jwt), h.NotificationsHandler)
+	router.POST("/api/notifications/read", middleware.AuthMiddleware(h.jwt), h.MarkNotificationsReadHandler)
+
+	wishlist := router.Group("/api/wishlist", middleware.AuthMiddleware(h.jwt))
+	wishlist.GET("", h.WishlistHandler)
+	wishlist.PUT("/:item", h.SaveWishlistItemHandler)
+	wishlist.DELETE("/:item", h.RemoveWishlistItemHandler)
+
+	requests := router.Group("/api/requests", middleware.AuthMiddleware(h.jwt))
+	requests.GET("", h.PendingRequestsHandler)
+	requests.POST("", h.CreateCoinRequestHandler)
+	requests.POST("/:id/accept", h.AcceptCoinRequestHandler)
+	requests.POST("/:id/decline", h.DeclineCoinRequestHandler)
+	requests.POST("/:id/cancel", h.CancelCoinRequestHandler)
+
+	approvals := router.Group("/api/approvals", middleware.AuthMiddleware(h.jwt), middleware.LoadRole(h.service))
+	approvals.GET("", h.PurchaseApprovalsHandler)
+	approvals.POST("/:id/approve", h.ApprovePurchaseHandler)
+	approvals.POST("/:id/reject", h.RejectPurchaseHandler)
+
+	wallets := router.Group("/api/wallets", middleware.AuthMiddleware(h.jwt))
+	wallets.GET("", h.ListWalletsHandler)
+	wallets.POST("", h.CreateWalletHandler)
+	wallets.GET("/:id/info", h.WalletInfoHandler)
+	wallets.POST("/:id/members", h.SaveWalletMemberHandler)
+	wallets.DELETE("/:id/members/:username", h.RemoveWalletMemberHandler)
+	wallets.POST("/:id/contribute", h.ContributeHandler)
+	wallets.POST("/:id/purchases", h.ProposeWalletPurchaseHandler)
+	wallets.POST("/:id/purchases/:pid/approve", h.ApproveWalletPurchaseHandler)
+	wallets.POST("/:id/purchases/:pid/reject", h.RejectWalletPurchaseHandler)
+
+	admin := router.Group("/api/admin", middleware.AuthMiddleware(h.jwt), middleware.RequireRole(h.service, domain.RoleAdmin))
+	admin.GET("/audit", h.AuditLogHandler)
+	admin.POST("/users/:username/credit", h.CreditHandler)
+	admin.POST("/users/:username/debit", h.DebitHandler)
+	admin.POST("/issuance", h.IssuanceHandler)
+	admin.PATCH("/users/:username", h.UpdateUserHandler)
+	admin.GET("/users/:username/balance", h.BalanceHandler)
+	admin.POST("/holds", h.CreateHoldHandler)
+	admin.POST("/holds/:id/capture", h.CaptureHoldHandler)
+	admin.POST("/holds/:id/release", h.ReleaseHoldHandler)
+	admin.POST("/merch", h.CreateMerchHandler)
+	admin.PATCH("/merch/:item", h.UpdateMerchHandler)
+	admin.POST("/merch/:item/images", h.UploadMerchImageHandler)
+	admin.DELETE("/merch/:item/images/:id", h.DeleteMerchImageHandler)
+	admin.POST("/categories", h.CreateCategoryHandler)
+	admin.PATCH("/categories/:name", h.UpdateCategoryHandler)
+	admin.DELETE("/categories/:name", h.DeleteCategoryHandler)
+	admin.GET("/promos", h.ListPromoCodesHandler)
+	admin.POST("/promos", h.CreatePromoCodeHandler)
+	admin.POST("/promos/:code/disable", h.DisablePromoCodeHandler)
+	admin.POST("/promos/:code/enable", h.EnablePromoCodeHandler)
+	admin.GET("/allowances", h.ListAllowancesHandler)
+	admin.POST("/allowances", h.CreateAllowanceHandler)
+	admin.GET("/allowances/:id/preview", h.PreviewAllowanceHandler)
+	admin.POST("/allowances/:id/pause", h.PauseAllowanceHandler)
+	admin.POST("/allowances/:id/resume", h.ResumeAllowanceHandler)
+	admin.POST("/allowances/:id/run", h.RunAllowanceHandler)
+	admin.GET("/allowances/:id/runs", h.AllowanceRunsHandler)
 
 	router.NoRoute(func(c *gin.Context) {
 		c.JSON(http.StatusNotImplemented,
@@ -47,22 +134,22 @@ func (h *Handler) AuthHandler(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.Auth(req.Username, req.Password)
+	user, err := h.service.Auth(c.Request.Context(), req.Username, req.Password)
 	if err != nil {
-		if err.Error() != "invalid password" {
+		if !errors.Is(err, domain.ErrInvalidPassword) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	token, err := middleware.JWT{}.GenerateToken(user)
+	token, err := h.jwt.GenerateToken(user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	expirationTime := time.Now().Add(5 * time.Hour)
-	c.SetCookie("accessToken", token, int(expirationTime.Unix()), "/", "localhost", false, false)
+	c.SetCookie("accessToken", token, int(h.auth.TokenTTL.Seconds()), "/", h.auth.CookieDomain, h.auth.CookieSecure, false)
 
 	c.JSON(http.StatusOK, gin.H{"response": gin.H{"accessToken": token}})
 }
@@ -71,6 +158,9 @@ func (h *Handler) SendCoinHandler(c *gin.Context) {
 	var req struct {
 		ReceiverUsername string  `json:"receiver_username"`
 		Amount           float64 `json:"amount"`
+		Message          string  `json:"message"`
+		Category         string  `json:"category"`
+		Private          bool    `json:"private"`
 	}
 	if err := c.ShouldBind(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
@@ -80,22 +170,51 @@ func (h *Handler) SendCoinHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid fields"})
 		return
 	}
+	note, err := validateNote(req.Message, req.Category, req.Private)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	senderUsername := c.MustGet("username").(string)
 
-	transaction, err := h.service.CreateTransaction(req.ReceiverUsername, senderUsername, req.Amount)
+	transaction, err := h.service.CreateTransaction(c.Request.Context(), req.ReceiverUsername, senderUsername, req.Amount, note)
 	if err != nil {
-		if err.Error() == "insufficient money" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		transferError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, transaction)
 }
 
+// transferError writes the response for an error of a transfer, limit errors
+// carry the limit and what is left of it.
+func transferError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrInsufficientMoney) || errors.Is(err, domain.ErrNoSuchUser) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var limitErr *domain.TransferLimitError
+	if errors.As(err, &limitErr) {
+		response := gin.H{
+			"error":     err.Error(),
+			"code":      limitErr.Code,
+			"limit":     limitErr.Limit,
+			"remaining": limitErr.Remaining,
+		}
+		if limitErr.ResetsAt != nil {
+			response["resets_at"] = limitErr.ResetsAt
+		}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// BuyItemHandler buys an item for the user. With a recipient in the
+// optional body the item is a gift delivered to that user, promo codes in it
+// discount the price. Items that need the manager's approval are answered
+// with 202 and the pending approval.
 func (h *Handler) BuyItemHandler(c *gin.Context) {
 	itemName := c.Param("item")
 	username := c.MustGet("username").(string)
@@ -104,15 +223,58 @@ func (h *Handler) BuyItemHandler(c *gin.Context) {
 		return
 	}
 
-	purchase, err := h.service.CreatePurchase(username, itemName)
+	var req struct {
+		Recipient  string   `json:"recipient"`
+		Message    string   `json:"message"`
+		PromoCode  string   `json:"promo_code"`
+		PromoCodes []string `json:"promo_codes"`
+	}
+	if c.Request.Body != nil {
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+	var gift domain.Gift
+	if req.Recipient != "" {
+		if req.Recipient == username {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot send a gift to yourself"})
+			return
+		}
+		note, err := validateNote(req.Message, "", false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		gift = domain.Gift{Recipient: req.Recipient, Message: note.Message}
+	} else if req.Message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "message requires a recipient"})
+		return
+	}
+
+	codes, err := promoCodes(req.PromoCode, req.PromoCodes)
 	if err != nil {
-		if err.Error() == "insufficient money" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	purchase, approval, err := h.service.CreatePurchase(c.Request.Context(), username, itemName, gift, codes)
+	if err != nil {
+		if errors.Is(err, domain.ErrInsufficientMoney) || errors.Is(err, domain.ErrNoSuchUser) || isPromoError(err) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, domain.ErrOutOfStock) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if approval != nil {
+		c.JSON(http.StatusAccepted, approval)
+		return
+	}
 
 	c.JSON(http.StatusOK, purchase)
 }
@@ -123,20 +285,65 @@ func (h *Handler) InfoHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Username is empty"})
 	}
 
-	purchases, err := h.service.GetPurchasesForUserByUsername(username)
+	purchases, err := h.service.GetPurchasesForUserByUsername(c.Request.Context(), username)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	transactions, err := h.service.GetTransactionsForUserByUsername(username)
+	transactions, err := h.service.GetTransactionsForUserByUsername(c.Request.Context(), username)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	adjustments, err := h.service.GetAdjustmentsForUserByUsername(c.Request.Context(), username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	expirations, err := h.service.GetCoinExpirations(c.Request.Context(), username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	inventory, err := h.service.GetInventory(c.Request.Context(), username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	balance, err := h.service.GetBalance(c.Request.Context(), username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"balance":      balance,
+		"inventory":    inventory,
 		"purchases":    purchases,
 		"transactions": transactions,
-	})
+		"adjustments":  adjustments,
+	}
+	// Upcoming expirations are shown only when coin expiry is enabled.
+	if expirations != nil {
+		response["expirations"] = expirations
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) LivenessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, h.health.Liveness(c.Request.Context()))
+}
+
+func (h *Handler) ReadinessHandler(c *gin.Context) {
+	report := h.health.Readiness(c.Request.Context())
+	if report.Status != health.StatusOK {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
 }
@@ -2,24 +2,34 @@ package controller
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"shop/domain"
 	"shop/internal/controller/middleware"
 	mockusecase "shop/internal/usecase/mocks"
+	"shop/pkg/config"
+	"shop/pkg/health"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
+var testAuth = config.Auth{SecretKey: "secret", TokenTTL: time.Hour, CookieDomain: "localhost"}
+
+var testJWT = middleware.JWT{SecretKey: testAuth.SecretKey, TokenTTL: testAuth.TokenTTL}
+
 func setupRouter() *gin.Engine {
 	usecaseMock := new(mockusecase.MockUsecase)
-	handler := NewHandler(usecaseMock)
+	handler := NewHandler(usecaseMock, testAuth, health.NewChecker(time.Second))
 	return handler.Handle().(*gin.Engine)
 }
 
@@ -71,15 +81,20 @@ func TestInfoHandler_Success_EmptyBody(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := mockusecase.NewMockUsecase(ctrl)
-	h := NewHandler(mockUsecase)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/info", nil)
 	c.Set("username", "test")
 
-	mockUsecase.EXPECT().GetPurchasesForUserByUserGUID("test").Return([]domain.Purchase{}, nil)
-	mockUsecase.EXPECT().GetTransactionsForUserByUserGUID("test").Return([]domain.Transaction{}, nil)
-	expectedResponseBody := `{"purchases":[],"transactions":[]}`
+	mockUsecase.EXPECT().GetPurchasesForUserByUsername(gomock.Any(), "test").Return([]domain.Purchase{}, nil)
+	mockUsecase.EXPECT().GetTransactionsForUserByUsername(gomock.Any(), "test").Return([]domain.Transaction{}, nil)
+	mockUsecase.EXPECT().GetAdjustmentsForUserByUsername(gomock.Any(), "test").Return([]domain.BalanceAdjustment{}, nil)
+	mockUsecase.EXPECT().GetCoinExpirations(gomock.Any(), "test").Return(nil, nil)
+	mockUsecase.EXPECT().GetInventory(gomock.Any(), "test").Return([]domain.InventoryItem{}, nil)
+	mockUsecase.EXPECT().GetBalance(gomock.Any(), "test").Return(&domain.Balance{Holds: []domain.Hold{}}, nil)
+	expectedResponseBody := `{"adjustments":[],"balance":{"balance":0,"held":0,"available":0,"holds":[]},"inventory":[],"purchases":[],"transactions":[]}`
 	h.InfoHandler(c)
 
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -91,18 +106,24 @@ func TestInfoHandler_Success_WithBody(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := mockusecase.NewMockUsecase(ctrl)
-	h := NewHandler(mockUsecase)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/info", nil)
 	c.Set("username", "test")
 
-	purchase := domain.Purchase{GUID: "1", UserID: "user1", MerchName: "socks", CreatedAt: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)}
+	purchase := domain.Purchase{GUID: "1", UserID: "user1", MerchName: "socks", UnitPrice: 10, Total: 10, CreatedAt: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)}
 	transaction := domain.Transaction{GUID: "1", ReceiverUsername: "user2", SenderUsername: "user1", MoneyAmount: 100, CreatedAt: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)}
-
-	mockUsecase.EXPECT().GetPurchasesForUserByUserGUID("test").Return([]domain.Purchase{purchase}, nil)
-	mockUsecase.EXPECT().GetTransactionsForUserByUserGUID("test").Return([]domain.Transaction{transaction}, nil)
-	expectedResponseBody := `{"purchases":[{"guid":"1","user_id":"user1","merch_name":"socks","created_at":"0001-01-01T00:00:00Z"}],"transactions":[{"guid":"1","created_at":"0001-01-01T00:00:00Z","receiver_username":"user2","sender_username":"user1","money_amount":100}]}`
+	adjustment := domain.BalanceAdjustment{GUID: "1", Username: "test", Amount: 50, Reason: "birthday", Actor: "admin", CreatedAt: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	mockUsecase.EXPECT().GetPurchasesForUserByUsername(gomock.Any(), "test").Return([]domain.Purchase{purchase}, nil)
+	mockUsecase.EXPECT().GetTransactionsForUserByUsername(gomock.Any(), "test").Return([]domain.Transaction{transaction}, nil)
+	mockUsecase.EXPECT().GetAdjustmentsForUserByUsername(gomock.Any(), "test").Return([]domain.BalanceAdjustment{adjustment}, nil)
+	mockUsecase.EXPECT().GetCoinExpirations(gomock.Any(), "test").Return(nil, nil)
+	mockUsecase.EXPECT().GetInventory(gomock.Any(), "test").Return([]domain.InventoryItem{{Type: "socks", Quantity: 1}}, nil)
+	mockUsecase.EXPECT().GetBalance(gomock.Any(), "test").Return(&domain.Balance{Balance: 150, Held: 30, Available: 120, Holds: []domain.Hold{}}, nil)
+	expectedResponseBody := `{"adjustments":[{"guid":"1","created_at":"0001-01-01T00:00:00Z","username":"test","amount":50,"reason":"birthday","actor":"admin"}],"balance":{"balance":150,"held":30,"available":120,"holds":[]},"inventory":[{"type":"socks","quantity":1}],"purchases":[{"guid":"1","user_id":"user1","merch_name":"socks","unit_price":10,"total":10,"created_at":"0001-01-01T00:00:00Z"}],"transactions":[{"guid":"1","created_at":"0001-01-01T00:00:00Z","receiver_username":"user2","sender_username":"user1","money_amount":100}]}`
 	h.InfoHandler(c)
 
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -114,13 +135,14 @@ func TestInfoHandler_InternalServerError_Purchases(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := mockusecase.NewMockUsecase(ctrl)
-	h := NewHandler(mockUsecase)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/info", nil)
 	c.Set("username", "test")
 
-	mockUsecase.EXPECT().GetPurchasesForUserByUserGUID("test").Return([]domain.Purchase{}, errors.New("db error"))
+	mockUsecase.EXPECT().GetPurchasesForUserByUsername(gomock.Any(), "test").Return([]domain.Purchase{}, errors.New("db error"))
 	expectedResponseBody := `{"error":"db error"}`
 	h.InfoHandler(c)
 
@@ -133,14 +155,15 @@ func TestInfoHandler_InternalServerError_Transactions(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := mockusecase.NewMockUsecase(ctrl)
-	h := NewHandler(mockUsecase)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/info", nil)
 	c.Set("username", "test")
 
-	mockUsecase.EXPECT().GetPurchasesForUserByUserGUID("test").Return([]domain.Purchase{}, nil)
-	mockUsecase.EXPECT().GetTransactionsForUserByUserGUID("test").Return([]domain.Transaction{}, errors.New("db error"))
+	mockUsecase.EXPECT().GetPurchasesForUserByUsername(gomock.Any(), "test").Return([]domain.Purchase{}, nil)
+	mockUsecase.EXPECT().GetTransactionsForUserByUsername(gomock.Any(), "test").Return([]domain.Transaction{}, errors.New("db error"))
 	expectedResponseBody := `{"error":"db error"}`
 	h.InfoHandler(c)
 
@@ -153,7 +176,7 @@ func TestSendCoinHandler_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := mockusecase.NewMockUsecase(ctrl)
-	h := NewHandler(mockUsecase)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -162,7 +185,7 @@ func TestSendCoinHandler_Success(t *testing.T) {
 	c.Set("username", "sender")
 
 	transaction := domain.Transaction{GUID: "1", ReceiverUsername: "user2", SenderUsername: "user1", MoneyAmount: 10.0, CreatedAt: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)}
-	mockUsecase.EXPECT().CreateTransaction("receiver", "sender", 10.0).Return(&transaction, nil)
+	mockUsecase.EXPECT().CreateTransaction(gomock.Any(), "receiver", "sender", 10.0, domain.TransferNote{}).Return(&transaction, nil)
 	expectedResponseBody := `{"guid":"1","created_at":"0001-01-01T00:00:00Z","receiver_username":"user2","sender_username":"user1","money_amount":10}`
 
 	h.SendCoinHandler(c)
@@ -175,7 +198,7 @@ func TestSendCoinHandler_InternalServerError(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := mockusecase.NewMockUsecase(ctrl)
-	h := NewHandler(mockUsecase)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -183,7 +206,7 @@ func TestSendCoinHandler_InternalServerError(t *testing.T) {
 	c.Request.Header.Set("Content-Type", "application/json")
 	c.Set("username", "sender")
 
-	mockUsecase.EXPECT().CreateTransaction("receiver", "sender", 10.0).Return(nil, errors.New("db error"))
+	mockUsecase.EXPECT().CreateTransaction(gomock.Any(), "receiver", "sender", 10.0, domain.TransferNote{}).Return(nil, errors.New("db error"))
 	expectedResponseBody := `{"error":"db error"}`
 
 	h.SendCoinHandler(c)
@@ -196,7 +219,7 @@ func TestSendCoinHandler_InsufficientFunds(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := mockusecase.NewMockUsecase(ctrl)
-	h := NewHandler(mockUsecase)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -204,7 +227,7 @@ func TestSendCoinHandler_InsufficientFunds(t *testing.T) {
 	c.Request.Header.Set("Content-Type", "application/json")
 	c.Set("username", "sender")
 
-	mockUsecase.EXPECT().CreateTransaction("receiver", "sender", 10.0).Return(nil, errors.New("insufficient money"))
+	mockUsecase.EXPECT().CreateTransaction(gomock.Any(), "receiver", "sender", 10.0, domain.TransferNote{}).Return(nil, domain.ErrInsufficientMoney)
 	expectedResponseBody := `{"error":"insufficient money"}`
 
 	h.SendCoinHandler(c)
@@ -212,10 +235,41 @@ func TestSendCoinHandler_InsufficientFunds(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+func TestSendCoinHandler_TransferLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/api/sendCoin", bytes.NewBufferString(`{"receiver_username":"receiver","amount":10.0}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("username", "sender")
+		h.SendCoinHandler(c)
+		return w
+	}
+
+	resetsAt := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	mockUsecase.EXPECT().CreateTransaction(gomock.Any(), "receiver", "sender", 10.0, domain.TransferNote{}).
+		Return(nil, &domain.TransferLimitError{Code: domain.LimitDailyAmount, Limit: 100, Remaining: 5, ResetsAt: &resetsAt})
+	w := send()
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"transfer limit exceeded: daily_amount_limit","code":"daily_amount_limit","limit":100,"remaining":5,"resets_at":"2026-10-20T00:00:00Z"}`, w.Body.String())
+
+	mockUsecase.EXPECT().CreateTransaction(gomock.Any(), "receiver", "sender", 10.0, domain.TransferNote{}).
+		Return(nil, &domain.TransferLimitError{Code: domain.LimitTransferAmount, Limit: 5, Remaining: 5})
+	w = send()
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"transfer limit exceeded: transfer_amount_limit","code":"transfer_amount_limit","limit":5,"remaining":5}`, w.Body.String())
+}
+
 func TestSendCoinHandler_BadRequest_MissingFields(t *testing.T) {
 	router := setupRouter()
 	user := domain.User{Username: "test", Password: "test"}
-	validToken, err := middleware.JWT{}.GenerateToken(&user)
+	validToken, err := testJWT.GenerateToken(&user)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -234,7 +288,7 @@ func TestSendCoinHandler_BadRequest_MissingFields(t *testing.T) {
 func TestSendCoinHandler_BadRequest_WrongFieldNames(t *testing.T) {
 	router := setupRouter()
 	user := domain.User{Username: "test", Password: "test"}
-	validToken, err := middleware.JWT{}.GenerateToken(&user)
+	validToken, err := testJWT.GenerateToken(&user)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -255,16 +309,17 @@ func TestBuyItemHandler_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := mockusecase.NewMockUsecase(ctrl)
-	h := NewHandler(mockUsecase)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/api/buy/sock", nil)
 	c.Params = append(c.Params, gin.Param{Key: "item", Value: "sock"})
 	c.Set("username", "buyer")
 
-	purchase := domain.Purchase{GUID: "1", UserID: "1", MerchName: "1", CreatedAt: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)}
-	mockUsecase.EXPECT().CreatePurchase("buyer", "sock").Return(&purchase, nil)
-	expectedResponseBody := `{"guid":"1","user_id":"1","merch_name":"1","created_at":"0001-01-01T00:00:00Z"}`
+	purchase := domain.Purchase{GUID: "1", UserID: "1", MerchName: "1", UnitPrice: 1, Total: 1, CreatedAt: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)}
+	mockUsecase.EXPECT().CreatePurchase(gomock.Any(), "buyer", "sock", domain.Gift{}, gomock.Nil()).Return(&purchase, nil, nil)
+	expectedResponseBody := `{"guid":"1","user_id":"1","merch_name":"1","unit_price":1,"total":1,"created_at":"0001-01-01T00:00:00Z"}`
 
 	h.BuyItemHandler(c)
 
@@ -277,14 +332,15 @@ func TestBuyItemHandler_InternalServerError(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := mockusecase.NewMockUsecase(ctrl)
-	h := NewHandler(mockUsecase)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/api/buy/sock", nil)
 	c.Params = append(c.Params, gin.Param{Key: "item", Value: "sock"})
 	c.Set("username", "buyer")
 
-	mockUsecase.EXPECT().CreatePurchase("buyer", "sock").Return(nil, errors.New("db error"))
+	mockUsecase.EXPECT().CreatePurchase(gomock.Any(), "buyer", "sock", domain.Gift{}, gomock.Nil()).Return(nil, nil, errors.New("db error"))
 	expectedResponseBody := `{"error":"db error"}`
 
 	h.BuyItemHandler(c)
@@ -298,7 +354,7 @@ func TestBuyItemHandler_BadRequest_InsufficientBalance(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := mockusecase.NewMockUsecase(ctrl)
-	h := NewHandler(mockUsecase)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -309,7 +365,7 @@ func TestBuyItemHandler_BadRequest_InsufficientBalance(t *testing.T) {
 		{Key: "item", Value: "socks"},
 	}
 
-	mockUsecase.EXPECT().CreatePurchase("buyer", "socks").Return(nil, errors.New("insufficient money"))
+	mockUsecase.EXPECT().CreatePurchase(gomock.Any(), "buyer", "socks", domain.Gift{}, gomock.Nil()).Return(nil, nil, domain.ErrInsufficientMoney)
 	expectedResponseBody := `{"error":"insufficient money"}`
 
 	h.BuyItemHandler(c)
@@ -322,7 +378,7 @@ func TestAuthHandler(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := mockusecase.NewMockUsecase(ctrl)
-	h := NewHandler(mockUsecase)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
 
 	t.Run("Invalid JSON Request", func(t *testing.T) {
 		w := httptest.NewRecorder()
@@ -355,7 +411,7 @@ func TestAuthHandler(t *testing.T) {
 	})
 
 	t.Run("Authentication Failure", func(t *testing.T) {
-		mockUsecase.EXPECT().Auth("user1", "user1user1").Return(nil, errors.New("invalid password"))
+		mockUsecase.EXPECT().Auth(gomock.Any(), "user1", "user1user1").Return(nil, domain.ErrInvalidPassword)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -369,9 +425,9 @@ func TestAuthHandler(t *testing.T) {
 
 	t.Run("Successful Authentication", func(t *testing.T) {
 		mockUser := &domain.User{Username: "user1", Password: "user1"}
-		mockUsecase.EXPECT().Auth("user1", "user1").Return(mockUser, nil)
+		mockUsecase.EXPECT().Auth(gomock.Any(), "user1", "user1").Return(mockUser, nil)
 
-		mockToken, err := middleware.JWT{}.GenerateToken(mockUser)
+		mockToken, err := testJWT.GenerateToken(mockUser)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -386,3 +442,1124 @@ func TestAuthHandler(t *testing.T) {
 		assert.JSONEq(t, `{"response": {"accessToken": "`+mockToken+`"}}`, w.Body.String())
 	})
 }
+
+func TestHealthHandlers(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	databaseErr := errors.New("connection refused")
+	checker.AddReadinessCheck("database", func(context.Context) (map[string]any, error) {
+		return nil, databaseErr
+	})
+	router := NewHandler(new(mockusecase.MockUsecase), testAuth, checker).Handle()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"ok"`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"status":"fail","components":{"database":{"status":"fail","error":"connection refused"},"shutdown":{"status":"ok"}}}`, w.Body.String())
+
+	databaseErr = nil
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	checker.SetDraining()
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"status":"fail","components":{"database":{"status":"ok"},"shutdown":{"status":"fail","error":"server is shutting down"}}}`, w.Body.String())
+}
+
+// expectRoles reports admin as the only admin, whatever the tokens say.
+func expectRoles(mockUsecase *mockusecase.MockUsecase) {
+	mockUsecase.EXPECT().GetRole(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, username string) (string, error) {
+		if username == "admin" {
+			return domain.RoleAdmin, nil
+		}
+		return domain.RoleUser, nil
+	}).AnyTimes()
+}
+
+func TestAuditLogHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	expectRoles(mockUsecase)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+
+	request := func(user *domain.User, query string) *httptest.ResponseRecorder {
+		token, err := testJWT.GenerateToken(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/audit"+query, nil)
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	admin := &domain.User{Username: "admin", Role: domain.RoleAdmin}
+
+	w := request(&domain.User{Username: "user1", Role: domain.RoleUser}, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"error": "forbidden"}`, w.Body.String())
+	// The token of a demoted admin still says admin.
+	w = request(&domain.User{Username: "former", Role: domain.RoleAdmin}, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUsecase.EXPECT().GetAuditLog(gomock.Any(), domain.AuditFilter{
+		Subject: "user1",
+		Action:  domain.AuditTransfer,
+		From:    from,
+		Limit:   maxAuditLimit,
+	}).Return([]domain.AuditEntry{{ID: 1, Action: domain.AuditTransfer, Actor: "user1", Subject: "user1"}}, nil)
+	w = request(admin, "?subject=user1&action=coins.transfer&from=2025-01-01T00:00:00Z&limit=5000")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"action":"coins.transfer"`)
+
+	w = request(admin, "?from=yesterday")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(admin, "?limit=0")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdjustBalanceHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	expectRoles(mockUsecase)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	token, err := testJWT.GenerateToken(&domain.User{Username: "admin", Role: domain.RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	mockUsecase.EXPECT().AdjustBalance(gomock.Any(), "user1", 100.0, "hackathon").
+		Return(&domain.BalanceAdjustment{Username: "user1", Amount: 100, Reason: "hackathon"}, nil)
+	w := request("/api/admin/users/user1/credit", `{"amount": 100, "reason": " hackathon "}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockUsecase.EXPECT().AdjustBalance(gomock.Any(), "user1", -30.0, "clawback").Return(nil, domain.ErrInsufficientMoney)
+	w = request("/api/admin/users/user1/debit", `{"amount": 30, "reason": "clawback"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "insufficient money"}`, w.Body.String())
+
+	mockUsecase.EXPECT().AdjustBalance(gomock.Any(), "ghost", 10.0, "bonus").Return(nil, fmt.Errorf("%w: ghost", domain.ErrNoSuchUser))
+	w = request("/api/admin/users/ghost/credit", `{"amount": 10, "reason": "bonus"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = request("/api/admin/users/user1/credit", `{"amount": 10}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "reason is required"}`, w.Body.String())
+	w = request("/api/admin/users/user1/debit", `{"amount": -10, "reason": "bonus"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestIssuanceHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	expectRoles(mockUsecase)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	token, err := testJWT.GenerateToken(&domain.User{Username: "admin", Role: domain.RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issued := []domain.BalanceAdjustment{
+		{Username: "user1", Amount: 100, Reason: "birthday"},
+		{Username: "user2", Amount: 50.5, Reason: "hackathon, 2nd place"},
+	}
+	mockUsecase.EXPECT().IssueCoins(gomock.Any(), issued).Return(issued, nil)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, _ := form.CreateFormFile("file", "bonuses.csv")
+	file.Write([]byte("username,amount,reason\nuser1,100,birthday\nuser2,50.5,\"hackathon, 2nd place\"\n"))
+	form.Close()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/issuance", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/issuance", bytes.NewBufferString("user1,100,birthday\nuser2,ten,bonus\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "line 2: invalid amount \"ten\""}`, w.Body.String())
+}
+
+func TestAllowanceHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	expectRoles(mockUsecase)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	token, err := testJWT.GenerateToken(&domain.User{Username: "admin", Role: domain.RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	policy := &domain.AllowancePolicy{Name: "monthly", Schedule: "0 9 1 * *", Amount: 100, Department: "sales"}
+	mockUsecase.EXPECT().CreateAllowancePolicy(gomock.Any(), policy).Return(policy, nil)
+	w := request(http.MethodPost, "/api/admin/allowances", `{"name": "monthly", "schedule": "0 9 1 * *", "amount": 100, "department": " sales "}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	mockUsecase.EXPECT().CreateAllowancePolicy(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w: bad field", domain.ErrInvalidSchedule))
+	w = request(http.MethodPost, "/api/admin/allowances", `{"name": "monthly", "schedule": "monthly", "amount": 100}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request(http.MethodPost, "/api/admin/allowances", `{"name": "monthly", "schedule": "0 9 1 * *", "amount": 0}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "amount must be positive"}`, w.Body.String())
+	w = request(http.MethodPost, "/api/admin/allowances", `{"name": "monthly", "schedule": "0 9 1 * *", "amount": 1, "role": "boss"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUsecase.EXPECT().SetAllowancePaused(gomock.Any(), int64(1), true).Return(&domain.AllowancePolicy{ID: 1, Paused: true}, nil)
+	w = request(http.MethodPost, "/api/admin/allowances/1/pause", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockUsecase.EXPECT().RunAllowance(gomock.Any(), int64(1)).Return(nil, domain.ErrAllowanceAlreadyPaid)
+	w = request(http.MethodPost, "/api/admin/allowances/1/run", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockUsecase.EXPECT().PreviewAllowance(gomock.Any(), int64(2)).Return(nil, domain.ErrNoAllowancePolicy)
+	w = request(http.MethodGet, "/api/admin/allowances/2/preview", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = request(http.MethodGet, "/api/admin/allowances/abc/runs", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateUserHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	expectRoles(mockUsecase)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	token, err := testJWT.GenerateToken(&domain.User{Username: "admin", Role: domain.RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	inactive := false
+	mockUsecase.EXPECT().UpdateProfile(gomock.Any(), "user1", nil, nil, &inactive).
+		Return(&domain.User{Username: "user1", Department: "sales"}, nil)
+	w := request("/api/admin/users/user1", `{"active": false}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"username": "user1", "department": "sales", "manager": "", "active": false}`, w.Body.String())
+
+	mockUsecase.EXPECT().UpdateProfile(gomock.Any(), "ghost", gomock.Any(), nil, nil).Return(nil, domain.ErrNoSuchUser)
+	w = request("/api/admin/users/ghost", `{"department": "sales"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = request("/api/admin/users/user1", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	manager := "boss"
+	mockUsecase.EXPECT().UpdateProfile(gomock.Any(), "user1", nil, &manager, nil).
+		Return(&domain.User{Username: "user1", Manager: "boss", Active: true}, nil)
+	w = request("/api/admin/users/user1", `{"manager": "boss"}`)
+	assert.JSONEq(t, `{"username": "user1", "department": "", "manager": "boss", "active": true}`, w.Body.String())
+	w = request("/api/admin/users/user1", `{"manager": "user1"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestInfoHandler_Expirations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/info", nil)
+	c.Set("username", "test")
+
+	expiration := domain.CoinExpiration{Amount: 120, ExpiresAt: time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC)}
+	mockUsecase.EXPECT().GetPurchasesForUserByUsername(gomock.Any(), "test").Return([]domain.Purchase{}, nil)
+	mockUsecase.EXPECT().GetTransactionsForUserByUsername(gomock.Any(), "test").Return([]domain.Transaction{}, nil)
+	mockUsecase.EXPECT().GetAdjustmentsForUserByUsername(gomock.Any(), "test").Return([]domain.BalanceAdjustment{}, nil)
+	mockUsecase.EXPECT().GetCoinExpirations(gomock.Any(), "test").Return([]domain.CoinExpiration{expiration}, nil)
+	mockUsecase.EXPECT().GetInventory(gomock.Any(), "test").Return([]domain.InventoryItem{}, nil)
+	mockUsecase.EXPECT().GetBalance(gomock.Any(), "test").Return(&domain.Balance{Holds: []domain.Hold{}}, nil)
+	h.InfoHandler(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"adjustments":[],"balance":{"balance":0,"held":0,"available":0,"holds":[]},"inventory":[],"purchases":[],"transactions":[],"expirations":[{"amount":120,"expires_at":"2027-01-15T00:00:00Z"}]}`, w.Body.String())
+}
+
+func TestSendCoinHandler_Note(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
+	send := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/api/sendCoin", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("username", "sender")
+		h.SendCoinHandler(c)
+		return w
+	}
+
+	note := domain.TransferNote{Message: "Thanks for the review!", Category: "help", Private: true}
+	mockUsecase.EXPECT().CreateTransaction(gomock.Any(), "receiver", "sender", 10.0, note).
+		Return(&domain.Transaction{MoneyAmount: 10, TransferNote: note}, nil)
+	w := send(`{"receiver_username":"receiver","amount":10,"message":"  <b>Thanks</b> for the‮ review!\u0007 ","category":"help","private":true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send(`{"receiver_username":"receiver","amount":10,"message":"` + strings.Repeat("a", 281) + `"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"message must be at most 280 characters"}`, w.Body.String())
+
+	w = send(`{"receiver_username":"receiver","amount":10,"category":"bribe"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSanitizeMessage(t *testing.T) {
+	testTable := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{name: "Plain", message: "Great job", expected: "Great job"},
+		{name: "Markup", message: `<script>alert(1)</script> <a href="x">link</a>`, expected: "alert(1) link"},
+		{name: "Comparison", message: "2 < 3 and 5 > 4", expected: "2 < 3 and 5 > 4"},
+		{name: "LineBreaks", message: "line\r\nnext\ttab", expected: "line\nnext tab"},
+		{name: "Invisible", message: "zero​width ‮override", expected: "zerowidth override"},
+		{name: "InvalidUTF8", message: "bad\xffbyte", expected: "badbyte"},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, sanitizeMessage(test.message))
+		})
+	}
+}
+
+func TestKudosFeedHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	token, err := testJWT.GenerateToken(&domain.User{Username: "user1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/kudos"+query, nil)
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	before := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	mockUsecase.EXPECT().GetKudosFeed(gomock.Any(), domain.FeedFilter{Category: "teamwork", Before: before, Limit: 200}).
+		Return([]domain.Transaction{{GUID: "1", TransferNote: domain.TransferNote{Message: "thanks", Category: "teamwork"}}}, nil)
+	w := request("?category=teamwork&before=2026-10-19T12:00:00Z&limit=1000")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"message":"thanks"`)
+
+	mockUsecase.EXPECT().GetKudosFeed(gomock.Any(), domain.FeedFilter{Limit: 50}).Return([]domain.Transaction{}, nil)
+	w = request("")
+	assert.JSONEq(t, `{"kudos":[]}`, w.Body.String())
+
+	w = request("?category=bribe")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request("?limit=0")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSendCoinBatchHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
+	send := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/api/sendCoin/batch", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("username", "lead")
+		h.SendCoinBatchHandler(c)
+		return w
+	}
+
+	note := domain.TransferNote{Message: "Great sprint", Category: "teamwork"}
+	transfers := []domain.Transaction{
+		{ReceiverUsername: "user1", MoneyAmount: 50, TransferNote: note},
+		{ReceiverUsername: "user2", MoneyAmount: 30, TransferNote: note},
+	}
+	mockUsecase.EXPECT().CreateTransactionBatch(gomock.Any(), "lead", transfers).
+		Return(&domain.TransferBatch{ID: "b1", Total: 80, Transactions: []domain.Transaction{}}, nil)
+	w := send(`{"transfers":[{"receiver_username":"user1","amount":50},{"receiver_username":"user2","amount":30}],"message":"Great sprint","category":"teamwork"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"batch_id":"b1","total":80,"transactions":[]}`, w.Body.String())
+
+	mockUsecase.EXPECT().CreateTransactionBatch(gomock.Any(), "lead", gomock.Any()).Return(nil, fmt.Errorf("%w: ghost", domain.ErrNoSuchUser))
+	w = send(`{"transfers":[{"receiver_username":"ghost","amount":50}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"no such user: ghost"}`, w.Body.String())
+
+	mockUsecase.EXPECT().CreateTransactionBatch(gomock.Any(), "lead", gomock.Any()).Return(nil, domain.ErrInsufficientMoney)
+	w = send(`{"transfers":[{"receiver_username":"user1","amount":5000}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	testTable := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{name: "Empty", body: `{"transfers":[]}`, expected: `{"error":"transfers must have 1 to 100 entries"}`},
+		{name: "InvalidAmount", body: `{"transfers":[{"receiver_username":"user1","amount":10},{"receiver_username":"user2","amount":-1}]}`, expected: `{"error":"transfer 2: missing or invalid fields"}`},
+		{name: "DuplicateReceiver", body: `{"transfers":[{"receiver_username":"user1","amount":10},{"receiver_username":"user1","amount":10}]}`, expected: `{"error":"transfer 2: duplicate receiver user1"}`},
+		{name: "UnknownCategory", body: `{"transfers":[{"receiver_username":"user1","amount":10}],"category":"bribe"}`},
+	}
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			w := send(test.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			if test.expected != "" {
+				assert.JSONEq(t, test.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCoinRequestHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	token, err := testJWT.GenerateToken(&domain.User{Username: "user1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	mockUsecase.EXPECT().CreateCoinRequest(gomock.Any(), "user1", "user2", 30.0, "lunch").
+		Return(&domain.CoinRequest{ID: 1, Requester: "user1", Payer: "user2", Amount: 30, Status: domain.RequestPending}, nil)
+	w := request(http.MethodPost, "/api/requests", `{"payer_username":"user2","amount":30,"message":"<b>lunch</b>"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+
+	w = request(http.MethodPost, "/api/requests", `{"payer_username":"user1","amount":30}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(http.MethodPost, "/api/requests", `{"payer_username":"user2","amount":0}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUsecase.EXPECT().ListPendingRequests(gomock.Any(), "user1").
+		Return(&domain.PendingRequests{Incoming: []domain.CoinRequest{}, Outgoing: []domain.CoinRequest{}}, nil)
+	w = request(http.MethodGet, "/api/requests", "")
+	assert.JSONEq(t, `{"incoming":[],"outgoing":[]}`, w.Body.String())
+
+	transactionGUID := "t1"
+	mockUsecase.EXPECT().AcceptCoinRequest(gomock.Any(), int64(7), "user1").
+		Return(&domain.CoinRequest{ID: 7, Status: domain.RequestAccepted, TransactionGUID: &transactionGUID}, nil)
+	w = request(http.MethodPost, "/api/requests/7/accept", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"transaction_guid":"t1"`)
+
+	testTable := []struct {
+		name     string
+		path     string
+		err      error
+		expected int
+	}{
+		{name: "NotFound", path: "/api/requests/8/accept", err: domain.ErrNoCoinRequest, expected: http.StatusNotFound},
+		{name: "Expired", path: "/api/requests/8/accept", err: domain.ErrCoinRequestExpired, expected: http.StatusConflict},
+		{name: "InsufficientMoney", path: "/api/requests/8/accept", err: domain.ErrInsufficientMoney, expected: http.StatusBadRequest},
+		{name: "Declined", path: "/api/requests/8/decline", err: domain.ErrCoinRequestClosed, expected: http.StatusConflict},
+		{name: "Cancelled", path: "/api/requests/8/cancel", expected: http.StatusOK},
+	}
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			var result *domain.CoinRequest
+			if test.err == nil {
+				result = &domain.CoinRequest{ID: 8}
+			}
+			switch {
+			case strings.HasSuffix(test.path, "accept"):
+				mockUsecase.EXPECT().AcceptCoinRequest(gomock.Any(), int64(8), "user1").Return(result, test.err)
+			case strings.HasSuffix(test.path, "decline"):
+				mockUsecase.EXPECT().DeclineCoinRequest(gomock.Any(), int64(8), "user1").Return(result, test.err)
+			default:
+				mockUsecase.EXPECT().CancelCoinRequest(gomock.Any(), int64(8), "user1").Return(result, test.err)
+			}
+			w := request(http.MethodPost, test.path, "")
+			assert.Equal(t, test.expected, w.Code)
+		})
+	}
+
+	w = request(http.MethodPost, "/api/requests/abc/accept", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBuyItemHandler_Gift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	h := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second))
+	buy := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/api/buy/cup", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "item", Value: "cup"}}
+		c.Set("username", "buyer")
+		h.BuyItemHandler(c)
+		return w
+	}
+
+	mockUsecase.EXPECT().CreatePurchase(gomock.Any(), "buyer", "cup", domain.Gift{Recipient: "friend", Message: "Happy birthday"}, gomock.Nil()).
+		Return(&domain.Purchase{GUID: "1", UserID: "buyer", RecipientID: "friend", MerchName: "cup", GiftMessage: "Happy birthday"}, nil, nil)
+	w := buy(`{"recipient":"friend","message":"<b>Happy birthday</b>"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"recipient_id":"friend"`)
+
+	mockUsecase.EXPECT().CreatePurchase(gomock.Any(), "buyer", "cup", domain.Gift{Recipient: "ghost"}, gomock.Nil()).
+		Return(nil, nil, fmt.Errorf("%w: ghost", domain.ErrNoSuchUser))
+	w = buy(`{"recipient":"ghost"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = buy(`{"recipient":"buyer"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"Cannot send a gift to yourself"}`, w.Body.String())
+
+	w = buy(`{"message":"hi"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = buy(`{"recipient":`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestNotificationHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	token, err := testJWT.GenerateToken(&domain.User{Username: "user1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	mockUsecase.EXPECT().ListNotifications(gomock.Any(), "user1").
+		Return([]domain.Notification{{ID: 1, Username: "user1", Kind: domain.NotificationGift, Message: "user2 sent you a gift: cup"}}, nil)
+	w := request(http.MethodGet, "/api/notifications")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"kind":"gift"`)
+	assert.NotContains(t, w.Body.String(), `"username"`)
+
+	mockUsecase.EXPECT().MarkNotificationsRead(gomock.Any(), "user1").Return(int64(1), nil)
+	w = request(http.MethodPost, "/api/notifications/read")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"marked":1}`, w.Body.String())
+}
+
+func TestScheduledTransferHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	token, err := testJWT.GenerateToken(&domain.User{Username: "user1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	executeAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	mockUsecase.EXPECT().ScheduleTransfer(gomock.Any(), "user2", "user1", 25.0, domain.TransferNote{Message: "happy birthday"}, executeAt).
+		Return(&domain.ScheduledTransfer{ID: 1, SenderUsername: "user1", ReceiverUsername: "user2", Amount: 25, Status: domain.ScheduledPending, ExecuteAt: executeAt}, nil)
+	w := request(http.MethodPost, "/api/sendCoin/scheduled",
+		fmt.Sprintf(`{"receiver_username":"user2","amount":25,"execute_at":%q,"message":"happy birthday"}`, executeAt.Format(time.RFC3339)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	w = request(http.MethodPost, "/api/sendCoin/scheduled", fmt.Sprintf(`{"receiver_username":"user2","amount":25,"execute_at":%q}`, past))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(http.MethodPost, "/api/sendCoin/scheduled", `{"receiver_username":"user2","amount":25,"execute_at":"tomorrow"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(http.MethodPost, "/api/sendCoin/scheduled", `{"receiver_username":"user2","amount":25}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUsecase.EXPECT().ListScheduledTransfers(gomock.Any(), "user1").
+		Return([]domain.ScheduledTransfer{{ID: 2, Status: domain.ScheduledFailed, FailureReason: "insufficient money"}}, nil)
+	w = request(http.MethodGet, "/api/sendCoin/scheduled", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"failure_reason":"insufficient money"`)
+
+	mockUsecase.EXPECT().CancelScheduledTransfer(gomock.Any(), int64(1), "user1").
+		Return(&domain.ScheduledTransfer{ID: 1, Status: domain.ScheduledCancelled}, nil)
+	w = request(http.MethodPost, "/api/sendCoin/scheduled/1/cancel", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockUsecase.EXPECT().CancelScheduledTransfer(gomock.Any(), int64(2), "user1").Return(nil, domain.ErrScheduledTransferClosed)
+	w = request(http.MethodPost, "/api/sendCoin/scheduled/2/cancel", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockUsecase.EXPECT().CancelScheduledTransfer(gomock.Any(), int64(3), "user1").Return(nil, domain.ErrNoScheduledTransfer)
+	w = request(http.MethodPost, "/api/sendCoin/scheduled/3/cancel", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = request(http.MethodPost, "/api/sendCoin/scheduled/abc/cancel", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHoldHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	expectRoles(mockUsecase)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	token, err := testJWT.GenerateToken(&domain.User{Username: "admin", Role: domain.RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	mockUsecase.EXPECT().CreateHold(gomock.Any(), "user1", 50.0, "order 7", time.Time{}).
+		Return(&domain.Hold{ID: 1, Username: "user1", Amount: 50, Reason: "order 7", Status: domain.HoldActive}, nil)
+	w := request(http.MethodPost, "/api/admin/holds", `{"username":"user1","amount":50,"reason":"order 7"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"active"`)
+
+	mockUsecase.EXPECT().CreateHold(gomock.Any(), "user1", 5000.0, "order 8", gomock.Any()).Return(nil, domain.ErrInsufficientMoney)
+	w = request(http.MethodPost, "/api/admin/holds", `{"username":"user1","amount":5000,"reason":"order 8","expires_at":"2099-01-01T00:00:00Z"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request(http.MethodPost, "/api/admin/holds", `{"username":"user1","amount":50,"reason":"order 9","expires_at":"2001-01-01T00:00:00Z"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(http.MethodPost, "/api/admin/holds", `{"username":"user1","amount":50}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUsecase.EXPECT().CaptureHold(gomock.Any(), int64(1), 30.0).
+		Return(&domain.Hold{ID: 1, Amount: 50, Captured: 30, Status: domain.HoldCaptured}, nil)
+	w = request(http.MethodPost, "/api/admin/holds/1/capture", `{"amount":30}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"captured":30`)
+
+	mockUsecase.EXPECT().CaptureHold(gomock.Any(), int64(2), 0.0).Return(nil, domain.ErrHoldExpired)
+	w = request(http.MethodPost, "/api/admin/holds/2/capture", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockUsecase.EXPECT().CaptureHold(gomock.Any(), int64(3), 80.0).Return(nil, domain.ErrCaptureExceedsHold)
+	w = request(http.MethodPost, "/api/admin/holds/3/capture", `{"amount":80}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUsecase.EXPECT().ReleaseHold(gomock.Any(), int64(4)).Return(nil, domain.ErrNoHold)
+	w = request(http.MethodPost, "/api/admin/holds/4/release", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockUsecase.EXPECT().GetBalance(gomock.Any(), "user1").Return(&domain.Balance{Balance: 100, Held: 50, Available: 50, Holds: []domain.Hold{}}, nil)
+	w = request(http.MethodGet, "/api/admin/users/user1/balance", "")
+	assert.JSONEq(t, `{"balance":100,"held":50,"available":50,"holds":[]}`, w.Body.String())
+}
+
+func TestWalletHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	token, err := testJWT.GenerateToken(&domain.User{Username: "user1", Role: domain.RoleUser})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	mockUsecase.EXPECT().CreateWallet(gomock.Any(), "user1", "team", 2, []string{"user2", "user3"}).
+		Return(&domain.Wallet{ID: 1, Name: "team", Quorum: 2, CreatedBy: "user1"}, nil)
+	w := request(http.MethodPost, "/api/wallets", `{"name":" team ","quorum":2,"members":["user2","user3"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"quorum":2`)
+
+	w = request(http.MethodPost, "/api/wallets", `{"name":"team","quorum":3,"members":["user2"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(http.MethodPost, "/api/wallets", `{"name":" "}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUsecase.EXPECT().ContributeToWallet(gomock.Any(), int64(1), "user1", 50.0).
+		Return(&domain.WalletContribution{GUID: "c1", WalletID: 1, Username: "user1", Amount: 50}, nil)
+	w = request(http.MethodPost, "/api/wallets/1/contribute", `{"amount":50}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	mockUsecase.EXPECT().ContributeToWallet(gomock.Any(), int64(2), "user1", 50.0).Return(nil, domain.ErrNoWallet)
+	w = request(http.MethodPost, "/api/wallets/2/contribute", `{"amount":50}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockUsecase.EXPECT().ProposeWalletPurchase(gomock.Any(), int64(1), "user1", "hoody").
+		Return(&domain.WalletPurchase{ID: 1, MerchName: "hoody", Status: domain.WalletPurchasePending, Approvals: []string{"user1"}}, nil)
+	w = request(http.MethodPost, "/api/wallets/1/purchases", `{"item":"hoody"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"approvals":["user1"]`)
+
+	mockUsecase.EXPECT().ApproveWalletPurchase(gomock.Any(), int64(1), int64(1), "user1").Return(nil, domain.ErrInsufficientMoney)
+	w = request(http.MethodPost, "/api/wallets/1/purchases/1/approve", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUsecase.EXPECT().RejectWalletPurchase(gomock.Any(), int64(1), int64(2), "user1").Return(nil, domain.ErrWalletPurchaseClosed)
+	w = request(http.MethodPost, "/api/wallets/1/purchases/2/reject", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockUsecase.EXPECT().SaveWalletMember(gomock.Any(), "user1", &domain.WalletMember{WalletID: 1, Username: "user4", Role: domain.WalletRoleMember}).
+		Return(domain.ErrNotWalletOwner)
+	w = request(http.MethodPost, "/api/wallets/1/members", `{"username":"user4"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(http.MethodPost, "/api/wallets/1/members", `{"username":"user4","role":"admin"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUsecase.EXPECT().RemoveWalletMember(gomock.Any(), int64(1), "user1", "user1").Return(nil)
+	w = request(http.MethodDelete, "/api/wallets/1/members/user1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = request(http.MethodGet, "/api/wallets/abc/info", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPurchaseApprovalHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	expectRoles(mockUsecase)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	request := func(user *domain.User, method, path string) *httptest.ResponseRecorder {
+		token, err := testJWT.GenerateToken(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(method, path, nil)
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	buyer := &domain.User{Username: "user1", Role: domain.RoleUser}
+	manager := &domain.User{Username: "boss", Role: domain.RoleUser}
+	admin := &domain.User{Username: "admin", Role: domain.RoleAdmin}
+
+	approval := &domain.PurchaseApproval{ID: 1, Username: "user1", MerchName: "pink-hoody", Price: 500, Approver: "boss", Status: domain.ApprovalPending}
+	mockUsecase.EXPECT().CreatePurchase(gomock.Any(), "user1", "pink-hoody", domain.Gift{}, gomock.Nil()).Return(nil, approval, nil)
+	w := request(buyer, http.MethodPost, "/api/buy/pink-hoody")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+
+	mockUsecase.EXPECT().ListPurchaseApprovals(gomock.Any(), "admin", true).
+		Return(&domain.PendingApprovals{Assigned: []domain.PurchaseApproval{}, Requested: []domain.PurchaseApproval{}}, nil)
+	w = request(admin, http.MethodGet, "/api/approvals")
+	assert.JSONEq(t, `{"assigned":[],"requested":[]}`, w.Body.String())
+
+	mockUsecase.EXPECT().ApprovePurchase(gomock.Any(), int64(1), "boss", false).
+		Return(&domain.PurchaseApproval{ID: 1, Status: domain.ApprovalApproved, PurchaseGUID: "p1"}, nil)
+	w = request(manager, http.MethodPost, "/api/approvals/1/approve")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"purchase_guid":"p1"`)
+
+	mockUsecase.EXPECT().ApprovePurchase(gomock.Any(), int64(2), "user1", false).Return(nil, domain.ErrNoApproval)
+	w = request(buyer, http.MethodPost, "/api/approvals/2/approve")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockUsecase.EXPECT().RejectPurchase(gomock.Any(), int64(3), "admin", true).Return(nil, domain.ErrApprovalExpired)
+	w = request(admin, http.MethodPost, "/api/approvals/3/reject")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = request(admin, http.MethodPost, "/api/approvals/x/reject")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPromoCodeHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	expectRoles(mockUsecase)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	request := func(user *domain.User, method, path, body string) *httptest.ResponseRecorder {
+		token, err := testJWT.GenerateToken(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	buyer := &domain.User{Username: "user1", Role: domain.RoleUser}
+	admin := &domain.User{Username: "admin", Role: domain.RoleAdmin}
+
+	endsAt := time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC)
+	mockUsecase.EXPECT().CreatePromoCode(gomock.Any(), &domain.PromoCode{
+		Code: "ONBOARD20", Kind: domain.DiscountPercent, Amount: 20, Items: []string{"hoody"}, EndsAt: &endsAt, MaxUsesPerUser: 1, CreatedBy: "admin",
+	}).Return(&domain.PromoCode{Code: "ONBOARD20", Kind: domain.DiscountPercent, Amount: 20, Active: true}, nil)
+	w := request(admin, http.MethodPost, "/api/admin/promos", `{"code":" onboard20 ","kind":"percent","amount":20,"items":["hoody"],"ends_at":"2026-10-26T00:00:00Z","max_uses_per_user":1}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	mockUsecase.EXPECT().CreatePromoCode(gomock.Any(), gomock.Any()).Return(nil, domain.ErrPromoCodeExists)
+	w = request(admin, http.MethodPost, "/api/admin/promos", `{"code":"ONBOARD20","kind":"fixed","amount":5}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	invalid := []string{
+		`{"kind":"fixed","amount":5}`,
+		`{"code":"X","kind":"gift","amount":5}`,
+		`{"code":"X","kind":"percent","amount":120}`,
+		`{"code":"X","kind":"fixed","amount":5,"max_uses":-1}`,
+		`{"code":"X","kind":"fixed","amount":5,"starts_at":"tomorrow"}`,
+		`{"code":"X","kind":"fixed","amount":5,"starts_at":"2026-10-26T00:00:00Z","ends_at":"2026-10-19T00:00:00Z"}`,
+	}
+	for _, body := range invalid {
+		w = request(admin, http.MethodPost, "/api/admin/promos", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	w = request(buyer, http.MethodPost, "/api/admin/promos", `{"code":"X","kind":"fixed","amount":5}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	mockUsecase.EXPECT().SetPromoCodeActive(gomock.Any(), "ONBOARD20", false).Return(&domain.PromoCode{Code: "ONBOARD20"}, nil)
+	w = request(admin, http.MethodPost, "/api/admin/promos/onboard20/disable", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUsecase.EXPECT().SetPromoCodeActive(gomock.Any(), "GHOST", true).Return(nil, domain.ErrNoPromoCode)
+	w = request(admin, http.MethodPost, "/api/admin/promos/GHOST/enable", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Codes are matched case-insensitively, a code given twice is rejected.
+	mockUsecase.EXPECT().CreatePurchase(gomock.Any(), "user1", "hoody", domain.Gift{}, []string{"ONBOARD20", "WELCOME"}).
+		Return(&domain.Purchase{GUID: "p1", MerchName: "hoody", UnitPrice: 300, Total: 190, Discount: 110, PromoCodes: []string{"ONBOARD20", "WELCOME"}}, nil, nil)
+	w = request(buyer, http.MethodPost, "/api/buy/hoody", `{"promo_code":"onboard20","promo_codes":["welcome"]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"unit_price":300,"total":190,"discount":110,"promo_codes":["ONBOARD20","WELCOME"]`)
+
+	w = request(buyer, http.MethodPost, "/api/buy/hoody", `{"promo_codes":["WELCOME","welcome"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUsecase.EXPECT().CreatePurchase(gomock.Any(), "user1", "cup", domain.Gift{}, []string{"ONBOARD20"}).
+		Return(nil, nil, fmt.Errorf("%w: ONBOARD20 does not apply to cup", domain.ErrPromoCodeNotApplicable))
+	w = request(buyer, http.MethodPost, "/api/buy/cup", `{"promo_code":"ONBOARD20"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"promo code cannot be used: ONBOARD20 does not apply to cup"}`, w.Body.String())
+}
+
+func TestPriceTimelineHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	token, err := testJWT.GenerateToken(&domain.User{Username: "user1", Role: domain.RoleUser})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	prices := []domain.MerchPrice{
+		{Price: 20, EffectiveFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Price: 25, EffectiveFrom: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	mockUsecase.EXPECT().GetPriceTimeline(gomock.Any(), "cup").Return(domain.NewPriceTimeline(domain.Merch{Name: "cup", Price: 25}, prices), nil)
+	w := request("/api/merch/cup/prices")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"item":"cup","price":25,"prices":[
+		{"price":20,"effective_from":"2025-01-01T00:00:00Z","effective_to":"2026-10-01T00:00:00Z"},
+		{"price":25,"effective_from":"2026-10-01T00:00:00Z"}
+	]}`, w.Body.String())
+
+	mockUsecase.EXPECT().GetPriceTimeline(gomock.Any(), "ghost").Return(nil, domain.ErrNoMerch)
+	w = request("/api/merch/ghost/prices")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCatalogHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	expectRoles(mockUsecase)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	request := func(user *domain.User, method, path, body string) *httptest.ResponseRecorder {
+		token, err := testJWT.GenerateToken(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	buyer := &domain.User{Username: "user1", Role: domain.RoleUser}
+	admin := &domain.User{Username: "admin", Role: domain.RoleAdmin}
+	apparel := "apparel"
+
+	mockUsecase.EXPECT().ListMerch(gomock.Any(), domain.MerchFilter{Category: "apparel", Tag: "warm"}).Return([]domain.Merch{
+		{Name: "hoody", Price: 300, Category: &apparel, Tags: []string{"warm"}, Images: []domain.MerchImage{{ID: 1, ContentType: "image/png", Width: 800, Height: 600}}},
+	}, nil)
+	w := request(buyer, http.MethodGet, "/api/merch?category=apparel&tag=Warm", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"merch":[{"name":"hoody","price":300,"category":"apparel","tags":["warm"],
+		"images":[{"id":1,"content_type":"image/png","width":800,"height":600,"created_at":"0001-01-01T00:00:00Z"}]}]}`, w.Body.String())
+
+	mockUsecase.EXPECT().CreateMerch(gomock.Any(), &domain.Merch{Name: "mug", Price: 30, Description: "Big mug", Category: &apparel, Tags: []string{"kitchen", "big"}}).
+		Return(&domain.Merch{Name: "mug", Price: 30}, nil)
+	w = request(admin, http.MethodPost, "/api/admin/merch", `{"name":"mug","price":30,"description":" Big mug ","category":"apparel","tags":["Kitchen","big","kitchen"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockUsecase.EXPECT().CreateMerch(gomock.Any(), gomock.Any()).Return(nil, domain.ErrMerchExists)
+	w = request(admin, http.MethodPost, "/api/admin/merch", `{"name":"mug","price":30}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	for _, body := range []string{`{"price":30}`, `{"name":"a/b","price":30}`, `{"name":"mug"}`, `{"name":"mug","price":30,"tags":[" "]}`} {
+		w = request(admin, http.MethodPost, "/api/admin/merch", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	w = request(buyer, http.MethodPost, "/api/admin/merch", `{"name":"mug","price":30}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	price, none, tags := 250.0, "", []string{}
+	mockUsecase.EXPECT().UpdateMerch(gomock.Any(), "hoody", domain.MerchUpdate{Price: &price, Category: &none, Tags: &tags}).
+		Return(&domain.Merch{Name: "hoody", Price: 250}, nil)
+	w = request(admin, http.MethodPatch, "/api/admin/merch/hoody", `{"price":250,"category":"","tags":[]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUsecase.EXPECT().UpdateMerch(gomock.Any(), "hoody", gomock.Any()).Return(nil, fmt.Errorf("%w: food", domain.ErrNoCategory))
+	w = request(admin, http.MethodPatch, "/api/admin/merch/hoody", `{"category":"food"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(admin, http.MethodPatch, "/api/admin/merch/hoody", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(admin, http.MethodPatch, "/api/admin/merch/hoody", `{"price":0}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	untracked := -1
+	mockUsecase.EXPECT().UpdateMerch(gomock.Any(), "hoody", domain.MerchUpdate{Stock: &untracked}).Return(&domain.Merch{Name: "hoody", Price: 250}, nil)
+	w = request(admin, http.MethodPatch, "/api/admin/merch/hoody", `{"stock":-1}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = request(admin, http.MethodPatch, "/api/admin/merch/hoody", `{"stock":-2}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(admin, http.MethodPost, "/api/admin/merch", `{"name":"mug","price":30,"stock":-1}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.EXPECT().CreatePurchase(gomock.Any(), "user1", "hoody", domain.Gift{}, nil).Return(nil, nil, domain.ErrOutOfStock)
+	w = request(buyer, http.MethodPost, "/api/buy/hoody", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockUsecase.EXPECT().ListCategories(gomock.Any()).Return([]domain.Category{{Name: "apparel", Title: "Apparel"}}, nil)
+	w = request(buyer, http.MethodGet, "/api/categories", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"categories":[{"name":"apparel","title":"Apparel"}]}`, w.Body.String())
+
+	mockUsecase.EXPECT().CreateCategory(gomock.Any(), &domain.Category{Name: "stationery", Title: "Stationery"}).
+		Return(&domain.Category{Name: "stationery", Title: "Stationery"}, nil)
+	w = request(admin, http.MethodPost, "/api/admin/categories", `{"name":"stationery","title":" Stationery "}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	for _, body := range []string{`{"name":"Stationery","title":"S"}`, `{"name":"-x","title":"S"}`, `{"name":"pens"}`} {
+		w = request(admin, http.MethodPost, "/api/admin/categories", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	mockUsecase.EXPECT().UpdateCategory(gomock.Any(), &domain.Category{Name: "ghost", Title: "Ghost"}).Return(nil, domain.ErrNoCategory)
+	w = request(admin, http.MethodPatch, "/api/admin/categories/ghost", `{"title":"Ghost"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockUsecase.EXPECT().DeleteCategory(gomock.Any(), "apparel").Return(fmt.Errorf("%w: 2 items", domain.ErrCategoryInUse))
+	w = request(admin, http.MethodDelete, "/api/admin/categories/apparel", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockUsecase.EXPECT().DeleteCategory(gomock.Any(), "stationery").Return(nil)
+	w = request(admin, http.MethodDelete, "/api/admin/categories/stationery", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestMerchImageHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	expectRoles(mockUsecase)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	token, err := testJWT.GenerateToken(&domain.User{Username: "admin", Role: domain.RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	upload := func(field string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile(field, "hoody.png")
+		_, _ = part.Write([]byte("png data"))
+		_ = writer.Close()
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/merch/hoody/images", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return serve(req)
+	}
+
+	mockUsecase.EXPECT().AddMerchImage(gomock.Any(), "hoody", gomock.Any()).Return(&domain.MerchImage{ID: 3, ContentType: "image/png"}, nil)
+	w := upload("image")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":3`)
+
+	mockUsecase.EXPECT().AddMerchImage(gomock.Any(), "hoody", gomock.Any()).Return(nil, domain.ErrImageTooLarge)
+	w = upload("image")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	w = upload("file")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUsecase.EXPECT().GetMerchThumbnail(gomock.Any(), "hoody", int64(3), 256).Return([]byte("thumbnail"), "image/png", nil)
+	w = serve(httptest.NewRequest(http.MethodGet, "/api/merch/hoody/images/3/thumbnail", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, "thumbnail", w.Body.String())
+
+	w = serve(httptest.NewRequest(http.MethodGet, "/api/merch/hoody/images/3/thumbnail?width=100", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.EXPECT().GetMerchThumbnail(gomock.Any(), "hoody", int64(4), 64).Return(nil, "", domain.ErrNoMerchImage)
+	w = serve(httptest.NewRequest(http.MethodGet, "/api/merch/hoody/images/4/thumbnail?width=64", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockUsecase.EXPECT().DeleteMerchImage(gomock.Any(), "hoody", int64(3)).Return(nil)
+	w = serve(httptest.NewRequest(http.MethodDelete, "/api/admin/merch/hoody/images/3", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestWishlistHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mockusecase.NewMockUsecase(ctrl)
+	router := NewHandler(mockUsecase, testAuth, health.NewChecker(time.Second)).Handle()
+	token, err := testJWT.GenerateToken(&domain.User{Username: "user1", Role: domain.RoleUser})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	addedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	mockUsecase.EXPECT().GetWishlist(gomock.Any(), "user1").Return(&domain.Wishlist{
+		Available: 200,
+		CoinsToGo: 100,
+		Items:     []domain.WishlistEntry{{Item: "hoody", Price: 300, InStock: true, CoinsToGo: 100, NotifyPriceDrop: true, AddedAt: addedAt}},
+	}, nil)
+	w := request(http.MethodGet, "/api/wishlist", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"available":200,"coins_to_go":100,"items":[{"item":"hoody","price":300,"in_stock":true,"coins_to_go":100,
+		"affordable":false,"notify_restock":false,"notify_price_drop":true,"added_at":"2026-10-19T12:00:00Z"}]}`, w.Body.String())
+
+	mockUsecase.EXPECT().SaveWishlistItem(gomock.Any(), &domain.WishlistItem{Username: "user1", MerchName: "hoody", NotifyRestock: true}).
+		Return(&domain.WishlistItem{Username: "user1", MerchName: "hoody", NotifyRestock: true, CreatedAt: addedAt}, nil)
+	w = request(http.MethodPut, "/api/wishlist/hoody", `{"notify_restock":true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"item":"hoody","notify_restock":true,"notify_price_drop":false,"created_at":"2026-10-19T12:00:00Z"}`, w.Body.String())
+	mockUsecase.EXPECT().SaveWishlistItem(gomock.Any(), &domain.WishlistItem{Username: "user1", MerchName: "cup"}).
+		Return(&domain.WishlistItem{Username: "user1", MerchName: "cup"}, nil)
+	w = request(http.MethodPut, "/api/wishlist/cup", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUsecase.EXPECT().SaveWishlistItem(gomock.Any(), gomock.Any()).Return(nil, domain.ErrNoMerch)
+	w = request(http.MethodPut, "/api/wishlist/hat", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = request(http.MethodPut, "/api/wishlist/hoody", `{"notify_restock":"yes"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUsecase.EXPECT().RemoveWishlistItem(gomock.Any(), "user1", "hoody").Return(nil)
+	w = request(http.MethodDelete, "/api/wishlist/hoody", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockUsecase.EXPECT().RemoveWishlistItem(gomock.Any(), "user1", "hoody").Return(domain.ErrNotInWishlist)
+	w = request(http.MethodDelete, "/api/wishlist/hoody", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"shop/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateHoldHandler reserves coins of a user without moving them. The hold
+// expires at expires_at, or after HOLD_TTL when it is not given.
+func (h *Handler) CreateHoldHandler(c *gin.Context) {
+	var req struct {
+		Username  string  `json:"username"`
+		Amount    float64 `json:"amount"`
+		Reason    string  `json:"reason"`
+		ExpiresAt string  `json:"expires_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.Username == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid fields"})
+		return
+	}
+	reason, err := validateAdjustment(req.Amount, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var expiresAt time.Time
+	if req.ExpiresAt != "" {
+		if expiresAt, err = time.Parse(time.RFC3339, req.ExpiresAt); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expires_at, expected RFC 3339 time"})
+			return
+		}
+		if !expiresAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+			return
+		}
+	}
+
+	hold, err := h.service.CreateHold(c.Request.Context(), req.Username, req.Amount, reason, expiresAt)
+	if err != nil {
+		holdError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, hold)
+}
+
+// BalanceHandler shows a user's balance, the held part and the active holds.
+func (h *Handler) BalanceHandler(c *gin.Context) {
+	balance, err := h.service.GetBalance(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		holdError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, balance)
+}
+
+// CaptureHoldHandler debits the held coins, all of them or the given amount,
+// and releases the rest.
+func (h *Handler) CaptureHoldHandler(c *gin.Context) {
+	id, ok := holdID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Amount float64 `json:"amount"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+	if req.Amount < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be positive"})
+		return
+	}
+
+	hold, err := h.service.CaptureHold(c.Request.Context(), id, req.Amount)
+	if err != nil {
+		holdError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, hold)
+}
+
+func (h *Handler) ReleaseHoldHandler(c *gin.Context) {
+	id, ok := holdID(c)
+	if !ok {
+		return
+	}
+	hold, err := h.service.ReleaseHold(c.Request.Context(), id)
+	if err != nil {
+		holdError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, hold)
+}
+
+func holdID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hold id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func holdError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNoHold):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrHoldClosed), errors.Is(err, domain.ErrHoldExpired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCaptureExceedsHold):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		adjustmentError(c, err)
+	}
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"shop/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxMessageLength = 280
+
+	defaultFeedLimit = 50
+	maxFeedLimit     = 200
+)
+
+var htmlTag = regexp.MustCompile(`</?[A-Za-z][^<>]*>`)
+
+// KudosFeedHandler lists the latest public transfers, newest first. Older
+// pages are fetched with before set to the created_at of the last entry.
+func (h *Handler) KudosFeedHandler(c *gin.Context) {
+	filter := domain.FeedFilter{Category: c.Query("category"), Limit: defaultFeedLimit}
+	if filter.Category != "" && !domain.IsKudosCategory(filter.Category) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
+		return
+	}
+	if raw := c.Query("before"); raw != "" {
+		before, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before, expected RFC 3339 time"})
+			return
+		}
+		filter.Before = before
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = min(limit, maxFeedLimit)
+	}
+
+	kudos, err := h.service.GetKudosFeed(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"kudos": kudos})
+}
+
+// validateNote sanitizes the message sent along with coins and checks its
+// length and category.
+func validateNote(message, category string, private bool) (domain.TransferNote, error) {
+	note := domain.TransferNote{Message: sanitizeMessage(message), Category: category, Private: private}
+	if utf8.RuneCountInString(note.Message) > maxMessageLength {
+		return note, fmt.Errorf("message must be at most %d characters", maxMessageLength)
+	}
+	if category != "" && !domain.IsKudosCategory(category) {
+		return note, fmt.Errorf("category must be one of %s", strings.Join(domain.KudosCategories, ", "))
+	}
+	return note, nil
+}
+
+// sanitizeMessage removes markup, control and invisible formatting characters,
+// like bidirectional overrides, keeping line breaks.
+func sanitizeMessage(message string) string {
+	message = strings.ToValidUTF8(message, "")
+	message = htmlTag.ReplaceAllString(message, "")
+	message = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n':
+			return r
+		case r == '\t':
+			return ' '
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			return -1
+		}
+		return r
+	}, message)
+	return strings.TrimSpace(message)
+}
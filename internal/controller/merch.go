@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"shop/domain"
+	"shop/pkg/images"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultThumbnailWidth is the width of thumbnails requested without one.
+const defaultThumbnailWidth = 256
+
+// PriceTimelineHandler returns the current price of an item and the prices
+// it had before, with the dates they took effect.
+func (h *Handler) PriceTimelineHandler(c *gin.Context) {
+	timeline, err := h.service.GetPriceTimeline(c.Request.Context(), c.Param("item"))
+	if err != nil {
+		if errors.Is(err, domain.ErrNoMerch) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, timeline)
+}
+
+// ListMerchHandler returns the catalog, optionally only the items of a
+// category or with a tag.
+func (h *Handler) ListMerchHandler(c *gin.Context) {
+	filter := domain.MerchFilter{
+		Category: strings.TrimSpace(c.Query("category")),
+		Tag:      normalizeTag(c.Query("tag")),
+	}
+	merch, err := h.service.ListMerch(c.Request.Context(), filter)
+	if err != nil {
+		merchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"merch": merch})
+}
+
+func (h *Handler) CreateMerchHandler(c *gin.Context) {
+	var req struct {
+		Name        string   `json:"name"`
+		Price       float64  `json:"price"`
+		Stock       *int     `json:"stock"`
+		Description string   `json:"description"`
+		Category    string   `json:"category"`
+		Tags        []string `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	switch {
+	case name == "" || strings.Contains(name, "/"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required and cannot contain /"})
+		return
+	case req.Price <= 0:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "price must be positive"})
+		return
+	case req.Stock != nil && *req.Stock < 0:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "stock cannot be negative"})
+		return
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	merch := &domain.Merch{Name: name, Price: req.Price, Stock: req.Stock, Description: strings.TrimSpace(req.Description), Tags: tags}
+	if req.Category != "" {
+		merch.Category = &req.Category
+	}
+	merch, err = h.service.CreateMerch(c.Request.Context(), merch)
+	if err != nil {
+		merchError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, merch)
+}
+
+// UpdateMerchHandler changes the fields given in the request, an empty
+// category removes the item from its category and a stock of -1 stops
+// tracking its stock.
+func (h *Handler) UpdateMerchHandler(c *gin.Context) {
+	var req struct {
+		Price       *float64  `json:"price"`
+		Stock       *int      `json:"stock"`
+		Description *string   `json:"description"`
+		Category    *string   `json:"category"`
+		Tags        *[]string `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.Price == nil && req.Stock == nil && req.Description == nil && req.Category == nil && req.Tags == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update"})
+		return
+	}
+	if req.Price != nil && *req.Price <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "price must be positive"})
+		return
+	}
+	if req.Stock != nil && *req.Stock < -1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "stock cannot be negative, -1 stops tracking it"})
+		return
+	}
+
+	update := domain.MerchUpdate{Price: req.Price, Stock: req.Stock, Category: req.Category}
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		update.Description = &description
+	}
+	if req.Tags != nil {
+		tags, err := normalizeTags(*req.Tags)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		update.Tags = &tags
+	}
+
+	merch, err := h.service.UpdateMerch(c.Request.Context(), c.Param("item"), update)
+	if err != nil {
+		merchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, merch)
+}
+
+// UploadMerchImageHandler attaches the image sent as the "image" field of a
+// multipart form to the item.
+func (h *Handler) UploadMerchImageHandler(c *gin.Context) {
+	header, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image file is required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	image, err := h.service.AddMerchImage(c.Request.Context(), c.Param("item"), file)
+	if err != nil {
+		merchError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, image)
+}
+
+func (h *Handler) DeleteMerchImageHandler(c *gin.Context) {
+	id, ok := imageID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteMerchImage(c.Request.Context(), c.Param("item"), id); err != nil {
+		merchError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ThumbnailHandler serves an image of an item scaled down to the width
+// query parameter. Images never change, so clients can cache thumbnails.
+func (h *Handler) ThumbnailHandler(c *gin.Context) {
+	id, ok := imageID(c)
+	if !ok {
+		return
+	}
+	width := defaultThumbnailWidth
+	if value := c.Query("width"); value != "" {
+		var err error
+		width, err = strconv.Atoi(value)
+		if err != nil || !slices.Contains(images.ThumbnailWidths, width) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("width must be one of %v", images.ThumbnailWidths)})
+			return
+		}
+	}
+
+	data, contentType, err := h.service.GetMerchThumbnail(c.Request.Context(), c.Param("item"), id, width)
+	if err != nil {
+		merchError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func imageID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// normalizeTags makes tags lower-case and drops repeated ones.
+func normalizeTags(tags []string) ([]string, error) {
+	result := []string{}
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" {
+			return nil, errors.New("tag cannot be empty")
+		}
+		if !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result, nil
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+func merchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNoMerch), errors.Is(err, domain.ErrNoMerchImage):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrMerchExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrNoCategory), errors.Is(err, images.ErrUnsupportedFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrImageTooLarge), errors.Is(err, images.ErrTooManyPixels):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,23 +1,29 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
-	"os"
 	"time"
 
 	"shop/domain"
+	"shop/pkg/audit"
+	"shop/pkg/logger"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 )
 
-type JWT struct{}
+type JWT struct {
+	SecretKey string
+	TokenTTL  time.Duration
+}
 
-func (JWT) GenerateToken(user *domain.User) (string, error) {
-	expirationTime := time.Now().Add(5 * time.Hour)
+func (j JWT) GenerateToken(user *domain.User) (string, error) {
+	expirationTime := time.Now().Add(j.TokenTTL)
 	claims := &domain.Claims{
 		Username: user.Username,
+		Role:     user.Role,
 		StandardClaims: jwt.StandardClaims{
 			Subject:   user.Username,
 			ExpiresAt: expirationTime.Unix(),
@@ -25,7 +31,7 @@ func (JWT) GenerateToken(user *domain.User) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
-	tokenString, err := token.SignedString([]byte(os.Getenv("SECRET_KEY")))
+	tokenString, err := token.SignedString([]byte(j.SecretKey))
 	if err != nil {
 		log.Errorf("error generating token: %v", err)
 	}
@@ -33,7 +39,7 @@ func (JWT) GenerateToken(user *domain.User) (string, error) {
 	return tokenString, err
 }
 
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(j JWT) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader, err := c.Cookie("accessToken")
 		if err != nil {
@@ -47,7 +53,7 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		claims := jwt.MapClaims{}
 		token, err := jwt.ParseWithClaims(authHeader, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("SECRET_KEY")), nil
+			return []byte(j.SecretKey), nil
 		})
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
@@ -62,7 +68,55 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		username := claims["username"].(string)
+		role, _ := claims["role"].(string)
 		c.Set("username", username)
+		c.Set("role", role)
+		ctx := logger.WithFields(c.Request.Context(), log.Fields{"username": username})
+		c.Request = c.Request.WithContext(audit.WithUsername(ctx, username))
+		c.Next()
+	}
+}
+
+// Roles looks up the current role of a user.
+type Roles interface {
+	GetRole(ctx context.Context, username string) (string, error)
+}
+
+// LoadRole must follow AuthMiddleware. It replaces the role of the token with
+// the user's current one, so that a demoted admin loses access at once
+// instead of when the token expires.
+func LoadRole(roles Roles) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !loadRole(c, roles) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole must follow AuthMiddleware, the user's current role is checked,
+// not the one in the token.
+func RequireRole(roles Roles, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !loadRole(c, roles) {
+			return
+		}
+		if c.GetString("role") != role {
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
+
+func loadRole(c *gin.Context, roles Roles) bool {
+	role, err := roles.GetRole(c.Request.Context(), c.GetString("username"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Abort()
+		return false
+	}
+	c.Set("role", role)
+	return true
+}
@@ -1,19 +1,26 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"shop/domain"
+	"shop/pkg/logger"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
+var testJWT = JWT{SecretKey: "secret", TokenTTL: time.Hour}
+
 func Setup() *gin.Engine {
 	router := gin.New()
-	router.Use(AuthMiddleware())
+	router.Use(AuthMiddleware(testJWT))
 	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
 
 	return router
@@ -52,7 +59,7 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 	router := Setup()
 
 	user := domain.User{Username: "test", Password: "test"}
-	validToken, err := JWT{}.GenerateToken(&user)
+	validToken, err := testJWT.GenerateToken(&user)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -63,3 +70,61 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+type fakeRoles map[string]string
+
+func (r fakeRoles) GetRole(_ context.Context, username string) (string, error) {
+	return r[username], nil
+}
+
+func TestRequireRole_UsesCurrentRole(t *testing.T) {
+	router := gin.New()
+	router.Use(AuthMiddleware(testJWT), RequireRole(fakeRoles{"admin": domain.RoleAdmin, "promoted": domain.RoleAdmin}, domain.RoleAdmin))
+	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, test := range []struct {
+		user     domain.User
+		expected int
+	}{
+		{user: domain.User{Username: "admin", Role: domain.RoleAdmin}, expected: http.StatusOK},
+		{user: domain.User{Username: "demoted", Role: domain.RoleAdmin}, expected: http.StatusForbidden},
+		{user: domain.User{Username: "promoted", Role: domain.RoleUser}, expected: http.StatusOK},
+	} {
+		token, err := testJWT.GenerateToken(&test.user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/test", nil)
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		router.ServeHTTP(w, req)
+		assert.Equal(t, test.expected, w.Code, test.user.Username)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	router := gin.New()
+	router.Use(RequestID())
+	var logged log.Fields
+	router.GET("/test", func(c *gin.Context) {
+		logged = logger.FromContext(c.Request.Context()).Data
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "req-42", w.Header().Get(RequestIDHeader))
+	assert.Equal(t, log.Fields{"request_id": "req-42", "method": "GET", "route": "/test"}, logged)
+
+	for _, incoming := range []string{"", "bad id\nwith newline"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/test", nil)
+		req.Header.Set(RequestIDHeader, incoming)
+		router.ServeHTTP(w, req)
+		generated := w.Header().Get(RequestIDHeader)
+		assert.NoError(t, uuid.Validate(generated))
+		assert.Equal(t, generated, logged["request_id"])
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"regexp"
+	"time"
+
+	"shop/pkg/audit"
+	"shop/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// Incoming IDs are echoed into logs and responses, so only short tokens
+// without spaces or control characters are accepted.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID accepts the caller's X-Request-ID or generates one, echoes it in
+// the response and adds it, together with the route, to the request logger.
+// The ID, client IP and user agent are also recorded for the audit log.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Set("request_id", requestID)
+
+		ctx := logger.WithFields(c.Request.Context(), log.Fields{
+			"request_id": requestID,
+			"method":     c.Request.Method,
+			"route":      c.FullPath(),
+		})
+		ctx = audit.WithActor(ctx, audit.Actor{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: requestID,
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// AccessLog logs every request once it is served. It reads the logger after
+// the handlers have run, so the username set by AuthMiddleware is included.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		entry := logger.FromContext(c.Request.Context()).WithFields(log.Fields{
+			"status":   c.Writer.Status(),
+			"latency":  time.Since(start).String(),
+			"path":     c.Request.URL.Path,
+			"client":   c.ClientIP(),
+			"response": c.Writer.Size(),
+		})
+		switch {
+		case c.Writer.Status() >= 500:
+			entry.Error("request served")
+		case c.Writer.Status() >= 400:
+			entry.Warn("request served")
+		default:
+			entry.Info("request served")
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationsHandler lists the user's latest notifications, such as gifts
+// they have received.
+func (h *Handler) NotificationsHandler(c *gin.Context) {
+	notifications, err := h.service.ListNotifications(c.Request.Context(), c.MustGet("username").(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications})
+}
+
+func (h *Handler) MarkNotificationsReadHandler(c *gin.Context) {
+	marked, err := h.service.MarkNotificationsRead(c.Request.Context(), c.MustGet("username").(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
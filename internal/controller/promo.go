@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"shop/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) CreatePromoCodeHandler(c *gin.Context) {
+	var req struct {
+		Code           string   `json:"code"`
+		Kind           string   `json:"kind"`
+		Amount         float64  `json:"amount"`
+		Items          []string `json:"items"`
+		StartsAt       string   `json:"starts_at"`
+		EndsAt         string   `json:"ends_at"`
+		MaxUses        int      `json:"max_uses"`
+		MaxUsesPerUser int      `json:"max_uses_per_user"`
+		Stackable      bool     `json:"stackable"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	code := normalizePromoCode(req.Code)
+	switch {
+	case code == "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+		return
+	case req.Kind != domain.DiscountPercent && req.Kind != domain.DiscountFixed:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be percent or fixed"})
+		return
+	case req.Amount <= 0 || (req.Kind == domain.DiscountPercent && req.Amount > 100):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be positive, and at most 100 for percent"})
+		return
+	case req.MaxUses < 0 || req.MaxUsesPerUser < 0:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "usage caps cannot be negative"})
+		return
+	}
+
+	startsAt, err := promoTime(req.StartsAt, "starts_at")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	endsAt, err := promoTime(req.EndsAt, "ends_at")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+		return
+	}
+
+	promo, err := h.service.CreatePromoCode(c.Request.Context(), &domain.PromoCode{
+		Code:           code,
+		Kind:           req.Kind,
+		Amount:         req.Amount,
+		Items:          req.Items,
+		StartsAt:       startsAt,
+		EndsAt:         endsAt,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+		Stackable:      req.Stackable,
+		CreatedBy:      c.MustGet("username").(string),
+	})
+	if err != nil {
+		promoError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, promo)
+}
+
+func (h *Handler) ListPromoCodesHandler(c *gin.Context) {
+	codes, err := h.service.ListPromoCodes(c.Request.Context())
+	if err != nil {
+		promoError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"promo_codes": codes})
+}
+
+func (h *Handler) DisablePromoCodeHandler(c *gin.Context) {
+	h.setPromoCodeActive(c, false)
+}
+
+func (h *Handler) EnablePromoCodeHandler(c *gin.Context) {
+	h.setPromoCodeActive(c, true)
+}
+
+func (h *Handler) setPromoCodeActive(c *gin.Context, active bool) {
+	promo, err := h.service.SetPromoCodeActive(c.Request.Context(), normalizePromoCode(c.Param("code")), active)
+	if err != nil {
+		promoError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, promo)
+}
+
+// promoCodes merges the single code and the list of codes of a purchase,
+// codes are case-insensitive and cannot repeat.
+func promoCodes(code string, codes []string) ([]string, error) {
+	if code != "" {
+		codes = append([]string{code}, codes...)
+	}
+	var result []string
+	for _, code := range codes {
+		code = normalizePromoCode(code)
+		if code == "" {
+			return nil, errors.New("promo code cannot be empty")
+		}
+		if slices.Contains(result, code) {
+			return nil, fmt.Errorf("duplicate promo code %s", code)
+		}
+		result = append(result, code)
+	}
+	return result, nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func promoTime(value, field string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s, expected RFC 3339 time", field)
+	}
+	return &t, nil
+}
+
+func isPromoError(err error) bool {
+	return errors.Is(err, domain.ErrNoPromoCode) || errors.Is(err, domain.ErrPromoCodeNotApplicable) ||
+		errors.Is(err, domain.ErrPromoCodeUsedUp) || errors.Is(err, domain.ErrPromoCodesNotStackable)
+}
+
+func promoError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNoPromoCode):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPromoCodeExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrNoMerch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		adjustmentError(c, err)
+	}
+}
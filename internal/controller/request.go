@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"shop/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateCoinRequestHandler asks another user for coins. The payer sees the
+// request among their pending requests and can accept or decline it.
+func (h *Handler) CreateCoinRequestHandler(c *gin.Context) {
+	var req struct {
+		PayerUsername string  `json:"payer_username"`
+		Amount        float64 `json:"amount"`
+		Message       string  `json:"message"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.PayerUsername == "" || req.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid fields"})
+		return
+	}
+	requester := c.MustGet("username").(string)
+	if req.PayerUsername == requester {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot request coins from yourself"})
+		return
+	}
+	note, err := validateNote(req.Message, "", false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request, err := h.service.CreateCoinRequest(c.Request.Context(), requester, req.PayerUsername, req.Amount, note.Message)
+	if err != nil {
+		requestError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, request)
+}
+
+// PendingRequestsHandler lists the open requests the user has received
+// (incoming) and sent (outgoing).
+func (h *Handler) PendingRequestsHandler(c *gin.Context) {
+	pending, err := h.service.ListPendingRequests(c.Request.Context(), c.MustGet("username").(string))
+	if err != nil {
+		requestError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, pending)
+}
+
+func (h *Handler) AcceptCoinRequestHandler(c *gin.Context) {
+	h.resolveCoinRequest(c, h.service.AcceptCoinRequest)
+}
+
+func (h *Handler) DeclineCoinRequestHandler(c *gin.Context) {
+	h.resolveCoinRequest(c, h.service.DeclineCoinRequest)
+}
+
+func (h *Handler) CancelCoinRequestHandler(c *gin.Context) {
+	h.resolveCoinRequest(c, h.service.CancelCoinRequest)
+}
+
+func (h *Handler) resolveCoinRequest(c *gin.Context, resolve func(context.Context, int64, string) (*domain.CoinRequest, error)) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request id"})
+		return
+	}
+	request, err := resolve(c.Request.Context(), id, c.MustGet("username").(string))
+	if err != nil {
+		requestError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+func requestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNoCoinRequest):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCoinRequestClosed), errors.Is(err, domain.ErrCoinRequestExpired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		transferError(c, err)
+	}
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"shop/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleTransferHandler sets up a transfer to be made at execute_at, for
+// example on a colleague's birthday.
+func (h *Handler) ScheduleTransferHandler(c *gin.Context) {
+	var req struct {
+		ReceiverUsername string  `json:"receiver_username"`
+		Amount           float64 `json:"amount"`
+		ExecuteAt        string  `json:"execute_at"`
+		Message          string  `json:"message"`
+		Category         string  `json:"category"`
+		Private          bool    `json:"private"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.ReceiverUsername == "" || req.Amount <= 0 || req.ExecuteAt == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid fields"})
+		return
+	}
+	executeAt, err := time.Parse(time.RFC3339, req.ExecuteAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid execute_at, expected RFC 3339 time"})
+		return
+	}
+	if !executeAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "execute_at must be in the future"})
+		return
+	}
+	note, err := validateNote(req.Message, req.Category, req.Private)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sender := c.MustGet("username").(string)
+	scheduled, err := h.service.ScheduleTransfer(c.Request.Context(), req.ReceiverUsername, sender, req.Amount, note, executeAt)
+	if err != nil {
+		scheduledError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, scheduled)
+}
+
+// ScheduledTransfersHandler lists the transfers the user has scheduled with
+// their status, failed ones carry the reason.
+func (h *Handler) ScheduledTransfersHandler(c *gin.Context) {
+	transfers, err := h.service.ListScheduledTransfers(c.Request.Context(), c.MustGet("username").(string))
+	if err != nil {
+		scheduledError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"scheduled": transfers})
+}
+
+func (h *Handler) CancelScheduledTransferHandler(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scheduled transfer id"})
+		return
+	}
+	scheduled, err := h.service.CancelScheduledTransfer(c.Request.Context(), id, c.MustGet("username").(string))
+	if err != nil {
+		scheduledError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, scheduled)
+}
+
+func scheduledError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNoScheduledTransfer):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrScheduledTransferClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		transferError(c, err)
+	}
+}
@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"shop/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxWalletNameLength = 100
+
+// ListWalletsHandler lists the wallets the user is a member of.
+func (h *Handler) ListWalletsHandler(c *gin.Context) {
+	wallets, err := h.service.ListWallets(c.Request.Context(), c.MustGet("username").(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"wallets": wallets})
+}
+
+// CreateWalletHandler creates a wallet owned by the user. Purchases need an
+// owner's approval, or quorum approvals from any members when quorum is set.
+func (h *Handler) CreateWalletHandler(c *gin.Context) {
+	var req struct {
+		Name    string   `json:"name"`
+		Quorum  int      `json:"quorum"`
+		Members []string `json:"members"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxWalletNameLength || req.Quorum < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid fields"})
+		return
+	}
+	username := c.MustGet("username").(string)
+	if req.Quorum > len(req.Members)+1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quorum cannot exceed the number of members"})
+		return
+	}
+
+	wallet, err := h.service.CreateWallet(c.Request.Context(), username, name, req.Quorum, req.Members)
+	if err != nil {
+		walletError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, wallet)
+}
+
+// WalletInfoHandler shows the wallet's balance, members, contributions and
+// purchases, like /api/info does for the user.
+func (h *Handler) WalletInfoHandler(c *gin.Context) {
+	id, ok := walletID(c)
+	if !ok {
+		return
+	}
+	info, err := h.service.GetWalletInfo(c.Request.Context(), id, c.MustGet("username").(string))
+	if err != nil {
+		walletError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+// SaveWalletMemberHandler adds a member or changes their role.
+func (h *Handler) SaveWalletMemberHandler(c *gin.Context) {
+	id, ok := walletID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Username string `json:"username"`
+		Role     string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.Role == "" {
+		req.Role = domain.WalletRoleMember
+	}
+	if req.Username == "" || (req.Role != domain.WalletRoleMember && req.Role != domain.WalletRoleOwner) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid fields"})
+		return
+	}
+
+	member := &domain.WalletMember{WalletID: id, Username: req.Username, Role: req.Role}
+	if err := h.service.SaveWalletMember(c.Request.Context(), c.MustGet("username").(string), member); err != nil {
+		walletError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveWalletMemberHandler removes a member, members can remove themselves.
+func (h *Handler) RemoveWalletMemberHandler(c *gin.Context) {
+	id, ok := walletID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.RemoveWalletMember(c.Request.Context(), id, c.MustGet("username").(string), c.Param("username")); err != nil {
+		walletError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ContributeHandler moves coins from the user's balance to the wallet.
+func (h *Handler) ContributeHandler(c *gin.Context) {
+	id, ok := walletID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Amount float64 `json:"amount"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid fields"})
+		return
+	}
+
+	contribution, err := h.service.ContributeToWallet(c.Request.Context(), id, c.MustGet("username").(string), req.Amount)
+	if err != nil {
+		walletError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, contribution)
+}
+
+// ProposeWalletPurchaseHandler proposes buying an item from the wallet. The
+// item is bought at once when the proposer is an owner or meets the quorum.
+func (h *Handler) ProposeWalletPurchaseHandler(c *gin.Context) {
+	id, ok := walletID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Item string `json:"item"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.Item == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid fields"})
+		return
+	}
+
+	purchase, err := h.service.ProposeWalletPurchase(c.Request.Context(), id, c.MustGet("username").(string), req.Item)
+	if err != nil {
+		walletError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, purchase)
+}
+
+func (h *Handler) ApproveWalletPurchaseHandler(c *gin.Context) {
+	id, purchaseID, ok := walletPurchaseID(c)
+	if !ok {
+		return
+	}
+	purchase, err := h.service.ApproveWalletPurchase(c.Request.Context(), id, purchaseID, c.MustGet("username").(string))
+	if err != nil {
+		walletError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, purchase)
+}
+
+func (h *Handler) RejectWalletPurchaseHandler(c *gin.Context) {
+	id, purchaseID, ok := walletPurchaseID(c)
+	if !ok {
+		return
+	}
+	purchase, err := h.service.RejectWalletPurchase(c.Request.Context(), id, purchaseID, c.MustGet("username").(string))
+	if err != nil {
+		walletError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, purchase)
+}
+
+func walletID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func walletPurchaseID(c *gin.Context) (int64, int64, bool) {
+	id, ok := walletID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	purchaseID, err := strconv.ParseInt(c.Param("pid"), 10, 64)
+	if err != nil || purchaseID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase id"})
+		return 0, 0, false
+	}
+	return id, purchaseID, true
+}
+
+func walletError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNoWallet), errors.Is(err, domain.ErrNoWalletPurchase), errors.Is(err, domain.ErrNoMerch):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrNotWalletOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrWalletPurchaseClosed), errors.Is(err, domain.ErrLastWalletOwner), errors.Is(err, domain.ErrOutOfStock):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		transferError(c, err)
+	}
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"shop/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WishlistHandler returns the user's wishlist with the coins they lack to
+// buy each item and all of them, given their available balance.
+func (h *Handler) WishlistHandler(c *gin.Context) {
+	wishlist, err := h.service.GetWishlist(c.Request.Context(), c.MustGet("username").(string))
+	if err != nil {
+		wishlistError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, wishlist)
+}
+
+// SaveWishlistItemHandler adds the item to the wishlist, the optional body
+// subscribes to alerts when the item is restocked or gets cheaper. Saving an
+// item again replaces its alerts.
+func (h *Handler) SaveWishlistItemHandler(c *gin.Context) {
+	var req struct {
+		NotifyRestock   bool `json:"notify_restock"`
+		NotifyPriceDrop bool `json:"notify_price_drop"`
+	}
+	if c.Request.Body != nil {
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+
+	item, err := h.service.SaveWishlistItem(c.Request.Context(), &domain.WishlistItem{
+		Username:        c.MustGet("username").(string),
+		MerchName:       c.Param("item"),
+		NotifyRestock:   req.NotifyRestock,
+		NotifyPriceDrop: req.NotifyPriceDrop,
+	})
+	if err != nil {
+		wishlistError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *Handler) RemoveWishlistItemHandler(c *gin.Context) {
+	err := h.service.RemoveWishlistItem(c.Request.Context(), c.MustGet("username").(string), c.Param("item"))
+	if err != nil {
+		wishlistError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func wishlistError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNoMerch), errors.Is(err, domain.ErrNotInWishlist), errors.Is(err, domain.ErrNoSuchUser):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -5,8 +5,10 @@
 package mock_repository
 
 import (
+	context "context"
 	reflect "reflect"
 	domain "shop/domain"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	gorm "gorm.io/gorm"
@@ -35,33 +37,78 @@ func (m *MockUsers) EXPECT() *MockUsersMockRecorder {
 	return m.recorder
 }
 
-// GetUserByUsername mocks_repo base method.
-func (m *MockUsers) GetUserByUsername(arg0 string) (*domain.User, error) {
+// CreateUser mocks base method.
+func (m *MockUsers) CreateUser(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.User) (*domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByUsername", arg0)
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockUsersMockRecorder) CreateUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUsers)(nil).CreateUser), arg0, arg1, arg2)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockUsers) GetUserByUsername(arg0 context.Context, arg1 string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", arg0, arg1)
 	ret0, _ := ret[0].(*domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserByUsername indicates an expected call of GetUserByUsername.
-func (mr *MockUsersMockRecorder) GetUserByUsername(arg0 interface{}) *gomock.Call {
+func (mr *MockUsersMockRecorder) GetUserByUsername(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUsers)(nil).GetUserByUsername), arg0, arg1)
+}
+
+// ListActiveUsers mocks base method.
+func (m *MockUsers) ListActiveUsers(ctx context.Context, tx *gorm.DB, role, department string) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveUsers", ctx, tx, role, department)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveUsers indicates an expected call of ListActiveUsers.
+func (mr *MockUsersMockRecorder) ListActiveUsers(ctx, tx, role, department interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveUsers", reflect.TypeOf((*MockUsers)(nil).ListActiveUsers), ctx, tx, role, department)
+}
+
+// LockUserByUsername mocks base method.
+func (m *MockUsers) LockUserByUsername(arg0 context.Context, arg1 *gorm.DB, arg2 string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUserByUsername", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockUserByUsername indicates an expected call of LockUserByUsername.
+func (mr *MockUsersMockRecorder) LockUserByUsername(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUsers)(nil).GetUserByUsername), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUserByUsername", reflect.TypeOf((*MockUsers)(nil).LockUserByUsername), arg0, arg1, arg2)
 }
 
-// UpdateUser mocks_repo base method.
-func (m *MockUsers) UpdateUser(arg0 *gorm.DB, arg1 *domain.User) error {
+// UpdateUser mocks base method.
+func (m *MockUsers) UpdateUser(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.User) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateUser indicates an expected call of UpdateUser.
-func (mr *MockUsersMockRecorder) UpdateUser(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockUsersMockRecorder) UpdateUser(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUsers)(nil).UpdateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUsers)(nil).UpdateUser), arg0, arg1, arg2)
 }
 
 // MockMerch is a mock of Merch interface.
@@ -87,19 +134,277 @@ func (m *MockMerch) EXPECT() *MockMerchMockRecorder {
 	return m.recorder
 }
 
-// GetMerchByName mocks_repo base method.
-func (m *MockMerch) GetMerchByName(arg0 string) (*domain.Merch, error) {
+// CountByCategory mocks base method.
+func (m *MockMerch) CountByCategory(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByCategory", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByCategory indicates an expected call of CountByCategory.
+func (mr *MockMerchMockRecorder) CountByCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByCategory", reflect.TypeOf((*MockMerch)(nil).CountByCategory), arg0, arg1)
+}
+
+// Create mocks base method.
+func (m *MockMerch) Create(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.Merch) (*domain.Merch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Merch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMerchMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMerch)(nil).Create), arg0, arg1, arg2)
+}
+
+// CreateImage mocks base method.
+func (m *MockMerch) CreateImage(arg0 context.Context, arg1 *domain.MerchImage) (*domain.MerchImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImage", arg0, arg1)
+	ret0, _ := ret[0].(*domain.MerchImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateImage indicates an expected call of CreateImage.
+func (mr *MockMerchMockRecorder) CreateImage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImage", reflect.TypeOf((*MockMerch)(nil).CreateImage), arg0, arg1)
+}
+
+// CreatePrice mocks base method.
+func (m *MockMerch) CreatePrice(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.MerchPrice) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePrice", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePrice indicates an expected call of CreatePrice.
+func (mr *MockMerchMockRecorder) CreatePrice(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePrice", reflect.TypeOf((*MockMerch)(nil).CreatePrice), arg0, arg1, arg2)
+}
+
+// DeleteImage mocks base method.
+func (m *MockMerch) DeleteImage(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteImage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteImage indicates an expected call of DeleteImage.
+func (mr *MockMerchMockRecorder) DeleteImage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImage", reflect.TypeOf((*MockMerch)(nil).DeleteImage), arg0, arg1)
+}
+
+// GetImage mocks base method.
+func (m *MockMerch) GetImage(ctx context.Context, name string, id int64) (*domain.MerchImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImage", ctx, name, id)
+	ret0, _ := ret[0].(*domain.MerchImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImage indicates an expected call of GetImage.
+func (mr *MockMerchMockRecorder) GetImage(ctx, name, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImage", reflect.TypeOf((*MockMerch)(nil).GetImage), ctx, name, id)
+}
+
+// GetMerchByName mocks base method.
+func (m *MockMerch) GetMerchByName(arg0 context.Context, arg1 string) (*domain.Merch, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMerchByName", arg0)
+	ret := m.ctrl.Call(m, "GetMerchByName", arg0, arg1)
 	ret0, _ := ret[0].(*domain.Merch)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetMerchByName indicates an expected call of GetMerchByName.
-func (mr *MockMerchMockRecorder) GetMerchByName(arg0 interface{}) *gomock.Call {
+func (mr *MockMerchMockRecorder) GetMerchByName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerchByName", reflect.TypeOf((*MockMerch)(nil).GetMerchByName), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockMerch) List(arg0 context.Context, arg1 domain.MerchFilter) ([]domain.Merch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]domain.Merch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockMerchMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMerch)(nil).List), arg0, arg1)
+}
+
+// ListPrices mocks base method.
+func (m *MockMerch) ListPrices(arg0 context.Context, arg1 string) ([]domain.MerchPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPrices", arg0, arg1)
+	ret0, _ := ret[0].([]domain.MerchPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPrices indicates an expected call of ListPrices.
+func (mr *MockMerchMockRecorder) ListPrices(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPrices", reflect.TypeOf((*MockMerch)(nil).ListPrices), arg0, arg1)
+}
+
+// Lock mocks base method.
+func (m *MockMerch) Lock(ctx context.Context, tx *gorm.DB, name string) (*domain.Merch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, tx, name)
+	ret0, _ := ret[0].(*domain.Merch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockMerchMockRecorder) Lock(ctx, tx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockMerch)(nil).Lock), ctx, tx, name)
+}
+
+// TakeStock mocks base method.
+func (m *MockMerch) TakeStock(ctx context.Context, tx *gorm.DB, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeStock", ctx, tx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeStock indicates an expected call of TakeStock.
+func (mr *MockMerchMockRecorder) TakeStock(ctx, tx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeStock", reflect.TypeOf((*MockMerch)(nil).TakeStock), ctx, tx, name)
+}
+
+// Update mocks base method.
+func (m *MockMerch) Update(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.Merch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMerchMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMerch)(nil).Update), arg0, arg1, arg2)
+}
+
+// MockCategories is a mock of Categories interface.
+type MockCategories struct {
+	ctrl     *gomock.Controller
+	recorder *MockCategoriesMockRecorder
+}
+
+// MockCategoriesMockRecorder is the mock recorder for MockCategories.
+type MockCategoriesMockRecorder struct {
+	mock *MockCategories
+}
+
+// NewMockCategories creates a new mock instance.
+func NewMockCategories(ctrl *gomock.Controller) *MockCategories {
+	mock := &MockCategories{ctrl: ctrl}
+	mock.recorder = &MockCategoriesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCategories) EXPECT() *MockCategoriesMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockCategories) Create(arg0 context.Context, arg1 *domain.Category) (*domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCategoriesMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCategories)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *MockCategories) Delete(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCategoriesMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCategories)(nil).Delete), arg0, arg1)
+}
+
+// Get mocks base method.
+func (m *MockCategories) Get(arg0 context.Context, arg1 string) (*domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockCategoriesMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCategories)(nil).Get), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockCategories) List(arg0 context.Context) ([]domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0)
+	ret0, _ := ret[0].([]domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCategoriesMockRecorder) List(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCategories)(nil).List), arg0)
+}
+
+// Update mocks base method.
+func (m *MockCategories) Update(arg0 context.Context, arg1 *domain.Category) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCategoriesMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerchByName", reflect.TypeOf((*MockMerch)(nil).GetMerchByName), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCategories)(nil).Update), arg0, arg1)
 }
 
 // MockPurchases is a mock of Purchases interface.
@@ -125,34 +430,49 @@ func (m *MockPurchases) EXPECT() *MockPurchasesMockRecorder {
 	return m.recorder
 }
 
-// Create mocks_repo base method.
-func (m *MockPurchases) Create(arg0 *gorm.DB, arg1 *domain.Purchase) (*domain.Purchase, error) {
+// Create mocks base method.
+func (m *MockPurchases) Create(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.Purchase) (*domain.Purchase, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*domain.Purchase)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockPurchasesMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockPurchasesMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPurchases)(nil).Create), arg0, arg1, arg2)
+}
+
+// GetInventory mocks base method.
+func (m *MockPurchases) GetInventory(arg0 context.Context, arg1 string) ([]domain.InventoryItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInventory", arg0, arg1)
+	ret0, _ := ret[0].([]domain.InventoryItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInventory indicates an expected call of GetInventory.
+func (mr *MockPurchasesMockRecorder) GetInventory(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPurchases)(nil).Create), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInventory", reflect.TypeOf((*MockPurchases)(nil).GetInventory), arg0, arg1)
 }
 
-// GetPurchasesForUserByUserGUID mocks_repo base method.
-func (m *MockPurchases) GetPurchasesForUserByUserGUID(arg0 string) ([]domain.Purchase, error) {
+// GetPurchasesForUserByUsername mocks base method.
+func (m *MockPurchases) GetPurchasesForUserByUsername(arg0 context.Context, arg1 string) ([]domain.Purchase, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPurchasesForUserByUserGUID", arg0)
+	ret := m.ctrl.Call(m, "GetPurchasesForUserByUsername", arg0, arg1)
 	ret0, _ := ret[0].([]domain.Purchase)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPurchasesForUserByUserGUID indicates an expected call of GetPurchasesForUserByUserGUID.
-func (mr *MockPurchasesMockRecorder) GetPurchasesForUserByUserGUID(arg0 interface{}) *gomock.Call {
+// GetPurchasesForUserByUsername indicates an expected call of GetPurchasesForUserByUsername.
+func (mr *MockPurchasesMockRecorder) GetPurchasesForUserByUsername(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurchasesForUserByUserGUID", reflect.TypeOf((*MockPurchases)(nil).GetPurchasesForUserByUserGUID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurchasesForUserByUsername", reflect.TypeOf((*MockPurchases)(nil).GetPurchasesForUserByUsername), arg0, arg1)
 }
 
 // MockTransactions is a mock of Transactions interface.
@@ -178,32 +498,1369 @@ func (m *MockTransactions) EXPECT() *MockTransactionsMockRecorder {
 	return m.recorder
 }
 
-// Create mocks_repo base method.
-func (m *MockTransactions) Create(arg0 *gorm.DB, arg1 *domain.Transaction) (*domain.Transaction, error) {
+// Create mocks base method.
+func (m *MockTransactions) Create(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.Transaction) (*domain.Transaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*domain.Transaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockTransactionsMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockTransactionsMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTransactions)(nil).Create), arg0, arg1, arg2)
+}
+
+// GetTransactionsForUserByUsername mocks base method.
+func (m *MockTransactions) GetTransactionsForUserByUsername(arg0 context.Context, arg1 string) ([]domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionsForUserByUsername", arg0, arg1)
+	ret0, _ := ret[0].([]domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionsForUserByUsername indicates an expected call of GetTransactionsForUserByUsername.
+func (mr *MockTransactionsMockRecorder) GetTransactionsForUserByUsername(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTransactions)(nil).Create), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionsForUserByUsername", reflect.TypeOf((*MockTransactions)(nil).GetTransactionsForUserByUsername), arg0, arg1)
 }
 
-// GetTransactionsForUserByUserGUID mocks_repo base method.
-func (m *MockTransactions) GetTransactionsForUserByUserGUID(arg0 string) ([]domain.Transaction, error) {
+// ListFeed mocks base method.
+func (m *MockTransactions) ListFeed(arg0 context.Context, arg1 domain.FeedFilter) ([]domain.Transaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransactionsForUserByUserGUID", arg0)
+	ret := m.ctrl.Call(m, "ListFeed", arg0, arg1)
 	ret0, _ := ret[0].([]domain.Transaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTransactionsForUserByUserGUID indicates an expected call of GetTransactionsForUserByUserGUID.
-func (mr *MockTransactionsMockRecorder) GetTransactionsForUserByUserGUID(arg0 interface{}) *gomock.Call {
+// ListFeed indicates an expected call of ListFeed.
+func (mr *MockTransactionsMockRecorder) ListFeed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeed", reflect.TypeOf((*MockTransactions)(nil).ListFeed), arg0, arg1)
+}
+
+// SentSince mocks base method.
+func (m *MockTransactions) SentSince(ctx context.Context, tx *gorm.DB, sender string, since time.Time) (*domain.TransferStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SentSince", ctx, tx, sender, since)
+	ret0, _ := ret[0].(*domain.TransferStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SentSince indicates an expected call of SentSince.
+func (mr *MockTransactionsMockRecorder) SentSince(ctx, tx, sender, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SentSince", reflect.TypeOf((*MockTransactions)(nil).SentSince), ctx, tx, sender, since)
+}
+
+// MockBalanceAdjustments is a mock of BalanceAdjustments interface.
+type MockBalanceAdjustments struct {
+	ctrl     *gomock.Controller
+	recorder *MockBalanceAdjustmentsMockRecorder
+}
+
+// MockBalanceAdjustmentsMockRecorder is the mock recorder for MockBalanceAdjustments.
+type MockBalanceAdjustmentsMockRecorder struct {
+	mock *MockBalanceAdjustments
+}
+
+// NewMockBalanceAdjustments creates a new mock instance.
+func NewMockBalanceAdjustments(ctrl *gomock.Controller) *MockBalanceAdjustments {
+	mock := &MockBalanceAdjustments{ctrl: ctrl}
+	mock.recorder = &MockBalanceAdjustmentsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBalanceAdjustments) EXPECT() *MockBalanceAdjustmentsMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockBalanceAdjustments) Create(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.BalanceAdjustment) (*domain.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockBalanceAdjustmentsMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBalanceAdjustments)(nil).Create), arg0, arg1, arg2)
+}
+
+// GetAdjustmentsForUserByUsername mocks base method.
+func (m *MockBalanceAdjustments) GetAdjustmentsForUserByUsername(arg0 context.Context, arg1 string) ([]domain.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdjustmentsForUserByUsername", arg0, arg1)
+	ret0, _ := ret[0].([]domain.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdjustmentsForUserByUsername indicates an expected call of GetAdjustmentsForUserByUsername.
+func (mr *MockBalanceAdjustmentsMockRecorder) GetAdjustmentsForUserByUsername(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdjustmentsForUserByUsername", reflect.TypeOf((*MockBalanceAdjustments)(nil).GetAdjustmentsForUserByUsername), arg0, arg1)
+}
+
+// MockAllowances is a mock of Allowances interface.
+type MockAllowances struct {
+	ctrl     *gomock.Controller
+	recorder *MockAllowancesMockRecorder
+}
+
+// MockAllowancesMockRecorder is the mock recorder for MockAllowances.
+type MockAllowancesMockRecorder struct {
+	mock *MockAllowances
+}
+
+// NewMockAllowances creates a new mock instance.
+func NewMockAllowances(ctrl *gomock.Controller) *MockAllowances {
+	mock := &MockAllowances{ctrl: ctrl}
+	mock.recorder = &MockAllowancesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAllowances) EXPECT() *MockAllowancesMockRecorder {
+	return m.recorder
+}
+
+// CreatePolicy mocks base method.
+func (m *MockAllowances) CreatePolicy(arg0 context.Context, arg1 *domain.AllowancePolicy) (*domain.AllowancePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePolicy", arg0, arg1)
+	ret0, _ := ret[0].(*domain.AllowancePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePolicy indicates an expected call of CreatePolicy.
+func (mr *MockAllowancesMockRecorder) CreatePolicy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePolicy", reflect.TypeOf((*MockAllowances)(nil).CreatePolicy), arg0, arg1)
+}
+
+// CreateRun mocks base method.
+func (m *MockAllowances) CreateRun(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.AllowanceRun) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRun", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRun indicates an expected call of CreateRun.
+func (mr *MockAllowancesMockRecorder) CreateRun(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRun", reflect.TypeOf((*MockAllowances)(nil).CreateRun), arg0, arg1, arg2)
+}
+
+// GetPolicy mocks base method.
+func (m *MockAllowances) GetPolicy(arg0 context.Context, arg1 int64) (*domain.AllowancePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolicy", arg0, arg1)
+	ret0, _ := ret[0].(*domain.AllowancePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPolicy indicates an expected call of GetPolicy.
+func (mr *MockAllowancesMockRecorder) GetPolicy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicy", reflect.TypeOf((*MockAllowances)(nil).GetPolicy), arg0, arg1)
+}
+
+// LastScheduledRun mocks base method.
+func (m *MockAllowances) LastScheduledRun(arg0 context.Context, arg1 int64) (*domain.AllowanceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastScheduledRun", arg0, arg1)
+	ret0, _ := ret[0].(*domain.AllowanceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastScheduledRun indicates an expected call of LastScheduledRun.
+func (mr *MockAllowancesMockRecorder) LastScheduledRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastScheduledRun", reflect.TypeOf((*MockAllowances)(nil).LastScheduledRun), arg0, arg1)
+}
+
+// ListPolicies mocks base method.
+func (m *MockAllowances) ListPolicies(arg0 context.Context) ([]domain.AllowancePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPolicies", arg0)
+	ret0, _ := ret[0].([]domain.AllowancePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPolicies indicates an expected call of ListPolicies.
+func (mr *MockAllowancesMockRecorder) ListPolicies(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPolicies", reflect.TypeOf((*MockAllowances)(nil).ListPolicies), arg0)
+}
+
+// ListRuns mocks base method.
+func (m *MockAllowances) ListRuns(arg0 context.Context, arg1 int64) ([]domain.AllowanceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRuns", arg0, arg1)
+	ret0, _ := ret[0].([]domain.AllowanceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRuns indicates an expected call of ListRuns.
+func (mr *MockAllowancesMockRecorder) ListRuns(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRuns", reflect.TypeOf((*MockAllowances)(nil).ListRuns), arg0, arg1)
+}
+
+// SetPaused mocks base method.
+func (m *MockAllowances) SetPaused(arg0 context.Context, arg1 int64, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPaused", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPaused indicates an expected call of SetPaused.
+func (mr *MockAllowancesMockRecorder) SetPaused(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPaused", reflect.TypeOf((*MockAllowances)(nil).SetPaused), arg0, arg1, arg2)
+}
+
+// UpdateRun mocks base method.
+func (m *MockAllowances) UpdateRun(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.AllowanceRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRun", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRun indicates an expected call of UpdateRun.
+func (mr *MockAllowancesMockRecorder) UpdateRun(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRun", reflect.TypeOf((*MockAllowances)(nil).UpdateRun), arg0, arg1, arg2)
+}
+
+// MockCoinLots is a mock of CoinLots interface.
+type MockCoinLots struct {
+	ctrl     *gomock.Controller
+	recorder *MockCoinLotsMockRecorder
+}
+
+// MockCoinLotsMockRecorder is the mock recorder for MockCoinLots.
+type MockCoinLotsMockRecorder struct {
+	mock *MockCoinLots
+}
+
+// NewMockCoinLots creates a new mock instance.
+func NewMockCoinLots(ctrl *gomock.Controller) *MockCoinLots {
+	mock := &MockCoinLots{ctrl: ctrl}
+	mock.recorder = &MockCoinLotsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCoinLots) EXPECT() *MockCoinLotsMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockCoinLots) Consume(ctx context.Context, tx *gorm.DB, username string, amount float64) ([]domain.CoinLot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, tx, username, amount)
+	ret0, _ := ret[0].([]domain.CoinLot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockCoinLotsMockRecorder) Consume(ctx, tx, username, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockCoinLots)(nil).Consume), ctx, tx, username, amount)
+}
+
+// ExpiredAmount mocks base method.
+func (m *MockCoinLots) ExpiredAmount(ctx context.Context, tx *gorm.DB, username string, cutoff time.Time) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpiredAmount", ctx, tx, username, cutoff)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpiredAmount indicates an expected call of ExpiredAmount.
+func (mr *MockCoinLotsMockRecorder) ExpiredAmount(ctx, tx, username, cutoff interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpiredAmount", reflect.TypeOf((*MockCoinLots)(nil).ExpiredAmount), ctx, tx, username, cutoff)
+}
+
+// Grant mocks base method.
+func (m *MockCoinLots) Grant(arg0 context.Context, arg1 *gorm.DB, arg2 []domain.CoinLot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Grant", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Grant indicates an expected call of Grant.
+func (mr *MockCoinLotsMockRecorder) Grant(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Grant", reflect.TypeOf((*MockCoinLots)(nil).Grant), arg0, arg1, arg2)
+}
+
+// ListExpiredOwners mocks base method.
+func (m *MockCoinLots) ListExpiredOwners(ctx context.Context, cutoff time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredOwners", ctx, cutoff)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiredOwners indicates an expected call of ListExpiredOwners.
+func (mr *MockCoinLotsMockRecorder) ListExpiredOwners(ctx, cutoff interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredOwners", reflect.TypeOf((*MockCoinLots)(nil).ListExpiredOwners), ctx, cutoff)
+}
+
+// ListOpenLots mocks base method.
+func (m *MockCoinLots) ListOpenLots(arg0 context.Context, arg1 string) ([]domain.CoinLot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOpenLots", arg0, arg1)
+	ret0, _ := ret[0].([]domain.CoinLot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOpenLots indicates an expected call of ListOpenLots.
+func (mr *MockCoinLotsMockRecorder) ListOpenLots(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpenLots", reflect.TypeOf((*MockCoinLots)(nil).ListOpenLots), arg0, arg1)
+}
+
+// MockCoinRequests is a mock of CoinRequests interface.
+type MockCoinRequests struct {
+	ctrl     *gomock.Controller
+	recorder *MockCoinRequestsMockRecorder
+}
+
+// MockCoinRequestsMockRecorder is the mock recorder for MockCoinRequests.
+type MockCoinRequestsMockRecorder struct {
+	mock *MockCoinRequests
+}
+
+// NewMockCoinRequests creates a new mock instance.
+func NewMockCoinRequests(ctrl *gomock.Controller) *MockCoinRequests {
+	mock := &MockCoinRequests{ctrl: ctrl}
+	mock.recorder = &MockCoinRequestsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCoinRequests) EXPECT() *MockCoinRequestsMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockCoinRequests) Create(arg0 context.Context, arg1 *domain.CoinRequest) (*domain.CoinRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(*domain.CoinRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCoinRequestsMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCoinRequests)(nil).Create), arg0, arg1)
+}
+
+// ExpirePending mocks base method.
+func (m *MockCoinRequests) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePending", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpirePending indicates an expected call of ExpirePending.
+func (mr *MockCoinRequestsMockRecorder) ExpirePending(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePending", reflect.TypeOf((*MockCoinRequests)(nil).ExpirePending), ctx, now)
+}
+
+// ListPending mocks base method.
+func (m *MockCoinRequests) ListPending(ctx context.Context, username string, now time.Time) ([]domain.CoinRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPending", ctx, username, now)
+	ret0, _ := ret[0].([]domain.CoinRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPending indicates an expected call of ListPending.
+func (mr *MockCoinRequestsMockRecorder) ListPending(ctx, username, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPending", reflect.TypeOf((*MockCoinRequests)(nil).ListPending), ctx, username, now)
+}
+
+// Lock mocks base method.
+func (m *MockCoinRequests) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.CoinRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, tx, id)
+	ret0, _ := ret[0].(*domain.CoinRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockCoinRequestsMockRecorder) Lock(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockCoinRequests)(nil).Lock), ctx, tx, id)
+}
+
+// Update mocks base method.
+func (m *MockCoinRequests) Update(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.CoinRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCoinRequestsMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCoinRequests)(nil).Update), arg0, arg1, arg2)
+}
+
+// MockScheduledTransfers is a mock of ScheduledTransfers interface.
+type MockScheduledTransfers struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduledTransfersMockRecorder
+}
+
+// MockScheduledTransfersMockRecorder is the mock recorder for MockScheduledTransfers.
+type MockScheduledTransfersMockRecorder struct {
+	mock *MockScheduledTransfers
+}
+
+// NewMockScheduledTransfers creates a new mock instance.
+func NewMockScheduledTransfers(ctrl *gomock.Controller) *MockScheduledTransfers {
+	mock := &MockScheduledTransfers{ctrl: ctrl}
+	mock.recorder = &MockScheduledTransfersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduledTransfers) EXPECT() *MockScheduledTransfersMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockScheduledTransfers) Create(arg0 context.Context, arg1 *domain.ScheduledTransfer) (*domain.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(*domain.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockScheduledTransfersMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockScheduledTransfers)(nil).Create), arg0, arg1)
+}
+
+// ListBySender mocks base method.
+func (m *MockScheduledTransfers) ListBySender(arg0 context.Context, arg1 string) ([]domain.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBySender", arg0, arg1)
+	ret0, _ := ret[0].([]domain.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBySender indicates an expected call of ListBySender.
+func (mr *MockScheduledTransfersMockRecorder) ListBySender(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySender", reflect.TypeOf((*MockScheduledTransfers)(nil).ListBySender), arg0, arg1)
+}
+
+// ListDue mocks base method.
+func (m *MockScheduledTransfers) ListDue(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDue", ctx, now, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDue indicates an expected call of ListDue.
+func (mr *MockScheduledTransfersMockRecorder) ListDue(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDue", reflect.TypeOf((*MockScheduledTransfers)(nil).ListDue), ctx, now, limit)
+}
+
+// Lock mocks base method.
+func (m *MockScheduledTransfers) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, tx, id)
+	ret0, _ := ret[0].(*domain.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockScheduledTransfersMockRecorder) Lock(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockScheduledTransfers)(nil).Lock), ctx, tx, id)
+}
+
+// Update mocks base method.
+func (m *MockScheduledTransfers) Update(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.ScheduledTransfer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockScheduledTransfersMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockScheduledTransfers)(nil).Update), arg0, arg1, arg2)
+}
+
+// MockHolds is a mock of Holds interface.
+type MockHolds struct {
+	ctrl     *gomock.Controller
+	recorder *MockHoldsMockRecorder
+}
+
+// MockHoldsMockRecorder is the mock recorder for MockHolds.
+type MockHoldsMockRecorder struct {
+	mock *MockHolds
+}
+
+// NewMockHolds creates a new mock instance.
+func NewMockHolds(ctrl *gomock.Controller) *MockHolds {
+	mock := &MockHolds{ctrl: ctrl}
+	mock.recorder = &MockHoldsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHolds) EXPECT() *MockHoldsMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockHolds) Create(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.Hold) (*domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockHoldsMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockHolds)(nil).Create), arg0, arg1, arg2)
+}
+
+// ListActive mocks base method.
+func (m *MockHolds) ListActive(arg0 context.Context, arg1 string) ([]domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActive", arg0, arg1)
+	ret0, _ := ret[0].([]domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActive indicates an expected call of ListActive.
+func (mr *MockHoldsMockRecorder) ListActive(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActive", reflect.TypeOf((*MockHolds)(nil).ListActive), arg0, arg1)
+}
+
+// ListExpired mocks base method.
+func (m *MockHolds) ListExpired(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpired", ctx, now, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpired indicates an expected call of ListExpired.
+func (mr *MockHoldsMockRecorder) ListExpired(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpired", reflect.TypeOf((*MockHolds)(nil).ListExpired), ctx, now, limit)
+}
+
+// Lock mocks base method.
+func (m *MockHolds) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, tx, id)
+	ret0, _ := ret[0].(*domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockHoldsMockRecorder) Lock(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockHolds)(nil).Lock), ctx, tx, id)
+}
+
+// Update mocks base method.
+func (m *MockHolds) Update(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.Hold) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockHoldsMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockHolds)(nil).Update), arg0, arg1, arg2)
+}
+
+// MockPurchaseApprovals is a mock of PurchaseApprovals interface.
+type MockPurchaseApprovals struct {
+	ctrl     *gomock.Controller
+	recorder *MockPurchaseApprovalsMockRecorder
+}
+
+// MockPurchaseApprovalsMockRecorder is the mock recorder for MockPurchaseApprovals.
+type MockPurchaseApprovalsMockRecorder struct {
+	mock *MockPurchaseApprovals
+}
+
+// NewMockPurchaseApprovals creates a new mock instance.
+func NewMockPurchaseApprovals(ctrl *gomock.Controller) *MockPurchaseApprovals {
+	mock := &MockPurchaseApprovals{ctrl: ctrl}
+	mock.recorder = &MockPurchaseApprovalsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPurchaseApprovals) EXPECT() *MockPurchaseApprovalsMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockPurchaseApprovals) Create(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.PurchaseApproval) (*domain.PurchaseApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.PurchaseApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPurchaseApprovalsMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPurchaseApprovals)(nil).Create), arg0, arg1, arg2)
+}
+
+// ListAssigned mocks base method.
+func (m *MockPurchaseApprovals) ListAssigned(ctx context.Context, username string, admin bool) ([]domain.PurchaseApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAssigned", ctx, username, admin)
+	ret0, _ := ret[0].([]domain.PurchaseApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAssigned indicates an expected call of ListAssigned.
+func (mr *MockPurchaseApprovalsMockRecorder) ListAssigned(ctx, username, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAssigned", reflect.TypeOf((*MockPurchaseApprovals)(nil).ListAssigned), ctx, username, admin)
+}
+
+// ListDue mocks base method.
+func (m *MockPurchaseApprovals) ListDue(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDue", ctx, now, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDue indicates an expected call of ListDue.
+func (mr *MockPurchaseApprovalsMockRecorder) ListDue(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDue", reflect.TypeOf((*MockPurchaseApprovals)(nil).ListDue), ctx, now, limit)
+}
+
+// ListRequested mocks base method.
+func (m *MockPurchaseApprovals) ListRequested(arg0 context.Context, arg1 string) ([]domain.PurchaseApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRequested", arg0, arg1)
+	ret0, _ := ret[0].([]domain.PurchaseApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRequested indicates an expected call of ListRequested.
+func (mr *MockPurchaseApprovalsMockRecorder) ListRequested(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRequested", reflect.TypeOf((*MockPurchaseApprovals)(nil).ListRequested), arg0, arg1)
+}
+
+// Lock mocks base method.
+func (m *MockPurchaseApprovals) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.PurchaseApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, tx, id)
+	ret0, _ := ret[0].(*domain.PurchaseApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockPurchaseApprovalsMockRecorder) Lock(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockPurchaseApprovals)(nil).Lock), ctx, tx, id)
+}
+
+// Update mocks base method.
+func (m *MockPurchaseApprovals) Update(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.PurchaseApproval) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockPurchaseApprovalsMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPurchaseApprovals)(nil).Update), arg0, arg1, arg2)
+}
+
+// MockWallets is a mock of Wallets interface.
+type MockWallets struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletsMockRecorder
+}
+
+// MockWalletsMockRecorder is the mock recorder for MockWallets.
+type MockWalletsMockRecorder struct {
+	mock *MockWallets
+}
+
+// NewMockWallets creates a new mock instance.
+func NewMockWallets(ctrl *gomock.Controller) *MockWallets {
+	mock := &MockWallets{ctrl: ctrl}
+	mock.recorder = &MockWalletsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWallets) EXPECT() *MockWalletsMockRecorder {
+	return m.recorder
+}
+
+// AddApproval mocks base method.
+func (m *MockWallets) AddApproval(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.WalletApproval) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddApproval", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddApproval indicates an expected call of AddApproval.
+func (mr *MockWalletsMockRecorder) AddApproval(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddApproval", reflect.TypeOf((*MockWallets)(nil).AddApproval), arg0, arg1, arg2)
+}
+
+// Create mocks base method.
+func (m *MockWallets) Create(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.Wallet) (*domain.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWalletsMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWallets)(nil).Create), arg0, arg1, arg2)
+}
+
+// CreateContribution mocks base method.
+func (m *MockWallets) CreateContribution(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.WalletContribution) (*domain.WalletContribution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateContribution", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.WalletContribution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateContribution indicates an expected call of CreateContribution.
+func (mr *MockWalletsMockRecorder) CreateContribution(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateContribution", reflect.TypeOf((*MockWallets)(nil).CreateContribution), arg0, arg1, arg2)
+}
+
+// CreatePurchase mocks base method.
+func (m *MockWallets) CreatePurchase(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.WalletPurchase) (*domain.WalletPurchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePurchase", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.WalletPurchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePurchase indicates an expected call of CreatePurchase.
+func (mr *MockWalletsMockRecorder) CreatePurchase(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePurchase", reflect.TypeOf((*MockWallets)(nil).CreatePurchase), arg0, arg1, arg2)
+}
+
+// Get mocks base method.
+func (m *MockWallets) Get(arg0 context.Context, arg1 int64) (*domain.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockWalletsMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockWallets)(nil).Get), arg0, arg1)
+}
+
+// ListContributions mocks base method.
+func (m *MockWallets) ListContributions(ctx context.Context, walletID int64) ([]domain.WalletContribution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListContributions", ctx, walletID)
+	ret0, _ := ret[0].([]domain.WalletContribution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListContributions indicates an expected call of ListContributions.
+func (mr *MockWalletsMockRecorder) ListContributions(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListContributions", reflect.TypeOf((*MockWallets)(nil).ListContributions), ctx, walletID)
+}
+
+// ListForUser mocks base method.
+func (m *MockWallets) ListForUser(arg0 context.Context, arg1 string) ([]domain.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListForUser", arg0, arg1)
+	ret0, _ := ret[0].([]domain.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListForUser indicates an expected call of ListForUser.
+func (mr *MockWalletsMockRecorder) ListForUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListForUser", reflect.TypeOf((*MockWallets)(nil).ListForUser), arg0, arg1)
+}
+
+// ListMembers mocks base method.
+func (m *MockWallets) ListMembers(ctx context.Context, tx *gorm.DB, walletID int64) ([]domain.WalletMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, tx, walletID)
+	ret0, _ := ret[0].([]domain.WalletMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockWalletsMockRecorder) ListMembers(ctx, tx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockWallets)(nil).ListMembers), ctx, tx, walletID)
+}
+
+// ListPurchases mocks base method.
+func (m *MockWallets) ListPurchases(ctx context.Context, walletID int64) ([]domain.WalletPurchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPurchases", ctx, walletID)
+	ret0, _ := ret[0].([]domain.WalletPurchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPurchases indicates an expected call of ListPurchases.
+func (mr *MockWalletsMockRecorder) ListPurchases(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPurchases", reflect.TypeOf((*MockWallets)(nil).ListPurchases), ctx, walletID)
+}
+
+// Lock mocks base method.
+func (m *MockWallets) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, tx, id)
+	ret0, _ := ret[0].(*domain.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockWalletsMockRecorder) Lock(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockWallets)(nil).Lock), ctx, tx, id)
+}
+
+// LockPurchase mocks base method.
+func (m *MockWallets) LockPurchase(ctx context.Context, tx *gorm.DB, walletID, id int64) (*domain.WalletPurchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockPurchase", ctx, tx, walletID, id)
+	ret0, _ := ret[0].(*domain.WalletPurchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockPurchase indicates an expected call of LockPurchase.
+func (mr *MockWalletsMockRecorder) LockPurchase(ctx, tx, walletID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockPurchase", reflect.TypeOf((*MockWallets)(nil).LockPurchase), ctx, tx, walletID, id)
+}
+
+// RemoveMember mocks base method.
+func (m *MockWallets) RemoveMember(ctx context.Context, tx *gorm.DB, walletID int64, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, tx, walletID, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockWalletsMockRecorder) RemoveMember(ctx, tx, walletID, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockWallets)(nil).RemoveMember), ctx, tx, walletID, username)
+}
+
+// SaveMember mocks base method.
+func (m *MockWallets) SaveMember(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.WalletMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMember indicates an expected call of SaveMember.
+func (mr *MockWalletsMockRecorder) SaveMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMember", reflect.TypeOf((*MockWallets)(nil).SaveMember), arg0, arg1, arg2)
+}
+
+// Update mocks base method.
+func (m *MockWallets) Update(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.Wallet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockWalletsMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockWallets)(nil).Update), arg0, arg1, arg2)
+}
+
+// UpdatePurchase mocks base method.
+func (m *MockWallets) UpdatePurchase(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.WalletPurchase) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePurchase", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePurchase indicates an expected call of UpdatePurchase.
+func (mr *MockWalletsMockRecorder) UpdatePurchase(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePurchase", reflect.TypeOf((*MockWallets)(nil).UpdatePurchase), arg0, arg1, arg2)
+}
+
+// MockPromoCodes is a mock of PromoCodes interface.
+type MockPromoCodes struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromoCodesMockRecorder
+}
+
+// MockPromoCodesMockRecorder is the mock recorder for MockPromoCodes.
+type MockPromoCodesMockRecorder struct {
+	mock *MockPromoCodes
+}
+
+// NewMockPromoCodes creates a new mock instance.
+func NewMockPromoCodes(ctrl *gomock.Controller) *MockPromoCodes {
+	mock := &MockPromoCodes{ctrl: ctrl}
+	mock.recorder = &MockPromoCodesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPromoCodes) EXPECT() *MockPromoCodesMockRecorder {
+	return m.recorder
+}
+
+// CompleteRedemptions mocks base method.
+func (m *MockPromoCodes) CompleteRedemptions(ctx context.Context, tx *gorm.DB, approvalID int64, purchaseGUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteRedemptions", ctx, tx, approvalID, purchaseGUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteRedemptions indicates an expected call of CompleteRedemptions.
+func (mr *MockPromoCodesMockRecorder) CompleteRedemptions(ctx, tx, approvalID, purchaseGUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteRedemptions", reflect.TypeOf((*MockPromoCodes)(nil).CompleteRedemptions), ctx, tx, approvalID, purchaseGUID)
+}
+
+// CountRedemptions mocks base method.
+func (m *MockPromoCodes) CountRedemptions(ctx context.Context, tx *gorm.DB, code, username string) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRedemptions", ctx, tx, code, username)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CountRedemptions indicates an expected call of CountRedemptions.
+func (mr *MockPromoCodesMockRecorder) CountRedemptions(ctx, tx, code, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRedemptions", reflect.TypeOf((*MockPromoCodes)(nil).CountRedemptions), ctx, tx, code, username)
+}
+
+// Create mocks base method.
+func (m *MockPromoCodes) Create(arg0 context.Context, arg1 *domain.PromoCode) (*domain.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(*domain.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPromoCodesMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPromoCodes)(nil).Create), arg0, arg1)
+}
+
+// CreateRedemptions mocks base method.
+func (m *MockPromoCodes) CreateRedemptions(arg0 context.Context, arg1 *gorm.DB, arg2 []domain.PromoRedemption) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRedemptions", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRedemptions indicates an expected call of CreateRedemptions.
+func (mr *MockPromoCodesMockRecorder) CreateRedemptions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRedemptions", reflect.TypeOf((*MockPromoCodes)(nil).CreateRedemptions), arg0, arg1, arg2)
+}
+
+// Get mocks base method.
+func (m *MockPromoCodes) Get(arg0 context.Context, arg1 string) (*domain.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(*domain.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockPromoCodesMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPromoCodes)(nil).Get), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockPromoCodes) List(arg0 context.Context) ([]domain.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0)
+	ret0, _ := ret[0].([]domain.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockPromoCodesMockRecorder) List(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockPromoCodes)(nil).List), arg0)
+}
+
+// Lock mocks base method.
+func (m *MockPromoCodes) Lock(ctx context.Context, tx *gorm.DB, codes []string) ([]domain.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, tx, codes)
+	ret0, _ := ret[0].([]domain.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockPromoCodesMockRecorder) Lock(ctx, tx, codes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockPromoCodes)(nil).Lock), ctx, tx, codes)
+}
+
+// ReleaseRedemptions mocks base method.
+func (m *MockPromoCodes) ReleaseRedemptions(ctx context.Context, tx *gorm.DB, approvalID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseRedemptions", ctx, tx, approvalID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseRedemptions indicates an expected call of ReleaseRedemptions.
+func (mr *MockPromoCodesMockRecorder) ReleaseRedemptions(ctx, tx, approvalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseRedemptions", reflect.TypeOf((*MockPromoCodes)(nil).ReleaseRedemptions), ctx, tx, approvalID)
+}
+
+// SetActive mocks base method.
+func (m *MockPromoCodes) SetActive(ctx context.Context, code string, active bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetActive", ctx, code, active)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetActive indicates an expected call of SetActive.
+func (mr *MockPromoCodesMockRecorder) SetActive(ctx, code, active interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActive", reflect.TypeOf((*MockPromoCodes)(nil).SetActive), ctx, code, active)
+}
+
+// MockNotifications is a mock of Notifications interface.
+type MockNotifications struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationsMockRecorder
+}
+
+// MockNotificationsMockRecorder is the mock recorder for MockNotifications.
+type MockNotificationsMockRecorder struct {
+	mock *MockNotifications
+}
+
+// NewMockNotifications creates a new mock instance.
+func NewMockNotifications(ctrl *gomock.Controller) *MockNotifications {
+	mock := &MockNotifications{ctrl: ctrl}
+	mock.recorder = &MockNotificationsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifications) EXPECT() *MockNotificationsMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockNotifications) Create(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockNotificationsMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockNotifications)(nil).Create), arg0, arg1, arg2)
+}
+
+// List mocks base method.
+func (m *MockNotifications) List(ctx context.Context, username string, limit int) ([]domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, username, limit)
+	ret0, _ := ret[0].([]domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockNotificationsMockRecorder) List(ctx, username, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockNotifications)(nil).List), ctx, username, limit)
+}
+
+// MarkRead mocks base method.
+func (m *MockNotifications) MarkRead(ctx context.Context, username string, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, username, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockNotificationsMockRecorder) MarkRead(ctx, username, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockNotifications)(nil).MarkRead), ctx, username, now)
+}
+
+// MockWishlists is a mock of Wishlists interface.
+type MockWishlists struct {
+	ctrl     *gomock.Controller
+	recorder *MockWishlistsMockRecorder
+}
+
+// MockWishlistsMockRecorder is the mock recorder for MockWishlists.
+type MockWishlistsMockRecorder struct {
+	mock *MockWishlists
+}
+
+// NewMockWishlists creates a new mock instance.
+func NewMockWishlists(ctrl *gomock.Controller) *MockWishlists {
+	mock := &MockWishlists{ctrl: ctrl}
+	mock.recorder = &MockWishlistsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWishlists) EXPECT() *MockWishlistsMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockWishlists) Delete(ctx context.Context, username, merchName string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, username, merchName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWishlistsMockRecorder) Delete(ctx, username, merchName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWishlists)(nil).Delete), ctx, username, merchName)
+}
+
+// List mocks base method.
+func (m *MockWishlists) List(ctx context.Context, username string) ([]domain.WishlistItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, username)
+	ret0, _ := ret[0].([]domain.WishlistItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockWishlistsMockRecorder) List(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockWishlists)(nil).List), ctx, username)
+}
+
+// ListChanged mocks base method.
+func (m *MockWishlists) ListChanged(ctx context.Context, limit int) ([]domain.WishlistItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChanged", ctx, limit)
+	ret0, _ := ret[0].([]domain.WishlistItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChanged indicates an expected call of ListChanged.
+func (mr *MockWishlistsMockRecorder) ListChanged(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChanged", reflect.TypeOf((*MockWishlists)(nil).ListChanged), ctx, limit)
+}
+
+// Save mocks base method.
+func (m *MockWishlists) Save(arg0 context.Context, arg1 *domain.WishlistItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockWishlistsMockRecorder) Save(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockWishlists)(nil).Save), arg0, arg1)
+}
+
+// UpdateSeen mocks base method.
+func (m *MockWishlists) UpdateSeen(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.WishlistItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSeen", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSeen indicates an expected call of UpdateSeen.
+func (mr *MockWishlistsMockRecorder) UpdateSeen(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSeen", reflect.TypeOf((*MockWishlists)(nil).UpdateSeen), arg0, arg1, arg2)
+}
+
+// MockAudit is a mock of Audit interface.
+type MockAudit struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditMockRecorder
+}
+
+// MockAuditMockRecorder is the mock recorder for MockAudit.
+type MockAuditMockRecorder struct {
+	mock *MockAudit
+}
+
+// NewMockAudit creates a new mock instance.
+func NewMockAudit(ctrl *gomock.Controller) *MockAudit {
+	mock := &MockAudit{ctrl: ctrl}
+	mock.recorder = &MockAuditMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAudit) EXPECT() *MockAuditMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockAudit) Append(arg0 context.Context, arg1 *gorm.DB, arg2 *domain.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockAuditMockRecorder) Append(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockAudit)(nil).Append), arg0, arg1, arg2)
+}
+
+// List mocks base method.
+func (m *MockAudit) List(arg0 context.Context, arg1 domain.AuditFilter) ([]domain.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]domain.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionsForUserByUserGUID", reflect.TypeOf((*MockTransactions)(nil).GetTransactionsForUserByUserGUID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAudit)(nil).List), arg0, arg1)
 }
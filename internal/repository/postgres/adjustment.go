@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BalanceAdjustments struct {
+	db *gorm.DB
+}
+
+func NewBalanceAdjustmentsRepository(db *gorm.DB) *BalanceAdjustments {
+	return &BalanceAdjustments{db: db}
+}
+
+func (r *BalanceAdjustments) Create(ctx context.Context, tx *gorm.DB, adjustment *domain.BalanceAdjustment) (*domain.BalanceAdjustment, error) {
+	ctx, span := tracing.Start(ctx, "postgres.BalanceAdjustments.Create")
+	defer span.End()
+
+	var db *gorm.DB
+	if tx != nil {
+		db = tx
+	} else {
+		db = r.db
+	}
+	adjustment.GUID = uuid.New().String()
+	db = db.WithContext(ctx).Create(adjustment)
+	if db.Error != nil {
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return nil, tracing.Error(span, db.Error)
+	}
+	return adjustment, nil
+}
+
+func (r *BalanceAdjustments) GetAdjustmentsForUserByUsername(ctx context.Context, username string) ([]domain.BalanceAdjustment, error) {
+	ctx, span := tracing.Start(ctx, "postgres.BalanceAdjustments.GetAdjustmentsForUserByUsername")
+	defer span.End()
+
+	var adjustments []domain.BalanceAdjustment
+	db := r.db.WithContext(ctx).Where("username = ?", username).Order("created_at").Find(&adjustments)
+	if db.Error != nil {
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return nil, tracing.Error(span, db.Error)
+	}
+	return adjustments, nil
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Allowances struct {
+	db *gorm.DB
+}
+
+func NewAllowancesRepository(db *gorm.DB) *Allowances {
+	return &Allowances{db: db}
+}
+
+func (r *Allowances) CreatePolicy(ctx context.Context, policy *domain.AllowancePolicy) (*domain.AllowancePolicy, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Allowances.CreatePolicy")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Create(policy).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return policy, nil
+}
+
+// GetPolicy returns an empty policy if there is no policy with this ID.
+func (r *Allowances) GetPolicy(ctx context.Context, id int64) (*domain.AllowancePolicy, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Allowances.GetPolicy")
+	defer span.End()
+
+	var policy domain.AllowancePolicy
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Limit(1).Find(&policy).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return &policy, nil
+}
+
+func (r *Allowances) ListPolicies(ctx context.Context) ([]domain.AllowancePolicy, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Allowances.ListPolicies")
+	defer span.End()
+
+	var policies []domain.AllowancePolicy
+	if err := r.db.WithContext(ctx).Order("id").Find(&policies).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return policies, nil
+}
+
+func (r *Allowances) SetPaused(ctx context.Context, id int64, paused bool) error {
+	ctx, span := tracing.Start(ctx, "postgres.Allowances.SetPaused")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Model(&domain.AllowancePolicy{}).Where("id = ?", id).Update("paused", paused).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// LastScheduledRun returns the latest run started by the schedule, or an
+// empty run if the policy has never run on schedule.
+func (r *Allowances) LastScheduledRun(ctx context.Context, policyID int64) (*domain.AllowanceRun, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Allowances.LastScheduledRun")
+	defer span.End()
+
+	var run domain.AllowanceRun
+	err := r.db.WithContext(ctx).
+		Where("policy_id = ? AND trigger = ?", policyID, domain.RunTriggerSchedule).
+		Order("scheduled_for DESC").Limit(1).Find(&run).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return &run, nil
+}
+
+func (r *Allowances) ListRuns(ctx context.Context, policyID int64) ([]domain.AllowanceRun, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Allowances.ListRuns")
+	defer span.End()
+
+	var runs []domain.AllowanceRun
+	if err := r.db.WithContext(ctx).Where("policy_id = ?", policyID).Order("scheduled_for DESC").Find(&runs).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return runs, nil
+}
+
+// CreateRun records the run in tx and reports false, without an error, if
+// this occurrence of the policy has already been recorded.
+func (r *Allowances) CreateRun(ctx context.Context, tx *gorm.DB, run *domain.AllowanceRun) (bool, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Allowances.CreateRun")
+	defer span.End()
+
+	run.GUID = uuid.New().String()
+	db := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(run)
+	if db.Error != nil {
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return false, tracing.Error(span, db.Error)
+	}
+	return db.RowsAffected == 1, nil
+}
+
+func (r *Allowances) UpdateRun(ctx context.Context, tx *gorm.DB, run *domain.AllowanceRun) error {
+	ctx, span := tracing.Start(ctx, "postgres.Allowances.UpdateRun")
+	defer span.End()
+
+	db := tx.WithContext(ctx).Model(run).Updates(map[string]any{"users_paid": run.UsersPaid, "total": run.Total})
+	if db.Error != nil {
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return tracing.Error(span, db.Error)
+	}
+	return nil
+}
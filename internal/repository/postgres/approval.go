@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PurchaseApprovals struct {
+	db *gorm.DB
+}
+
+func NewPurchaseApprovalsRepository(db *gorm.DB) *PurchaseApprovals {
+	return &PurchaseApprovals{db: db}
+}
+
+func (r *PurchaseApprovals) Create(ctx context.Context, tx *gorm.DB, approval *domain.PurchaseApproval) (*domain.PurchaseApproval, error) {
+	ctx, span := tracing.Start(ctx, "postgres.PurchaseApprovals.Create")
+	defer span.End()
+
+	if err := tx.WithContext(ctx).Create(approval).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return approval, nil
+}
+
+// Lock locks the approval for the rest of tx. An empty approval is returned
+// if there is no approval with this ID.
+func (r *PurchaseApprovals) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.PurchaseApproval, error) {
+	ctx, span := tracing.Start(ctx, "postgres.PurchaseApprovals.Lock")
+	defer span.End()
+
+	var approval domain.PurchaseApproval
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Limit(1).Find(&approval).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return &approval, nil
+}
+
+func (r *PurchaseApprovals) Update(ctx context.Context, tx *gorm.DB, approval *domain.PurchaseApproval) error {
+	ctx, span := tracing.Start(ctx, "postgres.PurchaseApprovals.Update")
+	defer span.End()
+
+	if err := tx.WithContext(ctx).Save(approval).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// ListAssigned returns the pending approvals assigned to the user, the ones
+// due first first. Admins also get the approvals assigned to admins.
+func (r *PurchaseApprovals) ListAssigned(ctx context.Context, username string, admin bool) ([]domain.PurchaseApproval, error) {
+	ctx, span := tracing.Start(ctx, "postgres.PurchaseApprovals.ListAssigned")
+	defer span.End()
+
+	approvers := []string{username}
+	if admin {
+		approvers = append(approvers, "")
+	}
+	approvals := []domain.PurchaseApproval{}
+	err := r.db.WithContext(ctx).Where("approver IN ? AND status = ?", approvers, domain.ApprovalPending).
+		Order("due_at").Find(&approvals).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return approvals, nil
+}
+
+// ListRequested returns the user's pending approvals, newest first.
+func (r *PurchaseApprovals) ListRequested(ctx context.Context, username string) ([]domain.PurchaseApproval, error) {
+	ctx, span := tracing.Start(ctx, "postgres.PurchaseApprovals.ListRequested")
+	defer span.End()
+
+	approvals := []domain.PurchaseApproval{}
+	err := r.db.WithContext(ctx).Where("username = ? AND status = ?", username, domain.ApprovalPending).
+		Order("created_at DESC").Find(&approvals).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return approvals, nil
+}
+
+// ListDue returns the IDs of up to limit pending approvals due by now.
+func (r *PurchaseApprovals) ListDue(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	ctx, span := tracing.Start(ctx, "postgres.PurchaseApprovals.ListDue")
+	defer span.End()
+
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&domain.PurchaseApproval{}).
+		Where("status = ? AND due_at <= ?", domain.ApprovalPending, now).
+		Order("due_at").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return ids, nil
+}
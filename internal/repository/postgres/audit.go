@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+
+	"shop/domain"
+	"shop/pkg/audit"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"gorm.io/gorm"
+)
+
+type Audit struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *Audit {
+	return &Audit{db: db}
+}
+
+// Append writes entry in tx, so it is stored only if the audited change is.
+// Without tx the entry is written in a transaction of its own.
+func (r *Audit) Append(ctx context.Context, tx *gorm.DB, entry *domain.AuditEntry) error {
+	ctx, span := tracing.Start(ctx, "postgres.Audit.Append")
+	defer span.End()
+
+	var err error
+	if tx != nil {
+		err = audit.Append(ctx, tx, entry)
+	} else {
+		err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return audit.Append(ctx, tx, entry)
+		})
+	}
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+func (r *Audit) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Audit.List")
+	defer span.End()
+
+	db := r.db.WithContext(ctx).Order("id DESC")
+	if filter.Actor != "" {
+		db = db.Where("actor = ?", filter.Actor)
+	}
+	if filter.Subject != "" {
+		db = db.Where("subject = ?", filter.Subject)
+	}
+	if filter.Action != "" {
+		db = db.Where("action = ?", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		db = db.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		db = db.Where("created_at < ?", filter.To)
+	}
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		db = db.Offset(filter.Offset)
+	}
+
+	var entries []domain.AuditEntry
+	if err := db.Find(&entries).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"gorm.io/gorm"
+)
+
+type Categories struct {
+	db *gorm.DB
+}
+
+func NewCategoriesRepository(db *gorm.DB) *Categories {
+	return &Categories{db: db}
+}
+
+func (r *Categories) Create(ctx context.Context, category *domain.Category) (*domain.Category, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Categories.Create")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Create(category).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return category, nil
+}
+
+// Get returns an empty category if there is no such category.
+func (r *Categories) Get(ctx context.Context, name string) (*domain.Category, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Categories.Get")
+	defer span.End()
+
+	var category domain.Category
+	if err := r.db.WithContext(ctx).Where("name = ?", name).Limit(1).Find(&category).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return &category, nil
+}
+
+func (r *Categories) List(ctx context.Context) ([]domain.Category, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Categories.List")
+	defer span.End()
+
+	categories := []domain.Category{}
+	if err := r.db.WithContext(ctx).Order("name").Find(&categories).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return categories, nil
+}
+
+func (r *Categories) Update(ctx context.Context, category *domain.Category) error {
+	ctx, span := tracing.Start(ctx, "postgres.Categories.Update")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Model(category).Update("title", category.Title).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+func (r *Categories) Delete(ctx context.Context, name string) error {
+	ctx, span := tracing.Start(ctx, "postgres.Categories.Delete")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Where("name = ?", name).Delete(&domain.Category{}).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
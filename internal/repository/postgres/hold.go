@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Holds struct {
+	db *gorm.DB
+}
+
+func NewHoldsRepository(db *gorm.DB) *Holds {
+	return &Holds{db: db}
+}
+
+func (r *Holds) Create(ctx context.Context, tx *gorm.DB, hold *domain.Hold) (*domain.Hold, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Holds.Create")
+	defer span.End()
+
+	db := r.db
+	if tx != nil {
+		db = tx
+	}
+	if err := db.WithContext(ctx).Create(hold).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return hold, nil
+}
+
+// Lock locks the hold for the rest of tx. An empty hold is returned if there
+// is no hold with this ID.
+func (r *Holds) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.Hold, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Holds.Lock")
+	defer span.End()
+
+	var hold domain.Hold
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Limit(1).Find(&hold).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return &hold, nil
+}
+
+func (r *Holds) Update(ctx context.Context, tx *gorm.DB, hold *domain.Hold) error {
+	ctx, span := tracing.Start(ctx, "postgres.Holds.Update")
+	defer span.End()
+
+	db := r.db
+	if tx != nil {
+		db = tx
+	}
+	if err := db.WithContext(ctx).Save(hold).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// ListActive returns the user's active holds, the ones expiring first first.
+func (r *Holds) ListActive(ctx context.Context, username string) ([]domain.Hold, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Holds.ListActive")
+	defer span.End()
+
+	holds := []domain.Hold{}
+	err := r.db.WithContext(ctx).Where("username = ? AND status = ?", username, domain.HoldActive).
+		Order("expires_at").Find(&holds).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return holds, nil
+}
+
+// ListExpired returns the IDs of up to limit active holds that expired by now.
+func (r *Holds) ListExpired(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Holds.ListExpired")
+	defer span.End()
+
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&domain.Hold{}).
+		Where("status = ? AND expires_at <= ?", domain.HoldActive, now).
+		Order("expires_at").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CoinLots struct {
+	db *gorm.DB
+}
+
+func NewCoinLotsRepository(db *gorm.DB) *CoinLots {
+	return &CoinLots{db: db}
+}
+
+// Grant stores new lots, without tx they are stored in a transaction of their own.
+func (r *CoinLots) Grant(ctx context.Context, tx *gorm.DB, lots []domain.CoinLot) error {
+	ctx, span := tracing.Start(ctx, "postgres.CoinLots.Grant")
+	defer span.End()
+
+	if len(lots) == 0 {
+		return nil
+	}
+	db := r.db
+	if tx != nil {
+		db = tx
+	}
+	if err := db.WithContext(ctx).Create(&lots).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// Consume takes amount from the user's oldest lots in tx and returns the
+// consumed portions with the dates they were granted at. The balance is
+// checked by the caller, lots missing for the rest of amount are ignored.
+func (r *CoinLots) Consume(ctx context.Context, tx *gorm.DB, username string, amount float64) ([]domain.CoinLot, error) {
+	ctx, span := tracing.Start(ctx, "postgres.CoinLots.Consume")
+	defer span.End()
+
+	var lots []domain.CoinLot
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("username = ? AND remaining > 0", username).
+		Order("granted_at, id").Find(&lots).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+
+	var consumed []domain.CoinLot
+	for i := 0; i < len(lots) && amount > 0; i++ {
+		lot := &lots[i]
+		taken := min(lot.Remaining, amount)
+		lot.Remaining -= taken
+		amount -= taken
+		err = tx.WithContext(ctx).Model(lot).Update("remaining", lot.Remaining).Error
+		if err != nil {
+			logger.FromContext(ctx).Errorf(err.Error())
+			return nil, tracing.Error(span, err)
+		}
+		consumed = append(consumed, domain.CoinLot{
+			Username:  username,
+			Amount:    taken,
+			Remaining: taken,
+			GrantedAt: lot.GrantedAt,
+			Source:    lot.Source,
+		})
+	}
+	return consumed, nil
+}
+
+// ListOpenLots returns the lots the user still has coins in, oldest first.
+func (r *CoinLots) ListOpenLots(ctx context.Context, username string) ([]domain.CoinLot, error) {
+	ctx, span := tracing.Start(ctx, "postgres.CoinLots.ListOpenLots")
+	defer span.End()
+
+	var lots []domain.CoinLot
+	err := r.db.WithContext(ctx).Where("username = ? AND remaining > 0", username).Order("granted_at, id").Find(&lots).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return lots, nil
+}
+
+// ListExpiredOwners returns the users that still have coins granted before cutoff.
+func (r *CoinLots) ListExpiredOwners(ctx context.Context, cutoff time.Time) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "postgres.CoinLots.ListExpiredOwners")
+	defer span.End()
+
+	var usernames []string
+	err := r.db.WithContext(ctx).Model(&domain.CoinLot{}).
+		Where("remaining > 0 AND granted_at < ?", cutoff).
+		Distinct("username").Order("username").Pluck("username", &usernames).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return usernames, nil
+}
+
+// ExpiredAmount sums the user's coins granted before cutoff, the user must be locked in tx.
+func (r *CoinLots) ExpiredAmount(ctx context.Context, tx *gorm.DB, username string, cutoff time.Time) (float64, error) {
+	ctx, span := tracing.Start(ctx, "postgres.CoinLots.ExpiredAmount")
+	defer span.End()
+
+	var amount float64
+	err := tx.WithContext(ctx).Model(&domain.CoinLot{}).
+		Where("username = ? AND remaining > 0 AND granted_at < ?", username, cutoff).
+		Select("COALESCE(SUM(remaining), 0)").Scan(&amount).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return 0, tracing.Error(span, err)
+	}
+	return amount, nil
+}
@@ -1,10 +1,15 @@
 package postgres
 
 import (
+	"context"
+	"encoding/json"
+
 	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
 
-	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Merch struct {
@@ -15,12 +20,161 @@ func NewMerchRepository(db *gorm.DB) *Merch {
 	return &Merch{db: db}
 }
 
-func (r *Merch) GetMerchByName(name string) (*domain.Merch, error) {
+func (r *Merch) GetMerchByName(ctx context.Context, name string) (*domain.Merch, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Merch.GetMerchByName")
+	defer span.End()
+
+	var merch domain.Merch
+	db := r.db.WithContext(ctx).Where("name = ?", name).Limit(1).Find(&merch)
+	if db.Error != nil {
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return nil, tracing.Error(span, db.Error)
+	}
+	return &merch, nil
+}
+
+// ListPrices returns the prices of the item, oldest first.
+func (r *Merch) ListPrices(ctx context.Context, name string) ([]domain.MerchPrice, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Merch.ListPrices")
+	defer span.End()
+
+	prices := []domain.MerchPrice{}
+	if err := r.db.WithContext(ctx).Where("merch_name = ?", name).Order("effective_from, id").Find(&prices).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return prices, nil
+}
+
+// List returns the items matching the filter with their images, by name.
+func (r *Merch) List(ctx context.Context, filter domain.MerchFilter) ([]domain.Merch, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Merch.List")
+	defer span.End()
+
+	db := r.db.WithContext(ctx).Preload("Images", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+	if filter.Category != "" {
+		db = db.Where("category = ?", filter.Category)
+	}
+	if filter.Tag != "" {
+		tags, _ := json.Marshal([]string{filter.Tag})
+		db = db.Where("tags @> ?::jsonb", string(tags))
+	}
+
+	merch := []domain.Merch{}
+	if err := db.Order("name").Find(&merch).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return merch, nil
+}
+
+// Lock returns an empty item if there is no such item.
+func (r *Merch) Lock(ctx context.Context, tx *gorm.DB, name string) (*domain.Merch, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Merch.Lock")
+	defer span.End()
+
 	var merch domain.Merch
-	r.db.Where("name = ?", name).First(&merch)
-	if r.db.Error != nil {
-		log.Errorf(r.db.Error.Error())
-		return nil, r.db.Error
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", name).Limit(1).Find(&merch).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
 	}
 	return &merch, nil
 }
+
+func (r *Merch) Create(ctx context.Context, tx *gorm.DB, merch *domain.Merch) (*domain.Merch, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Merch.Create")
+	defer span.End()
+
+	if err := tx.WithContext(ctx).Omit("Images").Create(merch).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return merch, nil
+}
+
+func (r *Merch) Update(ctx context.Context, tx *gorm.DB, merch *domain.Merch) error {
+	ctx, span := tracing.Start(ctx, "postgres.Merch.Update")
+	defer span.End()
+
+	err := tx.WithContext(ctx).Model(merch).Select("price", "stock", "description", "category", "tags").Updates(merch).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// TakeStock takes one item out of a tracked stock, it reports false when
+// none is left.
+func (r *Merch) TakeStock(ctx context.Context, tx *gorm.DB, name string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Merch.TakeStock")
+	defer span.End()
+
+	db := tx.WithContext(ctx).Model(&domain.Merch{}).Where("name = ? AND stock > 0", name).
+		UpdateColumn("stock", gorm.Expr("stock - 1"))
+	if db.Error != nil {
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return false, tracing.Error(span, db.Error)
+	}
+	return db.RowsAffected > 0, nil
+}
+
+// CreatePrice starts a new entry of the item's price history.
+func (r *Merch) CreatePrice(ctx context.Context, tx *gorm.DB, price *domain.MerchPrice) error {
+	ctx, span := tracing.Start(ctx, "postgres.Merch.CreatePrice")
+	defer span.End()
+
+	if err := tx.WithContext(ctx).Create(price).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+func (r *Merch) CountByCategory(ctx context.Context, category string) (int64, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Merch.CountByCategory")
+	defer span.End()
+
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&domain.Merch{}).Where("category = ?", category).Count(&count).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return 0, tracing.Error(span, err)
+	}
+	return count, nil
+}
+
+func (r *Merch) CreateImage(ctx context.Context, image *domain.MerchImage) (*domain.MerchImage, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Merch.CreateImage")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Create(image).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return image, nil
+}
+
+// GetImage returns an empty image if the item has no such image.
+func (r *Merch) GetImage(ctx context.Context, name string, id int64) (*domain.MerchImage, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Merch.GetImage")
+	defer span.End()
+
+	var image domain.MerchImage
+	if err := r.db.WithContext(ctx).Where("id = ? AND merch_name = ?", id, name).Limit(1).Find(&image).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return &image, nil
+}
+
+func (r *Merch) DeleteImage(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "postgres.Merch.DeleteImage")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Delete(&domain.MerchImage{}, id).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
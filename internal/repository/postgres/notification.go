@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"gorm.io/gorm"
+)
+
+type Notifications struct {
+	db *gorm.DB
+}
+
+func NewNotificationsRepository(db *gorm.DB) *Notifications {
+	return &Notifications{db: db}
+}
+
+// Create writes the notification in tx, so it is delivered only if the
+// change it tells about is stored.
+func (r *Notifications) Create(ctx context.Context, tx *gorm.DB, notification *domain.Notification) error {
+	ctx, span := tracing.Start(ctx, "postgres.Notifications.Create")
+	defer span.End()
+
+	db := r.db
+	if tx != nil {
+		db = tx
+	}
+	if err := db.WithContext(ctx).Create(notification).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// List returns the user's latest notifications, newest first.
+func (r *Notifications) List(ctx context.Context, username string, limit int) ([]domain.Notification, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Notifications.List")
+	defer span.End()
+
+	var notifications []domain.Notification
+	err := r.db.WithContext(ctx).Where("username = ?", username).Order("created_at DESC, id DESC").Limit(limit).Find(&notifications).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return notifications, nil
+}
+
+// MarkRead marks all unread notifications of the user as read at now and
+// returns how many there were.
+func (r *Notifications) MarkRead(ctx context.Context, username string, now time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Notifications.MarkRead")
+	defer span.End()
+
+	db := r.db.WithContext(ctx).Model(&domain.Notification{}).
+		Where("username = ? AND read_at IS NULL", username).Update("read_at", now)
+	if db.Error != nil {
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return 0, tracing.Error(span, db.Error)
+	}
+	return db.RowsAffected, nil
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromoCodes struct {
+	db *gorm.DB
+}
+
+func NewPromoCodesRepository(db *gorm.DB) *PromoCodes {
+	return &PromoCodes{db: db}
+}
+
+func (r *PromoCodes) Create(ctx context.Context, code *domain.PromoCode) (*domain.PromoCode, error) {
+	ctx, span := tracing.Start(ctx, "postgres.PromoCodes.Create")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Create(code).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return code, nil
+}
+
+// Get returns an empty code if there is no such code.
+func (r *PromoCodes) Get(ctx context.Context, code string) (*domain.PromoCode, error) {
+	ctx, span := tracing.Start(ctx, "postgres.PromoCodes.Get")
+	defer span.End()
+
+	var promo domain.PromoCode
+	if err := r.withUses(r.db.WithContext(ctx)).Where("code = ?", code).Limit(1).Find(&promo).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return &promo, nil
+}
+
+func (r *PromoCodes) List(ctx context.Context) ([]domain.PromoCode, error) {
+	ctx, span := tracing.Start(ctx, "postgres.PromoCodes.List")
+	defer span.End()
+
+	codes := []domain.PromoCode{}
+	if err := r.withUses(r.db.WithContext(ctx)).Order("created_at DESC, code").Find(&codes).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return codes, nil
+}
+
+func (r *PromoCodes) withUses(db *gorm.DB) *gorm.DB {
+	return db.Select("promo_codes.*, (SELECT count(*) FROM promo_redemptions WHERE promo_redemptions.code = promo_codes.code) AS uses")
+}
+
+func (r *PromoCodes) SetActive(ctx context.Context, code string, active bool) error {
+	ctx, span := tracing.Start(ctx, "postgres.PromoCodes.SetActive")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Model(&domain.PromoCode{}).Where("code = ?", code).Update("active", active).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// Lock locks the codes that exist for the rest of tx, in the order of their
+// names so that concurrent purchases lock them in the same order.
+func (r *PromoCodes) Lock(ctx context.Context, tx *gorm.DB, codes []string) ([]domain.PromoCode, error) {
+	ctx, span := tracing.Start(ctx, "postgres.PromoCodes.Lock")
+	defer span.End()
+
+	var promos []domain.PromoCode
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code IN ?", codes).Order("code").Find(&promos).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return promos, nil
+}
+
+// CountRedemptions counts the redemptions of the code by all users and by the user.
+func (r *PromoCodes) CountRedemptions(ctx context.Context, tx *gorm.DB, code, username string) (int, int, error) {
+	ctx, span := tracing.Start(ctx, "postgres.PromoCodes.CountRedemptions")
+	defer span.End()
+
+	var counts struct {
+		Total int
+		Own   int
+	}
+	err := tx.WithContext(ctx).Model(&domain.PromoRedemption{}).
+		Select("count(*) AS total, count(*) FILTER (WHERE username = ?) AS own", username).
+		Where("code = ?", code).Scan(&counts).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return 0, 0, tracing.Error(span, err)
+	}
+	return counts.Total, counts.Own, nil
+}
+
+func (r *PromoCodes) CreateRedemptions(ctx context.Context, tx *gorm.DB, redemptions []domain.PromoRedemption) error {
+	ctx, span := tracing.Start(ctx, "postgres.PromoCodes.CreateRedemptions")
+	defer span.End()
+
+	if err := tx.WithContext(ctx).Create(&redemptions).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// CompleteRedemptions links the redemptions of an approved purchase to the purchase made.
+func (r *PromoCodes) CompleteRedemptions(ctx context.Context, tx *gorm.DB, approvalID int64, purchaseGUID string) error {
+	ctx, span := tracing.Start(ctx, "postgres.PromoCodes.CompleteRedemptions")
+	defer span.End()
+
+	err := tx.WithContext(ctx).Model(&domain.PromoRedemption{}).
+		Where("approval_id = ?", approvalID).Update("purchase_guid", purchaseGUID).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// ReleaseRedemptions deletes the redemptions of a purchase that was not
+// approved, the codes can be used again.
+func (r *PromoCodes) ReleaseRedemptions(ctx context.Context, tx *gorm.DB, approvalID int64) error {
+	ctx, span := tracing.Start(ctx, "postgres.PromoCodes.ReleaseRedemptions")
+	defer span.End()
+
+	if err := tx.WithContext(ctx).Where("approval_id = ?", approvalID).Delete(&domain.PromoRedemption{}).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
@@ -1,9 +1,12 @@
 package postgres
 
 import (
+	"context"
+
 	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
 
-	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
@@ -15,27 +18,51 @@ func NewPurchasesRepository(db *gorm.DB) *Purchases {
 	return &Purchases{db: db}
 }
 
-func (r *Purchases) Create(tx *gorm.DB, purchase *domain.Purchase) (*domain.Purchase, error) {
+func (r *Purchases) Create(ctx context.Context, tx *gorm.DB, purchase *domain.Purchase) (*domain.Purchase, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Purchases.Create")
+	defer span.End()
+
 	var db *gorm.DB
 	if tx != nil {
 		db = tx
 	} else {
 		db = r.db
 	}
-	db.Create(purchase)
+	db = db.WithContext(ctx).Create(purchase)
 	if db.Error != nil {
-		log.Errorf(db.Error.Error())
-		return nil, db.Error
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return nil, tracing.Error(span, db.Error)
 	}
 	return purchase, nil
 }
 
-func (r *Purchases) GetPurchasesForUserByUsername(username string) ([]domain.Purchase, error) {
+func (r *Purchases) GetPurchasesForUserByUsername(ctx context.Context, username string) ([]domain.Purchase, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Purchases.GetPurchasesForUserByUsername")
+	defer span.End()
+
 	var purchases []domain.Purchase
-	db := r.db.Where("user_id= ?", username).Find(&purchases)
+	db := r.db.WithContext(ctx).Where("user_id= ?", username).Find(&purchases)
 	if db.Error != nil {
-		log.Errorf(db.Error.Error())
-		return nil, db.Error
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return nil, tracing.Error(span, db.Error)
 	}
 	return purchases, nil
 }
+
+// GetInventory counts the items delivered to the user, bought by themselves
+// or received as gifts.
+func (r *Purchases) GetInventory(ctx context.Context, username string) ([]domain.InventoryItem, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Purchases.GetInventory")
+	defer span.End()
+
+	inventory := []domain.InventoryItem{}
+	err := r.db.WithContext(ctx).Model(&domain.Purchase{}).
+		Select("merch_name AS type, count(*) AS quantity").
+		Where("recipient_id = ?", username).Group("merch_name").Order("merch_name").
+		Scan(&inventory).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return inventory, nil
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CoinRequests struct {
+	db *gorm.DB
+}
+
+func NewCoinRequestsRepository(db *gorm.DB) *CoinRequests {
+	return &CoinRequests{db: db}
+}
+
+func (r *CoinRequests) Create(ctx context.Context, request *domain.CoinRequest) (*domain.CoinRequest, error) {
+	ctx, span := tracing.Start(ctx, "postgres.CoinRequests.Create")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Create(request).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return request, nil
+}
+
+// Lock locks the request for the rest of tx. An empty request is returned if
+// there is no request with this ID.
+func (r *CoinRequests) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.CoinRequest, error) {
+	ctx, span := tracing.Start(ctx, "postgres.CoinRequests.Lock")
+	defer span.End()
+
+	var request domain.CoinRequest
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Limit(1).Find(&request).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return &request, nil
+}
+
+func (r *CoinRequests) Update(ctx context.Context, tx *gorm.DB, request *domain.CoinRequest) error {
+	ctx, span := tracing.Start(ctx, "postgres.CoinRequests.Update")
+	defer span.End()
+
+	db := r.db
+	if tx != nil {
+		db = tx
+	}
+	if err := db.WithContext(ctx).Save(request).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// ListPending returns the requests the user has sent or received that can
+// still be accepted at now, oldest first.
+func (r *CoinRequests) ListPending(ctx context.Context, username string, now time.Time) ([]domain.CoinRequest, error) {
+	ctx, span := tracing.Start(ctx, "postgres.CoinRequests.ListPending")
+	defer span.End()
+
+	var requests []domain.CoinRequest
+	err := r.db.WithContext(ctx).
+		Where("(requester = ? OR payer = ?) AND status = ? AND expires_at > ?", username, username, domain.RequestPending, now).
+		Order("created_at").Find(&requests).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return requests, nil
+}
+
+// ExpirePending marks the pending requests that expired by now and returns how many there were.
+func (r *CoinRequests) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "postgres.CoinRequests.ExpirePending")
+	defer span.End()
+
+	db := r.db.WithContext(ctx).Model(&domain.CoinRequest{}).
+		Where("status = ? AND expires_at <= ?", domain.RequestPending, now).
+		Updates(map[string]any{"status": domain.RequestExpired, "resolved_at": now})
+	if db.Error != nil {
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return 0, tracing.Error(span, db.Error)
+	}
+	return db.RowsAffected, nil
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ScheduledTransfers struct {
+	db *gorm.DB
+}
+
+func NewScheduledTransfersRepository(db *gorm.DB) *ScheduledTransfers {
+	return &ScheduledTransfers{db: db}
+}
+
+func (r *ScheduledTransfers) Create(ctx context.Context, transfer *domain.ScheduledTransfer) (*domain.ScheduledTransfer, error) {
+	ctx, span := tracing.Start(ctx, "postgres.ScheduledTransfers.Create")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Create(transfer).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return transfer, nil
+}
+
+// Lock locks the scheduled transfer for the rest of tx. An empty transfer is
+// returned if there is no transfer with this ID.
+func (r *ScheduledTransfers) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.ScheduledTransfer, error) {
+	ctx, span := tracing.Start(ctx, "postgres.ScheduledTransfers.Lock")
+	defer span.End()
+
+	var transfer domain.ScheduledTransfer
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Limit(1).Find(&transfer).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return &transfer, nil
+}
+
+func (r *ScheduledTransfers) Update(ctx context.Context, tx *gorm.DB, transfer *domain.ScheduledTransfer) error {
+	ctx, span := tracing.Start(ctx, "postgres.ScheduledTransfers.Update")
+	defer span.End()
+
+	db := r.db
+	if tx != nil {
+		db = tx
+	}
+	if err := db.WithContext(ctx).Save(transfer).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// ListBySender returns the transfers the user has scheduled, latest
+// execution time first.
+func (r *ScheduledTransfers) ListBySender(ctx context.Context, username string) ([]domain.ScheduledTransfer, error) {
+	ctx, span := tracing.Start(ctx, "postgres.ScheduledTransfers.ListBySender")
+	defer span.End()
+
+	transfers := []domain.ScheduledTransfer{}
+	err := r.db.WithContext(ctx).Where("sender_username = ?", username).Order("execute_at DESC").Find(&transfers).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return transfers, nil
+}
+
+// ListDue returns the IDs of up to limit pending transfers due by now, the
+// longest overdue first.
+func (r *ScheduledTransfers) ListDue(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	ctx, span := tracing.Start(ctx, "postgres.ScheduledTransfers.ListDue")
+	defer span.End()
+
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&domain.ScheduledTransfer{}).
+		Where("status = ? AND execute_at <= ?", domain.ScheduledPending, now).
+		Order("execute_at").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return ids, nil
+}
@@ -1,10 +1,14 @@
 package postgres
 
 import (
+	"context"
+	"time"
+
 	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
 
 	"github.com/google/uuid"
-	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
@@ -16,7 +20,10 @@ func NewTransactionsRepository(db *gorm.DB) *Transactions {
 	return &Transactions{db: db}
 }
 
-func (r *Transactions) Create(tx *gorm.DB, transaction *domain.Transaction) (*domain.Transaction, error) {
+func (r *Transactions) Create(ctx context.Context, tx *gorm.DB, transaction *domain.Transaction) (*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Transactions.Create")
+	defer span.End()
+
 	var db *gorm.DB
 	if tx != nil {
 		db = tx
@@ -24,21 +31,68 @@ func (r *Transactions) Create(tx *gorm.DB, transaction *domain.Transaction) (*do
 		db = r.db
 	}
 	transaction.GUID = uuid.New().String()
-	db.Create(transaction)
+	db = db.WithContext(ctx).Create(transaction)
 	if db.Error != nil {
-		log.Errorf(db.Error.Error())
-		return nil, db.Error
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return nil, tracing.Error(span, db.Error)
 	}
 	return transaction, nil
 }
 
-func (r *Transactions) GetTransactionsForUserByUsername(username string) ([]domain.Transaction, error) {
+func (r *Transactions) GetTransactionsForUserByUsername(ctx context.Context, username string) ([]domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Transactions.GetTransactionsForUserByUsername")
+	defer span.End()
+
+	var transactions []domain.Transaction
+	db := r.db.WithContext(ctx).Where("receiver_username = ? OR sender_username = ?", username, username).Find(&transactions)
+	if db.Error != nil {
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return nil, tracing.Error(span, db.Error)
+	}
+	return transactions, nil
+}
+
+// SentSince sums up the transfers made by sender since the given time.
+func (r *Transactions) SentSince(ctx context.Context, tx *gorm.DB, sender string, since time.Time) (*domain.TransferStats, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Transactions.SentSince")
+	defer span.End()
+
+	db := r.db
+	if tx != nil {
+		db = tx
+	}
 	var transactions []domain.Transaction
+	err := db.WithContext(ctx).Select("receiver_username", "money_amount").
+		Where("sender_username = ? AND created_at >= ?", sender, since).Find(&transactions).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+
+	stats := &domain.TransferStats{}
+	for _, transaction := range transactions {
+		stats.Add(transaction.ReceiverUsername, transaction.MoneyAmount)
+	}
+	return stats, nil
+}
 
-	r.db.Where("receiver_username = ? OR sender_username = ?", username, username).Find(&transactions)
-	if r.db.Error != nil {
-		log.Errorf(r.db.Error.Error())
-		return nil, r.db.Error
+// ListFeed returns the latest public transfers matching filter.
+func (r *Transactions) ListFeed(ctx context.Context, filter domain.FeedFilter) ([]domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Transactions.ListFeed")
+	defer span.End()
+
+	db := r.db.WithContext(ctx).Where("NOT private").Order("created_at DESC").Limit(filter.Limit)
+	if filter.Category != "" {
+		db = db.Where("category = ?", filter.Category)
+	}
+	if !filter.Before.IsZero() {
+		db = db.Where("created_at < ?", filter.Before)
+	}
+
+	var transactions []domain.Transaction
+	if err := db.Find(&transactions).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
 	}
 	return transactions, nil
 }
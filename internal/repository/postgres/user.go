@@ -1,10 +1,14 @@
 package postgres
 
 import (
+	"context"
+
 	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
 
-	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Users struct {
@@ -15,36 +19,90 @@ func NewUsersRepository(db *gorm.DB) *Users {
 	return &Users{db: db}
 }
 
-func (r *Users) GetUserByUsername(username string) (*domain.User, error) {
+func (r *Users) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Users.GetUserByUsername")
+	defer span.End()
+
 	var user domain.User
-	r.db.Where("username = ?", username).First(&user)
-	if r.db.Error != nil {
-		log.Errorf(r.db.Error.Error())
-		return nil, r.db.Error
+	db := r.db.WithContext(ctx).Where("username = ?", username).Limit(1).Find(&user)
+	if db.Error != nil {
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return nil, tracing.Error(span, db.Error)
 	}
 	return &user, nil
 }
 
-func (r *Users) UpdateUser(tx *gorm.DB, user *domain.User) error {
+// LockUserByUsername reads the user with a row lock held until tx ends.
+// An empty user is returned if there is no such user.
+func (r *Users) LockUserByUsername(ctx context.Context, tx *gorm.DB, username string) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Users.LockUserByUsername")
+	defer span.End()
+
+	var user domain.User
+	db := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("username = ?", username).Limit(1).Find(&user)
+	if db.Error != nil {
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return nil, tracing.Error(span, db.Error)
+	}
+	return &user, nil
+}
+
+func (r *Users) UpdateUser(ctx context.Context, tx *gorm.DB, user *domain.User) error {
+	ctx, span := tracing.Start(ctx, "postgres.Users.UpdateUser")
+	defer span.End()
+
 	var db *gorm.DB
 	if tx != nil {
 		db = tx
 	} else {
 		db = r.db
 	}
-	db.Save(&user)
+	db = db.WithContext(ctx).Save(&user)
 	if db.Error != nil {
-		log.Errorf(db.Error.Error())
-		return db.Error
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return tracing.Error(span, db.Error)
 	}
 	return nil
 }
 
-func (r *Users) CreateUser(user *domain.User) (*domain.User, error) {
-	r.db.Create(&user)
-	if r.db.Error != nil {
-		log.Errorf(r.db.Error.Error())
-		return nil, r.db.Error
+func (r *Users) CreateUser(ctx context.Context, tx *gorm.DB, user *domain.User) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Users.CreateUser")
+	defer span.End()
+
+	db := r.db
+	if tx != nil {
+		db = tx
+	}
+	db = db.WithContext(ctx).Create(&user)
+	if db.Error != nil {
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return nil, tracing.Error(span, db.Error)
 	}
 	return user, nil
 }
+
+// ListActiveUsers returns the active users with the given role and department,
+// empty values match any. With tx the rows are locked, in username order.
+func (r *Users) ListActiveUsers(ctx context.Context, tx *gorm.DB, role, department string) ([]domain.User, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Users.ListActiveUsers")
+	defer span.End()
+
+	db := r.db
+	if tx != nil {
+		db = tx.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	db = db.WithContext(ctx).Where("active")
+	if role != "" {
+		db = db.Where("role = ?", role)
+	}
+	if department != "" {
+		db = db.Where("department = ?", department)
+	}
+
+	var users []domain.User
+	if err := db.Order("username").Find(&users).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return users, nil
+}
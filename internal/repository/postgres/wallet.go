@@ -0,0 +1,247 @@
+package postgres
+
+import (
+	"context"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Wallets struct {
+	db *gorm.DB
+}
+
+func NewWalletsRepository(db *gorm.DB) *Wallets {
+	return &Wallets{db: db}
+}
+
+func (r *Wallets) Create(ctx context.Context, tx *gorm.DB, wallet *domain.Wallet) (*domain.Wallet, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.Create")
+	defer span.End()
+
+	if err := tx.WithContext(ctx).Create(wallet).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return wallet, nil
+}
+
+// Get returns the wallet, an empty wallet is returned if there is no wallet
+// with this ID.
+func (r *Wallets) Get(ctx context.Context, id int64) (*domain.Wallet, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.Get")
+	defer span.End()
+
+	var wallet domain.Wallet
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Limit(1).Find(&wallet).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return &wallet, nil
+}
+
+// Lock locks the wallet for the rest of tx. An empty wallet is returned if
+// there is no wallet with this ID.
+func (r *Wallets) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.Wallet, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.Lock")
+	defer span.End()
+
+	var wallet domain.Wallet
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Limit(1).Find(&wallet).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return &wallet, nil
+}
+
+func (r *Wallets) Update(ctx context.Context, tx *gorm.DB, wallet *domain.Wallet) error {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.Update")
+	defer span.End()
+
+	if err := tx.WithContext(ctx).Save(wallet).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// ListForUser returns the wallets the user is a member of.
+func (r *Wallets) ListForUser(ctx context.Context, username string) ([]domain.Wallet, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.ListForUser")
+	defer span.End()
+
+	wallets := []domain.Wallet{}
+	err := r.db.WithContext(ctx).
+		Joins("JOIN wallet_members ON wallet_members.wallet_id = wallets.id").
+		Where("wallet_members.username = ?", username).Order("wallets.name").Find(&wallets).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return wallets, nil
+}
+
+// SaveMember adds the member to the wallet or changes their role.
+func (r *Wallets) SaveMember(ctx context.Context, tx *gorm.DB, member *domain.WalletMember) error {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.SaveMember")
+	defer span.End()
+
+	err := tx.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "wallet_id"}, {Name: "username"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(member).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+func (r *Wallets) RemoveMember(ctx context.Context, tx *gorm.DB, walletID int64, username string) error {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.RemoveMember")
+	defer span.End()
+
+	err := tx.WithContext(ctx).Where("wallet_id = ? AND username = ?", walletID, username).Delete(&domain.WalletMember{}).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// ListMembers returns the wallet's members, owners first.
+func (r *Wallets) ListMembers(ctx context.Context, tx *gorm.DB, walletID int64) ([]domain.WalletMember, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.ListMembers")
+	defer span.End()
+
+	db := r.db
+	if tx != nil {
+		db = tx
+	}
+	members := []domain.WalletMember{}
+	err := db.WithContext(ctx).Where("wallet_id = ?", walletID).
+		Order(clause.Expr{SQL: "role = ? DESC", Vars: []any{domain.WalletRoleOwner}}).Order("username").
+		Find(&members).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return members, nil
+}
+
+func (r *Wallets) CreateContribution(ctx context.Context, tx *gorm.DB, contribution *domain.WalletContribution) (*domain.WalletContribution, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.CreateContribution")
+	defer span.End()
+
+	if err := tx.WithContext(ctx).Create(contribution).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return contribution, nil
+}
+
+func (r *Wallets) ListContributions(ctx context.Context, walletID int64) ([]domain.WalletContribution, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.ListContributions")
+	defer span.End()
+
+	contributions := []domain.WalletContribution{}
+	err := r.db.WithContext(ctx).Where("wallet_id = ?", walletID).Order("created_at").Find(&contributions).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return contributions, nil
+}
+
+func (r *Wallets) CreatePurchase(ctx context.Context, tx *gorm.DB, purchase *domain.WalletPurchase) (*domain.WalletPurchase, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.CreatePurchase")
+	defer span.End()
+
+	if err := tx.WithContext(ctx).Create(purchase).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return purchase, nil
+}
+
+// LockPurchase locks the proposed purchase for the rest of tx and loads its
+// approvals. An empty purchase is returned if there is no purchase with this
+// ID in the wallet.
+func (r *Wallets) LockPurchase(ctx context.Context, tx *gorm.DB, walletID, id int64) (*domain.WalletPurchase, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.LockPurchase")
+	defer span.End()
+
+	var purchase domain.WalletPurchase
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND wallet_id = ?", id, walletID).Limit(1).Find(&purchase).Error
+	if err == nil && purchase.ID != 0 {
+		err = tx.WithContext(ctx).Model(&domain.WalletApproval{}).Where("wallet_purchase_id = ?", id).
+			Order("created_at").Pluck("username", &purchase.Approvals).Error
+	}
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return &purchase, nil
+}
+
+func (r *Wallets) UpdatePurchase(ctx context.Context, tx *gorm.DB, purchase *domain.WalletPurchase) error {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.UpdatePurchase")
+	defer span.End()
+
+	if err := tx.WithContext(ctx).Save(purchase).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+func (r *Wallets) AddApproval(ctx context.Context, tx *gorm.DB, approval *domain.WalletApproval) error {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.AddApproval")
+	defer span.End()
+
+	if err := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(approval).Error; err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// ListPurchases returns the purchases proposed from the wallet with their
+// approvals, oldest first.
+func (r *Wallets) ListPurchases(ctx context.Context, walletID int64) ([]domain.WalletPurchase, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Wallets.ListPurchases")
+	defer span.End()
+
+	purchases := []domain.WalletPurchase{}
+	err := r.db.WithContext(ctx).Where("wallet_id = ?", walletID).Order("created_at").Find(&purchases).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+
+	var approvals []domain.WalletApproval
+	err = r.db.WithContext(ctx).
+		Where("wallet_purchase_id IN (?)", r.db.Model(&domain.WalletPurchase{}).Select("id").Where("wallet_id = ?", walletID)).
+		Order("created_at").Find(&approvals).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	byPurchase := make(map[int64][]string, len(purchases))
+	for _, approval := range approvals {
+		byPurchase[approval.WalletPurchaseID] = append(byPurchase[approval.WalletPurchaseID], approval.Username)
+	}
+	for i := range purchases {
+		purchases[i].Approvals = byPurchase[purchases[i].ID]
+		if purchases[i].Approvals == nil {
+			purchases[i].Approvals = []string{}
+		}
+	}
+	return purchases, nil
+}
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Wishlists struct {
+	db *gorm.DB
+}
+
+func NewWishlistsRepository(db *gorm.DB) *Wishlists {
+	return &Wishlists{db: db}
+}
+
+// Save adds the item to the wishlist or changes its alerts, the state of the
+// item it was last alerted about is replaced as well.
+func (r *Wishlists) Save(ctx context.Context, item *domain.WishlistItem) error {
+	ctx, span := tracing.Start(ctx, "postgres.Wishlists.Save")
+	defer span.End()
+
+	err := r.db.WithContext(ctx).Omit("Merch").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "username"}, {Name: "merch_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"notify_restock", "notify_price_drop", "seen_price", "seen_in_stock"}),
+	}).Create(item).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// Delete reports whether the item was in the wishlist.
+func (r *Wishlists) Delete(ctx context.Context, username, merchName string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Wishlists.Delete")
+	defer span.End()
+
+	db := r.db.WithContext(ctx).Where("username = ? AND merch_name = ?", username, merchName).Delete(&domain.WishlistItem{})
+	if db.Error != nil {
+		logger.FromContext(ctx).Errorf(db.Error.Error())
+		return false, tracing.Error(span, db.Error)
+	}
+	return db.RowsAffected > 0, nil
+}
+
+// List returns the wishlist of the user with the merch of its items, in the
+// order they were added.
+func (r *Wishlists) List(ctx context.Context, username string) ([]domain.WishlistItem, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Wishlists.List")
+	defer span.End()
+
+	items := []domain.WishlistItem{}
+	err := r.db.WithContext(ctx).Joins("Merch").Where("wishlist_items.username = ?", username).
+		Order("wishlist_items.created_at, wishlist_items.merch_name").Find(&items).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return items, nil
+}
+
+// ListChanged returns the watched items whose price or stock changed since
+// their users last saw them, with their merch.
+func (r *Wishlists) ListChanged(ctx context.Context, limit int) ([]domain.WishlistItem, error) {
+	ctx, span := tracing.Start(ctx, "postgres.Wishlists.ListChanged")
+	defer span.End()
+
+	var items []domain.WishlistItem
+	err := r.db.WithContext(ctx).Joins("Merch").
+		Where("wishlist_items.notify_restock OR wishlist_items.notify_price_drop").
+		Where(`"Merch".price <> wishlist_items.seen_price OR ("Merch".stock IS NULL OR "Merch".stock > 0) <> wishlist_items.seen_in_stock`).
+		Order("wishlist_items.created_at").Limit(limit).Find(&items).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return items, nil
+}
+
+// UpdateSeen records the price and stock of the item the user was alerted about.
+func (r *Wishlists) UpdateSeen(ctx context.Context, tx *gorm.DB, item *domain.WishlistItem) error {
+	ctx, span := tracing.Start(ctx, "postgres.Wishlists.UpdateSeen")
+	defer span.End()
+
+	err := tx.WithContext(ctx).Model(&domain.WishlistItem{}).
+		Where("username = ? AND merch_name = ?", item.Username, item.MerchName).
+		Updates(map[string]any{"seen_price": item.SeenPrice, "seen_in_stock": item.SeenInStock}).Error
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
@@ -1,158 +1,2248 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
 
 	"shop/domain"
 	"shop/internal/repository/postgres"
+	"shop/pkg/audit"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
 
-	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 //go:generate mockgen -source=repository.go -destination=mocks/mock.go
 type Repository struct {
-	DB           *gorm.DB
-	Users        Users
-	Merch        Merch
-	Purchases    Purchases
-	Transactions Transactions
+	DB                 *gorm.DB
+	Users              Users
+	Merch              Merch
+	Categories         Categories
+	Purchases          Purchases
+	Transactions       Transactions
+	BalanceAdjustments BalanceAdjustments
+	Allowances         Allowances
+	CoinLots           CoinLots
+	CoinRequests       CoinRequests
+	ScheduledTransfers ScheduledTransfers
+	Holds              Holds
+	PurchaseApprovals  PurchaseApprovals
+	PromoCodes         PromoCodes
+	Wallets            Wallets
+	Notifications      Notifications
+	Wishlists          Wishlists
+	Audit              Audit
 }
 
 func NewRepository(db *gorm.DB) *Repository {
 	return &Repository{
-		DB:           db,
-		Users:        postgres.NewUsersRepository(db),
-		Purchases:    postgres.NewPurchasesRepository(db),
-		Transactions: postgres.NewTransactionsRepository(db),
-		Merch:        postgres.NewMerchRepository(db),
+		DB:                 db,
+		Users:              postgres.NewUsersRepository(db),
+		Purchases:          postgres.NewPurchasesRepository(db),
+		Transactions:       postgres.NewTransactionsRepository(db),
+		Merch:              postgres.NewMerchRepository(db),
+		Categories:         postgres.NewCategoriesRepository(db),
+		BalanceAdjustments: postgres.NewBalanceAdjustmentsRepository(db),
+		Allowances:         postgres.NewAllowancesRepository(db),
+		CoinLots:           postgres.NewCoinLotsRepository(db),
+		CoinRequests:       postgres.NewCoinRequestsRepository(db),
+		ScheduledTransfers: postgres.NewScheduledTransfersRepository(db),
+		Holds:              postgres.NewHoldsRepository(db),
+		PurchaseApprovals:  postgres.NewPurchaseApprovalsRepository(db),
+		PromoCodes:         postgres.NewPromoCodesRepository(db),
+		Wallets:            postgres.NewWalletsRepository(db),
+		Notifications:      postgres.NewNotificationsRepository(db),
+		Wishlists:          postgres.NewWishlistsRepository(db),
+		Audit:              postgres.NewAuditRepository(db),
 	}
 }
 
 type Users interface {
-	GetUserByUsername(string) (*domain.User, error)
-	UpdateUser(*gorm.DB, *domain.User) error
-	CreateUser(*domain.User) (*domain.User, error)
+	GetUserByUsername(context.Context, string) (*domain.User, error)
+	LockUserByUsername(context.Context, *gorm.DB, string) (*domain.User, error)
+	UpdateUser(context.Context, *gorm.DB, *domain.User) error
+	CreateUser(context.Context, *gorm.DB, *domain.User) (*domain.User, error)
+	ListActiveUsers(ctx context.Context, tx *gorm.DB, role, department string) ([]domain.User, error)
 }
 
 type Merch interface {
-	GetMerchByName(string) (*domain.Merch, error)
+	GetMerchByName(context.Context, string) (*domain.Merch, error)
+	ListPrices(context.Context, string) ([]domain.MerchPrice, error)
+	List(context.Context, domain.MerchFilter) ([]domain.Merch, error)
+	Lock(ctx context.Context, tx *gorm.DB, name string) (*domain.Merch, error)
+	Create(context.Context, *gorm.DB, *domain.Merch) (*domain.Merch, error)
+	Update(context.Context, *gorm.DB, *domain.Merch) error
+	TakeStock(ctx context.Context, tx *gorm.DB, name string) (bool, error)
+	CreatePrice(context.Context, *gorm.DB, *domain.MerchPrice) error
+	CountByCategory(context.Context, string) (int64, error)
+	CreateImage(context.Context, *domain.MerchImage) (*domain.MerchImage, error)
+	GetImage(ctx context.Context, name string, id int64) (*domain.MerchImage, error)
+	DeleteImage(context.Context, int64) error
+}
+
+type Categories interface {
+	Create(context.Context, *domain.Category) (*domain.Category, error)
+	Get(context.Context, string) (*domain.Category, error)
+	List(context.Context) ([]domain.Category, error)
+	Update(context.Context, *domain.Category) error
+	Delete(context.Context, string) error
 }
 
 type Purchases interface {
-	Create(*gorm.DB, *domain.Purchase) (*domain.Purchase, error)
-	GetPurchasesForUserByUsername(string) ([]domain.Purchase, error)
+	Create(context.Context, *gorm.DB, *domain.Purchase) (*domain.Purchase, error)
+	GetPurchasesForUserByUsername(context.Context, string) ([]domain.Purchase, error)
+	GetInventory(context.Context, string) ([]domain.InventoryItem, error)
 }
 
 type Transactions interface {
-	Create(*gorm.DB, *domain.Transaction) (*domain.Transaction, error)
-	GetTransactionsForUserByUsername(string) ([]domain.Transaction, error)
+	Create(context.Context, *gorm.DB, *domain.Transaction) (*domain.Transaction, error)
+	GetTransactionsForUserByUsername(context.Context, string) ([]domain.Transaction, error)
+	SentSince(ctx context.Context, tx *gorm.DB, sender string, since time.Time) (*domain.TransferStats, error)
+	ListFeed(context.Context, domain.FeedFilter) ([]domain.Transaction, error)
+}
+
+type BalanceAdjustments interface {
+	Create(context.Context, *gorm.DB, *domain.BalanceAdjustment) (*domain.BalanceAdjustment, error)
+	GetAdjustmentsForUserByUsername(context.Context, string) ([]domain.BalanceAdjustment, error)
+}
+
+type Allowances interface {
+	CreatePolicy(context.Context, *domain.AllowancePolicy) (*domain.AllowancePolicy, error)
+	GetPolicy(context.Context, int64) (*domain.AllowancePolicy, error)
+	ListPolicies(context.Context) ([]domain.AllowancePolicy, error)
+	SetPaused(context.Context, int64, bool) error
+	LastScheduledRun(context.Context, int64) (*domain.AllowanceRun, error)
+	ListRuns(context.Context, int64) ([]domain.AllowanceRun, error)
+	CreateRun(context.Context, *gorm.DB, *domain.AllowanceRun) (bool, error)
+	UpdateRun(context.Context, *gorm.DB, *domain.AllowanceRun) error
+}
+
+type CoinLots interface {
+	Grant(context.Context, *gorm.DB, []domain.CoinLot) error
+	Consume(ctx context.Context, tx *gorm.DB, username string, amount float64) ([]domain.CoinLot, error)
+	ListOpenLots(context.Context, string) ([]domain.CoinLot, error)
+	ListExpiredOwners(ctx context.Context, cutoff time.Time) ([]string, error)
+	ExpiredAmount(ctx context.Context, tx *gorm.DB, username string, cutoff time.Time) (float64, error)
+}
+
+type CoinRequests interface {
+	Create(context.Context, *domain.CoinRequest) (*domain.CoinRequest, error)
+	Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.CoinRequest, error)
+	Update(context.Context, *gorm.DB, *domain.CoinRequest) error
+	ListPending(ctx context.Context, username string, now time.Time) ([]domain.CoinRequest, error)
+	ExpirePending(ctx context.Context, now time.Time) (int64, error)
+}
+
+type ScheduledTransfers interface {
+	Create(context.Context, *domain.ScheduledTransfer) (*domain.ScheduledTransfer, error)
+	Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.ScheduledTransfer, error)
+	Update(context.Context, *gorm.DB, *domain.ScheduledTransfer) error
+	ListBySender(context.Context, string) ([]domain.ScheduledTransfer, error)
+	ListDue(ctx context.Context, now time.Time, limit int) ([]int64, error)
+}
+
+type Holds interface {
+	Create(context.Context, *gorm.DB, *domain.Hold) (*domain.Hold, error)
+	Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.Hold, error)
+	Update(context.Context, *gorm.DB, *domain.Hold) error
+	ListActive(context.Context, string) ([]domain.Hold, error)
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]int64, error)
+}
+
+type PurchaseApprovals interface {
+	Create(context.Context, *gorm.DB, *domain.PurchaseApproval) (*domain.PurchaseApproval, error)
+	Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.PurchaseApproval, error)
+	Update(context.Context, *gorm.DB, *domain.PurchaseApproval) error
+	ListAssigned(ctx context.Context, username string, admin bool) ([]domain.PurchaseApproval, error)
+	ListRequested(context.Context, string) ([]domain.PurchaseApproval, error)
+	ListDue(ctx context.Context, now time.Time, limit int) ([]int64, error)
 }
 
-func (r *Repository) CreatePurchase(username string, merchName string) (*domain.Purchase, error) {
-	tx := r.DB.Begin()
-	user, err := r.Users.GetUserByUsername(username)
+type Wallets interface {
+	Create(context.Context, *gorm.DB, *domain.Wallet) (*domain.Wallet, error)
+	Get(context.Context, int64) (*domain.Wallet, error)
+	Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.Wallet, error)
+	Update(context.Context, *gorm.DB, *domain.Wallet) error
+	ListForUser(context.Context, string) ([]domain.Wallet, error)
+	SaveMember(context.Context, *gorm.DB, *domain.WalletMember) error
+	RemoveMember(ctx context.Context, tx *gorm.DB, walletID int64, username string) error
+	ListMembers(ctx context.Context, tx *gorm.DB, walletID int64) ([]domain.WalletMember, error)
+	CreateContribution(context.Context, *gorm.DB, *domain.WalletContribution) (*domain.WalletContribution, error)
+	ListContributions(ctx context.Context, walletID int64) ([]domain.WalletContribution, error)
+	CreatePurchase(context.Context, *gorm.DB, *domain.WalletPurchase) (*domain.WalletPurchase, error)
+	LockPurchase(ctx context.Context, tx *gorm.DB, walletID, id int64) (*domain.WalletPurchase, error)
+	UpdatePurchase(context.Context, *gorm.DB, *domain.WalletPurchase) error
+	AddApproval(context.Context, *gorm.DB, *domain.WalletApproval) error
+	ListPurchases(ctx context.Context, walletID int64) ([]domain.WalletPurchase, error)
+}
+
+type PromoCodes interface {
+	Create(context.Context, *domain.PromoCode) (*domain.PromoCode, error)
+	Get(context.Context, string) (*domain.PromoCode, error)
+	List(context.Context) ([]domain.PromoCode, error)
+	SetActive(ctx context.Context, code string, active bool) error
+	Lock(ctx context.Context, tx *gorm.DB, codes []string) ([]domain.PromoCode, error)
+	CountRedemptions(ctx context.Context, tx *gorm.DB, code, username string) (int, int, error)
+	CreateRedemptions(context.Context, *gorm.DB, []domain.PromoRedemption) error
+	CompleteRedemptions(ctx context.Context, tx *gorm.DB, approvalID int64, purchaseGUID string) error
+	ReleaseRedemptions(ctx context.Context, tx *gorm.DB, approvalID int64) error
+}
+
+type Notifications interface {
+	Create(context.Context, *gorm.DB, *domain.Notification) error
+	List(ctx context.Context, username string, limit int) ([]domain.Notification, error)
+	MarkRead(ctx context.Context, username string, now time.Time) (int64, error)
+}
+
+type Wishlists interface {
+	Save(context.Context, *domain.WishlistItem) error
+	Delete(ctx context.Context, username, merchName string) (bool, error)
+	List(ctx context.Context, username string) ([]domain.WishlistItem, error)
+	ListChanged(ctx context.Context, limit int) ([]domain.WishlistItem, error)
+	UpdateSeen(context.Context, *gorm.DB, *domain.WishlistItem) error
+}
+
+type Audit interface {
+	Append(context.Context, *gorm.DB, *domain.AuditEntry) error
+	List(context.Context, domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// CreatePurchase charges the buyer for the item, discounted by the promo
+// codes. A gift is delivered to its recipient, who gets a notification.
+// Items whose discounted price needs an approval under the policy are not
+// bought yet: their price is held and the pending approval is returned
+// instead of the purchase.
+func (r *Repository) CreatePurchase(ctx context.Context, username string, merchName string, gift domain.Gift, promoCodes []string, policy domain.PurchasePolicy) (*domain.Purchase, *domain.PurchaseApproval, error) {
+	ctx, span := tracing.Start(ctx, "repository.CreatePurchase")
+	defer span.End()
+
+	recipient := username
+	if gift.Recipient != "" {
+		recipient = gift.Recipient
+	}
+
+	ctx, tx := r.begin(ctx)
+	users, err := r.lockUsers(ctx, tx, username, recipient)
 	if err != nil {
 		tx.Rollback()
-		log.Errorf(err.Error())
-		return nil, err
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, nil, err
+	}
+	user := users[username]
+	if users[recipient] == nil || users[recipient].Username == "" {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("%w: %s", domain.ErrNoSuchUser, recipient)
 	}
 
-	merch, err := r.Merch.GetMerchByName(merchName)
+	merch, err := r.Merch.GetMerchByName(ctx, merchName)
 	if err != nil {
-		log.Errorf(err.Error())
+		logger.FromContext(ctx).Errorf(err.Error())
 		tx.Rollback()
-		return nil, err
+		return nil, nil, err
 	}
 	if merch == nil || merch.Name == "" {
 		tx.Rollback()
-		return nil, errors.New("no merch found")
+		return nil, nil, domain.ErrNoMerch
+	}
+	if !merch.InStock() {
+		tx.Rollback()
+		return nil, nil, domain.ErrOutOfStock
+	}
+
+	price, redemptions, err := r.redeemPromoCodes(ctx, tx, username, merch, promoCodes)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	discount := merch.Price - price
+
+	if policy.NeedsApproval(price) {
+		approval, err := r.requestApproval(ctx, tx, user, &domain.PurchaseApproval{
+			RecipientID: recipient,
+			GiftMessage: gift.Message,
+			MerchName:   merch.Name,
+			Price:       price,
+			Discount:    discount,
+			PromoCodes:  promoCodes,
+		}, policy.ApprovalTimeout)
+		if err == nil && len(redemptions) > 0 {
+			for i := range redemptions {
+				redemptions[i].ApprovalID = &approval.ID
+			}
+			err = r.PromoCodes.CreateRedemptions(ctx, tx, redemptions)
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
+		if err = r.commit(ctx, tx); err != nil {
+			logger.FromContext(ctx).Errorf(err.Error())
+			return nil, nil, tracing.Error(span, err)
+		}
+		return nil, approval, nil
 	}
 
-	if user.Balance < (merch.Price) {
+	purchase, err := r.buy(ctx, tx, user, merch, &domain.Purchase{
+		RecipientID: recipient,
+		GiftMessage: gift.Message,
+		UnitPrice:   merch.Price,
+		Total:       price,
+		Discount:    discount,
+		PromoCodes:  promoCodes,
+	})
+	if err == nil && len(redemptions) > 0 {
+		for i := range redemptions {
+			redemptions[i].PurchaseGUID = purchase.GUID
+		}
+		err = r.PromoCodes.CreateRedemptions(ctx, tx, redemptions)
+	}
+	if err != nil {
 		tx.Rollback()
-		return nil, errors.New("insufficient money")
+		return nil, nil, err
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, nil, tracing.Error(span, err)
+	}
+	return purchase, nil, nil
+}
+
+// buy charges the user the total of the order and records the purchase in
+// tx, the order names the recipient, the prices and the discount. An item
+// whose stock is tracked is taken out of it.
+func (r *Repository) buy(ctx context.Context, tx *gorm.DB, user *domain.User, merch *domain.Merch, order *domain.Purchase) (*domain.Purchase, error) {
+	if err := r.takeStock(ctx, tx, merch); err != nil {
+		return nil, err
+	}
+	before := map[string]float64{"balance": user.Balance}
+	if err := debit(user, order.Total); err != nil {
+		return nil, err
+	}
+	if _, err := r.CoinLots.Consume(ctx, tx, user.Username, order.Total); err != nil {
+		return nil, err
+	}
+
+	order.User = *user
+	order.UserID = user.Username
+	order.Merch = *merch
+	order.MerchName = merch.Name
+	purchase, err := r.Purchases.Create(ctx, tx, order)
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, err
+	}
+	if order.RecipientID != user.Username {
+		if err = r.Notifications.Create(ctx, tx, giftNotification(purchase)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = r.Users.UpdateUser(ctx, tx, user); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, err
+	}
+
+	after := map[string]any{"balance": user.Balance, "item": merch.Name, "price": order.Total}
+	if order.RecipientID != user.Username {
+		after["recipient"] = order.RecipientID
+	}
+	if len(order.PromoCodes) > 0 {
+		after["discount"] = order.Discount
+		after["promo_codes"] = order.PromoCodes
 	}
-	user.Balance -= merch.Price
+	return purchase, r.appendAudit(ctx, tx, domain.AuditPurchase, user.Username, before, after)
+}
+
+// takeStock takes one item out of its stock, if the stock is tracked, and
+// returns ErrOutOfStock when none is left.
+func (r *Repository) takeStock(ctx context.Context, tx *gorm.DB, merch *domain.Merch) error {
+	if merch.Stock == nil {
+		return nil
+	}
+	taken, err := r.Merch.TakeStock(ctx, tx, merch.Name)
+	if err != nil {
+		return err
+	}
+	if !taken {
+		return domain.ErrOutOfStock
+	}
+	return nil
+}
+
+// redeemPromoCodes locks the codes and checks that the user can use them on
+// the item now. It returns the discounted price with a redemption for each
+// code, to be created once the purchase or its approval exists.
+func (r *Repository) redeemPromoCodes(ctx context.Context, tx *gorm.DB, username string, merch *domain.Merch, codes []string) (float64, []domain.PromoRedemption, error) {
+	if len(codes) == 0 {
+		return merch.Price, nil, nil
+	}
+	promos, err := r.PromoCodes.Lock(ctx, tx, codes)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, code := range codes {
+		if !slices.ContainsFunc(promos, func(promo domain.PromoCode) bool { return promo.Code == code }) {
+			return 0, nil, fmt.Errorf("%w: %s", domain.ErrNoPromoCode, code)
+		}
+	}
+
+	now := time.Now()
+	for i := range promos {
+		if err = promos[i].Check(merch.Name, now); err != nil {
+			return 0, nil, err
+		}
+		if promos[i].MaxUses == 0 && promos[i].MaxUsesPerUser == 0 {
+			continue
+		}
+		total, own, err := r.PromoCodes.CountRedemptions(ctx, tx, promos[i].Code, username)
+		if err != nil {
+			return 0, nil, err
+		}
+		if (promos[i].MaxUses > 0 && total >= promos[i].MaxUses) || (promos[i].MaxUsesPerUser > 0 && own >= promos[i].MaxUsesPerUser) {
+			return 0, nil, fmt.Errorf("%w: %s", domain.ErrPromoCodeUsedUp, promos[i].Code)
+		}
+	}
+
+	price, discounts, err := domain.ApplyPromoCodes(promos, merch.Price)
+	if err != nil {
+		return 0, nil, err
+	}
+	redemptions := make([]domain.PromoRedemption, len(promos))
+	for i, promo := range promos {
+		redemptions[i] = domain.PromoRedemption{Code: promo.Code, Username: username, Discount: discounts[i]}
+	}
+	return price, redemptions, nil
+}
+
+func giftNotification(purchase *domain.Purchase) *domain.Notification {
+	message := fmt.Sprintf("%s sent you a gift: %s", purchase.UserID, purchase.MerchName)
+	if purchase.GiftMessage != "" {
+		message += "\n" + purchase.GiftMessage
+	}
+	return &domain.Notification{
+		Username:  purchase.RecipientID,
+		Kind:      domain.NotificationGift,
+		Message:   message,
+		Reference: purchase.GUID,
+	}
+}
+
+// CreateTransaction moves money from the sender to the receiver.
+func (r *Repository) CreateTransaction(ctx context.Context, receiverName, senderName string, money float64, note domain.TransferNote, policy domain.TransferPolicy) (*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "repository.CreateTransaction")
+	defer span.End()
 
-	purchase := &domain.Purchase{
-		User:      *user,
-		UserID:    user.Username,
-		Merch:     *merch,
-		MerchName: merch.Name,
+	transfers := []domain.Transaction{{ReceiverUsername: receiverName, MoneyAmount: money, TransferNote: note}}
+	transactions, err := r.CreateTransactions(ctx, senderName, transfers, policy)
+	if err != nil {
+		return nil, err
+	}
+	return &transactions[0], nil
+}
+
+// CreateTransactions moves money from the sender to the receivers of the
+// transfers in a single transaction, either all of them are made or none.
+// The sender's limits are checked after locking them, so concurrent transfers
+// cannot exceed a daily limit together, and every transfer counts towards them.
+func (r *Repository) CreateTransactions(ctx context.Context, senderName string, transfers []domain.Transaction, policy domain.TransferPolicy) ([]domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "repository.CreateTransactions")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	result, err := r.transfer(ctx, tx, senderName, transfers, policy)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
 	}
-	purchase, err = r.Purchases.Create(tx, purchase)
+	return result, nil
+}
+
+// AcceptCoinRequest pays a pending coin request, the transfer is made like
+// any other and the request is closed in the same transaction.
+func (r *Repository) AcceptCoinRequest(ctx context.Context, id int64, payer string, policy domain.TransferPolicy) (*domain.CoinRequest, error) {
+	ctx, span := tracing.Start(ctx, "repository.AcceptCoinRequest")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	request, err := r.lockOpenRequest(ctx, tx, id, func(request *domain.CoinRequest) bool { return request.Payer == payer })
 	if err != nil {
-		log.Errorf(err.Error())
+		if errors.Is(err, domain.ErrCoinRequestExpired) {
+			return nil, r.commitExpired(ctx, tx, err)
+		}
 		tx.Rollback()
 		return nil, err
 	}
 
-	err = r.Users.UpdateUser(tx, user)
+	transfer := domain.Transaction{
+		ReceiverUsername: request.Requester,
+		MoneyAmount:      request.Amount,
+		TransferNote:     domain.TransferNote{Message: request.Message, Private: true},
+	}
+	transactions, err := r.transfer(ctx, tx, payer, []domain.Transaction{transfer}, policy)
 	if err != nil {
-		log.Errorf(err.Error())
 		tx.Rollback()
 		return nil, err
 	}
+	request.TransactionGUID = &transactions[0].GUID
+	if err = r.resolveRequest(ctx, tx, request, domain.RequestAccepted); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
 
-	tx.Commit()
-	return purchase, nil
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return request, nil
 }
 
-func (r *Repository) CreateTransaction(receiverName, senderName string, money float64) (*domain.Transaction, error) {
-	tx := r.DB.Begin()
-	receiver, err := r.Users.GetUserByUsername(receiverName)
+// CloseCoinRequest closes a pending coin request without a transfer: the
+// payer declines it or the requester cancels it.
+func (r *Repository) CloseCoinRequest(ctx context.Context, id int64, username, status string) (*domain.CoinRequest, error) {
+	ctx, span := tracing.Start(ctx, "repository.CloseCoinRequest")
+	defer span.End()
+
+	allowed := func(request *domain.CoinRequest) bool {
+		if status == domain.RequestDeclined {
+			return request.Payer == username
+		}
+		return request.Requester == username
+	}
+	ctx, tx := r.begin(ctx)
+	request, err := r.lockOpenRequest(ctx, tx, id, allowed)
 	if err != nil {
+		if errors.Is(err, domain.ErrCoinRequestExpired) {
+			return nil, r.commitExpired(ctx, tx, err)
+		}
 		tx.Rollback()
-		log.Errorf(err.Error())
 		return nil, err
 	}
-	if receiver == nil || receiver.Username == "" {
+	if err = r.resolveRequest(ctx, tx, request, status); err != nil {
 		tx.Rollback()
-		return nil, errors.New("no such user")
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return request, nil
+}
+
+// lockOpenRequest locks a pending request the user is allowed to act on.
+// Requests of other users are reported as missing. An expired request is
+// marked as such in tx and ErrCoinRequestExpired is returned.
+func (r *Repository) lockOpenRequest(ctx context.Context, tx *gorm.DB, id int64, allowed func(*domain.CoinRequest) bool) (*domain.CoinRequest, error) {
+	request, err := r.CoinRequests.Lock(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.ID == 0 || !allowed(request) {
+		return nil, domain.ErrNoCoinRequest
+	}
+	if request.Status != domain.RequestPending {
+		return nil, domain.ErrCoinRequestClosed
+	}
+	if !time.Now().Before(request.ExpiresAt) {
+		if err = r.resolveRequest(ctx, tx, request, domain.RequestExpired); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrCoinRequestExpired
+	}
+	return request, nil
+}
+
+// commitExpired keeps the expired status set in tx and returns err.
+func (r *Repository) commitExpired(ctx context.Context, tx *gorm.DB, err error) error {
+	if commitErr := r.commit(ctx, tx); commitErr != nil {
+		logger.FromContext(ctx).Errorf(commitErr.Error())
+		return commitErr
+	}
+	return err
+}
+
+func (r *Repository) resolveRequest(ctx context.Context, tx *gorm.DB, request *domain.CoinRequest, status string) error {
+	now := time.Now()
+	before := map[string]any{"id": request.ID, "status": request.Status}
+	request.Status = status
+	request.ResolvedAt = &now
+	if err := r.CoinRequests.Update(ctx, tx, request); err != nil {
+		return err
 	}
+	after := map[string]any{"id": request.ID, "status": request.Status}
+	if request.TransactionGUID != nil {
+		after["transaction"] = *request.TransactionGUID
+	}
+	return r.appendAudit(ctx, tx, domain.AuditCoinRequest, request.Requester, before, after)
+}
 
-	sender, err := r.Users.GetUserByUsername(senderName)
+// ExecuteScheduledTransfer makes a due scheduled transfer with the same checks
+// as CreateTransaction. When the checks fail, for example the sender no longer
+// has enough coins, the transfer is marked failed instead of returning the
+// error, so it is not retried.
+func (r *Repository) ExecuteScheduledTransfer(ctx context.Context, id int64, policy domain.TransferPolicy) (*domain.ScheduledTransfer, error) {
+	ctx, span := tracing.Start(ctx, "repository.ExecuteScheduledTransfer")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	scheduled, err := r.lockPendingTransfer(ctx, tx, id, func(*domain.ScheduledTransfer) bool { return true })
 	if err != nil {
 		tx.Rollback()
-		log.Errorf(err.Error())
 		return nil, err
 	}
 
-	if sender.Balance < (money) {
+	transfer := domain.Transaction{
+		ReceiverUsername: scheduled.ReceiverUsername,
+		MoneyAmount:      scheduled.Amount,
+		TransferNote:     scheduled.TransferNote,
+	}
+	status := domain.ScheduledExecuted
+	if err = tx.SavePoint("transfer").Error; err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	buffer := ctx.Value(auditBufferKey{}).(*auditBuffer)
+	queued := len(buffer.entries)
+	transactions, err := r.transfer(ctx, tx, scheduled.SenderUsername, []domain.Transaction{transfer}, policy)
+	switch {
+	case err == nil:
+		scheduled.TransactionGUID = &transactions[0].GUID
+	case errors.Is(err, domain.ErrInsufficientMoney), errors.Is(err, domain.ErrNoSuchUser), errors.Is(err, domain.ErrTransferLimit):
+		status = domain.ScheduledFailed
+		scheduled.FailureReason = err.Error()
+		if err = tx.RollbackTo("transfer").Error; err != nil {
+			tx.Rollback()
+			return nil, tracing.Error(span, err)
+		}
+		buffer.entries = buffer.entries[:queued]
+	default:
+		tx.Rollback()
+		return nil, err
+	}
+	if err = r.closeScheduledTransfer(ctx, tx, scheduled, status); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return scheduled, nil
+}
+
+// CancelScheduledTransfer cancels a pending transfer scheduled by the sender.
+func (r *Repository) CancelScheduledTransfer(ctx context.Context, id int64, sender string) (*domain.ScheduledTransfer, error) {
+	ctx, span := tracing.Start(ctx, "repository.CancelScheduledTransfer")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	scheduled, err := r.lockPendingTransfer(ctx, tx, id, func(scheduled *domain.ScheduledTransfer) bool {
+		return scheduled.SenderUsername == sender
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = r.closeScheduledTransfer(ctx, tx, scheduled, domain.ScheduledCancelled); err != nil {
 		tx.Rollback()
-		return nil, errors.New("insufficient money")
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return scheduled, nil
+}
+
+// lockPendingTransfer locks a pending scheduled transfer the user is allowed
+// to act on. Transfers of other users are reported as missing.
+func (r *Repository) lockPendingTransfer(ctx context.Context, tx *gorm.DB, id int64, allowed func(*domain.ScheduledTransfer) bool) (*domain.ScheduledTransfer, error) {
+	scheduled, err := r.ScheduledTransfers.Lock(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if scheduled.ID == 0 || !allowed(scheduled) {
+		return nil, domain.ErrNoScheduledTransfer
+	}
+	if scheduled.Status != domain.ScheduledPending {
+		return nil, domain.ErrScheduledTransferClosed
+	}
+	return scheduled, nil
+}
+
+func (r *Repository) closeScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduled *domain.ScheduledTransfer, status string) error {
+	now := time.Now()
+	before := map[string]any{"id": scheduled.ID, "status": scheduled.Status}
+	scheduled.Status = status
+	scheduled.ResolvedAt = &now
+	if err := r.ScheduledTransfers.Update(ctx, tx, scheduled); err != nil {
+		return err
+	}
+	after := map[string]any{"id": scheduled.ID, "status": scheduled.Status}
+	if scheduled.TransactionGUID != nil {
+		after["transaction"] = *scheduled.TransactionGUID
+	}
+	if scheduled.FailureReason != "" {
+		after["reason"] = scheduled.FailureReason
+	}
+	return r.appendAudit(ctx, tx, domain.AuditScheduledTransfer, scheduled.SenderUsername, before, after)
+}
+
+// transfer makes the transfers from the sender in tx, checking the balance
+// and the sender's limits. The caller rolls tx back on error.
+func (r *Repository) transfer(ctx context.Context, tx *gorm.DB, senderName string, transfers []domain.Transaction, policy domain.TransferPolicy) ([]domain.Transaction, error) {
+	usernames := []string{senderName}
+	var total float64
+	for _, transfer := range transfers {
+		usernames = append(usernames, transfer.ReceiverUsername)
+		total += transfer.MoneyAmount
+	}
+
+	users, err := r.lockUsers(ctx, tx, usernames...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, err
+	}
+	sender := users[senderName]
+	for _, transfer := range transfers {
+		if receiver := users[transfer.ReceiverUsername]; receiver == nil || receiver.Username == "" {
+			return nil, fmt.Errorf("%w: %s", domain.ErrNoSuchUser, transfer.ReceiverUsername)
+		}
+	}
+	if sender.Available() < total {
+		return nil, domain.ErrInsufficientMoney
+	}
+
+	dayStart, dayEnd := domain.TransferDay(time.Now())
+	stats, err := r.Transactions.SentSince(ctx, tx, sender.Username, dayStart)
+	if err != nil {
+		return nil, err
+	}
+	limits := policy.For(sender.Role)
+
+	result := make([]domain.Transaction, 0, len(transfers))
+	for _, transfer := range transfers {
+		receiver := users[transfer.ReceiverUsername]
+		if err = limits.Check(*stats, receiver.Username, transfer.MoneyAmount, dayEnd); err != nil {
+			return nil, err
+		}
+		stats.Add(receiver.Username, transfer.MoneyAmount)
+
+		before := map[string]float64{"sender_balance": sender.Balance, "receiver_balance": receiver.Balance}
+		if err = debit(sender, transfer.MoneyAmount); err != nil {
+			return nil, err
+		}
+		credit(receiver, transfer.MoneyAmount)
+		if err = r.moveLots(ctx, tx, sender.Username, receiver.Username, transfer.MoneyAmount); err != nil {
+			return nil, err
+		}
+
+		transaction := &domain.Transaction{
+			Receiver:         *receiver,
+			Sender:           *sender,
+			MoneyAmount:      transfer.MoneyAmount,
+			ReceiverUsername: receiver.Username,
+			SenderUsername:   sender.Username,
+			TransferNote:     transfer.TransferNote,
+			BatchID:          transfer.BatchID,
+		}
+		transaction, err = r.Transactions.Create(ctx, tx, transaction)
+		if err != nil {
+			logger.FromContext(ctx).Errorf(err.Error())
+			return nil, err
+		}
+
+		after := map[string]any{
+			"receiver":         receiver.Username,
+			"sender_balance":   sender.Balance,
+			"receiver_balance": receiver.Balance,
+			"amount":           transfer.MoneyAmount,
+			"transaction":      transaction.GUID,
+		}
+		if transaction.BatchID != "" {
+			after["batch"] = transaction.BatchID
+		}
+		if err = r.appendAudit(ctx, tx, domain.AuditTransfer, sender.Username, before, after); err != nil {
+			return nil, err
+		}
+		result = append(result, *transaction)
 	}
 
-	sender.Balance -= money
-	receiver.Balance += money
+	for _, username := range slices.Sorted(maps.Keys(users)) {
+		if err = r.Users.UpdateUser(ctx, tx, users[username]); err != nil {
+			logger.FromContext(ctx).Errorf(err.Error())
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// CreateHold reserves hold.Amount of the user's available balance until
+// hold.ExpiresAt.
+func (r *Repository) CreateHold(ctx context.Context, hold *domain.Hold) (*domain.Hold, error) {
+	ctx, span := tracing.Start(ctx, "repository.CreateHold")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	users, err := r.lockUsers(ctx, tx, hold.Username)
+	if err != nil {
+		tx.Rollback()
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, err
+	}
+	user := users[hold.Username]
+	if user.Username == "" {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: %s", domain.ErrNoSuchUser, hold.Username)
+	}
+	hold, err = r.placeHold(ctx, tx, user, hold)
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
 
-	transaction := &domain.Transaction{
-		Receiver:         *receiver,
-		Sender:           *sender,
-		MoneyAmount:      money,
-		ReceiverUsername: receiver.Username,
-		SenderUsername:   sender.Username,
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
 	}
+	return hold, nil
+}
 
-	transaction, err = r.Transactions.Create(tx, transaction)
+// CaptureHold debits amount of an active hold, or all of it when amount is
+// zero, and releases the rest. The debit is recorded as a balance adjustment
+// with the reason of the hold.
+func (r *Repository) CaptureHold(ctx context.Context, id int64, amount float64) (*domain.Hold, error) {
+	ctx, span := tracing.Start(ctx, "repository.CaptureHold")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	hold, user, err := r.lockActiveHold(ctx, tx, id)
 	if err != nil {
-		log.Errorf(err.Error())
 		tx.Rollback()
 		return nil, err
 	}
-	err = r.Users.UpdateUser(tx, receiver)
+	if !time.Now().Before(hold.ExpiresAt) {
+		if err = r.resolveHold(ctx, tx, hold, user, domain.HoldExpired); err != nil {
+			tx.Rollback()
+			return nil, tracing.Error(span, err)
+		}
+		return nil, r.commitExpired(ctx, tx, domain.ErrHoldExpired)
+	}
+	if err = r.captureHold(ctx, tx, hold, user, amount); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return hold, nil
+}
+
+// ReleaseHold makes the coins of an active hold available again. A hold
+// expired by now is marked expired rather than released.
+func (r *Repository) ReleaseHold(ctx context.Context, id int64, now time.Time) (*domain.Hold, error) {
+	ctx, span := tracing.Start(ctx, "repository.ReleaseHold")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	hold, user, err := r.lockActiveHold(ctx, tx, id)
 	if err != nil {
-		log.Errorf(err.Error())
 		tx.Rollback()
 		return nil, err
 	}
-	err = r.Users.UpdateUser(tx, sender)
+	status := domain.HoldReleased
+	if !now.Before(hold.ExpiresAt) {
+		status = domain.HoldExpired
+	}
+	if err = r.resolveHold(ctx, tx, hold, user, status); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return hold, nil
+}
+
+// placeHold reserves hold.Amount of the user's available balance in tx.
+func (r *Repository) placeHold(ctx context.Context, tx *gorm.DB, user *domain.User, hold *domain.Hold) (*domain.Hold, error) {
+	if user.Available() < hold.Amount {
+		return nil, domain.ErrInsufficientMoney
+	}
+	before := map[string]float64{"held": user.Held}
+	user.Held += hold.Amount
+	if err := r.Users.UpdateUser(ctx, tx, user); err != nil {
+		return nil, err
+	}
+
+	hold.Username = user.Username
+	hold.Status = domain.HoldActive
+	hold, err := r.Holds.Create(ctx, tx, hold)
+	if err != nil {
+		return nil, err
+	}
+	after := map[string]any{"id": hold.ID, "status": hold.Status, "amount": hold.Amount, "reason": hold.Reason, "held": user.Held}
+	return hold, r.appendAudit(ctx, tx, domain.AuditHold, user.Username, before, after)
+}
+
+// lockActiveHold locks an active hold and its owner.
+func (r *Repository) lockActiveHold(ctx context.Context, tx *gorm.DB, id int64) (*domain.Hold, *domain.User, error) {
+	hold, err := r.Holds.Lock(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hold.ID == 0 {
+		return nil, nil, domain.ErrNoHold
+	}
+	if hold.Status != domain.HoldActive {
+		return nil, nil, domain.ErrHoldClosed
+	}
+	users, err := r.lockUsers(ctx, tx, hold.Username)
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, nil, err
+	}
+	return hold, users[hold.Username], nil
+}
+
+// captureHold frees the held coins and debits amount of them in tx, zero
+// captures the whole hold.
+func (r *Repository) captureHold(ctx context.Context, tx *gorm.DB, hold *domain.Hold, user *domain.User, amount float64) error {
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if amount > hold.Amount {
+		return domain.ErrCaptureExceedsHold
+	}
+	user.Held = max(user.Held-hold.Amount, 0)
+	adjustments, err := r.applyAdjustments(ctx, tx, map[string]*domain.User{user.Username: user}, []domain.BalanceAdjustment{
+		{Username: user.Username, Amount: -amount, Reason: hold.Reason},
+	})
+	if err != nil {
+		return err
+	}
+	hold.Captured = amount
+	hold.AdjustmentGUID = &adjustments[0].GUID
+	return r.closeHold(ctx, tx, hold, domain.HoldCaptured)
+}
+
+// resolveHold makes the held coins available again and closes the hold with status.
+func (r *Repository) resolveHold(ctx context.Context, tx *gorm.DB, hold *domain.Hold, user *domain.User, status string) error {
+	user.Held = max(user.Held-hold.Amount, 0)
+	if err := r.Users.UpdateUser(ctx, tx, user); err != nil {
+		return err
+	}
+	return r.closeHold(ctx, tx, hold, status)
+}
+
+func (r *Repository) closeHold(ctx context.Context, tx *gorm.DB, hold *domain.Hold, status string) error {
+	now := time.Now()
+	before := map[string]any{"id": hold.ID, "status": hold.Status}
+	hold.Status = status
+	hold.ResolvedAt = &now
+	if err := r.Holds.Update(ctx, tx, hold); err != nil {
+		return err
+	}
+	after := map[string]any{"id": hold.ID, "status": hold.Status}
+	if hold.AdjustmentGUID != nil {
+		after["captured"] = hold.Captured
+		after["adjustment"] = *hold.AdjustmentGUID
+	}
+	if hold.PurchaseGUID != nil {
+		after["captured"] = hold.Captured
+		after["purchase"] = *hold.PurchaseGUID
+	}
+	return r.appendAudit(ctx, tx, domain.AuditHold, hold.Username, before, after)
+}
+
+// requestApproval holds the price of the purchase and assigns the approval
+// to the buyer's manager, or to admins when the buyer has none. The hold
+// lasts long enough for the approval to be escalated once and then expire.
+func (r *Repository) requestApproval(ctx context.Context, tx *gorm.DB, user *domain.User, approval *domain.PurchaseApproval, timeout time.Duration) (*domain.PurchaseApproval, error) {
+	now := time.Now()
+	hold, err := r.placeHold(ctx, tx, user, &domain.Hold{
+		Amount:    approval.Price,
+		Reason:    "purchase approval: " + approval.MerchName,
+		ExpiresAt: now.Add(2 * timeout),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	approval.Username = user.Username
+	approval.Approver = user.Manager
+	approval.HoldID = hold.ID
+	approval.Status = domain.ApprovalPending
+	approval.DueAt = now.Add(timeout)
+	approval, err = r.PurchaseApprovals.Create(ctx, tx, approval)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Approver != "" {
+		notification := &domain.Notification{
+			Username:  approval.Approver,
+			Kind:      domain.NotificationApproval,
+			Message:   fmt.Sprintf("%s asks you to approve buying %s for %v coins", user.Username, approval.MerchName, approval.Price),
+			Reference: strconv.FormatInt(approval.ID, 10),
+		}
+		if err = r.Notifications.Create(ctx, tx, notification); err != nil {
+			return nil, err
+		}
+	}
+	after := map[string]any{"id": approval.ID, "status": approval.Status, "item": approval.MerchName, "price": approval.Price, "approver": approval.Approver}
+	return approval, r.appendAudit(ctx, tx, domain.AuditPurchaseApproval, user.Username, nil, after)
+}
+
+// ApprovePurchase buys the item of a pending approval with the held coins.
+// Only the assigned approver or an admin can approve, never the buyer.
+func (r *Repository) ApprovePurchase(ctx context.Context, id int64, approver string, admin bool) (*domain.PurchaseApproval, error) {
+	ctx, span := tracing.Start(ctx, "repository.ApprovePurchase")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	approval, hold, user, err := r.lockPendingApproval(ctx, tx, id, canApprove(approver, admin))
 	if err != nil {
-		log.Errorf(err.Error())
+		if errors.Is(err, domain.ErrApprovalExpired) {
+			return nil, r.commitExpired(ctx, tx, err)
+		}
 		tx.Rollback()
 		return nil, err
 	}
-	tx.Commit()
-	return transaction, nil
+	merch, err := r.Merch.GetMerchByName(ctx, approval.MerchName)
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	// The held coins pay for the item, so they are freed right before the debit.
+	user.Held = max(user.Held-hold.Amount, 0)
+	purchase, err := r.buy(ctx, tx, user, merch, &domain.Purchase{
+		RecipientID: approval.RecipientID,
+		GiftMessage: approval.GiftMessage,
+		UnitPrice:   approval.Price + approval.Discount,
+		Total:       approval.Price,
+		Discount:    approval.Discount,
+		PromoCodes:  approval.PromoCodes,
+	})
+	if err == nil && len(approval.PromoCodes) > 0 {
+		err = r.PromoCodes.CompleteRedemptions(ctx, tx, approval.ID, purchase.GUID)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	hold.Captured = approval.Price
+	hold.PurchaseGUID = &purchase.GUID
+	if err = r.closeHold(ctx, tx, hold, domain.HoldCaptured); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	approval.PurchaseGUID = purchase.GUID
+	if err = r.closeApproval(ctx, tx, approval, domain.ApprovalApproved, approver); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return approval, nil
+}
+
+// RejectPurchase closes a pending approval and releases the held coins.
+func (r *Repository) RejectPurchase(ctx context.Context, id int64, approver string, admin bool) (*domain.PurchaseApproval, error) {
+	ctx, span := tracing.Start(ctx, "repository.RejectPurchase")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	approval, hold, user, err := r.lockPendingApproval(ctx, tx, id, canApprove(approver, admin))
+	if err != nil {
+		if errors.Is(err, domain.ErrApprovalExpired) {
+			return nil, r.commitExpired(ctx, tx, err)
+		}
+		tx.Rollback()
+		return nil, err
+	}
+	if err = r.resolveHold(ctx, tx, hold, user, domain.HoldReleased); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	if err = r.closeApproval(ctx, tx, approval, domain.ApprovalRejected, approver); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return approval, nil
+}
+
+// EscalatePurchaseApproval handles a pending approval due by now. An approval
+// assigned to a manager is escalated to admins for another timeout, one
+// already with admins expires and its coins are released.
+func (r *Repository) EscalatePurchaseApproval(ctx context.Context, id int64, now time.Time, timeout time.Duration) (*domain.PurchaseApproval, error) {
+	ctx, span := tracing.Start(ctx, "repository.EscalatePurchaseApproval")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	approval, err := r.PurchaseApprovals.Lock(ctx, tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	if approval.ID == 0 {
+		tx.Rollback()
+		return nil, domain.ErrNoApproval
+	}
+	if approval.Status != domain.ApprovalPending || now.Before(approval.DueAt) {
+		tx.Rollback()
+		return nil, domain.ErrApprovalClosed
+	}
+
+	if approval.Approver != "" {
+		before := map[string]any{"id": approval.ID, "approver": approval.Approver, "due_at": approval.DueAt}
+		approval.Approver = ""
+		approval.Escalated = true
+		approval.DueAt = approval.DueAt.Add(timeout)
+		if err = r.PurchaseApprovals.Update(ctx, tx, approval); err != nil {
+			tx.Rollback()
+			return nil, tracing.Error(span, err)
+		}
+		after := map[string]any{"id": approval.ID, "approver": approval.Approver, "due_at": approval.DueAt}
+		if err = r.appendAudit(ctx, tx, domain.AuditPurchaseApproval, approval.Username, before, after); err != nil {
+			tx.Rollback()
+			return nil, tracing.Error(span, err)
+		}
+	} else if err = r.expireApproval(ctx, tx, approval); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return approval, nil
+}
+
+func canApprove(approver string, admin bool) func(*domain.PurchaseApproval) bool {
+	return func(approval *domain.PurchaseApproval) bool {
+		return approval.Username != approver && (admin || approval.Approver == approver)
+	}
+}
+
+// lockPendingApproval locks a pending approval the user is allowed to handle
+// with its hold and buyer. Approvals of other approvers are reported as
+// missing. When the hold has run out the approval is marked expired in tx and
+// ErrApprovalExpired is returned.
+func (r *Repository) lockPendingApproval(ctx context.Context, tx *gorm.DB, id int64, allowed func(*domain.PurchaseApproval) bool) (*domain.PurchaseApproval, *domain.Hold, *domain.User, error) {
+	approval, err := r.PurchaseApprovals.Lock(ctx, tx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if approval.ID == 0 || !allowed(approval) {
+		return nil, nil, nil, domain.ErrNoApproval
+	}
+	if approval.Status != domain.ApprovalPending {
+		return nil, nil, nil, domain.ErrApprovalClosed
+	}
+	hold, user, err := r.lockActiveHold(ctx, tx, approval.HoldID)
+	if err != nil && !errors.Is(err, domain.ErrHoldClosed) {
+		return nil, nil, nil, err
+	}
+	if err != nil || !time.Now().Before(hold.ExpiresAt) {
+		if err = r.expireApproval(ctx, tx, approval); err != nil {
+			return nil, nil, nil, err
+		}
+		return nil, nil, nil, domain.ErrApprovalExpired
+	}
+	return approval, hold, user, nil
+}
+
+// expireApproval closes the approval as expired and releases its hold, unless
+// the hold expiry job has already done it.
+func (r *Repository) expireApproval(ctx context.Context, tx *gorm.DB, approval *domain.PurchaseApproval) error {
+	hold, user, err := r.lockActiveHold(ctx, tx, approval.HoldID)
+	switch {
+	case errors.Is(err, domain.ErrHoldClosed):
+	case err != nil:
+		return err
+	default:
+		if err = r.resolveHold(ctx, tx, hold, user, domain.HoldExpired); err != nil {
+			return err
+		}
+	}
+	return r.closeApproval(ctx, tx, approval, domain.ApprovalExpired, actorFromContext(ctx))
+}
+
+// closeApproval resolves the approval with status and tells the buyer. The
+// promo codes of a purchase that was not approved can be used again.
+func (r *Repository) closeApproval(ctx context.Context, tx *gorm.DB, approval *domain.PurchaseApproval, status, resolvedBy string) error {
+	now := time.Now()
+	before := map[string]any{"id": approval.ID, "status": approval.Status}
+	approval.Status = status
+	approval.ResolvedAt = &now
+	approval.ResolvedBy = resolvedBy
+	if err := r.PurchaseApprovals.Update(ctx, tx, approval); err != nil {
+		return err
+	}
+	if status != domain.ApprovalApproved && len(approval.PromoCodes) > 0 {
+		if err := r.PromoCodes.ReleaseRedemptions(ctx, tx, approval.ID); err != nil {
+			return err
+		}
+	}
+
+	var message string
+	switch status {
+	case domain.ApprovalApproved:
+		message = fmt.Sprintf("%s approved your purchase of %s", resolvedBy, approval.MerchName)
+	case domain.ApprovalRejected:
+		message = fmt.Sprintf("%s rejected your purchase of %s, the coins are available again", resolvedBy, approval.MerchName)
+	default:
+		message = fmt.Sprintf("Your purchase of %s was not approved in time, the coins are available again", approval.MerchName)
+	}
+	notification := &domain.Notification{
+		Username:  approval.Username,
+		Kind:      domain.NotificationApproval,
+		Message:   message,
+		Reference: strconv.FormatInt(approval.ID, 10),
+	}
+	if err := r.Notifications.Create(ctx, tx, notification); err != nil {
+		return err
+	}
+
+	after := map[string]any{"id": approval.ID, "status": approval.Status, "resolved_by": approval.ResolvedBy}
+	if approval.PurchaseGUID != "" {
+		after["purchase"] = approval.PurchaseGUID
+	}
+	return r.appendAudit(ctx, tx, domain.AuditPurchaseApproval, approval.Username, before, after)
+}
+
+// CreateWallet creates a team wallet owned by its creator, the other members
+// join it as regular members.
+func (r *Repository) CreateWallet(ctx context.Context, wallet *domain.Wallet, members []string) (*domain.Wallet, error) {
+	ctx, span := tracing.Start(ctx, "repository.CreateWallet")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	users, err := r.lockUsers(ctx, tx, append([]string{wallet.CreatedBy}, members...)...)
+	if err != nil {
+		tx.Rollback()
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, err
+	}
+	for _, username := range members {
+		if users[username].Username == "" {
+			tx.Rollback()
+			return nil, fmt.Errorf("%w: %s", domain.ErrNoSuchUser, username)
+		}
+	}
+
+	wallet.Balance = 0
+	if wallet, err = r.Wallets.Create(ctx, tx, wallet); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	roles := map[string]string{wallet.CreatedBy: domain.WalletRoleOwner}
+	for _, username := range members {
+		if username != wallet.CreatedBy {
+			roles[username] = domain.WalletRoleMember
+		}
+	}
+	for _, username := range slices.Sorted(maps.Keys(roles)) {
+		member := &domain.WalletMember{WalletID: wallet.ID, Username: username, Role: roles[username]}
+		if err = r.Wallets.SaveMember(ctx, tx, member); err != nil {
+			tx.Rollback()
+			return nil, tracing.Error(span, err)
+		}
+	}
+	after := map[string]any{"id": wallet.ID, "name": wallet.Name, "quorum": wallet.Quorum, "members": roles}
+	if err = r.appendAudit(ctx, tx, domain.AuditWallet, walletSubject(wallet.ID), nil, after); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return wallet, nil
+}
+
+// SaveWalletMember adds a member to the wallet or changes their role, only
+// owners can do it.
+func (r *Repository) SaveWalletMember(ctx context.Context, actor string, member *domain.WalletMember) error {
+	ctx, span := tracing.Start(ctx, "repository.SaveWalletMember")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	_, members, err := r.lockWallet(ctx, tx, member.WalletID, actor)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if findMember(members, actor).Role != domain.WalletRoleOwner {
+		tx.Rollback()
+		return domain.ErrNotWalletOwner
+	}
+	users, err := r.lockUsers(ctx, tx, member.Username)
+	if err != nil {
+		tx.Rollback()
+		logger.FromContext(ctx).Errorf(err.Error())
+		return err
+	}
+	if users[member.Username].Username == "" {
+		tx.Rollback()
+		return fmt.Errorf("%w: %s", domain.ErrNoSuchUser, member.Username)
+	}
+	before := findMember(members, member.Username)
+	if before != nil && before.Role == domain.WalletRoleOwner && member.Role != domain.WalletRoleOwner && countOwners(members) == 1 {
+		tx.Rollback()
+		return domain.ErrLastWalletOwner
+	}
+
+	if err = r.Wallets.SaveMember(ctx, tx, member); err != nil {
+		tx.Rollback()
+		return tracing.Error(span, err)
+	}
+	var beforeAudit any
+	if before != nil {
+		beforeAudit = map[string]string{"member": before.Username, "role": before.Role}
+	}
+	after := map[string]string{"member": member.Username, "role": member.Role}
+	if err = r.appendAudit(ctx, tx, domain.AuditWallet, walletSubject(member.WalletID), beforeAudit, after); err != nil {
+		tx.Rollback()
+		return tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// RemoveWalletMember removes a member from the wallet. Owners can remove
+// anyone and members can leave, the last owner cannot.
+func (r *Repository) RemoveWalletMember(ctx context.Context, walletID int64, actor, username string) error {
+	ctx, span := tracing.Start(ctx, "repository.RemoveWalletMember")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	_, members, err := r.lockWallet(ctx, tx, walletID, actor)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if actor != username && findMember(members, actor).Role != domain.WalletRoleOwner {
+		tx.Rollback()
+		return domain.ErrNotWalletOwner
+	}
+	member := findMember(members, username)
+	if member == nil {
+		tx.Rollback()
+		return fmt.Errorf("%w: %s", domain.ErrNoSuchUser, username)
+	}
+	if member.Role == domain.WalletRoleOwner && countOwners(members) == 1 {
+		tx.Rollback()
+		return domain.ErrLastWalletOwner
+	}
+
+	if err = r.Wallets.RemoveMember(ctx, tx, walletID, username); err != nil {
+		tx.Rollback()
+		return tracing.Error(span, err)
+	}
+	before := map[string]string{"member": member.Username, "role": member.Role}
+	if err = r.appendAudit(ctx, tx, domain.AuditWallet, walletSubject(walletID), before, nil); err != nil {
+		tx.Rollback()
+		return tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// ContributeToWallet moves amount from the member's available balance to the wallet.
+func (r *Repository) ContributeToWallet(ctx context.Context, walletID int64, username string, amount float64) (*domain.WalletContribution, error) {
+	ctx, span := tracing.Start(ctx, "repository.ContributeToWallet")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	wallet, _, err := r.lockWallet(ctx, tx, walletID, username)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	users, err := r.lockUsers(ctx, tx, username)
+	if err != nil {
+		tx.Rollback()
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, err
+	}
+	user := users[username]
+
+	before := map[string]float64{"balance": user.Balance, "wallet_balance": wallet.Balance}
+	if err = debit(user, amount); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if _, err = r.CoinLots.Consume(ctx, tx, username, amount); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	wallet.Balance += amount
+	if err = r.Users.UpdateUser(ctx, tx, user); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	if err = r.Wallets.Update(ctx, tx, wallet); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	contribution, err := r.Wallets.CreateContribution(ctx, tx, &domain.WalletContribution{WalletID: walletID, Username: username, Amount: amount})
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	after := map[string]any{
+		"wallet":         walletID,
+		"amount":         amount,
+		"balance":        user.Balance,
+		"wallet_balance": wallet.Balance,
+		"contribution":   contribution.GUID,
+	}
+	if err = r.appendAudit(ctx, tx, domain.AuditWalletContribute, username, before, after); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return contribution, nil
+}
+
+// ProposeWalletPurchase proposes buying the item from the wallet, the proposal
+// counts as the member's approval.
+func (r *Repository) ProposeWalletPurchase(ctx context.Context, walletID int64, username, merchName string) (*domain.WalletPurchase, error) {
+	ctx, span := tracing.Start(ctx, "repository.ProposeWalletPurchase")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	wallet, members, err := r.lockWallet(ctx, tx, walletID, username)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	merch, err := r.Merch.GetMerchByName(ctx, merchName)
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	if merch == nil || merch.Name == "" {
+		tx.Rollback()
+		return nil, domain.ErrNoMerch
+	}
+
+	purchase, err := r.Wallets.CreatePurchase(ctx, tx, &domain.WalletPurchase{
+		WalletID:   walletID,
+		MerchName:  merch.Name,
+		Price:      merch.Price,
+		ProposedBy: username,
+		Status:     domain.WalletPurchasePending,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	if err = r.approveWalletPurchase(ctx, tx, wallet, findMember(members, username), purchase); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return purchase, nil
+}
+
+// ApproveWalletPurchase adds the member's approval to a pending purchase and
+// makes the purchase once it is approved by an owner or the quorum.
+func (r *Repository) ApproveWalletPurchase(ctx context.Context, walletID, id int64, username string) (*domain.WalletPurchase, error) {
+	ctx, span := tracing.Start(ctx, "repository.ApproveWalletPurchase")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	wallet, members, purchase, err := r.lockPendingWalletPurchase(ctx, tx, walletID, id, username)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = r.approveWalletPurchase(ctx, tx, wallet, findMember(members, username), purchase); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return purchase, nil
+}
+
+// RejectWalletPurchase closes a pending purchase without buying it. Owners
+// can reject any purchase, the proposer can withdraw their own.
+func (r *Repository) RejectWalletPurchase(ctx context.Context, walletID, id int64, username string) (*domain.WalletPurchase, error) {
+	ctx, span := tracing.Start(ctx, "repository.RejectWalletPurchase")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	_, members, purchase, err := r.lockPendingWalletPurchase(ctx, tx, walletID, id, username)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if purchase.ProposedBy != username && findMember(members, username).Role != domain.WalletRoleOwner {
+		tx.Rollback()
+		return nil, domain.ErrNotWalletOwner
+	}
+	if err = r.closeWalletPurchase(ctx, tx, purchase, domain.WalletPurchaseRejected); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return purchase, nil
+}
+
+// lockWallet locks the wallet and returns it with its members. Wallets the
+// user is not a member of are reported as missing.
+func (r *Repository) lockWallet(ctx context.Context, tx *gorm.DB, id int64, username string) (*domain.Wallet, []domain.WalletMember, error) {
+	wallet, err := r.Wallets.Lock(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if wallet.ID == 0 {
+		return nil, nil, domain.ErrNoWallet
+	}
+	members, err := r.Wallets.ListMembers(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if findMember(members, username) == nil {
+		return nil, nil, domain.ErrNoWallet
+	}
+	return wallet, members, nil
+}
+
+func (r *Repository) lockPendingWalletPurchase(ctx context.Context, tx *gorm.DB, walletID, id int64, username string) (*domain.Wallet, []domain.WalletMember, *domain.WalletPurchase, error) {
+	wallet, members, err := r.lockWallet(ctx, tx, walletID, username)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	purchase, err := r.Wallets.LockPurchase(ctx, tx, walletID, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if purchase.ID == 0 {
+		return nil, nil, nil, domain.ErrNoWalletPurchase
+	}
+	if purchase.Status != domain.WalletPurchasePending {
+		return nil, nil, nil, domain.ErrWalletPurchaseClosed
+	}
+	return wallet, members, purchase, nil
+}
+
+// approveWalletPurchase records the member's approval and, once an owner or
+// the quorum has approved, pays for the item from the wallet at its current
+// price. The item is delivered to the proposer.
+func (r *Repository) approveWalletPurchase(ctx context.Context, tx *gorm.DB, wallet *domain.Wallet, member *domain.WalletMember, purchase *domain.WalletPurchase) error {
+	if err := r.Wallets.AddApproval(ctx, tx, &domain.WalletApproval{WalletPurchaseID: purchase.ID, Username: member.Username}); err != nil {
+		return err
+	}
+	if !slices.Contains(purchase.Approvals, member.Username) {
+		purchase.Approvals = append(purchase.Approvals, member.Username)
+	}
+	approved := member.Role == domain.WalletRoleOwner || (wallet.Quorum > 0 && len(purchase.Approvals) >= wallet.Quorum)
+	if !approved {
+		after := map[string]any{"id": purchase.ID, "status": purchase.Status, "approvals": purchase.Approvals}
+		return r.appendAudit(ctx, tx, domain.AuditWalletPurchase, walletSubject(wallet.ID), nil, after)
+	}
+
+	// The price is read under the lock, so it cannot change before the debit.
+	merch, err := r.Merch.Lock(ctx, tx, purchase.MerchName)
+	if err != nil {
+		return err
+	}
+	if merch == nil || merch.Name == "" {
+		return domain.ErrNoMerch
+	}
+	if wallet.Balance < merch.Price {
+		return domain.ErrInsufficientMoney
+	}
+	if err = r.takeStock(ctx, tx, merch); err != nil {
+		return err
+	}
+	wallet.Balance -= merch.Price
+	if err = r.Wallets.Update(ctx, tx, wallet); err != nil {
+		return err
+	}
+	walletID := wallet.ID
+	made, err := r.Purchases.Create(ctx, tx, &domain.Purchase{
+		UserID:      purchase.ProposedBy,
+		RecipientID: purchase.ProposedBy,
+		MerchName:   merch.Name,
+		UnitPrice:   merch.Price,
+		Total:       merch.Price,
+		WalletID:    &walletID,
+	})
+	if err != nil {
+		return err
+	}
+	purchase.Price = merch.Price
+	purchase.PurchaseGUID = made.GUID
+	return r.closeWalletPurchase(ctx, tx, purchase, domain.WalletPurchaseCompleted)
+}
+
+func (r *Repository) closeWalletPurchase(ctx context.Context, tx *gorm.DB, purchase *domain.WalletPurchase, status string) error {
+	now := time.Now()
+	before := map[string]any{"id": purchase.ID, "status": purchase.Status}
+	purchase.Status = status
+	purchase.ResolvedAt = &now
+	if err := r.Wallets.UpdatePurchase(ctx, tx, purchase); err != nil {
+		return err
+	}
+	after := map[string]any{"id": purchase.ID, "status": purchase.Status, "approvals": purchase.Approvals}
+	if purchase.PurchaseGUID != "" {
+		after["price"] = purchase.Price
+		after["purchase"] = purchase.PurchaseGUID
+	}
+	return r.appendAudit(ctx, tx, domain.AuditWalletPurchase, walletSubject(purchase.WalletID), before, after)
+}
+
+// walletSubject is the audit subject of wallet changes.
+func walletSubject(id int64) string {
+	return fmt.Sprintf("wallet:%d", id)
+}
+
+func findMember(members []domain.WalletMember, username string) *domain.WalletMember {
+	for i := range members {
+		if members[i].Username == username {
+			return &members[i]
+		}
+	}
+	return nil
+}
+
+func countOwners(members []domain.WalletMember) int {
+	owners := 0
+	for _, member := range members {
+		if member.Role == domain.WalletRoleOwner {
+			owners++
+		}
+	}
+	return owners
+}
+
+// RegisterUser creates the user and grants its starting balance as a coin
+// lot, the audit entry is written in the same transaction.
+func (r *Repository) RegisterUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "repository.RegisterUser")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	user, err := r.Users.CreateUser(ctx, tx, user)
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	if user.Balance > 0 {
+		lot := domain.CoinLot{
+			Username:  user.Username,
+			Amount:    user.Balance,
+			Remaining: user.Balance,
+			GrantedAt: time.Now(),
+			Source:    domain.LotSourceRegistration,
+		}
+		if err = r.CoinLots.Grant(ctx, tx, []domain.CoinLot{lot}); err != nil {
+			tx.Rollback()
+			return nil, tracing.Error(span, err)
+		}
+	}
+	after := map[string]any{"balance": user.Balance, "role": user.Role}
+	if err = r.appendAudit(ctx, tx, domain.AuditRegistration, user.Username, nil, after); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return user, nil
+}
+
+// UpdateProfile changes the department, the manager and the active flag of
+// a user, nil values are left unchanged. An empty manager removes it.
+func (r *Repository) UpdateProfile(ctx context.Context, username string, department, manager *string, active *bool) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "repository.UpdateProfile")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	user, err := r.Users.LockUserByUsername(ctx, tx, username)
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	if user.Username == "" {
+		tx.Rollback()
+		return nil, domain.ErrNoSuchUser
+	}
+	if manager != nil && *manager != "" {
+		found, err := r.Users.GetUserByUsername(ctx, *manager)
+		if err != nil {
+			tx.Rollback()
+			return nil, tracing.Error(span, err)
+		}
+		if found.Username == "" {
+			tx.Rollback()
+			return nil, fmt.Errorf("%w: %s", domain.ErrNoSuchUser, *manager)
+		}
+	}
+
+	before := map[string]any{"department": user.Department, "manager": user.Manager, "active": user.Active}
+	if department != nil {
+		user.Department = *department
+	}
+	if manager != nil {
+		user.Manager = *manager
+	}
+	if active != nil {
+		user.Active = *active
+	}
+	if err = r.Users.UpdateUser(ctx, tx, user); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	after := map[string]any{"department": user.Department, "manager": user.Manager, "active": user.Active}
+	if err = r.appendAudit(ctx, tx, domain.AuditUserUpdate, user.Username, before, after); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return user, nil
+}
+
+// CreateMerch adds an item to the catalog and starts its price history.
+func (r *Repository) CreateMerch(ctx context.Context, merch *domain.Merch) (*domain.Merch, error) {
+	ctx, span := tracing.Start(ctx, "repository.CreateMerch")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	existing, err := r.Merch.Lock(ctx, tx, merch.Name)
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	if existing.Name != "" {
+		tx.Rollback()
+		return nil, domain.ErrMerchExists
+	}
+	if err = r.checkCategory(ctx, merch.Category); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if merch, err = r.Merch.Create(ctx, tx, merch); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	price := &domain.MerchPrice{MerchName: merch.Name, Price: merch.Price, EffectiveFrom: time.Now()}
+	if err = r.Merch.CreatePrice(ctx, tx, price); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	if err = r.appendAudit(ctx, tx, domain.AuditMerch, merch.Name, nil, merch); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return merch, nil
+}
+
+// UpdateMerch changes the price, the stock, the description, the category and
+// the tags of an item. A new price starts a new entry of the item's price history and
+// is audited as a price change, like the ones made by seeding.
+func (r *Repository) UpdateMerch(ctx context.Context, name string, update domain.MerchUpdate) (*domain.Merch, error) {
+	ctx, span := tracing.Start(ctx, "repository.UpdateMerch")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	merch, err := r.Merch.Lock(ctx, tx, name)
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	if merch.Name == "" {
+		tx.Rollback()
+		return nil, domain.ErrNoMerch
+	}
+	if update.Category != nil && *update.Category != "" {
+		if err = r.checkCategory(ctx, update.Category); err != nil {
+			tx.Rollback()
+			return nil, tracing.Error(span, err)
+		}
+	}
+
+	before := map[string]any{"stock": merch.Stock, "description": merch.Description, "category": merch.Category, "tags": merch.Tags}
+	previousPrice := merch.Price
+	if update.Price != nil {
+		merch.Price = *update.Price
+	}
+	if update.Stock != nil {
+		merch.Stock = update.Stock
+		if *update.Stock < 0 {
+			merch.Stock = nil
+		}
+	}
+	if update.Description != nil {
+		merch.Description = *update.Description
+	}
+	if update.Category != nil {
+		merch.Category = update.Category
+		if *update.Category == "" {
+			merch.Category = nil
+		}
+	}
+	if update.Tags != nil {
+		merch.Tags = *update.Tags
+	}
+	if err = r.Merch.Update(ctx, tx, merch); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if merch.Price != previousPrice {
+		price := &domain.MerchPrice{MerchName: merch.Name, Price: merch.Price, EffectiveFrom: time.Now()}
+		if err = r.Merch.CreatePrice(ctx, tx, price); err != nil {
+			tx.Rollback()
+			return nil, tracing.Error(span, err)
+		}
+		err = r.appendAudit(ctx, tx, domain.AuditPriceChange, merch.Name,
+			map[string]float64{"price": previousPrice}, map[string]float64{"price": merch.Price})
+		if err != nil {
+			tx.Rollback()
+			return nil, tracing.Error(span, err)
+		}
+	}
+	if update.Stock != nil || update.Description != nil || update.Category != nil || update.Tags != nil {
+		after := map[string]any{"stock": merch.Stock, "description": merch.Description, "category": merch.Category, "tags": merch.Tags}
+		if err = r.appendAudit(ctx, tx, domain.AuditMerch, merch.Name, before, after); err != nil {
+			tx.Rollback()
+			return nil, tracing.Error(span, err)
+		}
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return merch, nil
+}
+
+// SaveWishlistAlert records that the user was alerted about the wishlist
+// item, together with the notification of the alert, so that it is not sent
+// again. Without a notification the change is only marked as seen.
+func (r *Repository) SaveWishlistAlert(ctx context.Context, item *domain.WishlistItem, notification *domain.Notification) error {
+	ctx, span := tracing.Start(ctx, "repository.SaveWishlistAlert")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	if err := r.Wishlists.UpdateSeen(ctx, tx, item); err != nil {
+		tx.Rollback()
+		return tracing.Error(span, err)
+	}
+	if notification != nil {
+		if err := r.Notifications.Create(ctx, tx, notification); err != nil {
+			tx.Rollback()
+			return tracing.Error(span, err)
+		}
+	}
+
+	if err := r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+// checkCategory returns ErrNoCategory if the item is put in a category that
+// does not exist, no category is always fine.
+func (r *Repository) checkCategory(ctx context.Context, category *string) error {
+	if category == nil {
+		return nil
+	}
+	found, err := r.Categories.Get(ctx, *category)
+	if err != nil {
+		return err
+	}
+	if found.Name == "" {
+		return fmt.Errorf("%w: %s", domain.ErrNoCategory, *category)
+	}
+	return nil
+}
+
+// AdjustBalances applies the admin adjustments in a single transaction, either
+// all of them are stored or none. Debits cannot make a balance negative.
+func (r *Repository) AdjustBalances(ctx context.Context, adjustments []domain.BalanceAdjustment) ([]domain.BalanceAdjustment, error) {
+	ctx, span := tracing.Start(ctx, "repository.AdjustBalances")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	result, err := r.ApplyAdjustments(ctx, tx, adjustments)
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return result, nil
+}
+
+// ApplyAdjustments locks the users and applies the adjustments in tx, the
+// way AdjustBalances does, for callers that run their own transaction.
+func (r *Repository) ApplyAdjustments(ctx context.Context, tx *gorm.DB, adjustments []domain.BalanceAdjustment) ([]domain.BalanceAdjustment, error) {
+	usernames := make([]string, 0, len(adjustments))
+	for _, adjustment := range adjustments {
+		usernames = append(usernames, adjustment.Username)
+	}
+	users, err := r.lockUsers(ctx, tx, usernames...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, err
+	}
+	return r.applyAdjustments(ctx, tx, users, adjustments)
+}
+
+// PayAllowance credits the policy amount to every active user it targets.
+// The run is recorded in the same transaction, if the occurrence has already
+// been paid nothing is changed and ErrAllowanceAlreadyPaid is returned.
+func (r *Repository) PayAllowance(ctx context.Context, policy *domain.AllowancePolicy, scheduledFor time.Time, trigger string) (*domain.AllowanceRun, error) {
+	ctx, span := tracing.Start(ctx, "repository.PayAllowance")
+	defer span.End()
+
+	run := &domain.AllowanceRun{
+		PolicyID:     policy.ID,
+		ScheduledFor: scheduledFor,
+		Trigger:      trigger,
+		Actor:        actorFromContext(ctx),
+	}
+
+	ctx, tx := r.begin(ctx)
+	inserted, err := r.Allowances.CreateRun(ctx, tx, run)
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	if !inserted {
+		tx.Rollback()
+		return nil, domain.ErrAllowanceAlreadyPaid
+	}
+
+	recipients, err := r.Users.ListActiveUsers(ctx, tx, policy.Role, policy.Department)
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	users := make(map[string]*domain.User, len(recipients))
+	adjustments := make([]domain.BalanceAdjustment, 0, len(recipients))
+	for i := range recipients {
+		users[recipients[i].Username] = &recipients[i]
+		adjustments = append(adjustments, domain.BalanceAdjustment{
+			Username: recipients[i].Username,
+			Amount:   policy.Amount,
+			Reason:   "allowance: " + policy.Name,
+			BatchID:  run.GUID,
+		})
+	}
+	if _, err = r.applyAdjustments(ctx, tx, users, adjustments); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	run.UsersPaid = len(adjustments)
+	run.Total = policy.Amount * float64(len(adjustments))
+	if err = r.Allowances.UpdateRun(ctx, tx, run); err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return run, nil
+}
+
+// ExpireCoins removes the user's coins granted before cutoff and records the
+// removal as a debit with the given reason. It returns nil if nothing expired.
+func (r *Repository) ExpireCoins(ctx context.Context, username string, cutoff time.Time, reason string) (*domain.BalanceAdjustment, error) {
+	ctx, span := tracing.Start(ctx, "repository.ExpireCoins")
+	defer span.End()
+
+	ctx, tx := r.begin(ctx)
+	users, err := r.lockUsers(ctx, tx, username)
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	user := users[username]
+	if user == nil || user.Username == "" {
+		tx.Rollback()
+		return nil, domain.ErrNoSuchUser
+	}
+
+	expired, err := r.CoinLots.ExpiredAmount(ctx, tx, username, cutoff)
+	if err != nil {
+		tx.Rollback()
+		return nil, tracing.Error(span, err)
+	}
+	// The balance may be lower than the lots if it was changed outside of
+	// the repository, such lots are closed without a debit. Coins reserved
+	// by holds are left to a later run.
+	unbacked := expired - min(expired, user.Balance)
+	amount := min(expired-unbacked, max(user.Available(), 0))
+
+	var result *domain.BalanceAdjustment
+	if amount > 0 {
+		adjustments := []domain.BalanceAdjustment{{Username: username, Amount: -amount, Reason: reason}}
+		applied, err := r.applyAdjustments(ctx, tx, users, adjustments)
+		if err != nil {
+			tx.Rollback()
+			return nil, tracing.Error(span, err)
+		}
+		result = &applied[0]
+	}
+	if unbacked > 0 {
+		if _, err = r.CoinLots.Consume(ctx, tx, username, unbacked); err != nil {
+			tx.Rollback()
+			return nil, tracing.Error(span, err)
+		}
+	}
+
+	if err = r.commit(ctx, tx); err != nil {
+		logger.FromContext(ctx).Errorf(err.Error())
+		return nil, tracing.Error(span, err)
+	}
+	return result, nil
+}
+
+// applyAdjustments credits or debits the locked users in tx and records each
+// adjustment in the history and the audit log. The caller rolls tx back on error.
+func (r *Repository) applyAdjustments(ctx context.Context, tx *gorm.DB, users map[string]*domain.User, adjustments []domain.BalanceAdjustment) ([]domain.BalanceAdjustment, error) {
+	actor := actorFromContext(ctx)
+	result := make([]domain.BalanceAdjustment, 0, len(adjustments))
+	for _, adjustment := range adjustments {
+		user := users[adjustment.Username]
+		if user == nil || user.Username == "" {
+			return nil, fmt.Errorf("%w: %s", domain.ErrNoSuchUser, adjustment.Username)
+		}
+
+		before := map[string]float64{"balance": user.Balance}
+		if adjustment.Amount < 0 {
+			if err := debit(user, -adjustment.Amount); err != nil {
+				return nil, fmt.Errorf("%w: %s", err, adjustment.Username)
+			}
+			if _, err := r.CoinLots.Consume(ctx, tx, user.Username, -adjustment.Amount); err != nil {
+				return nil, err
+			}
+		} else {
+			credit(user, adjustment.Amount)
+			lot := domain.CoinLot{
+				Username:  user.Username,
+				Amount:    adjustment.Amount,
+				Remaining: adjustment.Amount,
+				GrantedAt: time.Now(),
+				Source:    domain.LotSourceAdjustment,
+			}
+			if err := r.CoinLots.Grant(ctx, tx, []domain.CoinLot{lot}); err != nil {
+				return nil, err
+			}
+		}
+
+		adjustment.Actor = actor
+		created, err := r.BalanceAdjustments.Create(ctx, tx, &adjustment)
+		if err != nil {
+			return nil, err
+		}
+		if err = r.Users.UpdateUser(ctx, tx, user); err != nil {
+			return nil, err
+		}
+
+		after := map[string]any{
+			"balance":    user.Balance,
+			"amount":     created.Amount,
+			"reason":     created.Reason,
+			"adjustment": created.GUID,
+		}
+		if err = r.appendAudit(ctx, tx, domain.AuditBalanceAdjustment, user.Username, before, after); err != nil {
+			return nil, err
+		}
+		result = append(result, *created)
+	}
+	return result, nil
+}
+
+// moveLots passes the sender's oldest coins to the receiver, keeping the
+// dates they were granted at.
+func (r *Repository) moveLots(ctx context.Context, tx *gorm.DB, sender, receiver string, amount float64) error {
+	lots, err := r.CoinLots.Consume(ctx, tx, sender, amount)
+	if err != nil {
+		return err
+	}
+	for i := range lots {
+		lots[i].Username = receiver
+		lots[i].Source = domain.LotSourceTransfer
+		amount -= lots[i].Amount
+	}
+	// Coins the sender had no lots for are dated at the transfer.
+	if amount > 0 {
+		lots = append(lots, domain.CoinLot{
+			Username:  receiver,
+			Amount:    amount,
+			Remaining: amount,
+			GrantedAt: time.Now(),
+			Source:    domain.LotSourceTransfer,
+		})
+	}
+	return r.CoinLots.Grant(ctx, tx, lots)
+}
+
+// actorFromContext names who made a change outside of a user's own request,
+// falling back to "system" when ctx carries no actor.
+func actorFromContext(ctx context.Context) string {
+	if actor := audit.ActorFromContext(ctx).Username; actor != "" {
+		return actor
+	}
+	return "system"
+}
+
+// debit takes amount from the user's available balance, coins reserved by
+// holds cannot be spent. Every operation spending coins goes through it.
+func debit(user *domain.User, amount float64) error {
+	if user.Available() < amount {
+		return domain.ErrInsufficientMoney
+	}
+	user.Balance -= amount
+	return nil
+}
+
+func credit(user *domain.User, amount float64) {
+	user.Balance += amount
+}
+
+// lockUsers locks the users' rows in a stable order, so that concurrent
+// transfers between the same users in opposite directions cannot deadlock.
+func (r *Repository) lockUsers(ctx context.Context, tx *gorm.DB, usernames ...string) (map[string]*domain.User, error) {
+	sorted := append([]string(nil), usernames...)
+	sort.Strings(sorted)
+
+	users := make(map[string]*domain.User, len(sorted))
+	for _, username := range sorted {
+		if _, ok := users[username]; ok {
+			continue
+		}
+		user, err := r.Users.LockUserByUsername(ctx, tx, username)
+		if err != nil {
+			return nil, err
+		}
+		users[username] = user
+	}
+	return users, nil
+}
+
+// auditBuffer holds the audit entries of a transaction until it commits.
+// Appending an entry locks the whole audit log until the transaction ends,
+// so the entries are appended last to keep other transactions waiting for as
+// short as possible.
+type auditBuffer struct {
+	entries []*domain.AuditEntry
+}
+
+type auditBufferKey struct{}
+
+// begin starts a transaction whose audit entries are appended by commit.
+// The returned ctx must be used for the rest of the transaction.
+func (r *Repository) begin(ctx context.Context) (context.Context, *gorm.DB) {
+	ctx = context.WithValue(ctx, auditBufferKey{}, &auditBuffer{})
+	return ctx, r.DB.WithContext(ctx).Begin()
+}
+
+// commit appends the audit entries of tx and commits it. tx is rolled back
+// if an entry cannot be appended.
+func (r *Repository) commit(ctx context.Context, tx *gorm.DB) error {
+	if buffer, ok := ctx.Value(auditBufferKey{}).(*auditBuffer); ok {
+		for _, entry := range buffer.entries {
+			if err := r.Audit.Append(ctx, tx, entry); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		buffer.entries = nil
+	}
+	return tx.Commit().Error
+}
+
+// appendAudit adds an entry to the audit log in tx, it is written when tx
+// commits if tx was started by begin.
+func (r *Repository) appendAudit(ctx context.Context, tx *gorm.DB, action, subject string, before, after any) error {
+	entry, err := audit.NewEntry(ctx, action, subject, before, after)
+	if err != nil {
+		return err
+	}
+	if buffer, ok := ctx.Value(auditBufferKey{}).(*auditBuffer); ok {
+		buffer.entries = append(buffer.entries, entry)
+		return nil
+	}
+	return r.Audit.Append(ctx, tx, entry)
 }
@@ -1,9 +1,15 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"shop/domain"
+	"shop/pkg/audit"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -12,57 +18,531 @@ import (
 )
 
 type (
-	MockUsers        struct{ mock.Mock }
-	MockMerch        struct{ mock.Mock }
-	MockPurchases    struct{ mock.Mock }
-	MockTransactions struct{ mock.Mock }
+	MockUsers              struct{ mock.Mock }
+	MockMerch              struct{ mock.Mock }
+	MockPurchases          struct{ mock.Mock }
+	MockTransactions       struct{ mock.Mock }
+	MockAudit              struct{ mock.Mock }
+	MockAdjustments        struct{ mock.Mock }
+	MockAllowances         struct{ mock.Mock }
+	MockCoinLots           struct{ mock.Mock }
+	MockCoinRequests       struct{ mock.Mock }
+	MockNotifications      struct{ mock.Mock }
+	MockScheduledTransfers struct{ mock.Mock }
+	MockHolds              struct{ mock.Mock }
+	MockWallets            struct{ mock.Mock }
+	MockPurchaseApprovals  struct{ mock.Mock }
+	MockPromoCodes         struct{ mock.Mock }
+	MockCategories         struct{ mock.Mock }
+	MockWishlists          struct{ mock.Mock }
 )
 
-func (m *MockUsers) GetUserByUsername(username string) (*domain.User, error) {
+func (m *MockUsers) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
 	args := m.Called(username)
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
-func (m *MockUsers) UpdateUser(tx *gorm.DB, user *domain.User) error {
+func (m *MockUsers) LockUserByUsername(ctx context.Context, tx *gorm.DB, username string) (*domain.User, error) {
+	args := m.Called(tx, username)
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUsers) UpdateUser(ctx context.Context, tx *gorm.DB, user *domain.User) error {
 	args := m.Called(tx, user)
 	return args.Error(0)
 }
 
-func (m *MockUsers) CreateUser(user *domain.User) (*domain.User, error) {
-	args := m.Called(user)
+func (m *MockUsers) CreateUser(ctx context.Context, tx *gorm.DB, user *domain.User) (*domain.User, error) {
+	args := m.Called(tx, user)
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
-func (m *MockMerch) GetMerchByName(name string) (*domain.Merch, error) {
+func (m *MockUsers) ListActiveUsers(ctx context.Context, tx *gorm.DB, role, department string) ([]domain.User, error) {
+	args := m.Called(tx, role, department)
+	return args.Get(0).([]domain.User), args.Error(1)
+}
+
+func (m *MockMerch) GetMerchByName(ctx context.Context, name string) (*domain.Merch, error) {
+	args := m.Called(name)
+	return args.Get(0).(*domain.Merch), args.Error(1)
+}
+
+func (m *MockMerch) ListPrices(ctx context.Context, name string) ([]domain.MerchPrice, error) {
 	args := m.Called(name)
+	return args.Get(0).([]domain.MerchPrice), args.Error(1)
+}
+
+func (m *MockMerch) List(ctx context.Context, filter domain.MerchFilter) ([]domain.Merch, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.Merch), args.Error(1)
+}
+
+func (m *MockMerch) Lock(ctx context.Context, tx *gorm.DB, name string) (*domain.Merch, error) {
+	args := m.Called(tx, name)
 	return args.Get(0).(*domain.Merch), args.Error(1)
 }
 
-func (m *MockPurchases) Create(tx *gorm.DB, purchase *domain.Purchase) (*domain.Purchase, error) {
+func (m *MockMerch) Create(ctx context.Context, tx *gorm.DB, merch *domain.Merch) (*domain.Merch, error) {
+	args := m.Called(tx, merch)
+	return args.Get(0).(*domain.Merch), args.Error(1)
+}
+
+func (m *MockMerch) Update(ctx context.Context, tx *gorm.DB, merch *domain.Merch) error {
+	args := m.Called(tx, merch)
+	return args.Error(0)
+}
+
+func (m *MockMerch) TakeStock(ctx context.Context, tx *gorm.DB, name string) (bool, error) {
+	args := m.Called(tx, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMerch) CreatePrice(ctx context.Context, tx *gorm.DB, price *domain.MerchPrice) error {
+	args := m.Called(tx, price)
+	return args.Error(0)
+}
+
+func (m *MockMerch) CountByCategory(ctx context.Context, category string) (int64, error) {
+	args := m.Called(category)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMerch) CreateImage(ctx context.Context, image *domain.MerchImage) (*domain.MerchImage, error) {
+	args := m.Called(image)
+	return args.Get(0).(*domain.MerchImage), args.Error(1)
+}
+
+func (m *MockMerch) GetImage(ctx context.Context, name string, id int64) (*domain.MerchImage, error) {
+	args := m.Called(name, id)
+	return args.Get(0).(*domain.MerchImage), args.Error(1)
+}
+
+func (m *MockMerch) DeleteImage(ctx context.Context, id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockCategories) Create(ctx context.Context, category *domain.Category) (*domain.Category, error) {
+	args := m.Called(category)
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
+func (m *MockCategories) Get(ctx context.Context, name string) (*domain.Category, error) {
+	args := m.Called(name)
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
+func (m *MockCategories) List(ctx context.Context) ([]domain.Category, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Category), args.Error(1)
+}
+
+func (m *MockCategories) Update(ctx context.Context, category *domain.Category) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockCategories) Delete(ctx context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockPurchases) Create(ctx context.Context, tx *gorm.DB, purchase *domain.Purchase) (*domain.Purchase, error) {
 	args := m.Called(tx, purchase)
 	return args.Get(0).(*domain.Purchase), args.Error(1)
 }
 
-func (m *MockPurchases) GetPurchasesForUserByUsername(username string) ([]domain.Purchase, error) {
+func (m *MockPurchases) GetInventory(ctx context.Context, username string) ([]domain.InventoryItem, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.InventoryItem), args.Error(1)
+}
+
+func (m *MockPurchases) GetPurchasesForUserByUsername(ctx context.Context, username string) ([]domain.Purchase, error) {
 	args := m.Called(username)
 	return args.Get(0).([]domain.Purchase), args.Error(1)
 }
 
-func (m *MockTransactions) Create(tx *gorm.DB, transaction *domain.Transaction) (*domain.Transaction, error) {
+func (m *MockTransactions) Create(ctx context.Context, tx *gorm.DB, transaction *domain.Transaction) (*domain.Transaction, error) {
 	args := m.Called(tx, transaction)
-	return args.Get(0).(*domain.Transaction), args.Error(1)
+	if created, ok := args.Get(0).(*domain.Transaction); ok && created != nil {
+		return created, args.Error(1)
+	}
+	return transaction, args.Error(1)
 }
 
-func (m *MockTransactions) GetTransactionsForUserByUsername(username string) ([]domain.Transaction, error) {
+func (m *MockTransactions) GetTransactionsForUserByUsername(ctx context.Context, username string) ([]domain.Transaction, error) {
 	args := m.Called(username)
 	return args.Get(0).([]domain.Transaction), args.Error(1)
 }
 
+func (m *MockTransactions) SentSince(ctx context.Context, tx *gorm.DB, sender string, since time.Time) (*domain.TransferStats, error) {
+	args := m.Called(tx, sender, since)
+	return args.Get(0).(*domain.TransferStats), args.Error(1)
+}
+
+func (m *MockTransactions) ListFeed(ctx context.Context, filter domain.FeedFilter) ([]domain.Transaction, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockAudit) Append(ctx context.Context, tx *gorm.DB, entry *domain.AuditEntry) error {
+	args := m.Called(tx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAudit) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.AuditEntry), args.Error(1)
+}
+
+func (m *MockAdjustments) Create(ctx context.Context, tx *gorm.DB, adjustment *domain.BalanceAdjustment) (*domain.BalanceAdjustment, error) {
+	args := m.Called(tx, adjustment)
+	return adjustment, args.Error(0)
+}
+
+func (m *MockAdjustments) GetAdjustmentsForUserByUsername(ctx context.Context, username string) ([]domain.BalanceAdjustment, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.BalanceAdjustment), args.Error(1)
+}
+
+func (m *MockAllowances) CreatePolicy(ctx context.Context, policy *domain.AllowancePolicy) (*domain.AllowancePolicy, error) {
+	args := m.Called(policy)
+	return policy, args.Error(0)
+}
+
+func (m *MockAllowances) GetPolicy(ctx context.Context, id int64) (*domain.AllowancePolicy, error) {
+	args := m.Called(id)
+	return args.Get(0).(*domain.AllowancePolicy), args.Error(1)
+}
+
+func (m *MockAllowances) ListPolicies(ctx context.Context) ([]domain.AllowancePolicy, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.AllowancePolicy), args.Error(1)
+}
+
+func (m *MockAllowances) SetPaused(ctx context.Context, id int64, paused bool) error {
+	args := m.Called(id, paused)
+	return args.Error(0)
+}
+
+func (m *MockAllowances) LastScheduledRun(ctx context.Context, id int64) (*domain.AllowanceRun, error) {
+	args := m.Called(id)
+	return args.Get(0).(*domain.AllowanceRun), args.Error(1)
+}
+
+func (m *MockAllowances) ListRuns(ctx context.Context, id int64) ([]domain.AllowanceRun, error) {
+	args := m.Called(id)
+	return args.Get(0).([]domain.AllowanceRun), args.Error(1)
+}
+
+func (m *MockAllowances) CreateRun(ctx context.Context, tx *gorm.DB, run *domain.AllowanceRun) (bool, error) {
+	args := m.Called(tx, run)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAllowances) UpdateRun(ctx context.Context, tx *gorm.DB, run *domain.AllowanceRun) error {
+	args := m.Called(tx, run)
+	return args.Error(0)
+}
+
+func (m *MockCoinLots) Grant(ctx context.Context, tx *gorm.DB, lots []domain.CoinLot) error {
+	args := m.Called(tx, lots)
+	return args.Error(0)
+}
+
+func (m *MockCoinLots) Consume(ctx context.Context, tx *gorm.DB, username string, amount float64) ([]domain.CoinLot, error) {
+	args := m.Called(tx, username, amount)
+	lots, _ := args.Get(0).([]domain.CoinLot)
+	return lots, args.Error(1)
+}
+
+func (m *MockCoinLots) ListOpenLots(ctx context.Context, username string) ([]domain.CoinLot, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.CoinLot), args.Error(1)
+}
+
+func (m *MockCoinLots) ListExpiredOwners(ctx context.Context, cutoff time.Time) ([]string, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockCoinLots) ExpiredAmount(ctx context.Context, tx *gorm.DB, username string, cutoff time.Time) (float64, error) {
+	args := m.Called(tx, username, cutoff)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockCoinRequests) Create(ctx context.Context, request *domain.CoinRequest) (*domain.CoinRequest, error) {
+	args := m.Called(request)
+	return request, args.Error(0)
+}
+
+func (m *MockCoinRequests) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.CoinRequest, error) {
+	args := m.Called(tx, id)
+	return args.Get(0).(*domain.CoinRequest), args.Error(1)
+}
+
+func (m *MockCoinRequests) Update(ctx context.Context, tx *gorm.DB, request *domain.CoinRequest) error {
+	args := m.Called(tx, request)
+	return args.Error(0)
+}
+
+func (m *MockCoinRequests) ListPending(ctx context.Context, username string, now time.Time) ([]domain.CoinRequest, error) {
+	args := m.Called(username, now)
+	return args.Get(0).([]domain.CoinRequest), args.Error(1)
+}
+
+func (m *MockCoinRequests) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockScheduledTransfers) Create(ctx context.Context, transfer *domain.ScheduledTransfer) (*domain.ScheduledTransfer, error) {
+	args := m.Called(transfer)
+	return transfer, args.Error(0)
+}
+
+func (m *MockScheduledTransfers) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.ScheduledTransfer, error) {
+	args := m.Called(tx, id)
+	return args.Get(0).(*domain.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransfers) Update(ctx context.Context, tx *gorm.DB, transfer *domain.ScheduledTransfer) error {
+	args := m.Called(tx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockScheduledTransfers) ListBySender(ctx context.Context, username string) ([]domain.ScheduledTransfer, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransfers) ListDue(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockHolds) Create(ctx context.Context, tx *gorm.DB, hold *domain.Hold) (*domain.Hold, error) {
+	args := m.Called(tx, hold)
+	return hold, args.Error(0)
+}
+
+func (m *MockHolds) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.Hold, error) {
+	args := m.Called(tx, id)
+	return args.Get(0).(*domain.Hold), args.Error(1)
+}
+
+func (m *MockHolds) Update(ctx context.Context, tx *gorm.DB, hold *domain.Hold) error {
+	args := m.Called(tx, hold)
+	return args.Error(0)
+}
+
+func (m *MockHolds) ListActive(ctx context.Context, username string) ([]domain.Hold, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.Hold), args.Error(1)
+}
+
+func (m *MockHolds) ListExpired(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockNotifications) Create(ctx context.Context, tx *gorm.DB, notification *domain.Notification) error {
+	args := m.Called(tx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotifications) List(ctx context.Context, username string, limit int) ([]domain.Notification, error) {
+	args := m.Called(username, limit)
+	return args.Get(0).([]domain.Notification), args.Error(1)
+}
+
+func (m *MockNotifications) MarkRead(ctx context.Context, username string, now time.Time) (int64, error) {
+	args := m.Called(username, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWallets) Create(ctx context.Context, tx *gorm.DB, wallet *domain.Wallet) (*domain.Wallet, error) {
+	args := m.Called(tx, wallet)
+	return wallet, args.Error(0)
+}
+
+func (m *MockWallets) Get(ctx context.Context, id int64) (*domain.Wallet, error) {
+	args := m.Called(id)
+	return args.Get(0).(*domain.Wallet), args.Error(1)
+}
+
+func (m *MockWallets) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.Wallet, error) {
+	args := m.Called(tx, id)
+	return args.Get(0).(*domain.Wallet), args.Error(1)
+}
+
+func (m *MockWallets) Update(ctx context.Context, tx *gorm.DB, wallet *domain.Wallet) error {
+	args := m.Called(tx, wallet)
+	return args.Error(0)
+}
+
+func (m *MockWallets) ListForUser(ctx context.Context, username string) ([]domain.Wallet, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.Wallet), args.Error(1)
+}
+
+func (m *MockWallets) SaveMember(ctx context.Context, tx *gorm.DB, member *domain.WalletMember) error {
+	args := m.Called(tx, member)
+	return args.Error(0)
+}
+
+func (m *MockWallets) RemoveMember(ctx context.Context, tx *gorm.DB, walletID int64, username string) error {
+	args := m.Called(tx, walletID, username)
+	return args.Error(0)
+}
+
+func (m *MockWallets) ListMembers(ctx context.Context, tx *gorm.DB, walletID int64) ([]domain.WalletMember, error) {
+	args := m.Called(tx, walletID)
+	return args.Get(0).([]domain.WalletMember), args.Error(1)
+}
+
+func (m *MockWallets) CreateContribution(ctx context.Context, tx *gorm.DB, contribution *domain.WalletContribution) (*domain.WalletContribution, error) {
+	args := m.Called(tx, contribution)
+	return contribution, args.Error(0)
+}
+
+func (m *MockWallets) ListContributions(ctx context.Context, walletID int64) ([]domain.WalletContribution, error) {
+	args := m.Called(walletID)
+	return args.Get(0).([]domain.WalletContribution), args.Error(1)
+}
+
+func (m *MockWallets) CreatePurchase(ctx context.Context, tx *gorm.DB, purchase *domain.WalletPurchase) (*domain.WalletPurchase, error) {
+	args := m.Called(tx, purchase)
+	return purchase, args.Error(0)
+}
+
+func (m *MockWallets) LockPurchase(ctx context.Context, tx *gorm.DB, walletID, id int64) (*domain.WalletPurchase, error) {
+	args := m.Called(tx, walletID, id)
+	return args.Get(0).(*domain.WalletPurchase), args.Error(1)
+}
+
+func (m *MockWallets) UpdatePurchase(ctx context.Context, tx *gorm.DB, purchase *domain.WalletPurchase) error {
+	args := m.Called(tx, purchase)
+	return args.Error(0)
+}
+
+func (m *MockWallets) AddApproval(ctx context.Context, tx *gorm.DB, approval *domain.WalletApproval) error {
+	args := m.Called(tx, approval)
+	return args.Error(0)
+}
+
+func (m *MockWallets) ListPurchases(ctx context.Context, walletID int64) ([]domain.WalletPurchase, error) {
+	args := m.Called(walletID)
+	return args.Get(0).([]domain.WalletPurchase), args.Error(1)
+}
+
+func (m *MockPurchaseApprovals) Create(ctx context.Context, tx *gorm.DB, approval *domain.PurchaseApproval) (*domain.PurchaseApproval, error) {
+	args := m.Called(tx, approval)
+	return approval, args.Error(0)
+}
+
+func (m *MockPurchaseApprovals) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.PurchaseApproval, error) {
+	args := m.Called(tx, id)
+	return args.Get(0).(*domain.PurchaseApproval), args.Error(1)
+}
+
+func (m *MockPurchaseApprovals) Update(ctx context.Context, tx *gorm.DB, approval *domain.PurchaseApproval) error {
+	args := m.Called(tx, approval)
+	return args.Error(0)
+}
+
+func (m *MockPurchaseApprovals) ListAssigned(ctx context.Context, username string, admin bool) ([]domain.PurchaseApproval, error) {
+	args := m.Called(username, admin)
+	return args.Get(0).([]domain.PurchaseApproval), args.Error(1)
+}
+
+func (m *MockPurchaseApprovals) ListRequested(ctx context.Context, username string) ([]domain.PurchaseApproval, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.PurchaseApproval), args.Error(1)
+}
+
+func (m *MockPurchaseApprovals) ListDue(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockPromoCodes) Create(ctx context.Context, code *domain.PromoCode) (*domain.PromoCode, error) {
+	args := m.Called(code)
+	return code, args.Error(0)
+}
+
+func (m *MockPromoCodes) Get(ctx context.Context, code string) (*domain.PromoCode, error) {
+	args := m.Called(code)
+	return args.Get(0).(*domain.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodes) List(ctx context.Context) ([]domain.PromoCode, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodes) SetActive(ctx context.Context, code string, active bool) error {
+	args := m.Called(code, active)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodes) Lock(ctx context.Context, tx *gorm.DB, codes []string) ([]domain.PromoCode, error) {
+	args := m.Called(tx, codes)
+	return args.Get(0).([]domain.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodes) CountRedemptions(ctx context.Context, tx *gorm.DB, code, username string) (int, int, error) {
+	args := m.Called(tx, code, username)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockPromoCodes) CreateRedemptions(ctx context.Context, tx *gorm.DB, redemptions []domain.PromoRedemption) error {
+	args := m.Called(tx, redemptions)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodes) CompleteRedemptions(ctx context.Context, tx *gorm.DB, approvalID int64, purchaseGUID string) error {
+	args := m.Called(tx, approvalID, purchaseGUID)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodes) ReleaseRedemptions(ctx context.Context, tx *gorm.DB, approvalID int64) error {
+	args := m.Called(tx, approvalID)
+	return args.Error(0)
+}
+
+func (m *MockWishlists) Save(ctx context.Context, item *domain.WishlistItem) error {
+	args := m.Called(item)
+	return args.Error(0)
+}
+
+func (m *MockWishlists) Delete(ctx context.Context, username, merchName string) (bool, error) {
+	args := m.Called(username, merchName)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWishlists) List(ctx context.Context, username string) ([]domain.WishlistItem, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlists) ListChanged(ctx context.Context, limit int) ([]domain.WishlistItem, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]domain.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlists) UpdateSeen(ctx context.Context, tx *gorm.DB, item *domain.WishlistItem) error {
+	args := m.Called(tx, item)
+	return args.Error(0)
+}
+
 func TestCreatePurchase(t *testing.T) {
 	mockUsers := new(MockUsers)
 	mockMerch := new(MockMerch)
 	mockPurchases := new(MockPurchases)
 	mockTransactions := new(MockTransactions)
+	mockCoinLots := new(MockCoinLots)
+	mockAudit := new(MockAudit)
 	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	repo := &Repository{
 		DB:           mockDB,
@@ -70,34 +550,94 @@ func TestCreatePurchase(t *testing.T) {
 		Merch:        mockMerch,
 		Purchases:    mockPurchases,
 		Transactions: mockTransactions,
+		CoinLots:     mockCoinLots,
+		Audit:        mockAudit,
 	}
 
 	user := &domain.User{Username: "user", Balance: 10000}
 	merch := &domain.Merch{Name: "cup", Price: 20}
 	purchase := &domain.Purchase{UserID: user.Username, MerchName: merch.Name}
 
-	mockUsers.On("GetUserByUsername", "user").Return(user, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user").Return(user, nil)
 	mockMerch.On("GetMerchByName", "cup").Return(merch, nil)
 	mockPurchases.On("Create", mock.Anything, mock.Anything).Return(purchase, nil)
 	mockUsers.On("UpdateUser", mock.Anything, mock.Anything).Return(nil)
+	mockCoinLots.On("Consume", mock.Anything, "user", 20.0).Return(nil, nil).Once()
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditPurchase && entry.Actor == "user" &&
+			entry.Before == `{"balance":10000}` && entry.After == `{"balance":9980,"item":"cup","price":20}`
+	})).Return(nil).Once()
 
-	result, err := repo.CreatePurchase("user", "cup")
+	result, _, err := repo.CreatePurchase(context.Background(), "user", "cup", domain.Gift{}, nil, domain.PurchasePolicy{})
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, purchase, result)
+	mockAudit.AssertExpectations(t)
+	mockCoinLots.AssertExpectations(t)
 
 	user.Balance = 10
-	result, err = repo.CreatePurchase("user", "cup")
+	result, _, err = repo.CreatePurchase(context.Background(), "user", "cup", domain.Gift{}, nil, domain.PurchasePolicy{})
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Equal(t, "insufficient money", err.Error())
 }
 
+func TestCreatePurchase_Gift(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockMerch := new(MockMerch)
+	mockPurchases := new(MockPurchases)
+	mockCoinLots := new(MockCoinLots)
+	mockNotifications := new(MockNotifications)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{
+		DB:            mockDB,
+		Users:         mockUsers,
+		Merch:         mockMerch,
+		Purchases:     mockPurchases,
+		CoinLots:      mockCoinLots,
+		Notifications: mockNotifications,
+		Audit:         mockAudit,
+	}
+
+	buyer := &domain.User{Username: "user1", Balance: 100}
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(buyer, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user2").Return(&domain.User{Username: "user2", Balance: 5}, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "ghost").Return(&domain.User{}, nil)
+	mockMerch.On("GetMerchByName", "cup").Return(&domain.Merch{Name: "cup", Price: 20}, nil)
+	mockPurchases.On("Create", mock.Anything, mock.MatchedBy(func(purchase *domain.Purchase) bool {
+		return purchase.UserID == "user1" && purchase.RecipientID == "user2" && purchase.GiftMessage == "Happy birthday"
+	})).Return(&domain.Purchase{GUID: "p1", UserID: "user1", RecipientID: "user2", MerchName: "cup", GiftMessage: "Happy birthday"}, nil)
+	mockUsers.On("UpdateUser", mock.Anything, mock.Anything).Return(nil)
+	mockCoinLots.On("Consume", mock.Anything, "user1", 20.0).Return(nil, nil)
+	mockNotifications.On("Create", mock.Anything, &domain.Notification{
+		Username:  "user2",
+		Kind:      domain.NotificationGift,
+		Message:   "user1 sent you a gift: cup\nHappy birthday",
+		Reference: "p1",
+	}).Return(nil).Once()
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Subject == "user1" && strings.Contains(entry.After, `"recipient":"user2"`)
+	})).Return(nil).Once()
+
+	_, _, err := repo.CreatePurchase(context.Background(), "user1", "cup", domain.Gift{Recipient: "ghost"}, nil, domain.PurchasePolicy{})
+	assert.ErrorIs(t, err, domain.ErrNoSuchUser)
+
+	purchase, _, err := repo.CreatePurchase(context.Background(), "user1", "cup", domain.Gift{Recipient: "user2", Message: "Happy birthday"}, nil, domain.PurchasePolicy{})
+	assert.NoError(t, err)
+	assert.Equal(t, "user2", purchase.RecipientID)
+	assert.Equal(t, 80.0, buyer.Balance)
+	mockNotifications.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
 func TestCreateTransaction(t *testing.T) {
 	mockUsers := new(MockUsers)
 	mockMerch := new(MockMerch)
 	mockPurchases := new(MockPurchases)
 	mockTransactions := new(MockTransactions)
+	mockCoinLots := new(MockCoinLots)
+	mockAudit := new(MockAudit)
 	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	repo := &Repository{
 		DB:           mockDB,
@@ -105,25 +645,980 @@ func TestCreateTransaction(t *testing.T) {
 		Merch:        mockMerch,
 		Purchases:    mockPurchases,
 		Transactions: mockTransactions,
+		CoinLots:     mockCoinLots,
+		Audit:        mockAudit,
 	}
 
 	sender := &domain.User{Username: "user1", Balance: 1000}
 	receiver := &domain.User{Username: "user2", Balance: 1000}
 	transaction := &domain.Transaction{SenderUsername: sender.Username, ReceiverUsername: receiver.Username, MoneyAmount: 20}
 
-	mockUsers.On("GetUserByUsername", "user1").Return(sender, nil)
-	mockUsers.On("GetUserByUsername", "user2").Return(receiver, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(sender, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user2").Return(receiver, nil)
+	mockTransactions.On("SentSince", mock.Anything, "user1", mock.Anything).Return(&domain.TransferStats{}, nil)
 	mockTransactions.On("Create", mock.Anything, mock.Anything).Return(transaction, nil)
 	mockUsers.On("UpdateUser", mock.Anything, mock.Anything).Return(nil)
+	granted := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockCoinLots.On("Consume", mock.Anything, "user1", 30.0).
+		Return([]domain.CoinLot{{Username: "user1", Amount: 25, Remaining: 25, GrantedAt: granted}}, nil).Once()
+	mockCoinLots.On("Grant", mock.Anything, mock.MatchedBy(func(lots []domain.CoinLot) bool {
+		return len(lots) == 2 && lots[0].Username == "user2" && lots[0].GrantedAt.Equal(granted) &&
+			lots[0].Source == domain.LotSourceTransfer && lots[1].Amount == 5
+	})).Return(nil).Once()
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditTransfer && entry.Subject == "user1" &&
+			entry.Before == `{"receiver_balance":1000,"sender_balance":1000}`
+	})).Return(nil).Once()
 
-	result, err := repo.CreateTransaction("user2", "user1", 30)
+	result, err := repo.CreateTransaction(context.Background(), "user2", "user1", 30, domain.TransferNote{}, domain.TransferPolicy{})
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, transaction, result)
+	mockAudit.AssertExpectations(t)
+	mockCoinLots.AssertExpectations(t)
 
 	sender.Balance = 10
-	result, err = repo.CreateTransaction("user2", "user1", 20)
+	result, err = repo.CreateTransaction(context.Background(), "user2", "user1", 20, domain.TransferNote{}, domain.TransferPolicy{})
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Equal(t, "insufficient money", err.Error())
 }
+
+func TestCreateTransaction_Limits(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockTransactions := new(MockTransactions)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Users: mockUsers, Transactions: mockTransactions}
+
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(&domain.User{Username: "user1", Role: domain.RoleUser, Balance: 1000}, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user2").Return(&domain.User{Username: "user2", Balance: 1000}, nil)
+	mockTransactions.On("SentSince", mock.Anything, "user1", mock.MatchedBy(func(since time.Time) bool {
+		return since.Equal(since.Truncate(24 * time.Hour))
+	})).Return(&domain.TransferStats{Amount: 80, Count: 2, Recipients: []string{"user3"}}, nil)
+
+	policy := domain.TransferPolicy{
+		Default: domain.TransferLimits{DailyAmount: 100},
+		Roles:   map[string]domain.TransferLimits{domain.RoleAdmin: {}},
+	}
+	_, err := repo.CreateTransaction(context.Background(), "user2", "user1", 30, domain.TransferNote{}, policy)
+	var limitErr *domain.TransferLimitError
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.Equal(t, domain.LimitDailyAmount, limitErr.Code)
+		assert.Equal(t, 20.0, limitErr.Remaining)
+	}
+	assert.ErrorIs(t, err, domain.ErrTransferLimit)
+	mockTransactions.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateTransactions(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockTransactions := new(MockTransactions)
+	mockCoinLots := new(MockCoinLots)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Users: mockUsers, Transactions: mockTransactions, CoinLots: mockCoinLots, Audit: mockAudit}
+
+	sender := &domain.User{Username: "lead", Balance: 100}
+	mockUsers.On("LockUserByUsername", mock.Anything, "lead").Return(sender, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(&domain.User{Username: "user1"}, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user2").Return(&domain.User{Username: "user2"}, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "ghost").Return(&domain.User{}, nil)
+	mockTransactions.On("SentSince", mock.Anything, "lead", mock.Anything).Return(&domain.TransferStats{}, nil)
+	mockTransactions.On("Create", mock.Anything, mock.Anything).Return(nil, nil)
+	mockUsers.On("UpdateUser", mock.Anything, mock.Anything).Return(nil)
+	mockCoinLots.On("Consume", mock.Anything, "lead", mock.Anything).Return([]domain.CoinLot{}, nil)
+	mockCoinLots.On("Grant", mock.Anything, mock.Anything).Return(nil)
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditTransfer && strings.Contains(entry.After, `"batch":"b1"`)
+	})).Return(nil)
+
+	transfers := func(amounts ...float64) []domain.Transaction {
+		var result []domain.Transaction
+		for i, amount := range amounts {
+			result = append(result, domain.Transaction{ReceiverUsername: fmt.Sprintf("user%d", i+1), MoneyAmount: amount, BatchID: "b1"})
+		}
+		return result
+	}
+
+	_, err := repo.CreateTransactions(context.Background(), "lead", transfers(60, 50), domain.TransferPolicy{})
+	assert.ErrorIs(t, err, domain.ErrInsufficientMoney)
+	mockTransactions.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	_, err = repo.CreateTransactions(context.Background(), "lead", append(transfers(10), domain.Transaction{ReceiverUsername: "ghost", MoneyAmount: 10}), domain.TransferPolicy{})
+	assert.ErrorIs(t, err, domain.ErrNoSuchUser)
+	assert.EqualError(t, err, "no such user: ghost")
+
+	// Every transfer of a batch counts towards the daily limits.
+	policy := domain.TransferPolicy{Default: domain.TransferLimits{DailyCount: 1}}
+	_, err = repo.CreateTransactions(context.Background(), "lead", transfers(10, 10), policy)
+	var limitErr *domain.TransferLimitError
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.Equal(t, domain.LimitDailyCount, limitErr.Code)
+	}
+	mockUsers.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+
+	sender.Balance = 100
+	result, err := repo.CreateTransactions(context.Background(), "lead", transfers(60, 40), domain.TransferPolicy{})
+	assert.NoError(t, err)
+	if assert.Len(t, result, 2) {
+		assert.Equal(t, "user2", result[1].ReceiverUsername)
+		assert.Equal(t, "b1", result[1].BatchID)
+	}
+	assert.Equal(t, 0.0, sender.Balance)
+	mockUsers.AssertNumberOfCalls(t, "UpdateUser", 3)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestAcceptCoinRequest(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockTransactions := new(MockTransactions)
+	mockCoinLots := new(MockCoinLots)
+	mockRequests := new(MockCoinRequests)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Users: mockUsers, Transactions: mockTransactions, CoinLots: mockCoinLots, CoinRequests: mockRequests, Audit: mockAudit}
+
+	pending := func(id int64, expiresAt time.Time) *domain.CoinRequest {
+		return &domain.CoinRequest{ID: id, Requester: "user1", Payer: "user2", Amount: 30, Message: "lunch", Status: domain.RequestPending, ExpiresAt: expiresAt}
+	}
+	tomorrow := time.Now().Add(24 * time.Hour)
+	mockRequests.On("Lock", mock.Anything, int64(1)).Return(pending(1, tomorrow), nil)
+	mockRequests.On("Lock", mock.Anything, int64(2)).Return(&domain.CoinRequest{ID: 2, Payer: "user2", Status: domain.RequestDeclined}, nil)
+	mockRequests.On("Lock", mock.Anything, int64(3)).Return(pending(3, time.Now().Add(-time.Minute)), nil)
+	mockRequests.On("Lock", mock.Anything, int64(4)).Return(&domain.CoinRequest{}, nil)
+	mockRequests.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(&domain.User{Username: "user1", Balance: 0}, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user2").Return(&domain.User{Username: "user2", Balance: 100}, nil)
+	mockUsers.On("UpdateUser", mock.Anything, mock.Anything).Return(nil)
+	mockTransactions.On("SentSince", mock.Anything, "user2", mock.Anything).Return(&domain.TransferStats{}, nil)
+	mockTransactions.On("Create", mock.Anything, mock.MatchedBy(func(transaction *domain.Transaction) bool {
+		return transaction.SenderUsername == "user2" && transaction.ReceiverUsername == "user1" &&
+			transaction.Message == "lunch" && transaction.Private
+	})).Return(&domain.Transaction{GUID: "t1"}, nil).Once()
+	mockCoinLots.On("Consume", mock.Anything, "user2", 30.0).Return([]domain.CoinLot{}, nil)
+	mockCoinLots.On("Grant", mock.Anything, mock.Anything).Return(nil)
+	mockAudit.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := repo.AcceptCoinRequest(context.Background(), 1, "user1", domain.TransferPolicy{})
+	assert.ErrorIs(t, err, domain.ErrNoCoinRequest)
+	_, err = repo.AcceptCoinRequest(context.Background(), 4, "user2", domain.TransferPolicy{})
+	assert.ErrorIs(t, err, domain.ErrNoCoinRequest)
+	_, err = repo.AcceptCoinRequest(context.Background(), 2, "user2", domain.TransferPolicy{})
+	assert.ErrorIs(t, err, domain.ErrCoinRequestClosed)
+
+	_, err = repo.AcceptCoinRequest(context.Background(), 3, "user2", domain.TransferPolicy{})
+	assert.ErrorIs(t, err, domain.ErrCoinRequestExpired)
+	mockRequests.AssertCalled(t, "Update", mock.Anything, mock.MatchedBy(func(request *domain.CoinRequest) bool {
+		return request.ID == 3 && request.Status == domain.RequestExpired
+	}))
+	mockTransactions.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	request, err := repo.AcceptCoinRequest(context.Background(), 1, "user2", domain.TransferPolicy{})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RequestAccepted, request.Status)
+	assert.Equal(t, "t1", *request.TransactionGUID)
+	assert.NotNil(t, request.ResolvedAt)
+	mockTransactions.AssertExpectations(t)
+}
+
+func TestCloseCoinRequest(t *testing.T) {
+	mockRequests := new(MockCoinRequests)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, CoinRequests: mockRequests, Audit: mockAudit}
+
+	mockRequests.On("Lock", mock.Anything, int64(1)).Return(&domain.CoinRequest{
+		ID: 1, Requester: "user1", Payer: "user2", Amount: 30, Status: domain.RequestPending, ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	mockRequests.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditCoinRequest && entry.After == `{"id":1,"status":"declined"}`
+	})).Return(nil).Once()
+
+	// Only the payer declines and only the requester cancels.
+	_, err := repo.CloseCoinRequest(context.Background(), 1, "user1", domain.RequestDeclined)
+	assert.ErrorIs(t, err, domain.ErrNoCoinRequest)
+	_, err = repo.CloseCoinRequest(context.Background(), 1, "user2", domain.RequestCancelled)
+	assert.ErrorIs(t, err, domain.ErrNoCoinRequest)
+
+	request, err := repo.CloseCoinRequest(context.Background(), 1, "user2", domain.RequestDeclined)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RequestDeclined, request.Status)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestLockUsers_StableOrder(t *testing.T) {
+	mockUsers := new(MockUsers)
+	repo := &Repository{Users: mockUsers}
+
+	mockUsers.On("LockUserByUsername", mock.Anything, mock.Anything).Return(&domain.User{}, nil)
+
+	users, err := repo.lockUsers(context.Background(), nil, "user2", "user1", "user2")
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+
+	var locked []string
+	for _, call := range mockUsers.Calls {
+		locked = append(locked, call.Arguments.String(1))
+	}
+	assert.Equal(t, []string{"user1", "user2"}, locked)
+}
+
+func TestAdjustBalances(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockAdjustments := new(MockAdjustments)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	mockCoinLots := new(MockCoinLots)
+	repo := &Repository{DB: mockDB, Users: mockUsers, BalanceAdjustments: mockAdjustments, CoinLots: mockCoinLots, Audit: mockAudit}
+
+	user1 := &domain.User{Username: "user1", Balance: 100}
+	user2 := &domain.User{Username: "user2", Balance: 10}
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(user1, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user2").Return(user2, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "ghost").Return(&domain.User{}, nil)
+	mockUsers.On("UpdateUser", mock.Anything, mock.Anything).Return(nil)
+	mockAdjustments.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mockCoinLots.On("Grant", mock.Anything, mock.MatchedBy(func(lots []domain.CoinLot) bool {
+		return len(lots) == 1 && lots[0].Username == "user1" && lots[0].Remaining == 50
+	})).Return(nil)
+	mockCoinLots.On("Consume", mock.Anything, "user2", 10.0).Return(nil, nil)
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditBalanceAdjustment && entry.Actor == "hr"
+	})).Return(nil)
+
+	ctx := audit.WithUsername(context.Background(), "hr")
+	result, err := repo.AdjustBalances(ctx, []domain.BalanceAdjustment{
+		{Username: "user1", Amount: 50, Reason: "birthday"},
+		{Username: "user2", Amount: -10, Reason: "clawback"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "hr", result[0].Actor)
+	assert.Equal(t, 150.0, user1.Balance)
+	assert.Equal(t, 0.0, user2.Balance)
+
+	_, err = repo.AdjustBalances(ctx, []domain.BalanceAdjustment{{Username: "user2", Amount: -1, Reason: "clawback"}})
+	assert.ErrorIs(t, err, domain.ErrInsufficientMoney)
+
+	_, err = repo.AdjustBalances(ctx, []domain.BalanceAdjustment{{Username: "ghost", Amount: 1, Reason: "bonus"}})
+	assert.ErrorIs(t, err, domain.ErrNoSuchUser)
+}
+
+func TestPayAllowance(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockAdjustments := new(MockAdjustments)
+	mockAllowances := new(MockAllowances)
+	mockCoinLots := new(MockCoinLots)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{
+		DB:                 mockDB,
+		Users:              mockUsers,
+		BalanceAdjustments: mockAdjustments,
+		Allowances:         mockAllowances,
+		CoinLots:           mockCoinLots,
+		Audit:              mockAudit,
+	}
+
+	policy := &domain.AllowancePolicy{ID: 1, Name: "monthly", Amount: 100, Department: "sales"}
+	scheduledFor := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	recipients := []domain.User{{Username: "user1", Balance: 10}, {Username: "user2"}}
+
+	mockAllowances.On("CreateRun", mock.Anything, mock.MatchedBy(func(run *domain.AllowanceRun) bool {
+		return run.ScheduledFor.Equal(scheduledFor)
+	})).Return(true, nil).Once()
+	mockUsers.On("ListActiveUsers", mock.Anything, "", "sales").Return(recipients, nil)
+	mockUsers.On("UpdateUser", mock.Anything, mock.Anything).Return(nil)
+	mockAdjustments.On("Create", mock.Anything, mock.MatchedBy(func(adjustment *domain.BalanceAdjustment) bool {
+		return adjustment.Amount == 100 && adjustment.Reason == "allowance: monthly" && adjustment.Actor == "system"
+	})).Return(nil).Twice()
+	mockCoinLots.On("Grant", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockAudit.On("Append", mock.Anything, mock.Anything).Return(nil)
+	mockAllowances.On("UpdateRun", mock.Anything, mock.MatchedBy(func(run *domain.AllowanceRun) bool {
+		return run.UsersPaid == 2 && run.Total == 200
+	})).Return(nil).Once()
+
+	run, err := repo.PayAllowance(context.Background(), policy, scheduledFor, domain.RunTriggerSchedule)
+	assert.NoError(t, err)
+	assert.Equal(t, "system", run.Actor)
+	assert.Equal(t, 2, run.UsersPaid)
+	assert.Equal(t, 110.0, recipients[0].Balance)
+
+	mockAllowances.On("CreateRun", mock.Anything, mock.Anything).Return(false, nil).Once()
+	_, err = repo.PayAllowance(context.Background(), policy, scheduledFor, domain.RunTriggerSchedule)
+	assert.ErrorIs(t, err, domain.ErrAllowanceAlreadyPaid)
+
+	mockUsers.AssertNumberOfCalls(t, "ListActiveUsers", 1)
+	mockAllowances.AssertExpectations(t)
+	mockAdjustments.AssertExpectations(t)
+}
+
+func TestExpireCoins(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockAdjustments := new(MockAdjustments)
+	mockCoinLots := new(MockCoinLots)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Users: mockUsers, BalanceAdjustments: mockAdjustments, CoinLots: mockCoinLots, Audit: mockAudit}
+
+	cutoff := time.Date(2025, 10, 19, 0, 0, 0, 0, time.UTC)
+	user := &domain.User{Username: "user1", Balance: 30}
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(user, nil)
+	mockUsers.On("UpdateUser", mock.Anything, mock.Anything).Return(nil)
+	mockCoinLots.On("ExpiredAmount", mock.Anything, "user1", cutoff).Return(50.0, nil).Once()
+	mockAdjustments.On("Create", mock.Anything, mock.MatchedBy(func(adjustment *domain.BalanceAdjustment) bool {
+		return adjustment.Amount == -30 && adjustment.Reason == "expired"
+	})).Return(nil).Once()
+	mockAudit.On("Append", mock.Anything, mock.Anything).Return(nil)
+	// The balance covers only 30 of the 50 expired coins, the rest of the lots is closed too.
+	mockCoinLots.On("Consume", mock.Anything, "user1", 30.0).Return(nil, nil).Once()
+	mockCoinLots.On("Consume", mock.Anything, "user1", 20.0).Return(nil, nil).Once()
+
+	adjustment, err := repo.ExpireCoins(context.Background(), "user1", cutoff, "expired")
+	assert.NoError(t, err)
+	assert.Equal(t, -30.0, adjustment.Amount)
+	assert.Equal(t, 0.0, user.Balance)
+
+	mockCoinLots.On("ExpiredAmount", mock.Anything, "user1", cutoff).Return(0.0, nil).Once()
+	adjustment, err = repo.ExpireCoins(context.Background(), "user1", cutoff, "expired")
+	assert.NoError(t, err)
+	assert.Nil(t, adjustment)
+
+	mockCoinLots.AssertExpectations(t)
+	mockAdjustments.AssertExpectations(t)
+}
+
+func TestExecuteScheduledTransfer(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockTransactions := new(MockTransactions)
+	mockCoinLots := new(MockCoinLots)
+	mockScheduled := new(MockScheduledTransfers)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Users: mockUsers, Transactions: mockTransactions, CoinLots: mockCoinLots, ScheduledTransfers: mockScheduled, Audit: mockAudit}
+
+	pending := func(id int64, sender string) *domain.ScheduledTransfer {
+		return &domain.ScheduledTransfer{
+			ID: id, SenderUsername: sender, ReceiverUsername: "user1", Amount: 30,
+			TransferNote: domain.TransferNote{Message: "happy birthday"}, Status: domain.ScheduledPending,
+		}
+	}
+	mockScheduled.On("Lock", mock.Anything, int64(1)).Return(pending(1, "user2"), nil)
+	mockScheduled.On("Lock", mock.Anything, int64(2)).Return(pending(2, "user3"), nil)
+	mockScheduled.On("Lock", mock.Anything, int64(3)).Return(&domain.ScheduledTransfer{ID: 3, SenderUsername: "user2", Status: domain.ScheduledCancelled}, nil)
+	mockScheduled.On("Lock", mock.Anything, int64(4)).Return(&domain.ScheduledTransfer{}, nil)
+	mockScheduled.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(&domain.User{Username: "user1", Balance: 0}, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user2").Return(&domain.User{Username: "user2", Balance: 100}, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user3").Return(&domain.User{Username: "user3", Balance: 10}, nil)
+	mockUsers.On("UpdateUser", mock.Anything, mock.Anything).Return(nil)
+	mockTransactions.On("SentSince", mock.Anything, "user2", mock.Anything).Return(&domain.TransferStats{}, nil)
+	mockTransactions.On("Create", mock.Anything, mock.MatchedBy(func(transaction *domain.Transaction) bool {
+		return transaction.SenderUsername == "user2" && transaction.ReceiverUsername == "user1" && transaction.Message == "happy birthday"
+	})).Return(&domain.Transaction{GUID: "t1"}, nil).Once()
+	mockCoinLots.On("Consume", mock.Anything, "user2", 30.0).Return([]domain.CoinLot{}, nil)
+	mockCoinLots.On("Grant", mock.Anything, mock.Anything).Return(nil)
+	mockAudit.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := repo.ExecuteScheduledTransfer(context.Background(), 3, domain.TransferPolicy{})
+	assert.ErrorIs(t, err, domain.ErrScheduledTransferClosed)
+	_, err = repo.ExecuteScheduledTransfer(context.Background(), 4, domain.TransferPolicy{})
+	assert.ErrorIs(t, err, domain.ErrNoScheduledTransfer)
+
+	// A sender short of coins fails the transfer for good instead of an error.
+	scheduled, err := repo.ExecuteScheduledTransfer(context.Background(), 2, domain.TransferPolicy{})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ScheduledFailed, scheduled.Status)
+	assert.Equal(t, domain.ErrInsufficientMoney.Error(), scheduled.FailureReason)
+	assert.Nil(t, scheduled.TransactionGUID)
+
+	scheduled, err = repo.ExecuteScheduledTransfer(context.Background(), 1, domain.TransferPolicy{})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ScheduledExecuted, scheduled.Status)
+	assert.Equal(t, "t1", *scheduled.TransactionGUID)
+	assert.NotNil(t, scheduled.ResolvedAt)
+	mockTransactions.AssertExpectations(t)
+}
+
+func TestCancelScheduledTransfer(t *testing.T) {
+	mockScheduled := new(MockScheduledTransfers)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, ScheduledTransfers: mockScheduled, Audit: mockAudit}
+
+	mockScheduled.On("Lock", mock.Anything, int64(1)).Return(&domain.ScheduledTransfer{
+		ID: 1, SenderUsername: "user1", ReceiverUsername: "user2", Amount: 30, Status: domain.ScheduledPending,
+	}, nil)
+	mockScheduled.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditScheduledTransfer && entry.After == `{"id":1,"status":"cancelled"}`
+	})).Return(nil).Once()
+
+	// Only the sender can cancel.
+	_, err := repo.CancelScheduledTransfer(context.Background(), 1, "user2")
+	assert.ErrorIs(t, err, domain.ErrNoScheduledTransfer)
+
+	scheduled, err := repo.CancelScheduledTransfer(context.Background(), 1, "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ScheduledCancelled, scheduled.Status)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestCreateHold(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockHolds := new(MockHolds)
+	mockTransactions := new(MockTransactions)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Users: mockUsers, Holds: mockHolds, Transactions: mockTransactions, Audit: mockAudit}
+
+	user := &domain.User{Username: "user1", Balance: 100, Held: 20}
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(user, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user2").Return(&domain.User{Username: "user2"}, nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "ghost").Return(&domain.User{}, nil)
+	mockUsers.On("UpdateUser", mock.Anything, user).Return(nil)
+	mockHolds.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditHold && entry.Subject == "user1"
+	})).Return(nil).Once()
+
+	_, err := repo.CreateHold(context.Background(), &domain.Hold{Username: "ghost", Amount: 10})
+	assert.ErrorIs(t, err, domain.ErrNoSuchUser)
+	_, err = repo.CreateHold(context.Background(), &domain.Hold{Username: "user1", Amount: 90})
+	assert.ErrorIs(t, err, domain.ErrInsufficientMoney)
+
+	hold, err := repo.CreateHold(context.Background(), &domain.Hold{Username: "user1", Amount: 50, Reason: "team pool"})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.HoldActive, hold.Status)
+	assert.Equal(t, 70.0, user.Held)
+	assert.Equal(t, 100.0, user.Balance)
+
+	// Held coins cannot be transferred.
+	_, err = repo.CreateTransaction(context.Background(), "user2", "user1", 40, domain.TransferNote{}, domain.TransferPolicy{})
+	assert.ErrorIs(t, err, domain.ErrInsufficientMoney)
+	mockTransactions.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockHolds.AssertExpectations(t)
+}
+
+func TestCaptureHold(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockHolds := new(MockHolds)
+	mockAdjustments := new(MockAdjustments)
+	mockCoinLots := new(MockCoinLots)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Users: mockUsers, Holds: mockHolds, BalanceAdjustments: mockAdjustments, CoinLots: mockCoinLots, Audit: mockAudit}
+
+	active := func(id int64, expiresAt time.Time) *domain.Hold {
+		return &domain.Hold{ID: id, Username: "user1", Amount: 50, Reason: "order 7", Status: domain.HoldActive, ExpiresAt: expiresAt}
+	}
+	tomorrow := time.Now().Add(24 * time.Hour)
+	user := &domain.User{Username: "user1", Balance: 100, Held: 100}
+	mockHolds.On("Lock", mock.Anything, int64(1)).Return(active(1, tomorrow), nil)
+	mockHolds.On("Lock", mock.Anything, int64(2)).Return(active(2, tomorrow), nil)
+	mockHolds.On("Lock", mock.Anything, int64(3)).Return(active(3, time.Now().Add(-time.Minute)), nil)
+	mockHolds.On("Lock", mock.Anything, int64(4)).Return(&domain.Hold{ID: 4, Username: "user1", Status: domain.HoldReleased}, nil)
+	mockHolds.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(user, nil)
+	mockUsers.On("UpdateUser", mock.Anything, user).Return(nil)
+	mockAdjustments.On("Create", mock.Anything, mock.MatchedBy(func(adjustment *domain.BalanceAdjustment) bool {
+		return adjustment.Amount == -30 && adjustment.Reason == "order 7"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.BalanceAdjustment).GUID = "a1"
+	}).Return(nil).Once()
+	mockCoinLots.On("Consume", mock.Anything, "user1", 30.0).Return(nil, nil)
+	mockAudit.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := repo.CaptureHold(context.Background(), 4, 0)
+	assert.ErrorIs(t, err, domain.ErrHoldClosed)
+	_, err = repo.CaptureHold(context.Background(), 2, 60)
+	assert.ErrorIs(t, err, domain.ErrCaptureExceedsHold)
+
+	_, err = repo.CaptureHold(context.Background(), 3, 0)
+	assert.ErrorIs(t, err, domain.ErrHoldExpired)
+	mockHolds.AssertCalled(t, "Update", mock.Anything, mock.MatchedBy(func(hold *domain.Hold) bool {
+		return hold.ID == 3 && hold.Status == domain.HoldExpired
+	}))
+	assert.Equal(t, 50.0, user.Held)
+
+	// A partial capture debits the amount and releases the rest of the hold.
+	hold, err := repo.CaptureHold(context.Background(), 1, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.HoldCaptured, hold.Status)
+	assert.Equal(t, 30.0, hold.Captured)
+	assert.Equal(t, "a1", *hold.AdjustmentGUID)
+	assert.Equal(t, 70.0, user.Balance)
+	assert.Equal(t, 0.0, user.Held)
+	mockAdjustments.AssertExpectations(t)
+}
+
+func TestReleaseHold(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockHolds := new(MockHolds)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Users: mockUsers, Holds: mockHolds, Audit: mockAudit}
+
+	expiresAt := time.Now().Add(time.Hour)
+	user := &domain.User{Username: "user1", Balance: 100, Held: 80}
+	mockHolds.On("Lock", mock.Anything, int64(1)).Return(&domain.Hold{ID: 1, Username: "user1", Amount: 50, Status: domain.HoldActive, ExpiresAt: expiresAt}, nil)
+	mockHolds.On("Lock", mock.Anything, int64(2)).Return(&domain.Hold{ID: 2, Username: "user1", Amount: 30, Status: domain.HoldActive, ExpiresAt: expiresAt}, nil)
+	mockHolds.On("Lock", mock.Anything, int64(3)).Return(&domain.Hold{}, nil)
+	mockHolds.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(user, nil)
+	mockUsers.On("UpdateUser", mock.Anything, user).Return(nil)
+	mockAudit.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := repo.ReleaseHold(context.Background(), 3, time.Now())
+	assert.ErrorIs(t, err, domain.ErrNoHold)
+
+	hold, err := repo.ReleaseHold(context.Background(), 1, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, domain.HoldReleased, hold.Status)
+	assert.Equal(t, 30.0, user.Held)
+
+	hold, err = repo.ReleaseHold(context.Background(), 2, expiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.HoldExpired, hold.Status)
+	assert.Equal(t, 0.0, user.Held)
+}
+
+func TestContributeToWallet(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockWallets := new(MockWallets)
+	mockCoinLots := new(MockCoinLots)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Users: mockUsers, Wallets: mockWallets, CoinLots: mockCoinLots, Audit: mockAudit}
+
+	wallet := &domain.Wallet{ID: 1, Balance: 100}
+	members := []domain.WalletMember{{WalletID: 1, Username: "owner", Role: domain.WalletRoleOwner}, {WalletID: 1, Username: "user1", Role: domain.WalletRoleMember}}
+	user := &domain.User{Username: "user1", Balance: 100, Held: 40}
+	mockWallets.On("Lock", mock.Anything, int64(1)).Return(wallet, nil)
+	mockWallets.On("ListMembers", mock.Anything, int64(1)).Return(members, nil)
+	mockWallets.On("Update", mock.Anything, wallet).Return(nil)
+	mockWallets.On("CreateContribution", mock.Anything, mock.Anything).Return(nil).Once()
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(user, nil)
+	mockUsers.On("UpdateUser", mock.Anything, user).Return(nil)
+	mockCoinLots.On("Consume", mock.Anything, "user1", 50.0).Return(nil, nil).Once()
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditWalletContribute && entry.Subject == "user1"
+	})).Return(nil).Once()
+
+	_, err := repo.ContributeToWallet(context.Background(), 1, "stranger", 10)
+	assert.ErrorIs(t, err, domain.ErrNoWallet)
+	// Held coins cannot be contributed.
+	_, err = repo.ContributeToWallet(context.Background(), 1, "user1", 70)
+	assert.ErrorIs(t, err, domain.ErrInsufficientMoney)
+
+	contribution, err := repo.ContributeToWallet(context.Background(), 1, "user1", 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 50.0, contribution.Amount)
+	assert.Equal(t, 50.0, user.Balance)
+	assert.Equal(t, 150.0, wallet.Balance)
+	mockCoinLots.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestApproveWalletPurchase(t *testing.T) {
+	mockMerch := new(MockMerch)
+	mockPurchases := new(MockPurchases)
+	mockWallets := new(MockWallets)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Merch: mockMerch, Purchases: mockPurchases, Wallets: mockWallets, Audit: mockAudit}
+
+	wallet := &domain.Wallet{ID: 1, Balance: 500, Quorum: 2}
+	members := []domain.WalletMember{
+		{WalletID: 1, Username: "owner", Role: domain.WalletRoleOwner},
+		{WalletID: 1, Username: "user1", Role: domain.WalletRoleMember},
+		{WalletID: 1, Username: "user2", Role: domain.WalletRoleMember},
+	}
+	pending := func(id int64, approvals ...string) *domain.WalletPurchase {
+		return &domain.WalletPurchase{ID: id, WalletID: 1, MerchName: "hoody", Price: 300, ProposedBy: "user1", Status: domain.WalletPurchasePending, Approvals: approvals}
+	}
+	mockWallets.On("Lock", mock.Anything, int64(1)).Return(wallet, nil)
+	mockWallets.On("ListMembers", mock.Anything, int64(1)).Return(members, nil)
+	mockWallets.On("LockPurchase", mock.Anything, int64(1), int64(1)).Return(pending(1, "user1"), nil)
+	mockWallets.On("LockPurchase", mock.Anything, int64(1), int64(2)).Return(pending(2, "user1"), nil)
+	mockWallets.On("LockPurchase", mock.Anything, int64(1), int64(3)).Return(&domain.WalletPurchase{ID: 3, WalletID: 1, Status: domain.WalletPurchaseRejected}, nil)
+	mockWallets.On("LockPurchase", mock.Anything, int64(1), int64(4)).Return(&domain.WalletPurchase{ID: 4, WalletID: 1, MerchName: "gone", ProposedBy: "user1", Status: domain.WalletPurchasePending}, nil)
+	mockWallets.On("AddApproval", mock.Anything, mock.Anything).Return(nil)
+	mockWallets.On("UpdatePurchase", mock.Anything, mock.Anything).Return(nil)
+	mockWallets.On("Update", mock.Anything, wallet).Return(nil)
+	mockMerch.On("Lock", mock.Anything, "hoody").Return(&domain.Merch{Name: "hoody", Price: 300}, nil)
+	mockMerch.On("Lock", mock.Anything, "gone").Return(&domain.Merch{}, nil)
+	mockPurchases.On("Create", mock.Anything, mock.MatchedBy(func(purchase *domain.Purchase) bool {
+		return purchase.UserID == "user1" && purchase.RecipientID == "user1" && *purchase.WalletID == 1
+	})).Return(&domain.Purchase{GUID: "p1"}, nil)
+	mockAudit.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := repo.ApproveWalletPurchase(context.Background(), 1, 3, "owner")
+	assert.ErrorIs(t, err, domain.ErrWalletPurchaseClosed)
+
+	// The second member approval meets the quorum.
+	purchase, err := repo.ApproveWalletPurchase(context.Background(), 1, 1, "user2")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.WalletPurchaseCompleted, purchase.Status)
+	assert.Equal(t, []string{"user1", "user2"}, purchase.Approvals)
+	assert.Equal(t, "p1", purchase.PurchaseGUID)
+	assert.Equal(t, 200.0, wallet.Balance)
+
+	// The wallet no longer covers the price, the approval is not recorded.
+	_, err = repo.ApproveWalletPurchase(context.Background(), 1, 2, "owner")
+	assert.ErrorIs(t, err, domain.ErrInsufficientMoney)
+
+	// The proposed item is not in the catalog.
+	_, err = repo.ApproveWalletPurchase(context.Background(), 1, 4, "owner")
+	assert.ErrorIs(t, err, domain.ErrNoMerch)
+	mockPurchases.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestRemoveWalletMember(t *testing.T) {
+	mockWallets := new(MockWallets)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Wallets: mockWallets, Audit: mockAudit}
+
+	members := []domain.WalletMember{
+		{WalletID: 1, Username: "owner", Role: domain.WalletRoleOwner},
+		{WalletID: 1, Username: "user1", Role: domain.WalletRoleMember},
+		{WalletID: 1, Username: "user2", Role: domain.WalletRoleMember},
+	}
+	mockWallets.On("Lock", mock.Anything, int64(1)).Return(&domain.Wallet{ID: 1}, nil)
+	mockWallets.On("ListMembers", mock.Anything, int64(1)).Return(members, nil)
+	mockWallets.On("RemoveMember", mock.Anything, int64(1), "user1").Return(nil).Once()
+	mockAudit.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+	assert.ErrorIs(t, repo.RemoveWalletMember(context.Background(), 1, "user2", "user1"), domain.ErrNotWalletOwner)
+	assert.ErrorIs(t, repo.RemoveWalletMember(context.Background(), 1, "owner", "owner"), domain.ErrLastWalletOwner)
+	assert.NoError(t, repo.RemoveWalletMember(context.Background(), 1, "user1", "user1"))
+	mockWallets.AssertExpectations(t)
+}
+
+func TestCreatePurchase_PromoCodes(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockMerch := new(MockMerch)
+	mockPurchases := new(MockPurchases)
+	mockCoinLots := new(MockCoinLots)
+	mockPromoCodes := new(MockPromoCodes)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{
+		DB:         mockDB,
+		Users:      mockUsers,
+		Merch:      mockMerch,
+		Purchases:  mockPurchases,
+		CoinLots:   mockCoinLots,
+		PromoCodes: mockPromoCodes,
+		Audit:      mockAudit,
+	}
+
+	user := &domain.User{Username: "user1", Balance: 1000}
+	onboarding := domain.PromoCode{Code: "ONBOARD20", Kind: domain.DiscountPercent, Amount: 20, Items: []string{"hoody"}, MaxUsesPerUser: 1, Active: true}
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(user, nil)
+	mockUsers.On("UpdateUser", mock.Anything, user).Return(nil)
+	mockMerch.On("GetMerchByName", "hoody").Return(&domain.Merch{Name: "hoody", Price: 300}, nil)
+	mockMerch.On("GetMerchByName", "cup").Return(&domain.Merch{Name: "cup", Price: 20}, nil)
+	mockPromoCodes.On("Lock", mock.Anything, []string{"ONBOARD20"}).Return([]domain.PromoCode{onboarding}, nil)
+	mockPromoCodes.On("Lock", mock.Anything, []string{"NOPE"}).Return([]domain.PromoCode{}, nil)
+	mockPromoCodes.On("CountRedemptions", mock.Anything, "ONBOARD20", "user1").Return(3, 0, nil).Once()
+	mockPromoCodes.On("CountRedemptions", mock.Anything, "ONBOARD20", "user1").Return(4, 1, nil).Once()
+	mockPromoCodes.On("CreateRedemptions", mock.Anything, []domain.PromoRedemption{
+		{Code: "ONBOARD20", Username: "user1", Discount: 60, PurchaseGUID: "p1"},
+	}).Return(nil).Once()
+	mockCoinLots.On("Consume", mock.Anything, "user1", 240.0).Return(nil, nil).Once()
+	mockPurchases.On("Create", mock.Anything, mock.MatchedBy(func(purchase *domain.Purchase) bool {
+		return purchase.UnitPrice == 300 && purchase.Total == 240 && purchase.Discount == 60 && slices.Equal(purchase.PromoCodes, []string{"ONBOARD20"})
+	})).Return(&domain.Purchase{GUID: "p1", UserID: "user1", RecipientID: "user1", MerchName: "hoody", UnitPrice: 300, Total: 240, Discount: 60}, nil).Once()
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditPurchase &&
+			entry.After == `{"balance":760,"discount":60,"item":"hoody","price":240,"promo_codes":["ONBOARD20"]}`
+	})).Return(nil).Once()
+
+	purchase, _, err := repo.CreatePurchase(context.Background(), "user1", "hoody", domain.Gift{}, []string{"ONBOARD20"}, domain.PurchasePolicy{})
+	assert.NoError(t, err)
+	assert.Equal(t, 240.0, purchase.Total)
+	assert.Equal(t, 760.0, user.Balance)
+
+	_, _, err = repo.CreatePurchase(context.Background(), "user1", "hoody", domain.Gift{}, []string{"ONBOARD20"}, domain.PurchasePolicy{})
+	assert.ErrorIs(t, err, domain.ErrPromoCodeUsedUp)
+
+	_, _, err = repo.CreatePurchase(context.Background(), "user1", "cup", domain.Gift{}, []string{"ONBOARD20"}, domain.PurchasePolicy{})
+	assert.ErrorIs(t, err, domain.ErrPromoCodeNotApplicable)
+
+	_, _, err = repo.CreatePurchase(context.Background(), "user1", "cup", domain.Gift{}, []string{"NOPE"}, domain.PurchasePolicy{})
+	assert.ErrorIs(t, err, domain.ErrNoPromoCode)
+
+	assert.Equal(t, 760.0, user.Balance)
+	mockPromoCodes.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestCreatePurchase_Approval(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockMerch := new(MockMerch)
+	mockPurchases := new(MockPurchases)
+	mockHolds := new(MockHolds)
+	mockApprovals := new(MockPurchaseApprovals)
+	mockNotifications := new(MockNotifications)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{
+		DB:                mockDB,
+		Users:             mockUsers,
+		Merch:             mockMerch,
+		Purchases:         mockPurchases,
+		Holds:             mockHolds,
+		PurchaseApprovals: mockApprovals,
+		Notifications:     mockNotifications,
+		Audit:             mockAudit,
+	}
+
+	user := &domain.User{Username: "user1", Balance: 1000, Manager: "boss"}
+	policy := domain.PurchasePolicy{ApprovalThreshold: 500, ApprovalTimeout: time.Hour}
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(user, nil)
+	mockUsers.On("UpdateUser", mock.Anything, user).Return(nil)
+	mockMerch.On("GetMerchByName", "pink-hoody").Return(&domain.Merch{Name: "pink-hoody", Price: 500}, nil)
+	mockHolds.On("Create", mock.Anything, mock.MatchedBy(func(hold *domain.Hold) bool {
+		return hold.Amount == 500 && hold.Username == "user1"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Hold).ID = 3
+	}).Return(nil).Once()
+	mockApprovals.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+	mockNotifications.On("Create", mock.Anything, mock.MatchedBy(func(notification *domain.Notification) bool {
+		return notification.Username == "boss" && notification.Kind == domain.NotificationApproval
+	})).Return(nil).Once()
+	mockAudit.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+	purchase, approval, err := repo.CreatePurchase(context.Background(), "user1", "pink-hoody", domain.Gift{}, nil, policy)
+	assert.NoError(t, err)
+	assert.Nil(t, purchase)
+	assert.Equal(t, domain.ApprovalPending, approval.Status)
+	assert.Equal(t, "boss", approval.Approver)
+	assert.Equal(t, int64(3), approval.HoldID)
+	assert.Equal(t, 1000.0, user.Balance)
+	assert.Equal(t, 500.0, user.Held)
+	mockPurchases.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockNotifications.AssertExpectations(t)
+}
+
+func TestApprovePurchase(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockMerch := new(MockMerch)
+	mockPurchases := new(MockPurchases)
+	mockCoinLots := new(MockCoinLots)
+	mockHolds := new(MockHolds)
+	mockApprovals := new(MockPurchaseApprovals)
+	mockPromoCodes := new(MockPromoCodes)
+	mockNotifications := new(MockNotifications)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{
+		DB:                mockDB,
+		Users:             mockUsers,
+		Merch:             mockMerch,
+		Purchases:         mockPurchases,
+		CoinLots:          mockCoinLots,
+		Holds:             mockHolds,
+		PurchaseApprovals: mockApprovals,
+		PromoCodes:        mockPromoCodes,
+		Notifications:     mockNotifications,
+		Audit:             mockAudit,
+	}
+
+	pending := func(id, holdID int64) *domain.PurchaseApproval {
+		return &domain.PurchaseApproval{ID: id, Username: "user1", RecipientID: "user1", MerchName: "pink-hoody", Price: 500, PromoCodes: []string{"WELCOME"}, Approver: "boss", HoldID: holdID, Status: domain.ApprovalPending}
+	}
+	expiresAt := time.Now().Add(time.Hour)
+	user := &domain.User{Username: "user1", Balance: 1000, Held: 1000}
+	mockApprovals.On("Lock", mock.Anything, int64(1)).Return(pending(1, 1), nil)
+	mockApprovals.On("Lock", mock.Anything, int64(2)).Return(pending(2, 2), nil)
+	mockApprovals.On("Lock", mock.Anything, int64(3)).Return(pending(3, 3), nil)
+	mockApprovals.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockHolds.On("Lock", mock.Anything, int64(1)).Return(&domain.Hold{ID: 1, Username: "user1", Amount: 500, Status: domain.HoldActive, ExpiresAt: expiresAt}, nil)
+	mockHolds.On("Lock", mock.Anything, int64(2)).Return(&domain.Hold{ID: 2, Username: "user1", Amount: 500, Status: domain.HoldActive, ExpiresAt: expiresAt}, nil)
+	mockHolds.On("Lock", mock.Anything, int64(3)).Return(&domain.Hold{ID: 3, Username: "user1", Amount: 500, Status: domain.HoldExpired}, nil)
+	mockHolds.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(user, nil)
+	mockUsers.On("UpdateUser", mock.Anything, user).Return(nil)
+	mockMerch.On("GetMerchByName", "pink-hoody").Return(&domain.Merch{Name: "pink-hoody", Price: 500}, nil)
+	mockCoinLots.On("Consume", mock.Anything, "user1", 500.0).Return(nil, nil).Once()
+	mockPurchases.On("Create", mock.Anything, mock.Anything).Return(&domain.Purchase{GUID: "p1", UserID: "user1", RecipientID: "user1", MerchName: "pink-hoody"}, nil).Once()
+	mockPromoCodes.On("CompleteRedemptions", mock.Anything, int64(1), "p1").Return(nil).Once()
+	mockPromoCodes.On("ReleaseRedemptions", mock.Anything, int64(2)).Return(nil).Once()
+	mockPromoCodes.On("ReleaseRedemptions", mock.Anything, int64(3)).Return(nil).Once()
+	mockNotifications.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mockAudit.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+	// Neither other users nor the buyer can approve.
+	_, err := repo.ApprovePurchase(context.Background(), 1, "user2", false)
+	assert.ErrorIs(t, err, domain.ErrNoApproval)
+	_, err = repo.ApprovePurchase(context.Background(), 1, "user1", true)
+	assert.ErrorIs(t, err, domain.ErrNoApproval)
+
+	_, err = repo.ApprovePurchase(context.Background(), 3, "boss", false)
+	assert.ErrorIs(t, err, domain.ErrApprovalExpired)
+	mockApprovals.AssertCalled(t, "Update", mock.Anything, mock.MatchedBy(func(approval *domain.PurchaseApproval) bool {
+		return approval.ID == 3 && approval.Status == domain.ApprovalExpired
+	}))
+
+	approval, err := repo.ApprovePurchase(context.Background(), 1, "boss", false)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ApprovalApproved, approval.Status)
+	assert.Equal(t, "boss", approval.ResolvedBy)
+	assert.Equal(t, "p1", approval.PurchaseGUID)
+	assert.Equal(t, 500.0, user.Balance)
+	assert.Equal(t, 500.0, user.Held)
+	mockHolds.AssertCalled(t, "Update", mock.Anything, mock.MatchedBy(func(hold *domain.Hold) bool {
+		return hold.ID == 1 && hold.Status == domain.HoldCaptured && hold.PurchaseGUID != nil && *hold.PurchaseGUID == "p1"
+	}))
+
+	approval, err = repo.RejectPurchase(context.Background(), 2, "admin", true)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ApprovalRejected, approval.Status)
+	assert.Equal(t, 500.0, user.Balance)
+	assert.Equal(t, 0.0, user.Held)
+	mockPurchases.AssertExpectations(t)
+	mockCoinLots.AssertExpectations(t)
+	mockPromoCodes.AssertExpectations(t)
+}
+
+func TestEscalatePurchaseApproval(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockHolds := new(MockHolds)
+	mockApprovals := new(MockPurchaseApprovals)
+	mockNotifications := new(MockNotifications)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Users: mockUsers, Holds: mockHolds, PurchaseApprovals: mockApprovals, Notifications: mockNotifications, Audit: mockAudit}
+
+	now := time.Now()
+	user := &domain.User{Username: "user1", Balance: 1000, Held: 500}
+	approval := &domain.PurchaseApproval{ID: 1, Username: "user1", MerchName: "pink-hoody", Price: 500, Approver: "boss", HoldID: 1, Status: domain.ApprovalPending, DueAt: now}
+	mockApprovals.On("Lock", mock.Anything, int64(1)).Return(approval, nil)
+	mockApprovals.On("Update", mock.Anything, approval).Return(nil)
+	mockHolds.On("Lock", mock.Anything, int64(1)).Return(&domain.Hold{ID: 1, Username: "user1", Amount: 500, Status: domain.HoldActive, ExpiresAt: now.Add(time.Hour)}, nil)
+	mockHolds.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockUsers.On("LockUserByUsername", mock.Anything, "user1").Return(user, nil)
+	mockUsers.On("UpdateUser", mock.Anything, user).Return(nil)
+	mockNotifications.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mockAudit.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := repo.EscalatePurchaseApproval(context.Background(), 1, now.Add(-time.Minute), time.Hour)
+	assert.ErrorIs(t, err, domain.ErrApprovalClosed)
+
+	result, err := repo.EscalatePurchaseApproval(context.Background(), 1, now, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ApprovalPending, result.Status)
+	assert.Empty(t, result.Approver)
+	assert.True(t, result.Escalated)
+	assert.Equal(t, now.Add(time.Hour), result.DueAt)
+
+	// Admins did not handle it in time either.
+	result, err = repo.EscalatePurchaseApproval(context.Background(), 1, now.Add(time.Hour), time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ApprovalExpired, result.Status)
+	assert.Equal(t, "system", result.ResolvedBy)
+	assert.Equal(t, 0.0, user.Held)
+}
+
+func TestUpdateMerch(t *testing.T) {
+	mockMerch := new(MockMerch)
+	mockCategories := new(MockCategories)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Merch: mockMerch, Categories: mockCategories, Audit: mockAudit}
+
+	merch := &domain.Merch{Name: "hoody", Price: 300}
+	mockMerch.On("Lock", mock.Anything, "hoody").Return(merch, nil)
+	mockMerch.On("Lock", mock.Anything, "hat").Return(&domain.Merch{}, nil)
+	mockMerch.On("Update", mock.Anything, merch).Return(nil)
+	mockMerch.On("CreatePrice", mock.Anything, mock.MatchedBy(func(price *domain.MerchPrice) bool {
+		return price.MerchName == "hoody" && price.Price == 250
+	})).Return(nil).Once()
+	mockCategories.On("Get", "apparel").Return(&domain.Category{Name: "apparel", Title: "Apparel"}, nil)
+	mockCategories.On("Get", "food").Return(&domain.Category{}, nil)
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditPriceChange && entry.Before == `{"price":300}` && entry.After == `{"price":250}`
+	})).Return(nil).Once()
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditMerch && entry.Subject == "hoody"
+	})).Return(nil).Once()
+
+	_, err := repo.UpdateMerch(context.Background(), "hat", domain.MerchUpdate{})
+	assert.ErrorIs(t, err, domain.ErrNoMerch)
+	food := "food"
+	_, err = repo.UpdateMerch(context.Background(), "hoody", domain.MerchUpdate{Category: &food})
+	assert.ErrorIs(t, err, domain.ErrNoCategory)
+
+	price, category, tags := 250.0, "apparel", []string{"warm"}
+	updated, err := repo.UpdateMerch(context.Background(), "hoody", domain.MerchUpdate{Price: &price, Category: &category, Tags: &tags})
+	assert.NoError(t, err)
+	assert.Equal(t, 250.0, updated.Price)
+	assert.Equal(t, "apparel", *updated.Category)
+	assert.Equal(t, []string{"warm"}, updated.Tags)
+	mockMerch.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestCreatePurchase_Stock(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockMerch := new(MockMerch)
+	mockPurchases := new(MockPurchases)
+	mockCoinLots := new(MockCoinLots)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{
+		DB:        mockDB,
+		Users:     mockUsers,
+		Merch:     mockMerch,
+		Purchases: mockPurchases,
+		CoinLots:  mockCoinLots,
+		Audit:     mockAudit,
+	}
+
+	none, one := 0, 1
+	user := &domain.User{Username: "user", Balance: 1000}
+	mockUsers.On("LockUserByUsername", mock.Anything, "user").Return(user, nil)
+	mockMerch.On("GetMerchByName", "cup").Return(&domain.Merch{Name: "cup", Price: 20, Stock: &none}, nil)
+	mockMerch.On("GetMerchByName", "hoody").Return(&domain.Merch{Name: "hoody", Price: 300, Stock: &one}, nil)
+	mockMerch.On("TakeStock", mock.Anything, "hoody").Return(true, nil).Once()
+	mockMerch.On("TakeStock", mock.Anything, "hoody").Return(false, nil).Once()
+	mockPurchases.On("Create", mock.Anything, mock.Anything).Return(&domain.Purchase{UserID: "user", MerchName: "hoody"}, nil)
+	mockUsers.On("UpdateUser", mock.Anything, mock.Anything).Return(nil)
+	mockCoinLots.On("Consume", mock.Anything, "user", 300.0).Return(nil, nil)
+	mockAudit.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+	_, _, err := repo.CreatePurchase(context.Background(), "user", "cup", domain.Gift{}, nil, domain.PurchasePolicy{})
+	assert.ErrorIs(t, err, domain.ErrOutOfStock)
+	mockMerch.AssertNotCalled(t, "TakeStock", mock.Anything, "cup")
+
+	purchase, _, err := repo.CreatePurchase(context.Background(), "user", "hoody", domain.Gift{}, nil, domain.PurchasePolicy{})
+	assert.NoError(t, err)
+	assert.NotNil(t, purchase)
+
+	// The last item was taken by a concurrent purchase.
+	_, _, err = repo.CreatePurchase(context.Background(), "user", "hoody", domain.Gift{}, nil, domain.PurchasePolicy{})
+	assert.ErrorIs(t, err, domain.ErrOutOfStock)
+	mockMerch.AssertExpectations(t)
+}
+
+func TestSaveWishlistAlert(t *testing.T) {
+	mockWishlists := new(MockWishlists)
+	mockNotifications := new(MockNotifications)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &Repository{DB: mockDB, Wishlists: mockWishlists, Notifications: mockNotifications}
+
+	item := &domain.WishlistItem{Username: "user", MerchName: "cup", SeenPrice: 15, SeenInStock: true}
+	notification := &domain.Notification{Username: "user", Kind: domain.NotificationPriceDrop, Reference: "cup"}
+	mockWishlists.On("UpdateSeen", mock.Anything, item).Return(nil).Twice()
+	mockNotifications.On("Create", mock.Anything, notification).Return(nil).Once()
+
+	assert.NoError(t, repo.SaveWishlistAlert(context.Background(), item, notification))
+	// A change nobody subscribed to is only marked as seen.
+	assert.NoError(t, repo.SaveWishlistAlert(context.Background(), item, nil))
+	mockWishlists.AssertExpectations(t)
+	mockNotifications.AssertExpectations(t)
+}
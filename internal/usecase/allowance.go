@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/metrics"
+	"shop/pkg/tracing"
+
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxCatchUp bounds the search for the latest missed occurrence of a
+// schedule, e.g. after a long downtime of an every-minute policy.
+const maxCatchUp = 100000
+
+func (r *UsecaseImplementation) UpdateProfile(ctx context.Context, username string, department, manager *string, active *bool) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "usecase.UpdateProfile")
+	defer span.End()
+
+	user, err := r.Repository.UpdateProfile(ctx, username, department, manager, active)
+	return user, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) CreateAllowancePolicy(ctx context.Context, policy *domain.AllowancePolicy) (*domain.AllowancePolicy, error) {
+	ctx, span := tracing.Start(ctx, "usecase.CreateAllowancePolicy")
+	defer span.End()
+
+	policy.Name = strings.TrimSpace(policy.Name)
+	if _, err := cron.ParseStandard(policy.Schedule); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSchedule, err)
+	}
+
+	policy, err := r.Repository.Allowances.CreatePolicy(ctx, policy)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if err = r.audit(ctx, domain.AuditAllowancePolicy, policy.Name, nil, policy); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return policy, nil
+}
+
+func (r *UsecaseImplementation) ListAllowancePolicies(ctx context.Context) ([]domain.AllowancePolicy, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ListAllowancePolicies")
+	defer span.End()
+
+	policies, err := r.Repository.Allowances.ListPolicies(ctx)
+	return policies, tracing.Error(span, err)
+}
+
+// PreviewAllowance lists who would be paid if the policy ran now.
+func (r *UsecaseImplementation) PreviewAllowance(ctx context.Context, id int64) (*domain.AllowancePreview, error) {
+	ctx, span := tracing.Start(ctx, "usecase.PreviewAllowance")
+	defer span.End()
+
+	policy, err := r.getPolicy(ctx, id)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	users, err := r.Repository.Users.ListActiveUsers(ctx, nil, policy.Role, policy.Department)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+
+	preview := &domain.AllowancePreview{Policy: *policy, Recipients: make([]domain.AllowanceRecipient, 0, len(users))}
+	for _, user := range users {
+		preview.Recipients = append(preview.Recipients, domain.AllowanceRecipient{Username: user.Username, Amount: policy.Amount})
+		preview.Total += policy.Amount
+	}
+	if schedule, err := cron.ParseStandard(policy.Schedule); err == nil && !policy.Paused {
+		next := schedule.Next(time.Now())
+		preview.NextRun = &next
+	}
+	return preview, nil
+}
+
+func (r *UsecaseImplementation) SetAllowancePaused(ctx context.Context, id int64, paused bool) (*domain.AllowancePolicy, error) {
+	ctx, span := tracing.Start(ctx, "usecase.SetAllowancePaused", attribute.Bool("paused", paused))
+	defer span.End()
+
+	policy, err := r.getPolicy(ctx, id)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if err = r.Repository.Allowances.SetPaused(ctx, id, paused); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	before := map[string]bool{"paused": policy.Paused}
+	policy.Paused = paused
+	if err = r.audit(ctx, domain.AuditAllowancePolicy, policy.Name, before, map[string]bool{"paused": paused}); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return policy, nil
+}
+
+// RunAllowance pays the policy now, whether it is paused or not.
+func (r *UsecaseImplementation) RunAllowance(ctx context.Context, id int64) (*domain.AllowanceRun, error) {
+	ctx, span := tracing.Start(ctx, "usecase.RunAllowance")
+	defer span.End()
+
+	policy, err := r.getPolicy(ctx, id)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	run, err := r.payAllowance(ctx, policy, time.Now().UTC().Truncate(time.Microsecond), domain.RunTriggerManual)
+	return run, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) ListAllowanceRuns(ctx context.Context, id int64) ([]domain.AllowanceRun, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ListAllowanceRuns")
+	defer span.End()
+
+	if _, err := r.getPolicy(ctx, id); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	runs, err := r.Repository.Allowances.ListRuns(ctx, id)
+	return runs, tracing.Error(span, err)
+}
+
+// RunDueAllowances pays, for every active policy, the latest occurrence of its
+// schedule that is due and not paid yet. Occurrences missed before it, while
+// the service was down, are skipped rather than paid several times at once.
+func (r *UsecaseImplementation) RunDueAllowances(ctx context.Context, now time.Time) error {
+	ctx, span := tracing.Start(ctx, "usecase.RunDueAllowances")
+	defer span.End()
+
+	policies, err := r.Repository.Allowances.ListPolicies(ctx)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	var errs []error
+	for i := range policies {
+		policy := &policies[i]
+		if policy.Paused {
+			continue
+		}
+		schedule, err := cron.ParseStandard(policy.Schedule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("policy %s: %w", policy.Name, err))
+			continue
+		}
+
+		last, err := r.Repository.Allowances.LastScheduledRun(ctx, policy.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("policy %s: %w", policy.Name, err))
+			continue
+		}
+		after := policy.CreatedAt
+		if last.GUID != "" {
+			after = last.ScheduledFor
+		}
+		occurrence, ok := lastOccurrence(schedule, after, now)
+		if !ok {
+			continue
+		}
+
+		run, err := r.payAllowance(ctx, policy, occurrence, domain.RunTriggerSchedule)
+		switch {
+		case errors.Is(err, domain.ErrAllowanceAlreadyPaid):
+		case err != nil:
+			errs = append(errs, fmt.Errorf("policy %s: %w", policy.Name, err))
+		default:
+			logger.FromContext(ctx).WithField("policy", policy.Name).
+				Infof("paid allowance to %d users, %v coins in total", run.UsersPaid, run.Total)
+		}
+	}
+	return tracing.Error(span, errors.Join(errs...))
+}
+
+func (r *UsecaseImplementation) payAllowance(ctx context.Context, policy *domain.AllowancePolicy, scheduledFor time.Time, trigger string) (*domain.AllowanceRun, error) {
+	run, err := r.Repository.PayAllowance(ctx, policy, scheduledFor, trigger)
+	if err != nil {
+		return nil, err
+	}
+	metrics.CoinsAdjusted.WithLabelValues("credit").Add(run.Total)
+	return run, nil
+}
+
+func (r *UsecaseImplementation) getPolicy(ctx context.Context, id int64) (*domain.AllowancePolicy, error) {
+	policy, err := r.Repository.Allowances.GetPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if policy.ID == 0 {
+		return nil, domain.ErrNoAllowancePolicy
+	}
+	return policy, nil
+}
+
+// lastOccurrence returns the latest time of schedule after the given time
+// and not later than now.
+func lastOccurrence(schedule cron.Schedule, after, now time.Time) (time.Time, bool) {
+	occurrence := schedule.Next(after)
+	if occurrence.After(now) {
+		return time.Time{}, false
+	}
+	for i := 0; i < maxCatchUp; i++ {
+		next := schedule.Next(occurrence)
+		if next.After(now) {
+			break
+		}
+		occurrence = next
+	}
+	return occurrence, true
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"shop/domain"
+	"shop/internal/repository"
+	"shop/pkg/config"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLastOccurrence(t *testing.T) {
+	monthly, _ := cron.ParseStandard("0 9 1 * *")
+	after := time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name     string
+		now      time.Time
+		expected time.Time
+		ok       bool
+	}{
+		{name: "NotDue", now: time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC)},
+		{name: "Due", now: time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC), expected: time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC), ok: true},
+		{name: "MissedSeveral", now: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), expected: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC), ok: true},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			occurrence, ok := lastOccurrence(monthly, after, test.now)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, occurrence)
+		})
+	}
+}
+
+func TestRunDueAllowances_SkipsPausedAndNotDue(t *testing.T) {
+	mockAllowances := new(MockAllowances)
+	repo := &repository.Repository{Allowances: mockAllowances}
+	usecase := NewUsecase(repo, nil, nil, config.Default())
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	mockAllowances.On("ListPolicies").Return([]domain.AllowancePolicy{
+		{ID: 1, Name: "paused", Schedule: "* * * * *", Amount: 1, Paused: true},
+		{ID: 2, Name: "paid", Schedule: "0 9 1 * *", Amount: 1, CreatedAt: now.AddDate(-1, 0, 0)},
+		{ID: 3, Name: "new", Schedule: "0 9 1 * *", Amount: 1, CreatedAt: now.Add(-time.Hour)},
+	}, nil)
+	mockAllowances.On("LastScheduledRun", int64(2)).
+		Return(&domain.AllowanceRun{GUID: "run", ScheduledFor: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)}, nil)
+	mockAllowances.On("LastScheduledRun", int64(3)).Return(&domain.AllowanceRun{}, nil)
+
+	assert.NoError(t, usecase.RunDueAllowances(context.Background(), now))
+	mockAllowances.AssertNotCalled(t, "LastScheduledRun", int64(1))
+	mockAllowances.AssertExpectations(t)
+}
+
+func TestRunDueAllowances_InvalidSchedule(t *testing.T) {
+	mockAllowances := new(MockAllowances)
+	repo := &repository.Repository{Allowances: mockAllowances}
+	usecase := NewUsecase(repo, nil, nil, config.Default())
+
+	mockAllowances.On("ListPolicies").Return([]domain.AllowancePolicy{{ID: 1, Name: "broken", Schedule: "monthly"}}, nil)
+
+	err := usecase.RunDueAllowances(context.Background(), time.Now())
+	assert.ErrorContains(t, err, "policy broken")
+}
+
+func TestCreateAllowancePolicy_InvalidSchedule(t *testing.T) {
+	usecase := NewUsecase(&repository.Repository{}, nil, nil, config.Default())
+
+	_, err := usecase.CreateAllowancePolicy(context.Background(), &domain.AllowancePolicy{Name: "monthly", Schedule: "every month", Amount: 100})
+	assert.ErrorIs(t, err, domain.ErrInvalidSchedule)
+}
+
+func TestPreviewAllowance(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockAllowances := new(MockAllowances)
+	repo := &repository.Repository{Users: mockUsers, Allowances: mockAllowances}
+	usecase := NewUsecase(repo, nil, nil, config.Default())
+
+	policy := &domain.AllowancePolicy{ID: 1, Name: "monthly", Schedule: "0 9 1 * *", Amount: 100, Role: domain.RoleUser}
+	mockAllowances.On("GetPolicy", int64(1)).Return(policy, nil)
+	mockAllowances.On("GetPolicy", int64(2)).Return(&domain.AllowancePolicy{}, nil)
+	mockUsers.On("ListActiveUsers", mock.Anything, domain.RoleUser, "").
+		Return([]domain.User{{Username: "user1"}, {Username: "user2"}}, nil)
+
+	preview, err := usecase.PreviewAllowance(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, preview.Recipients, 2)
+	assert.Equal(t, 200.0, preview.Total)
+	assert.NotNil(t, preview.NextRun)
+
+	_, err = usecase.PreviewAllowance(context.Background(), 2)
+	assert.ErrorIs(t, err, domain.ErrNoAllowancePolicy)
+}
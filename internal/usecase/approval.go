@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/metrics"
+	"shop/pkg/tracing"
+)
+
+// approvalsPerRun caps how many due approvals a single job tick handles.
+const approvalsPerRun = 100
+
+// ListPurchaseApprovals returns the pending approvals assigned to the user,
+// with the ones escalated to admins for admins, and the user's own.
+func (r *UsecaseImplementation) ListPurchaseApprovals(ctx context.Context, username string, admin bool) (*domain.PendingApprovals, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ListPurchaseApprovals")
+	defer span.End()
+
+	assigned, err := r.Repository.PurchaseApprovals.ListAssigned(ctx, username, admin)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	requested, err := r.Repository.PurchaseApprovals.ListRequested(ctx, username)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return &domain.PendingApprovals{Assigned: assigned, Requested: requested}, nil
+}
+
+func (r *UsecaseImplementation) ApprovePurchase(ctx context.Context, id int64, approver string, admin bool) (*domain.PurchaseApproval, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ApprovePurchase")
+	defer span.End()
+
+	approval, err := r.Repository.ApprovePurchase(ctx, id, approver, admin)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	metrics.Purchases.WithLabelValues(approval.MerchName).Inc()
+	return approval, nil
+}
+
+func (r *UsecaseImplementation) RejectPurchase(ctx context.Context, id int64, approver string, admin bool) (*domain.PurchaseApproval, error) {
+	ctx, span := tracing.Start(ctx, "usecase.RejectPurchase")
+	defer span.End()
+
+	approval, err := r.Repository.RejectPurchase(ctx, id, approver, admin)
+	return approval, tracing.Error(span, err)
+}
+
+// EscalatePurchaseApprovals escalates the due approvals of managers to admins
+// and expires the due approvals of admins.
+func (r *UsecaseImplementation) EscalatePurchaseApprovals(ctx context.Context, now time.Time) error {
+	ctx, span := tracing.Start(ctx, "usecase.EscalatePurchaseApprovals")
+	defer span.End()
+
+	ids, err := r.Repository.PurchaseApprovals.ListDue(ctx, now, approvalsPerRun)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	var errs []error
+	for _, id := range ids {
+		approval, err := r.Repository.EscalatePurchaseApproval(ctx, id, now, r.Config.Purchases.ApprovalTimeout)
+		switch {
+		case errors.Is(err, domain.ErrApprovalClosed):
+			// Handled since it was listed.
+		case err != nil:
+			errs = append(errs, fmt.Errorf("purchase approval %d: %w", id, err))
+		case approval.Status == domain.ApprovalExpired:
+			logger.FromContext(ctx).WithField("subject", approval.Username).Infof("purchase approval %d expired", approval.ID)
+		default:
+			logger.FromContext(ctx).WithField("subject", approval.Username).Infof("purchase approval %d escalated to admins", approval.ID)
+		}
+	}
+	return tracing.Error(span, errors.Join(errs...))
+}
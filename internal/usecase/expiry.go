@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/metrics"
+	"shop/pkg/tracing"
+)
+
+// ExpireCoins removes the coins granted more than COIN_EXPIRY_MONTHS before
+// now. It does nothing when expiry is disabled.
+func (r *UsecaseImplementation) ExpireCoins(ctx context.Context, now time.Time) error {
+	months := r.Config.Users.CoinExpiryMonths
+	if months == 0 {
+		return nil
+	}
+	ctx, span := tracing.Start(ctx, "usecase.ExpireCoins")
+	defer span.End()
+
+	cutoff := now.AddDate(0, -months, 0)
+	usernames, err := r.Repository.CoinLots.ListExpiredOwners(ctx, cutoff)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	reason := fmt.Sprintf("expired: coins granted before %s", cutoff.UTC().Format(time.DateOnly))
+	var errs []error
+	for _, username := range usernames {
+		adjustment, err := r.Repository.ExpireCoins(ctx, username, cutoff, reason)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", username, err))
+			continue
+		}
+		if adjustment != nil {
+			metrics.CoinsExpired.Add(-adjustment.Amount)
+			logger.FromContext(ctx).WithField("subject", username).Infof("expired %v coins", -adjustment.Amount)
+		}
+	}
+	return tracing.Error(span, errors.Join(errs...))
+}
+
+// GetCoinExpirations returns when the user's coins expire, soonest first.
+// It returns nil when expiry is disabled.
+func (r *UsecaseImplementation) GetCoinExpirations(ctx context.Context, username string) ([]domain.CoinExpiration, error) {
+	months := r.Config.Users.CoinExpiryMonths
+	if months == 0 {
+		return nil, nil
+	}
+	ctx, span := tracing.Start(ctx, "usecase.GetCoinExpirations")
+	defer span.End()
+
+	lots, err := r.Repository.CoinLots.ListOpenLots(ctx, username)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+
+	expirations := make([]domain.CoinExpiration, 0, len(lots))
+	for _, lot := range lots {
+		expiresAt := lot.GrantedAt.AddDate(0, months, 0)
+		if n := len(expirations); n > 0 && expirations[n-1].ExpiresAt.Equal(expiresAt) {
+			expirations[n-1].Amount += lot.Remaining
+			continue
+		}
+		expirations = append(expirations, domain.CoinExpiration{Amount: lot.Remaining, ExpiresAt: expiresAt})
+	}
+	return expirations, nil
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"shop/domain"
+	"shop/internal/repository"
+	"shop/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpireCoins_Disabled(t *testing.T) {
+	mockCoinLots := new(MockCoinLots)
+	usecase := NewUsecase(&repository.Repository{CoinLots: mockCoinLots}, nil, nil, config.Default())
+
+	assert.NoError(t, usecase.ExpireCoins(context.Background(), time.Now()))
+	expirations, err := usecase.GetCoinExpirations(context.Background(), "user1")
+	assert.NoError(t, err)
+	assert.Nil(t, expirations)
+	mockCoinLots.AssertNotCalled(t, "ListExpiredOwners")
+	mockCoinLots.AssertNotCalled(t, "ListOpenLots")
+}
+
+func TestGetCoinExpirations(t *testing.T) {
+	mockCoinLots := new(MockCoinLots)
+	cfg := config.Default()
+	cfg.Users.CoinExpiryMonths = 12
+	usecase := NewUsecase(&repository.Repository{CoinLots: mockCoinLots}, nil, nil, cfg)
+
+	january := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	march := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	mockCoinLots.On("ListOpenLots", "user1").Return([]domain.CoinLot{
+		{Remaining: 100, GrantedAt: january},
+		{Remaining: 20, GrantedAt: january},
+		{Remaining: 50, GrantedAt: march},
+	}, nil)
+
+	expirations, err := usecase.GetCoinExpirations(context.Background(), "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.CoinExpiration{
+		{Amount: 120, ExpiresAt: time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{Amount: 50, ExpiresAt: time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}, expirations)
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// holdsPerRun caps how many expired holds a single job tick releases.
+const holdsPerRun = 100
+
+// CreateHold reserves amount of the user's balance. Without expiresAt the
+// hold expires after HOLD_TTL.
+func (r *UsecaseImplementation) CreateHold(ctx context.Context, username string, amount float64, reason string, expiresAt time.Time) (*domain.Hold, error) {
+	ctx, span := tracing.Start(ctx, "usecase.CreateHold", attribute.Float64("amount", amount))
+	defer span.End()
+
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(r.Config.Transfers.HoldTTL)
+	}
+	hold, err := r.Repository.CreateHold(ctx, &domain.Hold{Username: username, Amount: amount, Reason: reason, ExpiresAt: expiresAt})
+	return hold, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) CaptureHold(ctx context.Context, id int64, amount float64) (*domain.Hold, error) {
+	ctx, span := tracing.Start(ctx, "usecase.CaptureHold", attribute.Float64("amount", amount))
+	defer span.End()
+
+	hold, err := r.Repository.CaptureHold(ctx, id, amount)
+	return hold, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) ReleaseHold(ctx context.Context, id int64) (*domain.Hold, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ReleaseHold")
+	defer span.End()
+
+	hold, err := r.Repository.ReleaseHold(ctx, id, time.Now())
+	return hold, tracing.Error(span, err)
+}
+
+// GetBalance returns the user's balance with the part reserved by active holds.
+func (r *UsecaseImplementation) GetBalance(ctx context.Context, username string) (*domain.Balance, error) {
+	ctx, span := tracing.Start(ctx, "usecase.GetBalance")
+	defer span.End()
+
+	user, err := r.Repository.Users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if user.Username == "" {
+		return nil, domain.ErrNoSuchUser
+	}
+	holds, err := r.Repository.Holds.ListActive(ctx, username)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return &domain.Balance{Balance: user.Balance, Held: user.Held, Available: user.Available(), Holds: holds}, nil
+}
+
+// ExpireHolds releases the active holds past their expiry.
+func (r *UsecaseImplementation) ExpireHolds(ctx context.Context, now time.Time) error {
+	ctx, span := tracing.Start(ctx, "usecase.ExpireHolds")
+	defer span.End()
+
+	ids, err := r.Repository.Holds.ListExpired(ctx, now, holdsPerRun)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	var errs []error
+	for _, id := range ids {
+		hold, err := r.Repository.ReleaseHold(ctx, id, now)
+		switch {
+		case errors.Is(err, domain.ErrHoldClosed):
+			// Captured or released since it was listed.
+		case err != nil:
+			errs = append(errs, fmt.Errorf("hold %d: %w", id, err))
+		default:
+			logger.FromContext(ctx).WithField("subject", hold.Username).Infof("hold %d of %v coins expired", hold.ID, hold.Amount)
+		}
+	}
+	return tracing.Error(span, errors.Join(errs...))
+}
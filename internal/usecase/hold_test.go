@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"shop/domain"
+	"shop/internal/repository"
+	"shop/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBalance(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockHolds := new(MockHolds)
+	usecase := NewUsecase(&repository.Repository{Users: mockUsers, Holds: mockHolds}, nil, nil, config.Default())
+
+	holds := []domain.Hold{{ID: 1, Username: "user1", Amount: 30, Status: domain.HoldActive}}
+	mockUsers.On("GetUserByUsername", "user1").Return(&domain.User{Username: "user1", Balance: 100, Held: 30}, nil)
+	mockUsers.On("GetUserByUsername", "ghost").Return(&domain.User{}, nil)
+	mockHolds.On("ListActive", "user1").Return(holds, nil)
+
+	_, err := usecase.GetBalance(context.Background(), "ghost")
+	assert.ErrorIs(t, err, domain.ErrNoSuchUser)
+
+	balance, err := usecase.GetBalance(context.Background(), "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.Balance{Balance: 100, Held: 30, Available: 70, Holds: holds}, balance)
+}
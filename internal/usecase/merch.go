@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"shop/domain"
+	"shop/pkg/blob"
+	"shop/pkg/images"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (r *UsecaseImplementation) ListMerch(ctx context.Context, filter domain.MerchFilter) ([]domain.Merch, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ListMerch")
+	defer span.End()
+
+	merch, err := r.Repository.Merch.List(ctx, filter)
+	return merch, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) CreateMerch(ctx context.Context, merch *domain.Merch) (*domain.Merch, error) {
+	ctx, span := tracing.Start(ctx, "usecase.CreateMerch", attribute.String("item", merch.Name))
+	defer span.End()
+
+	merch, err := r.Repository.CreateMerch(ctx, merch)
+	return merch, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) UpdateMerch(ctx context.Context, name string, update domain.MerchUpdate) (*domain.Merch, error) {
+	ctx, span := tracing.Start(ctx, "usecase.UpdateMerch", attribute.String("item", name))
+	defer span.End()
+
+	merch, err := r.Repository.UpdateMerch(ctx, name, update)
+	return merch, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) ListCategories(ctx context.Context) ([]domain.Category, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ListCategories")
+	defer span.End()
+
+	categories, err := r.Repository.Categories.List(ctx)
+	return categories, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) CreateCategory(ctx context.Context, category *domain.Category) (*domain.Category, error) {
+	ctx, span := tracing.Start(ctx, "usecase.CreateCategory")
+	defer span.End()
+
+	existing, err := r.Repository.Categories.Get(ctx, category.Name)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if existing.Name != "" {
+		return nil, domain.ErrCategoryExists
+	}
+	if category, err = r.Repository.Categories.Create(ctx, category); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if err = r.audit(ctx, domain.AuditCategory, category.Name, nil, category); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return category, nil
+}
+
+// UpdateCategory renames the category for users, its name stays the same.
+func (r *UsecaseImplementation) UpdateCategory(ctx context.Context, category *domain.Category) (*domain.Category, error) {
+	ctx, span := tracing.Start(ctx, "usecase.UpdateCategory")
+	defer span.End()
+
+	existing, err := r.Repository.Categories.Get(ctx, category.Name)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if existing.Name == "" {
+		return nil, domain.ErrNoCategory
+	}
+	if err = r.Repository.Categories.Update(ctx, category); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if err = r.audit(ctx, domain.AuditCategory, category.Name, existing, category); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return category, nil
+}
+
+// DeleteCategory deletes a category without merch, items have to be moved
+// out of it first.
+func (r *UsecaseImplementation) DeleteCategory(ctx context.Context, name string) error {
+	ctx, span := tracing.Start(ctx, "usecase.DeleteCategory")
+	defer span.End()
+
+	existing, err := r.Repository.Categories.Get(ctx, name)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+	if existing.Name == "" {
+		return domain.ErrNoCategory
+	}
+	count, err := r.Repository.Merch.CountByCategory(ctx, name)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: %d items", domain.ErrCategoryInUse, count)
+	}
+	if err = r.Repository.Categories.Delete(ctx, name); err != nil {
+		return tracing.Error(span, err)
+	}
+	return tracing.Error(span, r.audit(ctx, domain.AuditCategory, name, existing, nil))
+}
+
+// AddMerchImage stores the image in the blob store and attaches it to the
+// item. Only PNG, JPEG and GIF images up to MAX_IMAGE_SIZE and
+// MAX_IMAGE_PIXELS are accepted.
+func (r *UsecaseImplementation) AddMerchImage(ctx context.Context, item string, upload io.Reader) (*domain.MerchImage, error) {
+	ctx, span := tracing.Start(ctx, "usecase.AddMerchImage", attribute.String("item", item))
+	defer span.End()
+
+	data, err := io.ReadAll(io.LimitReader(upload, r.Config.Blobs.MaxImageSize+1))
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if int64(len(data)) > r.Config.Blobs.MaxImageSize {
+		return nil, fmt.Errorf("%w: the limit is %d bytes", domain.ErrImageTooLarge, r.Config.Blobs.MaxImageSize)
+	}
+	info, err := images.Inspect(bytes.NewReader(data), r.Config.Blobs.MaxImagePixels)
+	if err != nil {
+		return nil, err
+	}
+	merch, err := r.Repository.Merch.GetMerchByName(ctx, item)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if merch.Name == "" {
+		return nil, domain.ErrNoMerch
+	}
+
+	key := "merch/" + uuid.NewString()
+	image := &domain.MerchImage{
+		MerchName:   merch.Name,
+		Key:         key,
+		ContentType: info.ContentType,
+		Width:       info.Width,
+		Height:      info.Height,
+	}
+	if err = r.Blobs.Put(ctx, key, bytes.NewReader(data)); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if image, err = r.Repository.Merch.CreateImage(ctx, image); err != nil {
+		r.deleteBlobs(ctx, key)
+		return nil, tracing.Error(span, err)
+	}
+	after := map[string]any{"id": image.ID, "content_type": image.ContentType, "width": image.Width, "height": image.Height}
+	if err = r.audit(ctx, domain.AuditMerchImage, item, nil, after); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return image, nil
+}
+
+// DeleteMerchImage detaches the image from the item and deletes its file
+// and thumbnails.
+func (r *UsecaseImplementation) DeleteMerchImage(ctx context.Context, item string, id int64) error {
+	ctx, span := tracing.Start(ctx, "usecase.DeleteMerchImage", attribute.String("item", item), attribute.Int64("id", id))
+	defer span.End()
+
+	image, err := r.Repository.Merch.GetImage(ctx, item, id)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+	if image.ID == 0 {
+		return domain.ErrNoMerchImage
+	}
+	if err = r.Repository.Merch.DeleteImage(ctx, id); err != nil {
+		return tracing.Error(span, err)
+	}
+	keys := []string{image.Key}
+	for _, width := range images.ThumbnailWidths {
+		keys = append(keys, thumbnailKey(image, width))
+	}
+	r.deleteBlobs(ctx, keys...)
+
+	before := map[string]any{"id": image.ID, "content_type": image.ContentType, "width": image.Width, "height": image.Height}
+	return tracing.Error(span, r.audit(ctx, domain.AuditMerchImage, item, before, nil))
+}
+
+// GetMerchThumbnail returns the image scaled down to width with its content
+// type. Thumbnails are made on the first request and kept in the blob store.
+func (r *UsecaseImplementation) GetMerchThumbnail(ctx context.Context, item string, id int64, width int) ([]byte, string, error) {
+	ctx, span := tracing.Start(ctx, "usecase.GetMerchThumbnail", attribute.Int64("id", id), attribute.Int("width", width))
+	defer span.End()
+
+	image, err := r.Repository.Merch.GetImage(ctx, item, id)
+	if err != nil {
+		return nil, "", tracing.Error(span, err)
+	}
+	if image.ID == 0 {
+		return nil, "", domain.ErrNoMerchImage
+	}
+	contentType := images.ThumbnailContentType(image.ContentType)
+
+	cached, err := r.Blobs.Get(ctx, thumbnailKey(image, width))
+	if err == nil {
+		defer cached.Close()
+		data, err := io.ReadAll(cached)
+		return data, contentType, tracing.Error(span, err)
+	}
+	if !errors.Is(err, blob.ErrNotFound) {
+		return nil, "", tracing.Error(span, err)
+	}
+
+	original, err := r.Blobs.Get(ctx, image.Key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, "", fmt.Errorf("%w: the file of image %d is missing", domain.ErrNoMerchImage, id)
+	}
+	if err != nil {
+		return nil, "", tracing.Error(span, err)
+	}
+	defer original.Close()
+
+	var thumbnail bytes.Buffer
+	if _, err = images.Thumbnail(&thumbnail, original, width); err != nil {
+		return nil, "", tracing.Error(span, err)
+	}
+	if err = r.Blobs.Put(ctx, thumbnailKey(image, width), bytes.NewReader(thumbnail.Bytes())); err != nil {
+		// The thumbnail is made again on the next request.
+		logger.FromContext(ctx).Warnf("failed to cache thumbnail of image %d: %v", id, err)
+	}
+	return thumbnail.Bytes(), contentType, nil
+}
+
+func thumbnailKey(image *domain.MerchImage, width int) string {
+	return fmt.Sprintf("thumbnails/%s/%d", path.Base(image.Key), width)
+}
+
+// deleteBlobs deletes files that are no longer referenced, failures only
+// leave orphaned files behind and are logged.
+func (r *UsecaseImplementation) deleteBlobs(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := r.Blobs.Delete(ctx, key); err != nil {
+			logger.FromContext(ctx).Warnf("failed to delete blob %s: %v", key, err)
+		}
+	}
+}
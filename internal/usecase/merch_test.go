@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+
+	"shop/domain"
+	"shop/internal/repository"
+	"shop/pkg/blob"
+	"shop/pkg/config"
+	"shop/pkg/images"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMerchImages(t *testing.T) {
+	mockMerch := new(MockMerch)
+	mockAudit := new(MockAudit)
+	blobs, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Default()
+	cfg.Blobs.MaxImageSize = 1 << 16
+	usecase := NewUsecase(&repository.Repository{Merch: mockMerch, Audit: mockAudit}, blobs, nil, cfg)
+
+	var upload bytes.Buffer
+	_ = png.Encode(&upload, image.NewNRGBA(image.Rect(0, 0, 400, 300)))
+	mockMerch.On("GetMerchByName", "hoody").Return(&domain.Merch{Name: "hoody", Price: 300}, nil)
+	mockMerch.On("CreateImage", mock.Anything).Return(nil).Once()
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditMerchImage && entry.Subject == "hoody"
+	})).Return(nil)
+
+	_, err = usecase.AddMerchImage(context.Background(), "hoody", bytes.NewReader(make([]byte, 1<<17)))
+	assert.ErrorIs(t, err, domain.ErrImageTooLarge)
+	_, err = usecase.AddMerchImage(context.Background(), "hoody", bytes.NewBufferString("<svg/>"))
+	assert.ErrorIs(t, err, images.ErrUnsupportedFormat)
+
+	created, err := usecase.AddMerchImage(context.Background(), "hoody", bytes.NewReader(upload.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", created.ContentType)
+	assert.Equal(t, 400, created.Width)
+	assert.Equal(t, 300, created.Height)
+
+	created.ID = 1
+	mockMerch.On("GetImage", "hoody", int64(1)).Return(created, nil)
+	mockMerch.On("GetImage", "hoody", int64(2)).Return(&domain.MerchImage{}, nil)
+	_, _, err = usecase.GetMerchThumbnail(context.Background(), "hoody", 2, 128)
+	assert.ErrorIs(t, err, domain.ErrNoMerchImage)
+
+	data, contentType, err := usecase.GetMerchThumbnail(context.Background(), "hoody", 1, 128)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	info, err := images.Inspect(bytes.NewReader(data), cfg.Blobs.MaxImagePixels)
+	assert.NoError(t, err)
+	assert.Equal(t, 128, info.Width)
+	assert.Equal(t, 96, info.Height)
+
+	// The thumbnail is cached, it is served even once the original is gone.
+	assert.NoError(t, blobs.Delete(context.Background(), created.Key))
+	cached, _, err := usecase.GetMerchThumbnail(context.Background(), "hoody", 1, 128)
+	assert.NoError(t, err)
+	assert.Equal(t, data, cached)
+
+	mockMerch.On("DeleteImage", int64(1)).Return(nil).Once()
+	assert.NoError(t, usecase.DeleteMerchImage(context.Background(), "hoody", 1))
+	_, err = blobs.Get(context.Background(), thumbnailKey(created, 128))
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+	mockMerch.AssertExpectations(t)
+}
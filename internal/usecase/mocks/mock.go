@@ -5,8 +5,11 @@
 package mock_usecase
 
 import (
+	context "context"
+	io "io"
 	reflect "reflect"
 	domain "shop/domain"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,77 +37,1057 @@ func (m *MockUsecase) EXPECT() *MockUsecaseMockRecorder {
 	return m.recorder
 }
 
-// Auth mocks_repo base method.
-func (m *MockUsecase) Auth(username, password string) (*domain.User, error) {
+// AcceptCoinRequest mocks base method.
+func (m *MockUsecase) AcceptCoinRequest(ctx context.Context, id int64, payer string) (*domain.CoinRequest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Auth", username, password)
+	ret := m.ctrl.Call(m, "AcceptCoinRequest", ctx, id, payer)
+	ret0, _ := ret[0].(*domain.CoinRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptCoinRequest indicates an expected call of AcceptCoinRequest.
+func (mr *MockUsecaseMockRecorder) AcceptCoinRequest(ctx, id, payer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptCoinRequest", reflect.TypeOf((*MockUsecase)(nil).AcceptCoinRequest), ctx, id, payer)
+}
+
+// AddMerchImage mocks base method.
+func (m *MockUsecase) AddMerchImage(ctx context.Context, item string, upload io.Reader) (*domain.MerchImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMerchImage", ctx, item, upload)
+	ret0, _ := ret[0].(*domain.MerchImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMerchImage indicates an expected call of AddMerchImage.
+func (mr *MockUsecaseMockRecorder) AddMerchImage(ctx, item, upload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMerchImage", reflect.TypeOf((*MockUsecase)(nil).AddMerchImage), ctx, item, upload)
+}
+
+// AdjustBalance mocks base method.
+func (m *MockUsecase) AdjustBalance(ctx context.Context, username string, amount float64, reason string) (*domain.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalance", ctx, username, amount, reason)
+	ret0, _ := ret[0].(*domain.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustBalance indicates an expected call of AdjustBalance.
+func (mr *MockUsecaseMockRecorder) AdjustBalance(ctx, username, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalance", reflect.TypeOf((*MockUsecase)(nil).AdjustBalance), ctx, username, amount, reason)
+}
+
+// ApprovePurchase mocks base method.
+func (m *MockUsecase) ApprovePurchase(ctx context.Context, id int64, approver string, admin bool) (*domain.PurchaseApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApprovePurchase", ctx, id, approver, admin)
+	ret0, _ := ret[0].(*domain.PurchaseApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApprovePurchase indicates an expected call of ApprovePurchase.
+func (mr *MockUsecaseMockRecorder) ApprovePurchase(ctx, id, approver, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApprovePurchase", reflect.TypeOf((*MockUsecase)(nil).ApprovePurchase), ctx, id, approver, admin)
+}
+
+// ApproveWalletPurchase mocks base method.
+func (m *MockUsecase) ApproveWalletPurchase(ctx context.Context, walletID, id int64, username string) (*domain.WalletPurchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveWalletPurchase", ctx, walletID, id, username)
+	ret0, _ := ret[0].(*domain.WalletPurchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveWalletPurchase indicates an expected call of ApproveWalletPurchase.
+func (mr *MockUsecaseMockRecorder) ApproveWalletPurchase(ctx, walletID, id, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveWalletPurchase", reflect.TypeOf((*MockUsecase)(nil).ApproveWalletPurchase), ctx, walletID, id, username)
+}
+
+// Auth mocks base method.
+func (m *MockUsecase) Auth(ctx context.Context, username, password string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Auth", ctx, username, password)
 	ret0, _ := ret[0].(*domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Auth indicates an expected call of Auth.
-func (mr *MockUsecaseMockRecorder) Auth(username, password interface{}) *gomock.Call {
+func (mr *MockUsecaseMockRecorder) Auth(ctx, username, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Auth", reflect.TypeOf((*MockUsecase)(nil).Auth), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Auth", reflect.TypeOf((*MockUsecase)(nil).Auth), ctx, username, password)
 }
 
-// CreatePurchase mocks_repo base method.
-func (m *MockUsecase) CreatePurchase(arg0, arg1 string) (*domain.Purchase, error) {
+// CancelCoinRequest mocks base method.
+func (m *MockUsecase) CancelCoinRequest(ctx context.Context, id int64, requester string) (*domain.CoinRequest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreatePurchase", arg0, arg1)
-	ret0, _ := ret[0].(*domain.Purchase)
+	ret := m.ctrl.Call(m, "CancelCoinRequest", ctx, id, requester)
+	ret0, _ := ret[0].(*domain.CoinRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelCoinRequest indicates an expected call of CancelCoinRequest.
+func (mr *MockUsecaseMockRecorder) CancelCoinRequest(ctx, id, requester interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelCoinRequest", reflect.TypeOf((*MockUsecase)(nil).CancelCoinRequest), ctx, id, requester)
+}
+
+// CancelScheduledTransfer mocks base method.
+func (m *MockUsecase) CancelScheduledTransfer(ctx context.Context, id int64, sender string) (*domain.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduledTransfer", ctx, id, sender)
+	ret0, _ := ret[0].(*domain.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelScheduledTransfer indicates an expected call of CancelScheduledTransfer.
+func (mr *MockUsecaseMockRecorder) CancelScheduledTransfer(ctx, id, sender interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledTransfer", reflect.TypeOf((*MockUsecase)(nil).CancelScheduledTransfer), ctx, id, sender)
+}
+
+// CaptureHold mocks base method.
+func (m *MockUsecase) CaptureHold(ctx context.Context, id int64, amount float64) (*domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, id, amount)
+	ret0, _ := ret[0].(*domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockUsecaseMockRecorder) CaptureHold(ctx, id, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockUsecase)(nil).CaptureHold), ctx, id, amount)
+}
+
+// ContributeToWallet mocks base method.
+func (m *MockUsecase) ContributeToWallet(ctx context.Context, walletID int64, username string, amount float64) (*domain.WalletContribution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContributeToWallet", ctx, walletID, username, amount)
+	ret0, _ := ret[0].(*domain.WalletContribution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContributeToWallet indicates an expected call of ContributeToWallet.
+func (mr *MockUsecaseMockRecorder) ContributeToWallet(ctx, walletID, username, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContributeToWallet", reflect.TypeOf((*MockUsecase)(nil).ContributeToWallet), ctx, walletID, username, amount)
+}
+
+// CreateAllowancePolicy mocks base method.
+func (m *MockUsecase) CreateAllowancePolicy(arg0 context.Context, arg1 *domain.AllowancePolicy) (*domain.AllowancePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAllowancePolicy", arg0, arg1)
+	ret0, _ := ret[0].(*domain.AllowancePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAllowancePolicy indicates an expected call of CreateAllowancePolicy.
+func (mr *MockUsecaseMockRecorder) CreateAllowancePolicy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAllowancePolicy", reflect.TypeOf((*MockUsecase)(nil).CreateAllowancePolicy), arg0, arg1)
+}
+
+// CreateCategory mocks base method.
+func (m *MockUsecase) CreateCategory(arg0 context.Context, arg1 *domain.Category) (*domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategory", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategory indicates an expected call of CreateCategory.
+func (mr *MockUsecaseMockRecorder) CreateCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*MockUsecase)(nil).CreateCategory), arg0, arg1)
+}
+
+// CreateCoinRequest mocks base method.
+func (m *MockUsecase) CreateCoinRequest(ctx context.Context, requester, payer string, amount float64, message string) (*domain.CoinRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCoinRequest", ctx, requester, payer, amount, message)
+	ret0, _ := ret[0].(*domain.CoinRequest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// CreateCoinRequest indicates an expected call of CreateCoinRequest.
+func (mr *MockUsecaseMockRecorder) CreateCoinRequest(ctx, requester, payer, amount, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCoinRequest", reflect.TypeOf((*MockUsecase)(nil).CreateCoinRequest), ctx, requester, payer, amount, message)
+}
+
+// CreateHold mocks base method.
+func (m *MockUsecase) CreateHold(ctx context.Context, username string, amount float64, reason string, expiresAt time.Time) (*domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", ctx, username, amount, reason, expiresAt)
+	ret0, _ := ret[0].(*domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockUsecaseMockRecorder) CreateHold(ctx, username, amount, reason, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockUsecase)(nil).CreateHold), ctx, username, amount, reason, expiresAt)
+}
+
+// CreateMerch mocks base method.
+func (m *MockUsecase) CreateMerch(arg0 context.Context, arg1 *domain.Merch) (*domain.Merch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMerch", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Merch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMerch indicates an expected call of CreateMerch.
+func (mr *MockUsecaseMockRecorder) CreateMerch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMerch", reflect.TypeOf((*MockUsecase)(nil).CreateMerch), arg0, arg1)
+}
+
+// CreatePromoCode mocks base method.
+func (m *MockUsecase) CreatePromoCode(arg0 context.Context, arg1 *domain.PromoCode) (*domain.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromoCode", arg0, arg1)
+	ret0, _ := ret[0].(*domain.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePromoCode indicates an expected call of CreatePromoCode.
+func (mr *MockUsecaseMockRecorder) CreatePromoCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromoCode", reflect.TypeOf((*MockUsecase)(nil).CreatePromoCode), arg0, arg1)
+}
+
+// CreatePurchase mocks base method.
+func (m *MockUsecase) CreatePurchase(ctx context.Context, username, merchName string, gift domain.Gift, promoCodes []string) (*domain.Purchase, *domain.PurchaseApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePurchase", ctx, username, merchName, gift, promoCodes)
+	ret0, _ := ret[0].(*domain.Purchase)
+	ret1, _ := ret[1].(*domain.PurchaseApproval)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // CreatePurchase indicates an expected call of CreatePurchase.
-func (mr *MockUsecaseMockRecorder) CreatePurchase(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockUsecaseMockRecorder) CreatePurchase(ctx, username, merchName, gift, promoCodes interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePurchase", reflect.TypeOf((*MockUsecase)(nil).CreatePurchase), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePurchase", reflect.TypeOf((*MockUsecase)(nil).CreatePurchase), ctx, username, merchName, gift, promoCodes)
 }
 
-// CreateTransaction mocks_repo base method.
-func (m *MockUsecase) CreateTransaction(arg0, arg1 string, arg2 float64) (*domain.Transaction, error) {
+// CreateTransaction mocks base method.
+func (m *MockUsecase) CreateTransaction(ctx context.Context, receiver, sender string, money float64, note domain.TransferNote) (*domain.Transaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTransaction", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "CreateTransaction", ctx, receiver, sender, money, note)
 	ret0, _ := ret[0].(*domain.Transaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateTransaction indicates an expected call of CreateTransaction.
-func (mr *MockUsecaseMockRecorder) CreateTransaction(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockUsecaseMockRecorder) CreateTransaction(ctx, receiver, sender, money, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockUsecase)(nil).CreateTransaction), ctx, receiver, sender, money, note)
+}
+
+// CreateTransactionBatch mocks base method.
+func (m *MockUsecase) CreateTransactionBatch(ctx context.Context, sender string, transfers []domain.Transaction) (*domain.TransferBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransactionBatch", ctx, sender, transfers)
+	ret0, _ := ret[0].(*domain.TransferBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransactionBatch indicates an expected call of CreateTransactionBatch.
+func (mr *MockUsecaseMockRecorder) CreateTransactionBatch(ctx, sender, transfers interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransactionBatch", reflect.TypeOf((*MockUsecase)(nil).CreateTransactionBatch), ctx, sender, transfers)
+}
+
+// CreateWallet mocks base method.
+func (m *MockUsecase) CreateWallet(ctx context.Context, creator, name string, quorum int, members []string) (*domain.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWallet", ctx, creator, name, quorum, members)
+	ret0, _ := ret[0].(*domain.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWallet indicates an expected call of CreateWallet.
+func (mr *MockUsecaseMockRecorder) CreateWallet(ctx, creator, name, quorum, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockUsecase)(nil).CreateWallet), ctx, creator, name, quorum, members)
+}
+
+// DeclineCoinRequest mocks base method.
+func (m *MockUsecase) DeclineCoinRequest(ctx context.Context, id int64, payer string) (*domain.CoinRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclineCoinRequest", ctx, id, payer)
+	ret0, _ := ret[0].(*domain.CoinRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeclineCoinRequest indicates an expected call of DeclineCoinRequest.
+func (mr *MockUsecaseMockRecorder) DeclineCoinRequest(ctx, id, payer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclineCoinRequest", reflect.TypeOf((*MockUsecase)(nil).DeclineCoinRequest), ctx, id, payer)
+}
+
+// DeleteCategory mocks base method.
+func (m *MockUsecase) DeleteCategory(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory.
+func (mr *MockUsecaseMockRecorder) DeleteCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockUsecase)(nil).DeleteCategory), arg0, arg1)
+}
+
+// DeleteMerchImage mocks base method.
+func (m *MockUsecase) DeleteMerchImage(ctx context.Context, item string, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMerchImage", ctx, item, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMerchImage indicates an expected call of DeleteMerchImage.
+func (mr *MockUsecaseMockRecorder) DeleteMerchImage(ctx, item, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMerchImage", reflect.TypeOf((*MockUsecase)(nil).DeleteMerchImage), ctx, item, id)
+}
+
+// EscalatePurchaseApprovals mocks base method.
+func (m *MockUsecase) EscalatePurchaseApprovals(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EscalatePurchaseApprovals", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EscalatePurchaseApprovals indicates an expected call of EscalatePurchaseApprovals.
+func (mr *MockUsecaseMockRecorder) EscalatePurchaseApprovals(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EscalatePurchaseApprovals", reflect.TypeOf((*MockUsecase)(nil).EscalatePurchaseApprovals), ctx, now)
+}
+
+// ExpireCoinRequests mocks base method.
+func (m *MockUsecase) ExpireCoinRequests(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireCoinRequests", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpireCoinRequests indicates an expected call of ExpireCoinRequests.
+func (mr *MockUsecaseMockRecorder) ExpireCoinRequests(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireCoinRequests", reflect.TypeOf((*MockUsecase)(nil).ExpireCoinRequests), ctx, now)
+}
+
+// ExpireCoins mocks base method.
+func (m *MockUsecase) ExpireCoins(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireCoins", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpireCoins indicates an expected call of ExpireCoins.
+func (mr *MockUsecaseMockRecorder) ExpireCoins(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireCoins", reflect.TypeOf((*MockUsecase)(nil).ExpireCoins), ctx, now)
+}
+
+// ExpireHolds mocks base method.
+func (m *MockUsecase) ExpireHolds(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHolds", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpireHolds indicates an expected call of ExpireHolds.
+func (mr *MockUsecaseMockRecorder) ExpireHolds(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHolds", reflect.TypeOf((*MockUsecase)(nil).ExpireHolds), ctx, now)
+}
+
+// GetAdjustmentsForUserByUsername mocks base method.
+func (m *MockUsecase) GetAdjustmentsForUserByUsername(arg0 context.Context, arg1 string) ([]domain.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdjustmentsForUserByUsername", arg0, arg1)
+	ret0, _ := ret[0].([]domain.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdjustmentsForUserByUsername indicates an expected call of GetAdjustmentsForUserByUsername.
+func (mr *MockUsecaseMockRecorder) GetAdjustmentsForUserByUsername(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdjustmentsForUserByUsername", reflect.TypeOf((*MockUsecase)(nil).GetAdjustmentsForUserByUsername), arg0, arg1)
+}
+
+// GetAuditLog mocks base method.
+func (m *MockUsecase) GetAuditLog(arg0 context.Context, arg1 domain.AuditFilter) ([]domain.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLog", arg0, arg1)
+	ret0, _ := ret[0].([]domain.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLog indicates an expected call of GetAuditLog.
+func (mr *MockUsecaseMockRecorder) GetAuditLog(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLog", reflect.TypeOf((*MockUsecase)(nil).GetAuditLog), arg0, arg1)
+}
+
+// GetBalance mocks base method.
+func (m *MockUsecase) GetBalance(arg0 context.Context, arg1 string) (*domain.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Balance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockUsecaseMockRecorder) GetBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockUsecase)(nil).GetBalance), arg0, arg1)
+}
+
+// GetCoinExpirations mocks base method.
+func (m *MockUsecase) GetCoinExpirations(arg0 context.Context, arg1 string) ([]domain.CoinExpiration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCoinExpirations", arg0, arg1)
+	ret0, _ := ret[0].([]domain.CoinExpiration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCoinExpirations indicates an expected call of GetCoinExpirations.
+func (mr *MockUsecaseMockRecorder) GetCoinExpirations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCoinExpirations", reflect.TypeOf((*MockUsecase)(nil).GetCoinExpirations), arg0, arg1)
+}
+
+// GetInventory mocks base method.
+func (m *MockUsecase) GetInventory(arg0 context.Context, arg1 string) ([]domain.InventoryItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInventory", arg0, arg1)
+	ret0, _ := ret[0].([]domain.InventoryItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInventory indicates an expected call of GetInventory.
+func (mr *MockUsecaseMockRecorder) GetInventory(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockUsecase)(nil).CreateTransaction), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInventory", reflect.TypeOf((*MockUsecase)(nil).GetInventory), arg0, arg1)
 }
 
-// GetPurchasesForUserByUserGUID mocks_repo base method.
-func (m *MockUsecase) GetPurchasesForUserByUsername(arg0 string) ([]domain.Purchase, error) {
+// GetKudosFeed mocks base method.
+func (m *MockUsecase) GetKudosFeed(arg0 context.Context, arg1 domain.FeedFilter) ([]domain.Transaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPurchasesForUserByUserGUID", arg0)
+	ret := m.ctrl.Call(m, "GetKudosFeed", arg0, arg1)
+	ret0, _ := ret[0].([]domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKudosFeed indicates an expected call of GetKudosFeed.
+func (mr *MockUsecaseMockRecorder) GetKudosFeed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKudosFeed", reflect.TypeOf((*MockUsecase)(nil).GetKudosFeed), arg0, arg1)
+}
+
+// GetMerchThumbnail mocks base method.
+func (m *MockUsecase) GetMerchThumbnail(ctx context.Context, item string, id int64, width int) ([]byte, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMerchThumbnail", ctx, item, id, width)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetMerchThumbnail indicates an expected call of GetMerchThumbnail.
+func (mr *MockUsecaseMockRecorder) GetMerchThumbnail(ctx, item, id, width interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerchThumbnail", reflect.TypeOf((*MockUsecase)(nil).GetMerchThumbnail), ctx, item, id, width)
+}
+
+// GetPriceTimeline mocks base method.
+func (m *MockUsecase) GetPriceTimeline(arg0 context.Context, arg1 string) (*domain.PriceTimeline, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriceTimeline", arg0, arg1)
+	ret0, _ := ret[0].(*domain.PriceTimeline)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPriceTimeline indicates an expected call of GetPriceTimeline.
+func (mr *MockUsecaseMockRecorder) GetPriceTimeline(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceTimeline", reflect.TypeOf((*MockUsecase)(nil).GetPriceTimeline), arg0, arg1)
+}
+
+// GetPurchasesForUserByUsername mocks base method.
+func (m *MockUsecase) GetPurchasesForUserByUsername(arg0 context.Context, arg1 string) ([]domain.Purchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPurchasesForUserByUsername", arg0, arg1)
 	ret0, _ := ret[0].([]domain.Purchase)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPurchasesForUserByUserGUID indicates an expected call of GetPurchasesForUserByUserGUID.
-func (mr *MockUsecaseMockRecorder) GetPurchasesForUserByUserGUID(arg0 interface{}) *gomock.Call {
+// GetPurchasesForUserByUsername indicates an expected call of GetPurchasesForUserByUsername.
+func (mr *MockUsecaseMockRecorder) GetPurchasesForUserByUsername(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurchasesForUserByUserGUID", reflect.TypeOf((*MockUsecase)(nil).GetPurchasesForUserByUsername), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurchasesForUserByUsername", reflect.TypeOf((*MockUsecase)(nil).GetPurchasesForUserByUsername), arg0, arg1)
 }
 
-// GetTransactionsForUserByUserGUID mocks_repo base method.
-func (m *MockUsecase) GetTransactionsForUserByUsername(arg0 string) ([]domain.Transaction, error) {
+// GetRole mocks base method.
+func (m *MockUsecase) GetRole(ctx context.Context, username string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransactionsForUserByUserGUID", arg0)
+	ret := m.ctrl.Call(m, "GetRole", ctx, username)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRole indicates an expected call of GetRole.
+func (mr *MockUsecaseMockRecorder) GetRole(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRole", reflect.TypeOf((*MockUsecase)(nil).GetRole), ctx, username)
+}
+
+// GetTransactionsForUserByUsername mocks base method.
+func (m *MockUsecase) GetTransactionsForUserByUsername(arg0 context.Context, arg1 string) ([]domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionsForUserByUsername", arg0, arg1)
 	ret0, _ := ret[0].([]domain.Transaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTransactionsForUserByUserGUID indicates an expected call of GetTransactionsForUserByUserGUID.
-func (mr *MockUsecaseMockRecorder) GetTransactionsForUserByUserGUID(arg0 interface{}) *gomock.Call {
+// GetTransactionsForUserByUsername indicates an expected call of GetTransactionsForUserByUsername.
+func (mr *MockUsecaseMockRecorder) GetTransactionsForUserByUsername(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionsForUserByUsername", reflect.TypeOf((*MockUsecase)(nil).GetTransactionsForUserByUsername), arg0, arg1)
+}
+
+// GetWalletInfo mocks base method.
+func (m *MockUsecase) GetWalletInfo(ctx context.Context, id int64, username string) (*domain.WalletInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletInfo", ctx, id, username)
+	ret0, _ := ret[0].(*domain.WalletInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletInfo indicates an expected call of GetWalletInfo.
+func (mr *MockUsecaseMockRecorder) GetWalletInfo(ctx, id, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletInfo", reflect.TypeOf((*MockUsecase)(nil).GetWalletInfo), ctx, id, username)
+}
+
+// GetWishlist mocks base method.
+func (m *MockUsecase) GetWishlist(arg0 context.Context, arg1 string) (*domain.Wishlist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWishlist", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Wishlist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWishlist indicates an expected call of GetWishlist.
+func (mr *MockUsecaseMockRecorder) GetWishlist(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWishlist", reflect.TypeOf((*MockUsecase)(nil).GetWishlist), arg0, arg1)
+}
+
+// IssueCoins mocks base method.
+func (m *MockUsecase) IssueCoins(arg0 context.Context, arg1 []domain.BalanceAdjustment) ([]domain.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueCoins", arg0, arg1)
+	ret0, _ := ret[0].([]domain.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueCoins indicates an expected call of IssueCoins.
+func (mr *MockUsecaseMockRecorder) IssueCoins(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueCoins", reflect.TypeOf((*MockUsecase)(nil).IssueCoins), arg0, arg1)
+}
+
+// ListAllowancePolicies mocks base method.
+func (m *MockUsecase) ListAllowancePolicies(arg0 context.Context) ([]domain.AllowancePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllowancePolicies", arg0)
+	ret0, _ := ret[0].([]domain.AllowancePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllowancePolicies indicates an expected call of ListAllowancePolicies.
+func (mr *MockUsecaseMockRecorder) ListAllowancePolicies(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllowancePolicies", reflect.TypeOf((*MockUsecase)(nil).ListAllowancePolicies), arg0)
+}
+
+// ListAllowanceRuns mocks base method.
+func (m *MockUsecase) ListAllowanceRuns(arg0 context.Context, arg1 int64) ([]domain.AllowanceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllowanceRuns", arg0, arg1)
+	ret0, _ := ret[0].([]domain.AllowanceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllowanceRuns indicates an expected call of ListAllowanceRuns.
+func (mr *MockUsecaseMockRecorder) ListAllowanceRuns(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllowanceRuns", reflect.TypeOf((*MockUsecase)(nil).ListAllowanceRuns), arg0, arg1)
+}
+
+// ListCategories mocks base method.
+func (m *MockUsecase) ListCategories(arg0 context.Context) ([]domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategories", arg0)
+	ret0, _ := ret[0].([]domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategories indicates an expected call of ListCategories.
+func (mr *MockUsecaseMockRecorder) ListCategories(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategories", reflect.TypeOf((*MockUsecase)(nil).ListCategories), arg0)
+}
+
+// ListMerch mocks base method.
+func (m *MockUsecase) ListMerch(arg0 context.Context, arg1 domain.MerchFilter) ([]domain.Merch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerch", arg0, arg1)
+	ret0, _ := ret[0].([]domain.Merch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerch indicates an expected call of ListMerch.
+func (mr *MockUsecaseMockRecorder) ListMerch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerch", reflect.TypeOf((*MockUsecase)(nil).ListMerch), arg0, arg1)
+}
+
+// ListNotifications mocks base method.
+func (m *MockUsecase) ListNotifications(arg0 context.Context, arg1 string) ([]domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", arg0, arg1)
+	ret0, _ := ret[0].([]domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockUsecaseMockRecorder) ListNotifications(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockUsecase)(nil).ListNotifications), arg0, arg1)
+}
+
+// ListPendingRequests mocks base method.
+func (m *MockUsecase) ListPendingRequests(arg0 context.Context, arg1 string) (*domain.PendingRequests, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingRequests", arg0, arg1)
+	ret0, _ := ret[0].(*domain.PendingRequests)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingRequests indicates an expected call of ListPendingRequests.
+func (mr *MockUsecaseMockRecorder) ListPendingRequests(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingRequests", reflect.TypeOf((*MockUsecase)(nil).ListPendingRequests), arg0, arg1)
+}
+
+// ListPromoCodes mocks base method.
+func (m *MockUsecase) ListPromoCodes(arg0 context.Context) ([]domain.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromoCodes", arg0)
+	ret0, _ := ret[0].([]domain.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromoCodes indicates an expected call of ListPromoCodes.
+func (mr *MockUsecaseMockRecorder) ListPromoCodes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromoCodes", reflect.TypeOf((*MockUsecase)(nil).ListPromoCodes), arg0)
+}
+
+// ListPurchaseApprovals mocks base method.
+func (m *MockUsecase) ListPurchaseApprovals(ctx context.Context, username string, admin bool) (*domain.PendingApprovals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPurchaseApprovals", ctx, username, admin)
+	ret0, _ := ret[0].(*domain.PendingApprovals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPurchaseApprovals indicates an expected call of ListPurchaseApprovals.
+func (mr *MockUsecaseMockRecorder) ListPurchaseApprovals(ctx, username, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPurchaseApprovals", reflect.TypeOf((*MockUsecase)(nil).ListPurchaseApprovals), ctx, username, admin)
+}
+
+// ListScheduledTransfers mocks base method.
+func (m *MockUsecase) ListScheduledTransfers(arg0 context.Context, arg1 string) ([]domain.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]domain.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledTransfers indicates an expected call of ListScheduledTransfers.
+func (mr *MockUsecaseMockRecorder) ListScheduledTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledTransfers", reflect.TypeOf((*MockUsecase)(nil).ListScheduledTransfers), arg0, arg1)
+}
+
+// ListWallets mocks base method.
+func (m *MockUsecase) ListWallets(arg0 context.Context, arg1 string) ([]domain.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWallets", arg0, arg1)
+	ret0, _ := ret[0].([]domain.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWallets indicates an expected call of ListWallets.
+func (mr *MockUsecaseMockRecorder) ListWallets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWallets", reflect.TypeOf((*MockUsecase)(nil).ListWallets), arg0, arg1)
+}
+
+// MarkNotificationsRead mocks base method.
+func (m *MockUsecase) MarkNotificationsRead(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationsRead", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkNotificationsRead indicates an expected call of MarkNotificationsRead.
+func (mr *MockUsecaseMockRecorder) MarkNotificationsRead(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationsRead", reflect.TypeOf((*MockUsecase)(nil).MarkNotificationsRead), arg0, arg1)
+}
+
+// PreviewAllowance mocks base method.
+func (m *MockUsecase) PreviewAllowance(arg0 context.Context, arg1 int64) (*domain.AllowancePreview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewAllowance", arg0, arg1)
+	ret0, _ := ret[0].(*domain.AllowancePreview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewAllowance indicates an expected call of PreviewAllowance.
+func (mr *MockUsecaseMockRecorder) PreviewAllowance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewAllowance", reflect.TypeOf((*MockUsecase)(nil).PreviewAllowance), arg0, arg1)
+}
+
+// ProposeWalletPurchase mocks base method.
+func (m *MockUsecase) ProposeWalletPurchase(ctx context.Context, walletID int64, username, merchName string) (*domain.WalletPurchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProposeWalletPurchase", ctx, walletID, username, merchName)
+	ret0, _ := ret[0].(*domain.WalletPurchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProposeWalletPurchase indicates an expected call of ProposeWalletPurchase.
+func (mr *MockUsecaseMockRecorder) ProposeWalletPurchase(ctx, walletID, username, merchName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProposeWalletPurchase", reflect.TypeOf((*MockUsecase)(nil).ProposeWalletPurchase), ctx, walletID, username, merchName)
+}
+
+// RejectPurchase mocks base method.
+func (m *MockUsecase) RejectPurchase(ctx context.Context, id int64, approver string, admin bool) (*domain.PurchaseApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectPurchase", ctx, id, approver, admin)
+	ret0, _ := ret[0].(*domain.PurchaseApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectPurchase indicates an expected call of RejectPurchase.
+func (mr *MockUsecaseMockRecorder) RejectPurchase(ctx, id, approver, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectPurchase", reflect.TypeOf((*MockUsecase)(nil).RejectPurchase), ctx, id, approver, admin)
+}
+
+// RejectWalletPurchase mocks base method.
+func (m *MockUsecase) RejectWalletPurchase(ctx context.Context, walletID, id int64, username string) (*domain.WalletPurchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectWalletPurchase", ctx, walletID, id, username)
+	ret0, _ := ret[0].(*domain.WalletPurchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectWalletPurchase indicates an expected call of RejectWalletPurchase.
+func (mr *MockUsecaseMockRecorder) RejectWalletPurchase(ctx, walletID, id, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectWalletPurchase", reflect.TypeOf((*MockUsecase)(nil).RejectWalletPurchase), ctx, walletID, id, username)
+}
+
+// ReleaseHold mocks base method.
+func (m *MockUsecase) ReleaseHold(arg0 context.Context, arg1 int64) (*domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseHold", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseHold indicates an expected call of ReleaseHold.
+func (mr *MockUsecaseMockRecorder) ReleaseHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseHold", reflect.TypeOf((*MockUsecase)(nil).ReleaseHold), arg0, arg1)
+}
+
+// RemoveWalletMember mocks base method.
+func (m *MockUsecase) RemoveWalletMember(ctx context.Context, walletID int64, actor, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveWalletMember", ctx, walletID, actor, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveWalletMember indicates an expected call of RemoveWalletMember.
+func (mr *MockUsecaseMockRecorder) RemoveWalletMember(ctx, walletID, actor, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveWalletMember", reflect.TypeOf((*MockUsecase)(nil).RemoveWalletMember), ctx, walletID, actor, username)
+}
+
+// RemoveWishlistItem mocks base method.
+func (m *MockUsecase) RemoveWishlistItem(ctx context.Context, username, item string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveWishlistItem", ctx, username, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveWishlistItem indicates an expected call of RemoveWishlistItem.
+func (mr *MockUsecaseMockRecorder) RemoveWishlistItem(ctx, username, item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveWishlistItem", reflect.TypeOf((*MockUsecase)(nil).RemoveWishlistItem), ctx, username, item)
+}
+
+// RunAllowance mocks base method.
+func (m *MockUsecase) RunAllowance(arg0 context.Context, arg1 int64) (*domain.AllowanceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunAllowance", arg0, arg1)
+	ret0, _ := ret[0].(*domain.AllowanceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunAllowance indicates an expected call of RunAllowance.
+func (mr *MockUsecaseMockRecorder) RunAllowance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunAllowance", reflect.TypeOf((*MockUsecase)(nil).RunAllowance), arg0, arg1)
+}
+
+// RunDueAllowances mocks base method.
+func (m *MockUsecase) RunDueAllowances(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunDueAllowances", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunDueAllowances indicates an expected call of RunDueAllowances.
+func (mr *MockUsecaseMockRecorder) RunDueAllowances(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunDueAllowances", reflect.TypeOf((*MockUsecase)(nil).RunDueAllowances), ctx, now)
+}
+
+// RunScheduledTransfers mocks base method.
+func (m *MockUsecase) RunScheduledTransfers(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunScheduledTransfers", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunScheduledTransfers indicates an expected call of RunScheduledTransfers.
+func (mr *MockUsecaseMockRecorder) RunScheduledTransfers(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunScheduledTransfers", reflect.TypeOf((*MockUsecase)(nil).RunScheduledTransfers), ctx, now)
+}
+
+// SaveWalletMember mocks base method.
+func (m *MockUsecase) SaveWalletMember(ctx context.Context, actor string, member *domain.WalletMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWalletMember", ctx, actor, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWalletMember indicates an expected call of SaveWalletMember.
+func (mr *MockUsecaseMockRecorder) SaveWalletMember(ctx, actor, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWalletMember", reflect.TypeOf((*MockUsecase)(nil).SaveWalletMember), ctx, actor, member)
+}
+
+// SaveWishlistItem mocks base method.
+func (m *MockUsecase) SaveWishlistItem(arg0 context.Context, arg1 *domain.WishlistItem) (*domain.WishlistItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWishlistItem", arg0, arg1)
+	ret0, _ := ret[0].(*domain.WishlistItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveWishlistItem indicates an expected call of SaveWishlistItem.
+func (mr *MockUsecaseMockRecorder) SaveWishlistItem(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWishlistItem", reflect.TypeOf((*MockUsecase)(nil).SaveWishlistItem), arg0, arg1)
+}
+
+// ScheduleTransfer mocks base method.
+func (m *MockUsecase) ScheduleTransfer(ctx context.Context, receiver, sender string, money float64, note domain.TransferNote, executeAt time.Time) (*domain.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleTransfer", ctx, receiver, sender, money, note, executeAt)
+	ret0, _ := ret[0].(*domain.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleTransfer indicates an expected call of ScheduleTransfer.
+func (mr *MockUsecaseMockRecorder) ScheduleTransfer(ctx, receiver, sender, money, note, executeAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleTransfer", reflect.TypeOf((*MockUsecase)(nil).ScheduleTransfer), ctx, receiver, sender, money, note, executeAt)
+}
+
+// SendWishlistAlerts mocks base method.
+func (m *MockUsecase) SendWishlistAlerts(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendWishlistAlerts", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendWishlistAlerts indicates an expected call of SendWishlistAlerts.
+func (mr *MockUsecaseMockRecorder) SendWishlistAlerts(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWishlistAlerts", reflect.TypeOf((*MockUsecase)(nil).SendWishlistAlerts), ctx, now)
+}
+
+// SetAllowancePaused mocks base method.
+func (m *MockUsecase) SetAllowancePaused(ctx context.Context, id int64, paused bool) (*domain.AllowancePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAllowancePaused", ctx, id, paused)
+	ret0, _ := ret[0].(*domain.AllowancePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetAllowancePaused indicates an expected call of SetAllowancePaused.
+func (mr *MockUsecaseMockRecorder) SetAllowancePaused(ctx, id, paused interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAllowancePaused", reflect.TypeOf((*MockUsecase)(nil).SetAllowancePaused), ctx, id, paused)
+}
+
+// SetPromoCodeActive mocks base method.
+func (m *MockUsecase) SetPromoCodeActive(ctx context.Context, code string, active bool) (*domain.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPromoCodeActive", ctx, code, active)
+	ret0, _ := ret[0].(*domain.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPromoCodeActive indicates an expected call of SetPromoCodeActive.
+func (mr *MockUsecaseMockRecorder) SetPromoCodeActive(ctx, code, active interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPromoCodeActive", reflect.TypeOf((*MockUsecase)(nil).SetPromoCodeActive), ctx, code, active)
+}
+
+// UpdateCategory mocks base method.
+func (m *MockUsecase) UpdateCategory(arg0 context.Context, arg1 *domain.Category) (*domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategory", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCategory indicates an expected call of UpdateCategory.
+func (mr *MockUsecaseMockRecorder) UpdateCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*MockUsecase)(nil).UpdateCategory), arg0, arg1)
+}
+
+// UpdateMerch mocks base method.
+func (m *MockUsecase) UpdateMerch(ctx context.Context, name string, update domain.MerchUpdate) (*domain.Merch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMerch", ctx, name, update)
+	ret0, _ := ret[0].(*domain.Merch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMerch indicates an expected call of UpdateMerch.
+func (mr *MockUsecaseMockRecorder) UpdateMerch(ctx, name, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMerch", reflect.TypeOf((*MockUsecase)(nil).UpdateMerch), ctx, name, update)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockUsecase) UpdateProfile(ctx context.Context, username string, department, manager *string, active *bool) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, username, department, manager, active)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockUsecaseMockRecorder) UpdateProfile(ctx, username, department, manager, active interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionsForUserByUserGUID", reflect.TypeOf((*MockUsecase)(nil).GetTransactionsForUserByUsername), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUsecase)(nil).UpdateProfile), ctx, username, department, manager, active)
 }
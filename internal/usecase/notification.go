@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/tracing"
+)
+
+const notificationsLimit = 50
+
+// ListNotifications returns the user's latest notifications, newest first.
+func (r *UsecaseImplementation) ListNotifications(ctx context.Context, username string) ([]domain.Notification, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ListNotifications")
+	defer span.End()
+
+	notifications, err := r.Repository.Notifications.List(ctx, username, notificationsLimit)
+	return notifications, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) MarkNotificationsRead(ctx context.Context, username string) (int64, error) {
+	ctx, span := tracing.Start(ctx, "usecase.MarkNotificationsRead")
+	defer span.End()
+
+	marked, err := r.Repository.Notifications.MarkRead(ctx, username, time.Now())
+	return marked, tracing.Error(span, err)
+}
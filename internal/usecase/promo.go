@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"shop/domain"
+	"shop/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CreatePromoCode creates an active code, the items it is limited to must exist.
+func (r *UsecaseImplementation) CreatePromoCode(ctx context.Context, code *domain.PromoCode) (*domain.PromoCode, error) {
+	ctx, span := tracing.Start(ctx, "usecase.CreatePromoCode")
+	defer span.End()
+
+	existing, err := r.Repository.PromoCodes.Get(ctx, code.Code)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if existing.Code != "" {
+		return nil, domain.ErrPromoCodeExists
+	}
+	for _, item := range code.Items {
+		merch, err := r.Repository.Merch.GetMerchByName(ctx, item)
+		if err != nil {
+			return nil, tracing.Error(span, err)
+		}
+		if merch.Name == "" {
+			return nil, fmt.Errorf("%w: %s", domain.ErrNoMerch, item)
+		}
+	}
+
+	code.Active = true
+	code, err = r.Repository.PromoCodes.Create(ctx, code)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if err = r.audit(ctx, domain.AuditPromoCode, code.Code, nil, code); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return code, nil
+}
+
+func (r *UsecaseImplementation) ListPromoCodes(ctx context.Context) ([]domain.PromoCode, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ListPromoCodes")
+	defer span.End()
+
+	codes, err := r.Repository.PromoCodes.List(ctx)
+	return codes, tracing.Error(span, err)
+}
+
+// SetPromoCodeActive enables or disables a code, purchases already made with
+// it keep their discount.
+func (r *UsecaseImplementation) SetPromoCodeActive(ctx context.Context, code string, active bool) (*domain.PromoCode, error) {
+	ctx, span := tracing.Start(ctx, "usecase.SetPromoCodeActive", attribute.Bool("active", active))
+	defer span.End()
+
+	promo, err := r.Repository.PromoCodes.Get(ctx, code)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if promo.Code == "" {
+		return nil, domain.ErrNoPromoCode
+	}
+	if err = r.Repository.PromoCodes.SetActive(ctx, code, active); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	before := map[string]bool{"active": promo.Active}
+	promo.Active = active
+	if err = r.audit(ctx, domain.AuditPromoCode, promo.Code, before, map[string]bool{"active": active}); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return promo, nil
+}
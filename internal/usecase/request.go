@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/metrics"
+	"shop/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CreateCoinRequest asks payer to send amount to requester. The request can
+// be accepted until COIN_REQUEST_TTL passes.
+func (r *UsecaseImplementation) CreateCoinRequest(ctx context.Context, requester, payer string, amount float64, message string) (*domain.CoinRequest, error) {
+	ctx, span := tracing.Start(ctx, "usecase.CreateCoinRequest", attribute.Float64("amount", amount))
+	defer span.End()
+
+	user, err := r.Repository.Users.GetUserByUsername(ctx, payer)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if user.Username == "" {
+		return nil, domain.ErrNoSuchUser
+	}
+
+	now := time.Now()
+	request, err := r.Repository.CoinRequests.Create(ctx, &domain.CoinRequest{
+		Requester: requester,
+		Payer:     payer,
+		Amount:    amount,
+		Message:   message,
+		Status:    domain.RequestPending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(r.Config.Transfers.RequestTTL),
+	})
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+
+	after := map[string]any{"id": request.ID, "status": request.Status, "payer": payer, "amount": amount}
+	if err = r.audit(ctx, domain.AuditCoinRequest, requester, nil, after); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return request, nil
+}
+
+func (r *UsecaseImplementation) ListPendingRequests(ctx context.Context, username string) (*domain.PendingRequests, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ListPendingRequests")
+	defer span.End()
+
+	requests, err := r.Repository.CoinRequests.ListPending(ctx, username, time.Now())
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	pending := &domain.PendingRequests{Incoming: []domain.CoinRequest{}, Outgoing: []domain.CoinRequest{}}
+	for _, request := range requests {
+		if request.Payer == username {
+			pending.Incoming = append(pending.Incoming, request)
+		} else {
+			pending.Outgoing = append(pending.Outgoing, request)
+		}
+	}
+	return pending, nil
+}
+
+// AcceptCoinRequest sends the requested coins, the transfer is subject to
+// the payer's balance and transfer limits.
+func (r *UsecaseImplementation) AcceptCoinRequest(ctx context.Context, id int64, payer string) (*domain.CoinRequest, error) {
+	ctx, span := tracing.Start(ctx, "usecase.AcceptCoinRequest")
+	defer span.End()
+
+	request, err := r.Repository.AcceptCoinRequest(ctx, id, payer, r.transferPolicy())
+	if err != nil {
+		countRejectedTransfer(err)
+		return nil, tracing.Error(span, err)
+	}
+	metrics.CoinsTransferred.Add(request.Amount)
+	return request, nil
+}
+
+func (r *UsecaseImplementation) DeclineCoinRequest(ctx context.Context, id int64, payer string) (*domain.CoinRequest, error) {
+	ctx, span := tracing.Start(ctx, "usecase.DeclineCoinRequest")
+	defer span.End()
+
+	request, err := r.Repository.CloseCoinRequest(ctx, id, payer, domain.RequestDeclined)
+	return request, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) CancelCoinRequest(ctx context.Context, id int64, requester string) (*domain.CoinRequest, error) {
+	ctx, span := tracing.Start(ctx, "usecase.CancelCoinRequest")
+	defer span.End()
+
+	request, err := r.Repository.CloseCoinRequest(ctx, id, requester, domain.RequestCancelled)
+	return request, tracing.Error(span, err)
+}
+
+// ExpireCoinRequests closes the pending requests that were not accepted in time.
+func (r *UsecaseImplementation) ExpireCoinRequests(ctx context.Context, now time.Time) error {
+	ctx, span := tracing.Start(ctx, "usecase.ExpireCoinRequests")
+	defer span.End()
+
+	expired, err := r.Repository.CoinRequests.ExpirePending(ctx, now)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+	if expired > 0 {
+		logger.FromContext(ctx).Infof("expired %d coin requests", expired)
+	}
+	return nil
+}
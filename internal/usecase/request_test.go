@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"shop/domain"
+	"shop/internal/repository"
+	"shop/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateCoinRequest(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockRequests := new(MockCoinRequests)
+	mockAudit := new(MockAudit)
+	cfg := config.Default()
+	cfg.Transfers.RequestTTL = time.Hour
+	usecase := NewUsecase(&repository.Repository{Users: mockUsers, CoinRequests: mockRequests, Audit: mockAudit}, nil, nil, cfg)
+
+	mockUsers.On("GetUserByUsername", "ghost").Return(&domain.User{}, nil)
+	mockUsers.On("GetUserByUsername", "user2").Return(&domain.User{Username: "user2"}, nil)
+	mockRequests.On("Create", mock.Anything).Return(nil)
+	mockAudit.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := usecase.CreateCoinRequest(context.Background(), "user1", "ghost", 10, "")
+	assert.ErrorIs(t, err, domain.ErrNoSuchUser)
+
+	request, err := usecase.CreateCoinRequest(context.Background(), "user1", "user2", 10, "lunch")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RequestPending, request.Status)
+	assert.Equal(t, time.Hour, request.ExpiresAt.Sub(request.CreatedAt))
+	mockAudit.AssertNumberOfCalls(t, "Append", 1)
+}
+
+func TestListPendingRequests(t *testing.T) {
+	mockRequests := new(MockCoinRequests)
+	usecase := NewUsecase(&repository.Repository{CoinRequests: mockRequests}, nil, nil, config.Default())
+
+	mockRequests.On("ListPending", "user1", mock.Anything).Return([]domain.CoinRequest{
+		{ID: 1, Requester: "user2", Payer: "user1"},
+		{ID: 2, Requester: "user1", Payer: "user3"},
+	}, nil)
+	mockRequests.On("ListPending", "user4", mock.Anything).Return([]domain.CoinRequest(nil), nil)
+
+	pending, err := usecase.ListPendingRequests(context.Background(), "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.CoinRequest{{ID: 1, Requester: "user2", Payer: "user1"}}, pending.Incoming)
+	assert.Equal(t, []domain.CoinRequest{{ID: 2, Requester: "user1", Payer: "user3"}}, pending.Outgoing)
+
+	pending, err = usecase.ListPendingRequests(context.Background(), "user4")
+	assert.NoError(t, err)
+	assert.Empty(t, pending.Incoming)
+	assert.NotNil(t, pending.Outgoing)
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/metrics"
+	"shop/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// scheduledTransfersPerRun caps how many due transfers a single job tick
+// makes, the rest are picked up by the next tick.
+const scheduledTransfersPerRun = 100
+
+// ScheduleTransfer stores a transfer to be made at executeAt. The balance and
+// limits are checked when it is executed, not now.
+func (r *UsecaseImplementation) ScheduleTransfer(ctx context.Context, receiver, sender string, money float64, note domain.TransferNote, executeAt time.Time) (*domain.ScheduledTransfer, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ScheduleTransfer", attribute.Float64("amount", money))
+	defer span.End()
+
+	user, err := r.Repository.Users.GetUserByUsername(ctx, receiver)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if user.Username == "" {
+		return nil, domain.ErrNoSuchUser
+	}
+
+	scheduled, err := r.Repository.ScheduledTransfers.Create(ctx, &domain.ScheduledTransfer{
+		SenderUsername:   sender,
+		ReceiverUsername: receiver,
+		Amount:           money,
+		TransferNote:     note,
+		Status:           domain.ScheduledPending,
+		ExecuteAt:        executeAt,
+	})
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+
+	after := map[string]any{"id": scheduled.ID, "status": scheduled.Status, "receiver": receiver, "amount": money, "execute_at": executeAt}
+	if err = r.audit(ctx, domain.AuditScheduledTransfer, sender, nil, after); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return scheduled, nil
+}
+
+func (r *UsecaseImplementation) ListScheduledTransfers(ctx context.Context, sender string) ([]domain.ScheduledTransfer, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ListScheduledTransfers")
+	defer span.End()
+
+	transfers, err := r.Repository.ScheduledTransfers.ListBySender(ctx, sender)
+	return transfers, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) CancelScheduledTransfer(ctx context.Context, id int64, sender string) (*domain.ScheduledTransfer, error) {
+	ctx, span := tracing.Start(ctx, "usecase.CancelScheduledTransfer")
+	defer span.End()
+
+	scheduled, err := r.Repository.CancelScheduledTransfer(ctx, id, sender)
+	return scheduled, tracing.Error(span, err)
+}
+
+// RunScheduledTransfers makes the scheduled transfers that are due. Transfers
+// that fail the checks are marked failed, other errors leave them pending for
+// the next run.
+func (r *UsecaseImplementation) RunScheduledTransfers(ctx context.Context, now time.Time) error {
+	ctx, span := tracing.Start(ctx, "usecase.RunScheduledTransfers")
+	defer span.End()
+
+	ids, err := r.Repository.ScheduledTransfers.ListDue(ctx, now, scheduledTransfersPerRun)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	var errs []error
+	for _, id := range ids {
+		scheduled, err := r.Repository.ExecuteScheduledTransfer(ctx, id, r.transferPolicy())
+		switch {
+		case errors.Is(err, domain.ErrScheduledTransferClosed):
+			// Cancelled or made by another instance since it was listed.
+		case err != nil:
+			errs = append(errs, fmt.Errorf("scheduled transfer %d: %w", id, err))
+		case scheduled.Status == domain.ScheduledFailed:
+			logger.FromContext(ctx).WithField("scheduled_transfer", id).
+				Warnf("scheduled transfer from %s failed: %s", scheduled.SenderUsername, scheduled.FailureReason)
+		default:
+			metrics.CoinsTransferred.Add(scheduled.Amount)
+		}
+	}
+	return tracing.Error(span, errors.Join(errs...))
+}
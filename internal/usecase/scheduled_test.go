@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"shop/domain"
+	"shop/internal/repository"
+	"shop/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestScheduleTransfer(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockScheduled := new(MockScheduledTransfers)
+	mockAudit := new(MockAudit)
+	usecase := NewUsecase(&repository.Repository{Users: mockUsers, ScheduledTransfers: mockScheduled, Audit: mockAudit}, nil, nil, config.Default())
+
+	executeAt := time.Now().Add(48 * time.Hour)
+	mockUsers.On("GetUserByUsername", "ghost").Return(&domain.User{}, nil)
+	mockUsers.On("GetUserByUsername", "user2").Return(&domain.User{Username: "user2"}, nil)
+	mockScheduled.On("Create", mock.Anything).Return(nil)
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditScheduledTransfer && entry.Subject == "user1"
+	})).Return(nil).Once()
+
+	_, err := usecase.ScheduleTransfer(context.Background(), "ghost", "user1", 10, domain.TransferNote{}, executeAt)
+	assert.ErrorIs(t, err, domain.ErrNoSuchUser)
+
+	note := domain.TransferNote{Message: "happy birthday", Category: "teamwork"}
+	scheduled, err := usecase.ScheduleTransfer(context.Background(), "user2", "user1", 10, note, executeAt)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ScheduledPending, scheduled.Status)
+	assert.Equal(t, executeAt, scheduled.ExecuteAt)
+	assert.Equal(t, note, scheduled.TransferNote)
+	mockAudit.AssertExpectations(t)
+}
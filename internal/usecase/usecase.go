@@ -1,66 +1,373 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"io"
+	"time"
+
 	"shop/domain"
 	"shop/internal/repository"
 	hash "shop/pkg"
+	"shop/pkg/audit"
+	"shop/pkg/blob"
+	"shop/pkg/config"
+	"shop/pkg/logger"
+	"shop/pkg/metrics"
+	"shop/pkg/notify"
+	"shop/pkg/tracing"
 
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/crypto/bcrypt"
 )
 
 //go:generate mockgen -source=usecase.go -destination=mocks/mock.go
 type UsecaseImplementation struct {
 	Repository *repository.Repository
+	Blobs      blob.Store
+	Notifier   notify.Notifier
+	Config     *config.Config
 }
 
 type Usecase interface {
-	Auth(username string, password string) (*domain.User, error)
-	GetPurchasesForUserByUsername(string) ([]domain.Purchase, error)
-	CreateTransaction(string, string, float64) (*domain.Transaction, error)
-	CreatePurchase(string, string) (*domain.Purchase, error)
-	GetTransactionsForUserByUsername(string) ([]domain.Transaction, error)
+	Auth(ctx context.Context, username string, password string) (*domain.User, error)
+	GetRole(ctx context.Context, username string) (string, error)
+	GetPurchasesForUserByUsername(context.Context, string) ([]domain.Purchase, error)
+	CreateTransaction(ctx context.Context, receiver, sender string, money float64, note domain.TransferNote) (*domain.Transaction, error)
+	CreateTransactionBatch(ctx context.Context, sender string, transfers []domain.Transaction) (*domain.TransferBatch, error)
+	ScheduleTransfer(ctx context.Context, receiver, sender string, money float64, note domain.TransferNote, executeAt time.Time) (*domain.ScheduledTransfer, error)
+	ListScheduledTransfers(context.Context, string) ([]domain.ScheduledTransfer, error)
+	CancelScheduledTransfer(ctx context.Context, id int64, sender string) (*domain.ScheduledTransfer, error)
+	RunScheduledTransfers(ctx context.Context, now time.Time) error
+	CreateCoinRequest(ctx context.Context, requester, payer string, amount float64, message string) (*domain.CoinRequest, error)
+	ListPendingRequests(context.Context, string) (*domain.PendingRequests, error)
+	AcceptCoinRequest(ctx context.Context, id int64, payer string) (*domain.CoinRequest, error)
+	DeclineCoinRequest(ctx context.Context, id int64, payer string) (*domain.CoinRequest, error)
+	CancelCoinRequest(ctx context.Context, id int64, requester string) (*domain.CoinRequest, error)
+	ExpireCoinRequests(ctx context.Context, now time.Time) error
+	CreatePurchase(ctx context.Context, username, merchName string, gift domain.Gift, promoCodes []string) (*domain.Purchase, *domain.PurchaseApproval, error)
+	ListPurchaseApprovals(ctx context.Context, username string, admin bool) (*domain.PendingApprovals, error)
+	ApprovePurchase(ctx context.Context, id int64, approver string, admin bool) (*domain.PurchaseApproval, error)
+	RejectPurchase(ctx context.Context, id int64, approver string, admin bool) (*domain.PurchaseApproval, error)
+	EscalatePurchaseApprovals(ctx context.Context, now time.Time) error
+	CreatePromoCode(context.Context, *domain.PromoCode) (*domain.PromoCode, error)
+	ListPromoCodes(context.Context) ([]domain.PromoCode, error)
+	SetPromoCodeActive(ctx context.Context, code string, active bool) (*domain.PromoCode, error)
+	GetInventory(context.Context, string) ([]domain.InventoryItem, error)
+	GetPriceTimeline(context.Context, string) (*domain.PriceTimeline, error)
+	ListMerch(context.Context, domain.MerchFilter) ([]domain.Merch, error)
+	CreateMerch(context.Context, *domain.Merch) (*domain.Merch, error)
+	UpdateMerch(ctx context.Context, name string, update domain.MerchUpdate) (*domain.Merch, error)
+	ListCategories(context.Context) ([]domain.Category, error)
+	CreateCategory(context.Context, *domain.Category) (*domain.Category, error)
+	UpdateCategory(context.Context, *domain.Category) (*domain.Category, error)
+	DeleteCategory(context.Context, string) error
+	AddMerchImage(ctx context.Context, item string, upload io.Reader) (*domain.MerchImage, error)
+	DeleteMerchImage(ctx context.Context, item string, id int64) error
+	GetMerchThumbnail(ctx context.Context, item string, id int64, width int) ([]byte, string, error)
+	ListNotifications(context.Context, string) ([]domain.Notification, error)
+	MarkNotificationsRead(context.Context, string) (int64, error)
+	GetWishlist(context.Context, string) (*domain.Wishlist, error)
+	SaveWishlistItem(context.Context, *domain.WishlistItem) (*domain.WishlistItem, error)
+	RemoveWishlistItem(ctx context.Context, username, item string) error
+	SendWishlistAlerts(ctx context.Context, now time.Time) error
+	GetTransactionsForUserByUsername(context.Context, string) ([]domain.Transaction, error)
+	GetKudosFeed(context.Context, domain.FeedFilter) ([]domain.Transaction, error)
+	GetAuditLog(context.Context, domain.AuditFilter) ([]domain.AuditEntry, error)
+	AdjustBalance(ctx context.Context, username string, amount float64, reason string) (*domain.BalanceAdjustment, error)
+	IssueCoins(context.Context, []domain.BalanceAdjustment) ([]domain.BalanceAdjustment, error)
+	GetAdjustmentsForUserByUsername(context.Context, string) ([]domain.BalanceAdjustment, error)
+	CreateHold(ctx context.Context, username string, amount float64, reason string, expiresAt time.Time) (*domain.Hold, error)
+	CaptureHold(ctx context.Context, id int64, amount float64) (*domain.Hold, error)
+	ReleaseHold(context.Context, int64) (*domain.Hold, error)
+	GetBalance(context.Context, string) (*domain.Balance, error)
+	ExpireHolds(ctx context.Context, now time.Time) error
+	CreateWallet(ctx context.Context, creator, name string, quorum int, members []string) (*domain.Wallet, error)
+	ListWallets(context.Context, string) ([]domain.Wallet, error)
+	GetWalletInfo(ctx context.Context, id int64, username string) (*domain.WalletInfo, error)
+	SaveWalletMember(ctx context.Context, actor string, member *domain.WalletMember) error
+	RemoveWalletMember(ctx context.Context, walletID int64, actor, username string) error
+	ContributeToWallet(ctx context.Context, walletID int64, username string, amount float64) (*domain.WalletContribution, error)
+	ProposeWalletPurchase(ctx context.Context, walletID int64, username, merchName string) (*domain.WalletPurchase, error)
+	ApproveWalletPurchase(ctx context.Context, walletID, id int64, username string) (*domain.WalletPurchase, error)
+	RejectWalletPurchase(ctx context.Context, walletID, id int64, username string) (*domain.WalletPurchase, error)
+	UpdateProfile(ctx context.Context, username string, department, manager *string, active *bool) (*domain.User, error)
+	CreateAllowancePolicy(context.Context, *domain.AllowancePolicy) (*domain.AllowancePolicy, error)
+	ListAllowancePolicies(context.Context) ([]domain.AllowancePolicy, error)
+	PreviewAllowance(context.Context, int64) (*domain.AllowancePreview, error)
+	SetAllowancePaused(ctx context.Context, id int64, paused bool) (*domain.AllowancePolicy, error)
+	RunAllowance(context.Context, int64) (*domain.AllowanceRun, error)
+	ListAllowanceRuns(context.Context, int64) ([]domain.AllowanceRun, error)
+	RunDueAllowances(ctx context.Context, now time.Time) error
+	ExpireCoins(ctx context.Context, now time.Time) error
+	GetCoinExpirations(context.Context, string) ([]domain.CoinExpiration, error)
 }
 
-func NewUsecase(repository *repository.Repository) Usecase {
-	return &UsecaseImplementation{Repository: repository}
+func NewUsecase(repository *repository.Repository, blobs blob.Store, notifier notify.Notifier, cfg *config.Config) Usecase {
+	return &UsecaseImplementation{Repository: repository, Blobs: blobs, Notifier: notifier, Config: cfg}
 }
 
-func (r *UsecaseImplementation) Auth(username string, password string) (*domain.User, error) {
-	user, err := r.Repository.Users.GetUserByUsername(username)
+func (r *UsecaseImplementation) Auth(ctx context.Context, username string, password string) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "usecase.Auth")
+	defer span.End()
+
+	user, err := r.Repository.Users.GetUserByUsername(ctx, username)
 	if err != nil {
-		return nil, err
+		return nil, tracing.Error(span, err)
 	}
 	if user.Username == "" {
+		_, hashSpan := tracing.Start(ctx, "bcrypt.GenerateFromPassword", attribute.Int("bcrypt.cost", r.Config.Auth.BcryptCost))
 		newUser := &domain.User{
 			Username: username,
-			Password: hash.HashPassword(password),
+			Password: hash.HashPassword(password, r.Config.Auth.BcryptCost),
+			Balance:  r.Config.Users.StartingBalance,
+			Role:     domain.RoleUser,
+			Active:   true,
 		}
-		newUser, err = r.Repository.Users.CreateUser(newUser)
-		if err != nil {
-			return nil, err
-		}
-		return newUser, nil
+		hashSpan.End()
+		newUser, err = r.Repository.RegisterUser(ctx, newUser)
+		return newUser, tracing.Error(span, err)
 	}
-	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, errors.New("invalid password")
+
+	_, compareSpan := tracing.Start(ctx, "bcrypt.CompareHashAndPassword")
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	compareSpan.End()
+	if err != nil {
+		metrics.FailedLogins.Inc()
+		if err = r.audit(ctx, domain.AuditLoginFailed, username, nil, nil); err != nil {
+			logger.FromContext(ctx).Errorf("failed to audit failed login: %v", err)
+		}
+		return nil, tracing.Error(span, domain.ErrInvalidPassword)
 	}
 
+	if err = r.audit(ctx, domain.AuditLogin, username, nil, nil); err != nil {
+		return nil, tracing.Error(span, err)
+	}
 	return user, nil
 }
 
-func (r *UsecaseImplementation) GetPurchasesForUserByUsername(username string) ([]domain.Purchase, error) {
-	return r.Repository.Purchases.GetPurchasesForUserByUsername(username)
+// GetRole returns the current role of the user, unknown and inactive users
+// have none.
+func (r *UsecaseImplementation) GetRole(ctx context.Context, username string) (string, error) {
+	ctx, span := tracing.Start(ctx, "usecase.GetRole")
+	defer span.End()
+
+	user, err := r.Repository.Users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return "", tracing.Error(span, err)
+	}
+	if user.Username == "" || !user.Active {
+		return "", nil
+	}
+	return user.Role, nil
+}
+
+func (r *UsecaseImplementation) audit(ctx context.Context, action, subject string, before, after any) error {
+	entry, err := audit.NewEntry(ctx, action, subject, before, after)
+	if err != nil {
+		return err
+	}
+	return r.Repository.Audit.Append(ctx, nil, entry)
 }
 
-func (r *UsecaseImplementation) CreateTransaction(receiver, sender string, money float64) (*domain.Transaction, error) {
-	return r.Repository.CreateTransaction(receiver, sender, money)
+func (r *UsecaseImplementation) GetPurchasesForUserByUsername(ctx context.Context, username string) ([]domain.Purchase, error) {
+	ctx, span := tracing.Start(ctx, "usecase.GetPurchasesForUserByUsername")
+	defer span.End()
+
+	purchases, err := r.Repository.Purchases.GetPurchasesForUserByUsername(ctx, username)
+	return purchases, tracing.Error(span, err)
 }
 
-func (r *UsecaseImplementation) CreatePurchase(username string, merchName string) (*domain.Purchase, error) {
-	return r.Repository.CreatePurchase(username, merchName)
+func (r *UsecaseImplementation) CreateTransaction(ctx context.Context, receiver, sender string, money float64, note domain.TransferNote) (*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "usecase.CreateTransaction", attribute.Float64("amount", money))
+	defer span.End()
+
+	transaction, err := r.Repository.CreateTransaction(ctx, receiver, sender, money, note, r.transferPolicy())
+	if err != nil {
+		countRejectedTransfer(err)
+		return nil, tracing.Error(span, err)
+	}
+	metrics.CoinsTransferred.Add(transaction.MoneyAmount)
+	return transaction, nil
 }
 
-func (r *UsecaseImplementation) GetTransactionsForUserByUsername(username string) ([]domain.Transaction, error) {
-	return r.Repository.Transactions.GetTransactionsForUserByUsername(username)
+// CreateTransactionBatch makes all transfers from sender at once, they share a batch ID.
+func (r *UsecaseImplementation) CreateTransactionBatch(ctx context.Context, sender string, transfers []domain.Transaction) (*domain.TransferBatch, error) {
+	ctx, span := tracing.Start(ctx, "usecase.CreateTransactionBatch", attribute.Int("transfers", len(transfers)))
+	defer span.End()
+
+	batch := &domain.TransferBatch{ID: uuid.NewString()}
+	for i := range transfers {
+		transfers[i].BatchID = batch.ID
+	}
+	transactions, err := r.Repository.CreateTransactions(ctx, sender, transfers, r.transferPolicy())
+	if err != nil {
+		countRejectedTransfer(err)
+		return nil, tracing.Error(span, err)
+	}
+	for _, transaction := range transactions {
+		batch.Total += transaction.MoneyAmount
+	}
+	batch.Transactions = transactions
+	metrics.CoinsTransferred.Add(batch.Total)
+	return batch, nil
+}
+
+func countRejectedTransfer(err error) {
+	var limitErr *domain.TransferLimitError
+	if errors.Is(err, domain.ErrInsufficientMoney) {
+		metrics.InsufficientFunds.WithLabelValues("transfer").Inc()
+	} else if errors.As(err, &limitErr) {
+		metrics.TransfersRejected.WithLabelValues(limitErr.Code).Inc()
+	}
+}
+
+func (r *UsecaseImplementation) transferPolicy() domain.TransferPolicy {
+	policy := domain.TransferPolicy{
+		Default: transferLimits(r.Config.Transfers.TransferLimits),
+		Roles:   make(map[string]domain.TransferLimits, len(r.Config.Transfers.Roles)),
+	}
+	for role, limits := range r.Config.Transfers.Roles {
+		policy.Roles[role] = transferLimits(limits)
+	}
+	return policy
+}
+
+func transferLimits(limits config.TransferLimits) domain.TransferLimits {
+	return domain.TransferLimits{
+		MaxAmount:       limits.MaxAmount,
+		DailyAmount:     limits.DailyAmount,
+		DailyCount:      limits.DailyCount,
+		DailyRecipients: limits.DailyRecipients,
+	}
+}
+
+// CreatePurchase buys the item with the promo codes applied, or returns the
+// pending approval when the discounted price is PURCHASE_APPROVAL_THRESHOLD
+// or more.
+func (r *UsecaseImplementation) CreatePurchase(ctx context.Context, username, merchName string, gift domain.Gift, promoCodes []string) (*domain.Purchase, *domain.PurchaseApproval, error) {
+	ctx, span := tracing.Start(ctx, "usecase.CreatePurchase", attribute.String("item", merchName), attribute.Bool("gift", gift.Recipient != ""), attribute.Int("promo_codes", len(promoCodes)))
+	defer span.End()
+
+	purchase, approval, err := r.Repository.CreatePurchase(ctx, username, merchName, gift, promoCodes, r.purchasePolicy())
+	if err != nil {
+		if errors.Is(err, domain.ErrInsufficientMoney) {
+			metrics.InsufficientFunds.WithLabelValues("purchase").Inc()
+		}
+		return nil, nil, tracing.Error(span, err)
+	}
+	if purchase != nil {
+		metrics.Purchases.WithLabelValues(purchase.MerchName).Inc()
+	}
+	return purchase, approval, nil
+}
+
+func (r *UsecaseImplementation) purchasePolicy() domain.PurchasePolicy {
+	return domain.PurchasePolicy{
+		ApprovalThreshold: r.Config.Purchases.ApprovalThreshold,
+		ApprovalTimeout:   r.Config.Purchases.ApprovalTimeout,
+	}
+}
+
+func (r *UsecaseImplementation) GetInventory(ctx context.Context, username string) ([]domain.InventoryItem, error) {
+	ctx, span := tracing.Start(ctx, "usecase.GetInventory")
+	defer span.End()
+
+	inventory, err := r.Repository.Purchases.GetInventory(ctx, username)
+	return inventory, tracing.Error(span, err)
+}
+
+// GetPriceTimeline returns the current price of the item and its past prices.
+func (r *UsecaseImplementation) GetPriceTimeline(ctx context.Context, item string) (*domain.PriceTimeline, error) {
+	ctx, span := tracing.Start(ctx, "usecase.GetPriceTimeline", attribute.String("item", item))
+	defer span.End()
+
+	merch, err := r.Repository.Merch.GetMerchByName(ctx, item)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if merch.Name == "" {
+		return nil, domain.ErrNoMerch
+	}
+	prices, err := r.Repository.Merch.ListPrices(ctx, item)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return domain.NewPriceTimeline(*merch, prices), nil
+}
+
+func (r *UsecaseImplementation) GetTransactionsForUserByUsername(ctx context.Context, username string) ([]domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "usecase.GetTransactionsForUserByUsername")
+	defer span.End()
+
+	transactions, err := r.Repository.Transactions.GetTransactionsForUserByUsername(ctx, username)
+	return transactions, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) GetKudosFeed(ctx context.Context, filter domain.FeedFilter) ([]domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "usecase.GetKudosFeed")
+	defer span.End()
+
+	transactions, err := r.Repository.Transactions.ListFeed(ctx, filter)
+	return transactions, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) GetAuditLog(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	ctx, span := tracing.Start(ctx, "usecase.GetAuditLog")
+	defer span.End()
+
+	entries, err := r.Repository.Audit.List(ctx, filter)
+	return entries, tracing.Error(span, err)
+}
+
+// AdjustBalance credits (positive amount) or debits (negative amount) a user.
+func (r *UsecaseImplementation) AdjustBalance(ctx context.Context, username string, amount float64, reason string) (*domain.BalanceAdjustment, error) {
+	ctx, span := tracing.Start(ctx, "usecase.AdjustBalance", attribute.Float64("amount", amount))
+	defer span.End()
+
+	adjustments, err := r.adjustBalances(ctx, []domain.BalanceAdjustment{{Username: username, Amount: amount, Reason: reason}})
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return &adjustments[0], nil
+}
+
+// IssueCoins applies a bulk issuance, all adjustments share a batch ID.
+func (r *UsecaseImplementation) IssueCoins(ctx context.Context, adjustments []domain.BalanceAdjustment) ([]domain.BalanceAdjustment, error) {
+	ctx, span := tracing.Start(ctx, "usecase.IssueCoins", attribute.Int("adjustments", len(adjustments)))
+	defer span.End()
+
+	batchID := uuid.NewString()
+	for i := range adjustments {
+		adjustments[i].BatchID = batchID
+	}
+	adjustments, err := r.adjustBalances(ctx, adjustments)
+	return adjustments, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) adjustBalances(ctx context.Context, adjustments []domain.BalanceAdjustment) ([]domain.BalanceAdjustment, error) {
+	adjustments, err := r.Repository.AdjustBalances(ctx, adjustments)
+	if err != nil {
+		if errors.Is(err, domain.ErrInsufficientMoney) {
+			metrics.InsufficientFunds.WithLabelValues("adjustment").Inc()
+		}
+		return nil, err
+	}
+	for _, adjustment := range adjustments {
+		if adjustment.Amount < 0 {
+			metrics.CoinsAdjusted.WithLabelValues("debit").Add(-adjustment.Amount)
+		} else {
+			metrics.CoinsAdjusted.WithLabelValues("credit").Add(adjustment.Amount)
+		}
+	}
+	return adjustments, nil
+}
+
+func (r *UsecaseImplementation) GetAdjustmentsForUserByUsername(ctx context.Context, username string) ([]domain.BalanceAdjustment, error) {
+	ctx, span := tracing.Start(ctx, "usecase.GetAdjustmentsForUserByUsername")
+	defer span.End()
+
+	adjustments, err := r.Repository.BalanceAdjustments.GetAdjustmentsForUserByUsername(ctx, username)
+	return adjustments, tracing.Error(span, err)
 }
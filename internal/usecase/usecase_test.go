@@ -1,25 +1,42 @@
 package usecase
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"shop/domain"
 	"shop/internal/repository"
+	"shop/pkg/config"
+	"shop/pkg/metrics"
 
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type (
-	MockUsers        struct{ mock.Mock }
-	MockPurchases    struct{ mock.Mock }
-	MockTransactions struct{ mock.Mock }
+	MockUsers              struct{ mock.Mock }
+	MockPurchases          struct{ mock.Mock }
+	MockTransactions       struct{ mock.Mock }
+	MockAudit              struct{ mock.Mock }
+	MockAllowances         struct{ mock.Mock }
+	MockCoinLots           struct{ mock.Mock }
+	MockCoinRequests       struct{ mock.Mock }
+	MockScheduledTransfers struct{ mock.Mock }
+	MockHolds              struct{ mock.Mock }
+	MockWallets            struct{ mock.Mock }
+	MockMerch              struct{ mock.Mock }
+	MockWishlists          struct{ mock.Mock }
+	MockNotifications      struct{ mock.Mock }
+	MockNotifier           struct{ mock.Mock }
 )
 
-func (m *MockUsers) GetUserByUsername(username string) (*domain.User, error) {
+func (m *MockUsers) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
 	args := m.Called(username)
 	if user, ok := args.Get(0).(*domain.User); ok {
 		return user, args.Error(1)
@@ -27,17 +44,30 @@ func (m *MockUsers) GetUserByUsername(username string) (*domain.User, error) {
 	return nil, args.Error(1)
 }
 
-func (m *MockUsers) UpdateUser(tx *gorm.DB, user *domain.User) error {
+func (m *MockUsers) LockUserByUsername(ctx context.Context, tx *gorm.DB, username string) (*domain.User, error) {
+	args := m.Called(tx, username)
+	if user, ok := args.Get(0).(*domain.User); ok {
+		return user, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUsers) UpdateUser(ctx context.Context, tx *gorm.DB, user *domain.User) error {
 	args := m.Called(tx, user)
 	return args.Error(0)
 }
 
-func (m *MockUsers) CreateUser(user *domain.User) (*domain.User, error) {
-	args := m.Called(user)
+func (m *MockUsers) CreateUser(ctx context.Context, tx *gorm.DB, user *domain.User) (*domain.User, error) {
+	args := m.Called(tx, user)
 	return user, args.Error(0)
 }
 
-func (m *MockPurchases) Create(tx *gorm.DB, purchase *domain.Purchase) (*domain.Purchase, error) {
+func (m *MockUsers) ListActiveUsers(ctx context.Context, tx *gorm.DB, role, department string) ([]domain.User, error) {
+	args := m.Called(tx, role, department)
+	return args.Get(0).([]domain.User), args.Error(1)
+}
+
+func (m *MockPurchases) Create(ctx context.Context, tx *gorm.DB, purchase *domain.Purchase) (*domain.Purchase, error) {
 	args := m.Called(tx, purchase)
 	if p, ok := args.Get(0).(*domain.Purchase); ok {
 		return p, args.Error(1)
@@ -45,12 +75,17 @@ func (m *MockPurchases) Create(tx *gorm.DB, purchase *domain.Purchase) (*domain.
 	return nil, args.Error(1)
 }
 
-func (m *MockPurchases) GetPurchasesForUserByUsername(username string) ([]domain.Purchase, error) {
+func (m *MockPurchases) GetInventory(ctx context.Context, username string) ([]domain.InventoryItem, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.InventoryItem), args.Error(1)
+}
+
+func (m *MockPurchases) GetPurchasesForUserByUsername(ctx context.Context, username string) ([]domain.Purchase, error) {
 	args := m.Called(username)
 	return args.Get(0).([]domain.Purchase), args.Error(1)
 }
 
-func (m *MockTransactions) Create(tx *gorm.DB, transaction *domain.Transaction) (*domain.Transaction, error) {
+func (m *MockTransactions) Create(ctx context.Context, tx *gorm.DB, transaction *domain.Transaction) (*domain.Transaction, error) {
 	args := m.Called(tx, transaction)
 	if t, ok := args.Get(0).(*domain.Transaction); ok {
 		return t, args.Error(1)
@@ -58,50 +93,401 @@ func (m *MockTransactions) Create(tx *gorm.DB, transaction *domain.Transaction)
 	return nil, args.Error(1)
 }
 
-func (m *MockTransactions) GetTransactionsForUserByUsername(username string) ([]domain.Transaction, error) {
+func (m *MockTransactions) GetTransactionsForUserByUsername(ctx context.Context, username string) ([]domain.Transaction, error) {
 	args := m.Called(username)
 	return args.Get(0).([]domain.Transaction), args.Error(1)
 }
 
+func (m *MockTransactions) SentSince(ctx context.Context, tx *gorm.DB, sender string, since time.Time) (*domain.TransferStats, error) {
+	args := m.Called(tx, sender, since)
+	return args.Get(0).(*domain.TransferStats), args.Error(1)
+}
+
+func (m *MockTransactions) ListFeed(ctx context.Context, filter domain.FeedFilter) ([]domain.Transaction, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockAudit) Append(ctx context.Context, tx *gorm.DB, entry *domain.AuditEntry) error {
+	args := m.Called(tx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAudit) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.AuditEntry), args.Error(1)
+}
+
+func (m *MockAllowances) CreatePolicy(ctx context.Context, policy *domain.AllowancePolicy) (*domain.AllowancePolicy, error) {
+	args := m.Called(policy)
+	return policy, args.Error(0)
+}
+
+func (m *MockAllowances) GetPolicy(ctx context.Context, id int64) (*domain.AllowancePolicy, error) {
+	args := m.Called(id)
+	return args.Get(0).(*domain.AllowancePolicy), args.Error(1)
+}
+
+func (m *MockAllowances) ListPolicies(ctx context.Context) ([]domain.AllowancePolicy, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.AllowancePolicy), args.Error(1)
+}
+
+func (m *MockAllowances) SetPaused(ctx context.Context, id int64, paused bool) error {
+	args := m.Called(id, paused)
+	return args.Error(0)
+}
+
+func (m *MockAllowances) LastScheduledRun(ctx context.Context, id int64) (*domain.AllowanceRun, error) {
+	args := m.Called(id)
+	return args.Get(0).(*domain.AllowanceRun), args.Error(1)
+}
+
+func (m *MockAllowances) ListRuns(ctx context.Context, id int64) ([]domain.AllowanceRun, error) {
+	args := m.Called(id)
+	return args.Get(0).([]domain.AllowanceRun), args.Error(1)
+}
+
+func (m *MockAllowances) CreateRun(ctx context.Context, tx *gorm.DB, run *domain.AllowanceRun) (bool, error) {
+	args := m.Called(tx, run)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAllowances) UpdateRun(ctx context.Context, tx *gorm.DB, run *domain.AllowanceRun) error {
+	args := m.Called(tx, run)
+	return args.Error(0)
+}
+
+func (m *MockCoinRequests) Create(ctx context.Context, request *domain.CoinRequest) (*domain.CoinRequest, error) {
+	args := m.Called(request)
+	return request, args.Error(0)
+}
+
+func (m *MockCoinRequests) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.CoinRequest, error) {
+	args := m.Called(tx, id)
+	return args.Get(0).(*domain.CoinRequest), args.Error(1)
+}
+
+func (m *MockCoinRequests) Update(ctx context.Context, tx *gorm.DB, request *domain.CoinRequest) error {
+	args := m.Called(tx, request)
+	return args.Error(0)
+}
+
+func (m *MockCoinRequests) ListPending(ctx context.Context, username string, now time.Time) ([]domain.CoinRequest, error) {
+	args := m.Called(username, now)
+	return args.Get(0).([]domain.CoinRequest), args.Error(1)
+}
+
+func (m *MockCoinRequests) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockScheduledTransfers) Create(ctx context.Context, transfer *domain.ScheduledTransfer) (*domain.ScheduledTransfer, error) {
+	args := m.Called(transfer)
+	return transfer, args.Error(0)
+}
+
+func (m *MockScheduledTransfers) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.ScheduledTransfer, error) {
+	args := m.Called(tx, id)
+	return args.Get(0).(*domain.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransfers) Update(ctx context.Context, tx *gorm.DB, transfer *domain.ScheduledTransfer) error {
+	args := m.Called(tx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockScheduledTransfers) ListBySender(ctx context.Context, username string) ([]domain.ScheduledTransfer, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransfers) ListDue(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockHolds) Create(ctx context.Context, tx *gorm.DB, hold *domain.Hold) (*domain.Hold, error) {
+	args := m.Called(tx, hold)
+	return hold, args.Error(0)
+}
+
+func (m *MockHolds) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.Hold, error) {
+	args := m.Called(tx, id)
+	return args.Get(0).(*domain.Hold), args.Error(1)
+}
+
+func (m *MockHolds) Update(ctx context.Context, tx *gorm.DB, hold *domain.Hold) error {
+	args := m.Called(tx, hold)
+	return args.Error(0)
+}
+
+func (m *MockHolds) ListActive(ctx context.Context, username string) ([]domain.Hold, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.Hold), args.Error(1)
+}
+
+func (m *MockHolds) ListExpired(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockCoinLots) Grant(ctx context.Context, tx *gorm.DB, lots []domain.CoinLot) error {
+	args := m.Called(tx, lots)
+	return args.Error(0)
+}
+
+func (m *MockCoinLots) Consume(ctx context.Context, tx *gorm.DB, username string, amount float64) ([]domain.CoinLot, error) {
+	args := m.Called(tx, username, amount)
+	lots, _ := args.Get(0).([]domain.CoinLot)
+	return lots, args.Error(1)
+}
+
+func (m *MockCoinLots) ListOpenLots(ctx context.Context, username string) ([]domain.CoinLot, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.CoinLot), args.Error(1)
+}
+
+func (m *MockCoinLots) ListExpiredOwners(ctx context.Context, cutoff time.Time) ([]string, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockCoinLots) ExpiredAmount(ctx context.Context, tx *gorm.DB, username string, cutoff time.Time) (float64, error) {
+	args := m.Called(tx, username, cutoff)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockWallets) Create(ctx context.Context, tx *gorm.DB, wallet *domain.Wallet) (*domain.Wallet, error) {
+	args := m.Called(tx, wallet)
+	return wallet, args.Error(0)
+}
+
+func (m *MockWallets) Get(ctx context.Context, id int64) (*domain.Wallet, error) {
+	args := m.Called(id)
+	return args.Get(0).(*domain.Wallet), args.Error(1)
+}
+
+func (m *MockWallets) Lock(ctx context.Context, tx *gorm.DB, id int64) (*domain.Wallet, error) {
+	args := m.Called(tx, id)
+	return args.Get(0).(*domain.Wallet), args.Error(1)
+}
+
+func (m *MockWallets) Update(ctx context.Context, tx *gorm.DB, wallet *domain.Wallet) error {
+	args := m.Called(tx, wallet)
+	return args.Error(0)
+}
+
+func (m *MockWallets) ListForUser(ctx context.Context, username string) ([]domain.Wallet, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.Wallet), args.Error(1)
+}
+
+func (m *MockWallets) SaveMember(ctx context.Context, tx *gorm.DB, member *domain.WalletMember) error {
+	args := m.Called(tx, member)
+	return args.Error(0)
+}
+
+func (m *MockWallets) RemoveMember(ctx context.Context, tx *gorm.DB, walletID int64, username string) error {
+	args := m.Called(tx, walletID, username)
+	return args.Error(0)
+}
+
+func (m *MockWallets) ListMembers(ctx context.Context, tx *gorm.DB, walletID int64) ([]domain.WalletMember, error) {
+	args := m.Called(tx, walletID)
+	return args.Get(0).([]domain.WalletMember), args.Error(1)
+}
+
+func (m *MockWallets) CreateContribution(ctx context.Context, tx *gorm.DB, contribution *domain.WalletContribution) (*domain.WalletContribution, error) {
+	args := m.Called(tx, contribution)
+	return contribution, args.Error(0)
+}
+
+func (m *MockWallets) ListContributions(ctx context.Context, walletID int64) ([]domain.WalletContribution, error) {
+	args := m.Called(walletID)
+	return args.Get(0).([]domain.WalletContribution), args.Error(1)
+}
+
+func (m *MockWallets) CreatePurchase(ctx context.Context, tx *gorm.DB, purchase *domain.WalletPurchase) (*domain.WalletPurchase, error) {
+	args := m.Called(tx, purchase)
+	return purchase, args.Error(0)
+}
+
+func (m *MockWallets) LockPurchase(ctx context.Context, tx *gorm.DB, walletID, id int64) (*domain.WalletPurchase, error) {
+	args := m.Called(tx, walletID, id)
+	return args.Get(0).(*domain.WalletPurchase), args.Error(1)
+}
+
+func (m *MockWallets) UpdatePurchase(ctx context.Context, tx *gorm.DB, purchase *domain.WalletPurchase) error {
+	args := m.Called(tx, purchase)
+	return args.Error(0)
+}
+
+func (m *MockWallets) AddApproval(ctx context.Context, tx *gorm.DB, approval *domain.WalletApproval) error {
+	args := m.Called(tx, approval)
+	return args.Error(0)
+}
+
+func (m *MockWallets) ListPurchases(ctx context.Context, walletID int64) ([]domain.WalletPurchase, error) {
+	args := m.Called(walletID)
+	return args.Get(0).([]domain.WalletPurchase), args.Error(1)
+}
+
+func (m *MockMerch) GetMerchByName(ctx context.Context, name string) (*domain.Merch, error) {
+	args := m.Called(name)
+	return args.Get(0).(*domain.Merch), args.Error(1)
+}
+
+func (m *MockMerch) ListPrices(ctx context.Context, name string) ([]domain.MerchPrice, error) {
+	args := m.Called(name)
+	return args.Get(0).([]domain.MerchPrice), args.Error(1)
+}
+
+func (m *MockMerch) List(ctx context.Context, filter domain.MerchFilter) ([]domain.Merch, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.Merch), args.Error(1)
+}
+
+func (m *MockMerch) Lock(ctx context.Context, tx *gorm.DB, name string) (*domain.Merch, error) {
+	args := m.Called(tx, name)
+	return args.Get(0).(*domain.Merch), args.Error(1)
+}
+
+func (m *MockMerch) Create(ctx context.Context, tx *gorm.DB, merch *domain.Merch) (*domain.Merch, error) {
+	args := m.Called(tx, merch)
+	return args.Get(0).(*domain.Merch), args.Error(1)
+}
+
+func (m *MockMerch) Update(ctx context.Context, tx *gorm.DB, merch *domain.Merch) error {
+	args := m.Called(tx, merch)
+	return args.Error(0)
+}
+
+func (m *MockMerch) TakeStock(ctx context.Context, tx *gorm.DB, name string) (bool, error) {
+	args := m.Called(tx, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMerch) CreatePrice(ctx context.Context, tx *gorm.DB, price *domain.MerchPrice) error {
+	args := m.Called(tx, price)
+	return args.Error(0)
+}
+
+func (m *MockMerch) CountByCategory(ctx context.Context, category string) (int64, error) {
+	args := m.Called(category)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMerch) CreateImage(ctx context.Context, image *domain.MerchImage) (*domain.MerchImage, error) {
+	args := m.Called(image)
+	return image, args.Error(0)
+}
+
+func (m *MockMerch) GetImage(ctx context.Context, name string, id int64) (*domain.MerchImage, error) {
+	args := m.Called(name, id)
+	return args.Get(0).(*domain.MerchImage), args.Error(1)
+}
+
+func (m *MockMerch) DeleteImage(ctx context.Context, id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWishlists) Save(ctx context.Context, item *domain.WishlistItem) error {
+	args := m.Called(item)
+	return args.Error(0)
+}
+
+func (m *MockWishlists) Delete(ctx context.Context, username, merchName string) (bool, error) {
+	args := m.Called(username, merchName)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWishlists) List(ctx context.Context, username string) ([]domain.WishlistItem, error) {
+	args := m.Called(username)
+	return args.Get(0).([]domain.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlists) ListChanged(ctx context.Context, limit int) ([]domain.WishlistItem, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]domain.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlists) UpdateSeen(ctx context.Context, tx *gorm.DB, item *domain.WishlistItem) error {
+	args := m.Called(tx, item)
+	return args.Error(0)
+}
+
+func (m *MockNotifications) Create(ctx context.Context, tx *gorm.DB, notification *domain.Notification) error {
+	args := m.Called(tx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotifications) List(ctx context.Context, username string, limit int) ([]domain.Notification, error) {
+	args := m.Called(username, limit)
+	return args.Get(0).([]domain.Notification), args.Error(1)
+}
+
+func (m *MockNotifications) MarkRead(ctx context.Context, username string, now time.Time) (int64, error) {
+	args := m.Called(username, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
 func TestAuth(t *testing.T) {
 	mockUsers := new(MockUsers)
-	repo := &repository.Repository{Users: mockUsers}
-	usecase := NewUsecase(repo)
+	mockAudit := new(MockAudit)
+	repo := &repository.Repository{Users: mockUsers, Audit: mockAudit}
+	usecase := NewUsecase(repo, nil, nil, config.Default())
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("user"), bcrypt.DefaultCost)
 	user := &domain.User{Username: "user", Password: string(hashedPassword)}
 
 	mockUsers.On("GetUserByUsername", "user").Return(user, nil)
-	authUser, err := usecase.Auth("user", "user")
+	mockAudit.On("Append", (*gorm.DB)(nil), mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditLogin && entry.Actor == "user"
+	})).Return(nil).Once()
+	authUser, err := usecase.Auth(context.Background(), "user", "user")
 	assert.NoError(t, err)
 	assert.NotNil(t, authUser)
 	assert.Equal(t, "user", authUser.Username)
 
+	failedLogins := testutil.ToFloat64(metrics.FailedLogins)
 	mockUsers.On("GetUserByUsername", "user").Return(user, nil)
-	authUser, err = usecase.Auth("user", "user2")
+	mockAudit.On("Append", (*gorm.DB)(nil), mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditLoginFailed && entry.Subject == "user"
+	})).Return(nil).Once()
+	authUser, err = usecase.Auth(context.Background(), "user", "user2")
+	assert.Equal(t, failedLogins+1, testutil.ToFloat64(metrics.FailedLogins))
 	assert.Error(t, err)
 	assert.Nil(t, authUser)
-	assert.Equal(t, "invalid password", err.Error())
+	assert.ErrorIs(t, err, domain.ErrInvalidPassword)
 
 	mockUsers.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
 }
 
 func TestGetPurchasesForUserByUsername(t *testing.T) {
 	mockUsers := new(MockUsers)
 	mockPurchases := new(MockPurchases)
 	repo := &repository.Repository{Users: mockUsers, Purchases: mockPurchases}
-	usecase := NewUsecase(repo)
+	usecase := NewUsecase(repo, nil, nil, config.Default())
 
 	purchases := []domain.Purchase{{UserID: "user"}, {UserID: "user"}}
 
 	mockPurchases.On("GetPurchasesForUserByUsername", "user").Return(purchases, nil)
 
-	result, err := usecase.GetPurchasesForUserByUsername("user")
+	result, err := usecase.GetPurchasesForUserByUsername(context.Background(), "user")
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
 
 	mockPurchases.On("GetPurchasesForUserByUsername", "user2").Return([]domain.Purchase{}, nil)
-	result, err = usecase.GetPurchasesForUserByUsername("user2")
+	result, err = usecase.GetPurchasesForUserByUsername(context.Background(), "user2")
 	assert.NoError(t, err)
 	assert.Len(t, result, 0)
 
@@ -113,7 +499,7 @@ func TestGetTransactionsForUserByUsername(t *testing.T) {
 	mockUsers := new(MockUsers)
 	mockTransactions := new(MockTransactions)
 	repo := &repository.Repository{Users: mockUsers, Transactions: mockTransactions}
-	usecase := NewUsecase(repo)
+	usecase := NewUsecase(repo, nil, nil, config.Default())
 
 	transactions := []domain.Transaction{
 		{SenderUsername: "user1", ReceiverUsername: "user2", MoneyAmount: 20},
@@ -122,15 +508,48 @@ func TestGetTransactionsForUserByUsername(t *testing.T) {
 
 	mockTransactions.On("GetTransactionsForUserByUsername", "user1").Return(transactions, nil)
 
-	result, err := usecase.GetTransactionsForUserByUsername("user1")
+	result, err := usecase.GetTransactionsForUserByUsername(context.Background(), "user1")
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
 
 	mockTransactions.On("GetTransactionsForUserByUsername", "user8").Return([]domain.Transaction{}, nil)
-	result, err = usecase.GetTransactionsForUserByUsername("user8")
+	result, err = usecase.GetTransactionsForUserByUsername(context.Background(), "user8")
 	assert.NoError(t, err)
 	assert.Len(t, result, 0)
 
 	mockUsers.AssertExpectations(t)
 	mockTransactions.AssertExpectations(t)
 }
+
+func TestAuth_RegistersNewUser(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockCoinLots := new(MockCoinLots)
+	mockAudit := new(MockAudit)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &repository.Repository{DB: mockDB, Users: mockUsers, CoinLots: mockCoinLots, Audit: mockAudit}
+	cfg := config.Default()
+	cfg.Auth.BcryptCost = bcrypt.MinCost
+	cfg.Users.StartingBalance = 250
+	usecase := NewUsecase(repo, nil, nil, cfg)
+
+	mockUsers.On("GetUserByUsername", "newbie").Return(&domain.User{}, nil)
+	mockUsers.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *domain.User) bool {
+		return user.Username == "newbie" && user.Balance == 250 &&
+			bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("password")) == nil
+	})).Return(nil)
+	mockCoinLots.On("Grant", mock.Anything, mock.MatchedBy(func(lots []domain.CoinLot) bool {
+		return len(lots) == 1 && lots[0].Remaining == 250 && lots[0].Source == domain.LotSourceRegistration
+	})).Return(nil).Once()
+	mockAudit.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditRegistration && entry.After == `{"balance":250,"role":"user"}`
+	})).Return(nil)
+
+	user, err := usecase.Auth(context.Background(), "newbie", "password")
+	assert.NoError(t, err)
+	assert.Equal(t, 250.0, user.Balance)
+	assert.Equal(t, domain.RoleUser, user.Role)
+
+	mockUsers.AssertExpectations(t)
+	mockCoinLots.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
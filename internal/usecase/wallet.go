@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"shop/domain"
+	"shop/pkg/metrics"
+	"shop/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CreateWallet creates a team wallet owned by creator with the given members.
+func (r *UsecaseImplementation) CreateWallet(ctx context.Context, creator, name string, quorum int, members []string) (*domain.Wallet, error) {
+	ctx, span := tracing.Start(ctx, "usecase.CreateWallet", attribute.Int("members", len(members)))
+	defer span.End()
+
+	wallet, err := r.Repository.CreateWallet(ctx, &domain.Wallet{Name: name, Quorum: quorum, CreatedBy: creator}, members)
+	return wallet, tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) ListWallets(ctx context.Context, username string) ([]domain.Wallet, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ListWallets")
+	defer span.End()
+
+	wallets, err := r.Repository.Wallets.ListForUser(ctx, username)
+	return wallets, tracing.Error(span, err)
+}
+
+// GetWalletInfo returns the wallet's members, contributions and purchases.
+// Only members can see a wallet.
+func (r *UsecaseImplementation) GetWalletInfo(ctx context.Context, id int64, username string) (*domain.WalletInfo, error) {
+	ctx, span := tracing.Start(ctx, "usecase.GetWalletInfo")
+	defer span.End()
+
+	wallet, err := r.Repository.Wallets.Get(ctx, id)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if wallet.ID == 0 {
+		return nil, domain.ErrNoWallet
+	}
+	members, err := r.Repository.Wallets.ListMembers(ctx, nil, id)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if !isWalletMember(members, username) {
+		return nil, domain.ErrNoWallet
+	}
+	contributions, err := r.Repository.Wallets.ListContributions(ctx, id)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	purchases, err := r.Repository.Wallets.ListPurchases(ctx, id)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return &domain.WalletInfo{Wallet: *wallet, Members: members, Contributions: contributions, Purchases: purchases}, nil
+}
+
+func isWalletMember(members []domain.WalletMember, username string) bool {
+	for _, member := range members {
+		if member.Username == username {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *UsecaseImplementation) SaveWalletMember(ctx context.Context, actor string, member *domain.WalletMember) error {
+	ctx, span := tracing.Start(ctx, "usecase.SaveWalletMember", attribute.String("role", member.Role))
+	defer span.End()
+
+	return tracing.Error(span, r.Repository.SaveWalletMember(ctx, actor, member))
+}
+
+func (r *UsecaseImplementation) RemoveWalletMember(ctx context.Context, walletID int64, actor, username string) error {
+	ctx, span := tracing.Start(ctx, "usecase.RemoveWalletMember")
+	defer span.End()
+
+	return tracing.Error(span, r.Repository.RemoveWalletMember(ctx, walletID, actor, username))
+}
+
+func (r *UsecaseImplementation) ContributeToWallet(ctx context.Context, walletID int64, username string, amount float64) (*domain.WalletContribution, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ContributeToWallet", attribute.Float64("amount", amount))
+	defer span.End()
+
+	contribution, err := r.Repository.ContributeToWallet(ctx, walletID, username, amount)
+	if err != nil {
+		if errors.Is(err, domain.ErrInsufficientMoney) {
+			metrics.InsufficientFunds.WithLabelValues("wallet_contribution").Inc()
+		}
+		return nil, tracing.Error(span, err)
+	}
+	return contribution, nil
+}
+
+func (r *UsecaseImplementation) ProposeWalletPurchase(ctx context.Context, walletID int64, username, merchName string) (*domain.WalletPurchase, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ProposeWalletPurchase", attribute.String("item", merchName))
+	defer span.End()
+
+	purchase, err := r.Repository.ProposeWalletPurchase(ctx, walletID, username, merchName)
+	return countWalletPurchase(purchase, err), tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) ApproveWalletPurchase(ctx context.Context, walletID, id int64, username string) (*domain.WalletPurchase, error) {
+	ctx, span := tracing.Start(ctx, "usecase.ApproveWalletPurchase")
+	defer span.End()
+
+	purchase, err := r.Repository.ApproveWalletPurchase(ctx, walletID, id, username)
+	return countWalletPurchase(purchase, err), tracing.Error(span, err)
+}
+
+func (r *UsecaseImplementation) RejectWalletPurchase(ctx context.Context, walletID, id int64, username string) (*domain.WalletPurchase, error) {
+	ctx, span := tracing.Start(ctx, "usecase.RejectWalletPurchase")
+	defer span.End()
+
+	purchase, err := r.Repository.RejectWalletPurchase(ctx, walletID, id, username)
+	return purchase, tracing.Error(span, err)
+}
+
+// countWalletPurchase counts a wallet purchase once it is made, or the wallet
+// balance falling short when it is approved.
+func countWalletPurchase(purchase *domain.WalletPurchase, err error) *domain.WalletPurchase {
+	if err != nil {
+		if errors.Is(err, domain.ErrInsufficientMoney) {
+			metrics.InsufficientFunds.WithLabelValues("wallet_purchase").Inc()
+		}
+		return nil
+	}
+	if purchase.Status == domain.WalletPurchaseCompleted {
+		metrics.Purchases.WithLabelValues(purchase.MerchName).Inc()
+	}
+	return purchase
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"shop/domain"
+	"shop/internal/repository"
+	"shop/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetWalletInfo(t *testing.T) {
+	mockWallets := new(MockWallets)
+	usecase := NewUsecase(&repository.Repository{Wallets: mockWallets}, nil, nil, config.Default())
+
+	wallet := &domain.Wallet{ID: 1, Name: "team", Balance: 150}
+	members := []domain.WalletMember{{WalletID: 1, Username: "owner", Role: domain.WalletRoleOwner}, {WalletID: 1, Username: "user1", Role: domain.WalletRoleMember}}
+	contributions := []domain.WalletContribution{{WalletID: 1, Username: "user1", Amount: 150}}
+	purchases := []domain.WalletPurchase{{ID: 1, WalletID: 1, MerchName: "hoody", Status: domain.WalletPurchasePending, Approvals: []string{"user1"}}}
+	mockWallets.On("Get", int64(1)).Return(wallet, nil)
+	mockWallets.On("Get", int64(2)).Return(&domain.Wallet{}, nil)
+	mockWallets.On("ListMembers", mock.Anything, int64(1)).Return(members, nil)
+	mockWallets.On("ListContributions", int64(1)).Return(contributions, nil)
+	mockWallets.On("ListPurchases", int64(1)).Return(purchases, nil)
+
+	_, err := usecase.GetWalletInfo(context.Background(), 2, "user1")
+	assert.ErrorIs(t, err, domain.ErrNoWallet)
+	// Wallets are visible only to their members.
+	_, err = usecase.GetWalletInfo(context.Background(), 1, "stranger")
+	assert.ErrorIs(t, err, domain.ErrNoWallet)
+
+	info, err := usecase.GetWalletInfo(context.Background(), 1, "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.WalletInfo{Wallet: *wallet, Members: members, Contributions: contributions, Purchases: purchases}, info)
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/logger"
+	"shop/pkg/tracing"
+)
+
+const wishlistAlertsPerRun = 100
+
+// GetWishlist returns the user's wishlist with the coins they lack to buy
+// its items.
+func (r *UsecaseImplementation) GetWishlist(ctx context.Context, username string) (*domain.Wishlist, error) {
+	ctx, span := tracing.Start(ctx, "usecase.GetWishlist")
+	defer span.End()
+
+	user, err := r.Repository.Users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if user.Username == "" {
+		return nil, domain.ErrNoSuchUser
+	}
+	items, err := r.Repository.Wishlists.List(ctx, username)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return domain.NewWishlist(user.Available(), items), nil
+}
+
+// SaveWishlistItem adds the item to the user's wishlist or changes the
+// alerts they subscribed to. Alerts are about changes made after it.
+func (r *UsecaseImplementation) SaveWishlistItem(ctx context.Context, item *domain.WishlistItem) (*domain.WishlistItem, error) {
+	ctx, span := tracing.Start(ctx, "usecase.SaveWishlistItem")
+	defer span.End()
+
+	merch, err := r.Repository.Merch.GetMerchByName(ctx, item.MerchName)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if merch == nil || merch.Name == "" {
+		return nil, domain.ErrNoMerch
+	}
+	item.SeenPrice = merch.Price
+	item.SeenInStock = merch.InStock()
+	if err = r.Repository.Wishlists.Save(ctx, item); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return item, nil
+}
+
+func (r *UsecaseImplementation) RemoveWishlistItem(ctx context.Context, username, item string) error {
+	ctx, span := tracing.Start(ctx, "usecase.RemoveWishlistItem")
+	defer span.End()
+
+	removed, err := r.Repository.Wishlists.Delete(ctx, username, item)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+	if !removed {
+		return domain.ErrNotInWishlist
+	}
+	return nil
+}
+
+// SendWishlistAlerts notifies the users whose wishlist items were restocked
+// or got cheaper since they last saw them. The alert goes to the inbox and
+// then to the notifier, a failed delivery is logged and not retried.
+func (r *UsecaseImplementation) SendWishlistAlerts(ctx context.Context, now time.Time) error {
+	ctx, span := tracing.Start(ctx, "usecase.SendWishlistAlerts")
+	defer span.End()
+
+	items, err := r.Repository.Wishlists.ListChanged(ctx, wishlistAlertsPerRun)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	var errs []error
+	for i := range items {
+		item := &items[i]
+		notification := item.Alert()
+		if notification != nil {
+			notification.CreatedAt = now
+		}
+		if err = r.Repository.SaveWishlistAlert(ctx, item, notification); err != nil {
+			errs = append(errs, fmt.Errorf("wishlist item %s of %s: %w", item.MerchName, item.Username, err))
+			continue
+		}
+		if notification == nil {
+			continue
+		}
+		if err = r.Notifier.Notify(ctx, *notification); err != nil {
+			logger.FromContext(ctx).WithField("subject", item.Username).Warnf("failed to deliver %s alert: %v", notification.Kind, err)
+		}
+	}
+	return tracing.Error(span, errors.Join(errs...))
+}
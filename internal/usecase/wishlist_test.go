@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"shop/domain"
+	"shop/internal/repository"
+	"shop/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestWishlist(t *testing.T) {
+	mockUsers := new(MockUsers)
+	mockMerch := new(MockMerch)
+	mockWishlists := new(MockWishlists)
+	repo := &repository.Repository{Users: mockUsers, Merch: mockMerch, Wishlists: mockWishlists}
+	usecase := NewUsecase(repo, nil, nil, config.Default())
+
+	none := 0
+	mockUsers.On("GetUserByUsername", "user1").Return(&domain.User{Username: "user1", Balance: 300, Held: 100}, nil)
+	mockMerch.On("GetMerchByName", "hat").Return(&domain.Merch{}, nil)
+	mockMerch.On("GetMerchByName", "hoody").Return(&domain.Merch{Name: "hoody", Price: 300, Stock: &none}, nil)
+	mockWishlists.On("Save", mock.MatchedBy(func(item *domain.WishlistItem) bool {
+		return item.MerchName == "hoody" && item.SeenPrice == 300 && !item.SeenInStock
+	})).Return(nil).Once()
+	mockWishlists.On("List", "user1").Return([]domain.WishlistItem{
+		{Username: "user1", MerchName: "hoody", Merch: domain.Merch{Name: "hoody", Price: 300}},
+	}, nil)
+	mockWishlists.On("Delete", "user1", "hoody").Return(true, nil).Once()
+	mockWishlists.On("Delete", "user1", "hoody").Return(false, nil).Once()
+
+	_, err := usecase.SaveWishlistItem(context.Background(), &domain.WishlistItem{Username: "user1", MerchName: "hat"})
+	assert.ErrorIs(t, err, domain.ErrNoMerch)
+	_, err = usecase.SaveWishlistItem(context.Background(), &domain.WishlistItem{Username: "user1", MerchName: "hoody", NotifyRestock: true})
+	assert.NoError(t, err)
+
+	wishlist, err := usecase.GetWishlist(context.Background(), "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, 200.0, wishlist.Available)
+	assert.Equal(t, 100.0, wishlist.CoinsToGo)
+
+	assert.NoError(t, usecase.RemoveWishlistItem(context.Background(), "user1", "hoody"))
+	assert.ErrorIs(t, usecase.RemoveWishlistItem(context.Background(), "user1", "hoody"), domain.ErrNotInWishlist)
+	mockWishlists.AssertExpectations(t)
+}
+
+func TestSendWishlistAlerts(t *testing.T) {
+	mockWishlists := new(MockWishlists)
+	mockNotifications := new(MockNotifications)
+	mockNotifier := new(MockNotifier)
+	mockDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := &repository.Repository{DB: mockDB, Wishlists: mockWishlists, Notifications: mockNotifications}
+	usecase := NewUsecase(repo, nil, mockNotifier, config.Default())
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	mockWishlists.On("ListChanged", wishlistAlertsPerRun).Return([]domain.WishlistItem{
+		{Username: "user1", MerchName: "cup", NotifyPriceDrop: true, SeenPrice: 20, SeenInStock: true, Merch: domain.Merch{Name: "cup", Price: 15}},
+		{Username: "user2", MerchName: "cup", NotifyRestock: true, SeenPrice: 20, SeenInStock: true, Merch: domain.Merch{Name: "cup", Price: 15}},
+	}, nil)
+	mockWishlists.On("UpdateSeen", mock.Anything, mock.MatchedBy(func(item *domain.WishlistItem) bool {
+		return item.SeenPrice == 15
+	})).Return(nil).Twice()
+	alert := domain.Notification{
+		Username:  "user1",
+		Kind:      domain.NotificationPriceDrop,
+		Message:   "cup now costs 15 coins instead of 20",
+		Reference: "cup",
+		CreatedAt: now,
+	}
+	mockNotifications.On("Create", mock.Anything, &alert).Return(nil).Once()
+	mockNotifier.On("Notify", alert).Return(errors.New("webhook responded 502 Bad Gateway")).Once()
+
+	// A failed delivery leaves the alert in the inbox and is not an error.
+	assert.NoError(t, usecase.SendWishlistAlerts(context.Background(), now))
+	mockWishlists.AssertExpectations(t)
+	mockNotifications.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
+}
@@ -0,0 +1,142 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"shop/domain"
+
+	"gorm.io/gorm"
+)
+
+// lockKey serializes appends, each entry has to see the hash of the previous one.
+const lockKey = 0x617564697400
+
+// Actor describes who performs the request being audited.
+type Actor struct {
+	Username  string
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type contextKey struct{}
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, contextKey{}, actor)
+}
+
+// WithUsername sets the actor's username once the request is authenticated.
+func WithUsername(ctx context.Context, username string) context.Context {
+	actor := ActorFromContext(ctx)
+	actor.Username = username
+	return WithActor(ctx, actor)
+}
+
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(contextKey{}).(Actor)
+	return actor
+}
+
+// NewEntry builds an entry for the actor in ctx. Before and after are
+// marshalled to JSON, nil values are left empty. Requests made before
+// authentication, like logins, are attributed to the subject.
+func NewEntry(ctx context.Context, action, subject string, before, after any) (*domain.AuditEntry, error) {
+	actor := ActorFromContext(ctx)
+	entry := &domain.AuditEntry{
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		Action:    action,
+		Actor:     actor.Username,
+		Subject:   subject,
+		IP:        actor.IP,
+		UserAgent: actor.UserAgent,
+		RequestID: actor.RequestID,
+	}
+	if entry.Actor == "" {
+		entry.Actor = subject
+	}
+
+	var err error
+	if entry.Before, err = marshal(before); err != nil {
+		return nil, err
+	}
+	if entry.After, err = marshal(after); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func marshal(value any) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit value: %w", err)
+	}
+	return string(data), nil
+}
+
+// Hash returns the hash of entry chained to prevHash. It covers every
+// column except the ID, which is assigned by the database.
+func Hash(prevHash string, entry *domain.AuditEntry) string {
+	data, _ := json.Marshal([]string{
+		prevHash,
+		entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+		entry.Action,
+		entry.Actor,
+		entry.Subject,
+		entry.IP,
+		entry.UserAgent,
+		entry.RequestID,
+		entry.Before,
+		entry.After,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Append chains entry to the last one and inserts it in tx. The advisory
+// lock is held until tx ends, so entries are appended one at a time and
+// every transaction writing to the log waits for the previous one to end.
+// This is the price of a single hash chain, callers should append as late
+// in tx as they can.
+func Append(ctx context.Context, tx *gorm.DB, entry *domain.AuditEntry) error {
+	tx = tx.WithContext(ctx)
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockKey).Error; err != nil {
+		return err
+	}
+
+	var prevHash []string
+	err := tx.Model(&domain.AuditEntry{}).Order("id DESC").Limit(1).Pluck("hash", &prevHash).Error
+	if err != nil {
+		return err
+	}
+	entry.PrevHash = ""
+	if len(prevHash) > 0 {
+		entry.PrevHash = prevHash[0]
+	}
+	entry.Hash = Hash(entry.PrevHash, entry)
+	return tx.Create(entry).Error
+}
+
+// Verify checks the chain of entries ordered by ID, starting from the
+// first entry of the log, and reports the first one that does not match.
+func Verify(entries []domain.AuditEntry) error {
+	prevHash := ""
+	for i := range entries {
+		entry := &entries[i]
+		if entry.PrevHash != prevHash {
+			return fmt.Errorf("audit entry %d does not follow the previous entry", entry.ID)
+		}
+		if entry.Hash != Hash(prevHash, entry) {
+			return fmt.Errorf("audit entry %d was modified", entry.ID)
+		}
+		prevHash = entry.Hash
+	}
+	return nil
+}
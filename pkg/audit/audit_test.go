@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"shop/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func chain(entries ...*domain.AuditEntry) []domain.AuditEntry {
+	var result []domain.AuditEntry
+	prevHash := ""
+	for i, entry := range entries {
+		entry.ID = int64(i + 1)
+		entry.PrevHash = prevHash
+		entry.Hash = Hash(prevHash, entry)
+		prevHash = entry.Hash
+		result = append(result, *entry)
+	}
+	return result
+}
+
+func TestNewEntry(t *testing.T) {
+	ctx := WithActor(context.Background(), Actor{IP: "10.0.0.1", UserAgent: "curl", RequestID: "req-1"})
+
+	entry, err := NewEntry(ctx, domain.AuditLogin, "user1", nil, map[string]float64{"balance": 10})
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", entry.Actor, "unauthenticated requests are attributed to the subject")
+	assert.Equal(t, "10.0.0.1", entry.IP)
+	assert.Equal(t, "curl", entry.UserAgent)
+	assert.Equal(t, "req-1", entry.RequestID)
+	assert.Empty(t, entry.Before)
+	assert.Equal(t, `{"balance":10}`, entry.After)
+
+	entry, err = NewEntry(WithUsername(ctx, "admin"), domain.AuditBalanceAdjustment, "user1", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", entry.Actor)
+	assert.Equal(t, "req-1", entry.RequestID)
+}
+
+func TestVerify(t *testing.T) {
+	newEntries := func() []domain.AuditEntry {
+		first, _ := NewEntry(context.Background(), domain.AuditRegistration, "user1", nil, nil)
+		second, _ := NewEntry(context.Background(), domain.AuditTransfer, "user1", map[string]float64{"balance": 1000}, map[string]float64{"balance": 900})
+		third, _ := NewEntry(context.Background(), domain.AuditLogin, "user2", nil, nil)
+		return chain(first, second, third)
+	}
+	assert.NoError(t, Verify(newEntries()))
+
+	modified := newEntries()
+	modified[1].After = `{"balance":1900}`
+	assert.EqualError(t, Verify(modified), "audit entry 2 was modified")
+
+	deleted := newEntries()
+	deleted = append(deleted[:1], deleted[2:]...)
+	assert.EqualError(t, Verify(deleted), "audit entry 3 does not follow the previous entry")
+}
@@ -0,0 +1,33 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"shop/pkg/config"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Store keeps uploaded files under slash-separated keys such as
+// "merch/hoody/1.png". Put replaces the blob stored under the key.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the store configured by BLOB_STORE.
+func New(cfg config.Blobs) (Store, error) {
+	switch cfg.Store {
+	case "local":
+		return NewLocal(cfg.Dir)
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", cfg.Store)
+	}
+}
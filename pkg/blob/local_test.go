@@ -0,0 +1,54 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"shop/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	store, err := New(config.Blobs{Store: "local", Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, store.Put(ctx, "merch/hoody/1.png", strings.NewReader("first")))
+	assert.NoError(t, store.Put(ctx, "merch/hoody/1.png", strings.NewReader("second")))
+
+	r, err := store.Get(ctx, "merch/hoody/1.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "second", string(data), "put replaces the blob")
+
+	assert.NoError(t, store.Delete(ctx, "merch/hoody/1.png"))
+	_, err = store.Get(ctx, "merch/hoody/1.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete(ctx, "merch/hoody/1.png"), "deleting a missing blob is not an error")
+}
+
+func TestLocal_InvalidKey(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"", "/etc/passwd", "../secret", "merch/../../secret", "merch//1.png", `merch\1.png`} {
+		assert.ErrorIs(t, store.Put(context.Background(), key, strings.NewReader("x")), ErrInvalidKey, key)
+		_, err = store.Get(context.Background(), key)
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
+
+func TestNew_UnknownStore(t *testing.T) {
+	_, err := New(config.Blobs{Store: "s3"})
+	assert.EqualError(t, err, `unknown blob store "s3"`)
+}
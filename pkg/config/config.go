@@ -0,0 +1,415 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+	EnvTest        = "test"
+
+	redacted = "[REDACTED]"
+)
+
+// Config is the whole service configuration. Every field tagged with `env`
+// can be set, in increasing order of precedence, from the defaults, the
+// YAML/JSON config file, the environment and a command line flag named
+// after the variable (HTTP_PORT -> -http-port).
+type Config struct {
+	Env       string    `yaml:"env" env:"APP_ENV" desc:"environment: development, production or test"`
+	Log       Log       `yaml:"log"`
+	HTTP      HTTP      `yaml:"http"`
+	DB        DB        `yaml:"db"`
+	Auth      Auth      `yaml:"auth"`
+	Users     Users     `yaml:"users"`
+	Health    Health    `yaml:"health"`
+	Tracing   Tracing   `yaml:"tracing"`
+	Scheduler Scheduler `yaml:"scheduler"`
+	Transfers Transfers `yaml:"transfers"`
+	Purchases Purchases `yaml:"purchases"`
+	Blobs     Blobs     `yaml:"blobs"`
+	Notifier  Notifier  `yaml:"notifier"`
+}
+
+type Log struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL" desc:"minimum log level: debug, info, warn or error"`
+	Format string `yaml:"format" env:"LOG_FORMAT" desc:"log format: json or text"`
+}
+
+type HTTP struct {
+	Port            string        `yaml:"port" env:"HTTP_PORT" desc:"port to listen on"`
+	MetricsPort     string        `yaml:"metrics_port" env:"HTTP_METRICS_PORT" desc:"port serving /metrics, kept off the public port"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT" desc:"maximum duration for reading a request"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT" desc:"maximum duration for writing a response"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" desc:"how long keep-alive connections stay open"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT" desc:"deadline for draining in-flight requests on shutdown"`
+	DrainDelay      time.Duration `yaml:"drain_delay" env:"HTTP_DRAIN_DELAY" desc:"how long to report not-ready before shutting down"`
+}
+
+type DB struct {
+	Host               string        `yaml:"host" env:"DB_HOST" desc:"Postgres host"`
+	Port               string        `yaml:"port" env:"DB_PORT" desc:"Postgres port"`
+	User               string        `yaml:"user" env:"DB_USER" desc:"Postgres user"`
+	Password           string        `yaml:"password" env:"DB_PASSWORD" secret:"true" desc:"Postgres password"`
+	Name               string        `yaml:"name" env:"DB_NAME" desc:"Postgres database name"`
+	MaxIdleConnections int           `yaml:"max_idle_connections" env:"DB_MAX_IDLE_CONNECTIONS" desc:"idle connections kept in the pool"`
+	MaxOpenConnections int           `yaml:"max_open_connections" env:"DB_MAX_OPEN_CONNECTIONS" desc:"maximum open connections"`
+	ConnectRetries     int           `yaml:"connect_retries" env:"DB_CONNECT_RETRIES" desc:"connection attempts at startup"`
+	RetryDelay         time.Duration `yaml:"retry_delay" env:"DB_RETRY_DELAY" desc:"delay between connection attempts"`
+}
+
+type Auth struct {
+	SecretKey    string        `yaml:"secret_key" env:"SECRET_KEY" secret:"true" desc:"key used to sign access tokens"`
+	TokenTTL     time.Duration `yaml:"token_ttl" env:"TOKEN_TTL" desc:"lifetime of access tokens"`
+	CookieDomain string        `yaml:"cookie_domain" env:"COOKIE_DOMAIN" desc:"domain of the accessToken cookie"`
+	CookieSecure bool          `yaml:"cookie_secure" env:"COOKIE_SECURE" desc:"send the accessToken cookie over HTTPS only"`
+	BcryptCost   int           `yaml:"bcrypt_cost" env:"BCRYPT_COST" desc:"bcrypt cost for password hashes"`
+}
+
+type Users struct {
+	StartingBalance  float64 `yaml:"starting_balance" env:"STARTING_BALANCE" desc:"coins granted to a newly registered user"`
+	CoinExpiryMonths int     `yaml:"coin_expiry_months" env:"COIN_EXPIRY_MONTHS" desc:"months after which granted coins expire, 0 disables expiry"`
+}
+
+type Tracing struct {
+	Exporter     string  `yaml:"exporter" env:"TRACING_EXPORTER" desc:"span exporter: none, stdout, file or otlp"`
+	File         string  `yaml:"file" env:"TRACING_FILE" desc:"file the file exporter writes spans to"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT" desc:"host:port of the OTLP/HTTP collector"`
+	SampleRatio  float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" desc:"share of traces to sample"`
+	ServiceName  string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" desc:"service.name resource attribute"`
+}
+
+type Health struct {
+	CheckTimeout   time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" desc:"deadline for readiness checks"`
+	PoolSaturation float64       `yaml:"pool_saturation" env:"HEALTH_POOL_SATURATION" desc:"share of busy DB connections at which the instance is not ready"`
+}
+
+// Transfers holds the default transfer limits, roles listed in the config
+// file replace them as a whole, a limit left out of a role means no limit.
+type Transfers struct {
+	TransferLimits `yaml:",inline"`
+	Roles          map[string]TransferLimits `yaml:"roles"`
+	RequestTTL     time.Duration             `yaml:"request_ttl" env:"COIN_REQUEST_TTL" desc:"how long a coin request can be accepted"`
+	HoldTTL        time.Duration             `yaml:"hold_ttl" env:"HOLD_TTL" desc:"how long a hold reserves coins when no expiry is given"`
+}
+
+type TransferLimits struct {
+	MaxAmount       float64 `yaml:"max_amount" env:"TRANSFER_MAX_AMOUNT" desc:"largest single transfer, 0 means no limit"`
+	DailyAmount     float64 `yaml:"daily_amount" env:"TRANSFER_DAILY_AMOUNT" desc:"coins a user can send per UTC day, 0 means no limit"`
+	DailyCount      int     `yaml:"daily_count" env:"TRANSFER_DAILY_COUNT" desc:"transfers a user can make per UTC day, 0 means no limit"`
+	DailyRecipients int     `yaml:"daily_recipients" env:"TRANSFER_DAILY_RECIPIENTS" desc:"distinct users a user can send to per UTC day, 0 means no limit"`
+}
+
+// Purchases configures the manager approval of expensive purchases.
+type Purchases struct {
+	ApprovalThreshold float64       `yaml:"approval_threshold" env:"PURCHASE_APPROVAL_THRESHOLD" desc:"price from which a purchase needs the manager's approval, 0 disables approvals"`
+	ApprovalTimeout   time.Duration `yaml:"approval_timeout" env:"PURCHASE_APPROVAL_TIMEOUT" desc:"how long an approver has before the approval escalates to admins, and then expires"`
+}
+
+// Blobs configures where uploaded files such as merch images are stored.
+type Blobs struct {
+	Store          string `yaml:"store" env:"BLOB_STORE" desc:"blob store for uploaded files: local"`
+	Dir            string `yaml:"dir" env:"BLOB_DIR" desc:"directory the local blob store writes files to"`
+	MaxImageSize   int64  `yaml:"max_image_size" env:"MAX_IMAGE_SIZE" desc:"largest merch image upload in bytes"`
+	MaxImagePixels int64  `yaml:"max_image_pixels" env:"MAX_IMAGE_PIXELS" desc:"largest merch image in pixels, width times height"`
+}
+
+// Notifier configures where alerts such as wishlist notifications are
+// delivered besides the user's inbox.
+type Notifier struct {
+	Driver     string        `yaml:"driver" env:"NOTIFIER" desc:"where alerts are delivered besides the inbox: none, log or webhook"`
+	WebhookURL string        `yaml:"webhook_url" env:"NOTIFIER_WEBHOOK_URL" desc:"URL the webhook notifier posts alerts to"`
+	Timeout    time.Duration `yaml:"timeout" env:"NOTIFIER_TIMEOUT" desc:"deadline for delivering an alert"`
+}
+
+type Scheduler struct {
+	Enabled  bool          `yaml:"enabled" env:"SCHEDULER_ENABLED" desc:"run background jobs such as coin allowances"`
+	Interval time.Duration `yaml:"interval" env:"SCHEDULER_INTERVAL" desc:"how often background jobs check for due work"`
+}
+
+func Default() *Config {
+	return &Config{
+		Env: EnvDevelopment,
+		Log: Log{
+			Level:  "info",
+			Format: "json",
+		},
+		HTTP: HTTP{
+			Port:            "8080",
+			MetricsPort:     "9090",
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 20 * time.Second,
+		},
+		DB: DB{
+			Host:               "localhost",
+			Port:               "5432",
+			User:               "postgres",
+			Name:               "postgres",
+			MaxIdleConnections: 3,
+			MaxOpenConnections: 10,
+			ConnectRetries:     10,
+			RetryDelay:         5 * time.Second,
+		},
+		Auth: Auth{
+			TokenTTL:     5 * time.Hour,
+			CookieDomain: "localhost",
+			BcryptCost:   14,
+		},
+		Users: Users{
+			StartingBalance: 1000,
+		},
+		Health: Health{
+			CheckTimeout:   2 * time.Second,
+			PoolSaturation: 0.9,
+		},
+		Tracing: Tracing{
+			Exporter:     "none",
+			File:         "traces.jsonl",
+			OTLPEndpoint: "localhost:4318",
+			SampleRatio:  1,
+			ServiceName:  "shop",
+		},
+		Scheduler: Scheduler{
+			Enabled:  true,
+			Interval: time.Minute,
+		},
+		Transfers: Transfers{
+			RequestTTL: 72 * time.Hour,
+			HoldTTL:    7 * 24 * time.Hour,
+		},
+		Purchases: Purchases{
+			ApprovalTimeout: 48 * time.Hour,
+		},
+		Blobs: Blobs{
+			Store:          "local",
+			Dir:            "data/blobs",
+			MaxImageSize:   5 << 20,
+			MaxImagePixels: 25_000_000,
+		},
+		Notifier: Notifier{
+			Driver:  "none",
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the file given by -config
+// or CONFIG_FILE, the environment and the command line arguments, and validates it.
+func Load(name string, args []string) (*Config, error) {
+	cfg := Default()
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or JSON config file")
+	flagValues := map[string]string{}
+	for _, field := range fields(cfg) {
+		envName := field.env
+		flags.Func(flagName(envName), field.desc, func(value string) error {
+			flagValues[envName] = value
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err = yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", *configFile, err)
+		}
+	}
+
+	for _, field := range fields(cfg) {
+		value, ok := os.LookupEnv(field.env)
+		if flagValue, set := flagValues[field.env]; set {
+			value, ok = flagValue, true
+		}
+		if !ok {
+			continue
+		}
+		if err := setValue(field.value, value); err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s: %w", value, field.env, err)
+		}
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Env == EnvDevelopment || c.Env == EnvProduction || c.Env == EnvTest,
+		"APP_ENV must be one of development, production, test")
+	_, err := log.ParseLevel(c.Log.Level)
+	check(err == nil, "LOG_LEVEL must be one of debug, info, warn, error")
+	check(c.Log.Format == "json" || c.Log.Format == "text", "LOG_FORMAT must be json or text")
+	port, err := strconv.Atoi(c.HTTP.Port)
+	check(err == nil && port > 0 && port < 65536, "HTTP_PORT must be a valid port")
+	metricsPort, err := strconv.Atoi(c.HTTP.MetricsPort)
+	check(err == nil && metricsPort > 0 && metricsPort < 65536 && metricsPort != port,
+		"HTTP_METRICS_PORT must be a valid port other than HTTP_PORT")
+	check(c.HTTP.ReadTimeout > 0 && c.HTTP.WriteTimeout > 0 && c.HTTP.IdleTimeout > 0,
+		"HTTP_READ_TIMEOUT, HTTP_WRITE_TIMEOUT and HTTP_IDLE_TIMEOUT must be positive")
+	check(c.HTTP.ShutdownTimeout > 0, "HTTP_SHUTDOWN_TIMEOUT must be positive")
+	check(c.HTTP.DrainDelay >= 0, "HTTP_DRAIN_DELAY must not be negative")
+	check(c.DB.Host != "", "DB_HOST must not be empty")
+	check(c.DB.MaxOpenConnections > 0, "DB_MAX_OPEN_CONNECTIONS must be positive")
+	check(c.DB.MaxIdleConnections >= 0 && c.DB.MaxIdleConnections <= c.DB.MaxOpenConnections,
+		"DB_MAX_IDLE_CONNECTIONS must be between 0 and DB_MAX_OPEN_CONNECTIONS")
+	check(c.DB.ConnectRetries > 0, "DB_CONNECT_RETRIES must be positive")
+	check(c.DB.RetryDelay >= 0, "DB_RETRY_DELAY must not be negative")
+	check(c.Auth.SecretKey != "", "SECRET_KEY must not be empty")
+	check(c.Auth.TokenTTL > 0, "TOKEN_TTL must be positive")
+	check(c.Auth.BcryptCost >= bcrypt.MinCost && c.Auth.BcryptCost <= bcrypt.MaxCost,
+		"BCRYPT_COST must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	check(c.Env != EnvProduction || c.Auth.CookieSecure, "COOKIE_SECURE must be enabled in production")
+	check(c.Users.StartingBalance >= 0, "STARTING_BALANCE must not be negative")
+	check(c.Users.CoinExpiryMonths >= 0, "COIN_EXPIRY_MONTHS must not be negative")
+	check(c.Health.CheckTimeout > 0, "HEALTH_CHECK_TIMEOUT must be positive")
+	check(c.Health.PoolSaturation > 0 && c.Health.PoolSaturation <= 1, "HEALTH_POOL_SATURATION must be in (0, 1]")
+	check(c.Tracing.Exporter == "none" || c.Tracing.Exporter == "stdout" || c.Tracing.Exporter == "file" || c.Tracing.Exporter == "otlp",
+		"TRACING_EXPORTER must be one of none, stdout, file, otlp")
+	check(c.Tracing.Exporter != "file" || c.Tracing.File != "", "TRACING_FILE must be set for the file exporter")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO must be in [0, 1]")
+	check(c.Scheduler.Interval > 0, "SCHEDULER_INTERVAL must be positive")
+	check(c.Transfers.valid(), "TRANSFER_MAX_AMOUNT, TRANSFER_DAILY_AMOUNT, TRANSFER_DAILY_COUNT and TRANSFER_DAILY_RECIPIENTS must not be negative")
+	for role, limits := range c.Transfers.Roles {
+		check(limits.valid(), "transfer limits of role %s must not be negative", role)
+	}
+	check(c.Transfers.RequestTTL > 0, "COIN_REQUEST_TTL must be positive")
+	check(c.Transfers.HoldTTL > 0, "HOLD_TTL must be positive")
+	check(c.Purchases.ApprovalThreshold >= 0, "PURCHASE_APPROVAL_THRESHOLD must not be negative")
+	check(c.Purchases.ApprovalTimeout > 0, "PURCHASE_APPROVAL_TIMEOUT must be positive")
+	check(c.Blobs.Store == "local", "BLOB_STORE must be local")
+	check(c.Blobs.Store != "local" || c.Blobs.Dir != "", "BLOB_DIR must be set for the local blob store")
+	check(c.Blobs.MaxImageSize > 0, "MAX_IMAGE_SIZE must be positive")
+	check(c.Blobs.MaxImagePixels > 0, "MAX_IMAGE_PIXELS must be positive")
+	check(c.Notifier.Driver == "none" || c.Notifier.Driver == "log" || c.Notifier.Driver == "webhook",
+		"NOTIFIER must be one of none, log, webhook")
+	check(c.Notifier.Driver != "webhook" || c.Notifier.WebhookURL != "", "NOTIFIER_WEBHOOK_URL must be set for the webhook notifier")
+	check(c.Notifier.Timeout > 0, "NOTIFIER_TIMEOUT must be positive")
+
+	return errors.Join(errs...)
+}
+
+func (l TransferLimits) valid() bool {
+	return l.MaxAmount >= 0 && l.DailyAmount >= 0 && l.DailyCount >= 0 && l.DailyRecipients >= 0
+}
+
+func (c *Config) IsProduction() bool {
+	return c.Env == EnvProduction
+}
+
+// Redacted returns a copy of the config with every secret replaced by a placeholder.
+func (c *Config) Redacted() *Config {
+	redactedCfg := *c
+	for _, field := range fields(&redactedCfg) {
+		if field.secret && field.value.String() != "" {
+			field.value.SetString(redacted)
+		}
+	}
+	return &redactedCfg
+}
+
+// Dump renders the redacted config as YAML.
+func (c *Config) Dump() string {
+	data, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+type field struct {
+	env    string
+	desc   string
+	secret bool
+	value  reflect.Value
+}
+
+func fields(cfg *Config) []field {
+	var result []field
+	var walk func(reflect.Value)
+	walk = func(v reflect.Value) {
+		for i := 0; i < v.NumField(); i++ {
+			structField := v.Type().Field(i)
+			if env, ok := structField.Tag.Lookup("env"); ok {
+				result = append(result, field{
+					env:    env,
+					desc:   structField.Tag.Get("desc"),
+					secret: structField.Tag.Get("secret") == "true",
+					value:  v.Field(i),
+				})
+				continue
+			}
+			if structField.Type.Kind() == reflect.Struct {
+				walk(v.Field(i))
+			}
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem())
+	return result
+}
+
+func flagName(env string) string {
+	return strings.ReplaceAll(strings.ToLower(env), "_", "-")
+}
+
+func setValue(v reflect.Value, value string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(duration))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(parsed)
+	default:
+		return fmt.Errorf("unsupported config type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_Defaults(t *testing.T) {
+	t.Setenv("SECRET_KEY", "secret")
+
+	cfg, err := Load("test", nil)
+	assert.NoError(t, err)
+
+	expected := Default()
+	expected.Auth.SecretKey = "secret"
+	assert.Equal(t, expected, cfg)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+http:
+  port: "9000"
+db:
+  host: file-host
+  max_open_connections: 20
+auth:
+  token_ttl: 1h
+users:
+  starting_balance: 500
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SECRET_KEY", "secret")
+	t.Setenv("DB_HOST", "env-host")
+	t.Setenv("HTTP_PORT", "9001")
+
+	cfg, err := Load("test", []string{"-config", path, "-http-port", "9002", "-db-retry-delay", "1s"})
+	assert.NoError(t, err)
+	assert.Equal(t, "9002", cfg.HTTP.Port)
+	assert.Equal(t, "env-host", cfg.DB.Host)
+	assert.Equal(t, 20, cfg.DB.MaxOpenConnections)
+	assert.Equal(t, time.Second, cfg.DB.RetryDelay)
+	assert.Equal(t, time.Hour, cfg.Auth.TokenTTL)
+	assert.Equal(t, 500.0, cfg.Users.StartingBalance)
+}
+
+func TestLoad_TransferRoles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+transfers:
+  max_amount: 500
+  daily_count: 10
+  roles:
+    admin:
+      daily_count: 100
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SECRET_KEY", "secret")
+	t.Setenv("TRANSFER_DAILY_AMOUNT", "1000")
+
+	cfg, err := Load("test", []string{"-config", path})
+	assert.NoError(t, err)
+	assert.Equal(t, TransferLimits{MaxAmount: 500, DailyAmount: 1000, DailyCount: 10}, cfg.Transfers.TransferLimits)
+	assert.Equal(t, map[string]TransferLimits{"admin": {DailyCount: 100}}, cfg.Transfers.Roles)
+}
+
+func TestLoad_EmptySecretKey(t *testing.T) {
+	t.Setenv("SECRET_KEY", "")
+
+	_, err := Load("test", nil)
+	assert.ErrorContains(t, err, "SECRET_KEY must not be empty")
+}
+
+func TestLoad_InvalidValue(t *testing.T) {
+	t.Setenv("SECRET_KEY", "secret")
+	t.Setenv("TOKEN_TTL", "five hours")
+
+	_, err := Load("test", nil)
+	assert.ErrorContains(t, err, "TOKEN_TTL")
+}
+
+func TestValidate(t *testing.T) {
+	testTable := []struct {
+		name     string
+		modify   func(*Config)
+		expected string
+	}{
+		{name: "UnknownEnv", modify: func(c *Config) { c.Env = "staging" }, expected: "APP_ENV"},
+		{name: "UnknownLogLevel", modify: func(c *Config) { c.Log.Level = "verbose" }, expected: "LOG_LEVEL"},
+		{name: "UnknownLogFormat", modify: func(c *Config) { c.Log.Format = "xml" }, expected: "LOG_FORMAT"},
+		{name: "InvalidPort", modify: func(c *Config) { c.HTTP.Port = "http" }, expected: "HTTP_PORT"},
+		{name: "SharedMetricsPort", modify: func(c *Config) { c.HTTP.MetricsPort = c.HTTP.Port }, expected: "HTTP_METRICS_PORT"},
+		{name: "NoShutdownTimeout", modify: func(c *Config) { c.HTTP.ShutdownTimeout = 0 }, expected: "HTTP_SHUTDOWN_TIMEOUT"},
+		{name: "IdleAboveOpen", modify: func(c *Config) { c.DB.MaxIdleConnections = 20 }, expected: "DB_MAX_IDLE_CONNECTIONS"},
+		{name: "NoRetries", modify: func(c *Config) { c.DB.ConnectRetries = 0 }, expected: "DB_CONNECT_RETRIES"},
+		{name: "BcryptCostTooLow", modify: func(c *Config) { c.Auth.BcryptCost = 1 }, expected: "BCRYPT_COST"},
+		{name: "InsecureCookieInProduction", modify: func(c *Config) { c.Env = EnvProduction }, expected: "COOKIE_SECURE"},
+		{name: "NegativeStartingBalance", modify: func(c *Config) { c.Users.StartingBalance = -1 }, expected: "STARTING_BALANCE"},
+		{name: "NegativeCoinExpiry", modify: func(c *Config) { c.Users.CoinExpiryMonths = -1 }, expected: "COIN_EXPIRY_MONTHS"},
+		{name: "UnknownTracingExporter", modify: func(c *Config) { c.Tracing.Exporter = "jaeger" }, expected: "TRACING_EXPORTER"},
+		{name: "PoolSaturationAboveOne", modify: func(c *Config) { c.Health.PoolSaturation = 1.5 }, expected: "HEALTH_POOL_SATURATION"},
+		{name: "NegativeTransferLimit", modify: func(c *Config) { c.Transfers.DailyCount = -1 }, expected: "TRANSFER_DAILY_COUNT"},
+		{name: "NegativeRoleTransferLimit", modify: func(c *Config) {
+			c.Transfers.Roles = map[string]TransferLimits{"admin": {MaxAmount: -1}}
+		}, expected: "role admin"},
+		{name: "NoRequestTTL", modify: func(c *Config) { c.Transfers.RequestTTL = 0 }, expected: "COIN_REQUEST_TTL"},
+		{name: "NoHoldTTL", modify: func(c *Config) { c.Transfers.HoldTTL = 0 }, expected: "HOLD_TTL"},
+		{name: "NegativeApprovalThreshold", modify: func(c *Config) { c.Purchases.ApprovalThreshold = -1 }, expected: "PURCHASE_APPROVAL_THRESHOLD"},
+		{name: "NoApprovalTimeout", modify: func(c *Config) { c.Purchases.ApprovalTimeout = 0 }, expected: "PURCHASE_APPROVAL_TIMEOUT"},
+		{name: "UnknownBlobStore", modify: func(c *Config) { c.Blobs.Store = "s3" }, expected: "BLOB_STORE"},
+		{name: "NoBlobDir", modify: func(c *Config) { c.Blobs.Dir = "" }, expected: "BLOB_DIR"},
+		{name: "NoMaxImageSize", modify: func(c *Config) { c.Blobs.MaxImageSize = 0 }, expected: "MAX_IMAGE_SIZE"},
+		{name: "NoMaxImagePixels", modify: func(c *Config) { c.Blobs.MaxImagePixels = 0 }, expected: "MAX_IMAGE_PIXELS"},
+		{name: "UnknownNotifier", modify: func(c *Config) { c.Notifier.Driver = "sms" }, expected: "NOTIFIER"},
+		{name: "NoWebhookURL", modify: func(c *Config) { c.Notifier.Driver = "webhook" }, expected: "NOTIFIER_WEBHOOK_URL"},
+		{name: "NoNotifierTimeout", modify: func(c *Config) { c.Notifier.Timeout = 0 }, expected: "NOTIFIER_TIMEOUT"},
+		{name: "NoSchedulerInterval", modify: func(c *Config) { c.Scheduler.Interval = 0 }, expected: "SCHEDULER_INTERVAL"},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Auth.SecretKey = "secret"
+			test.modify(cfg)
+			assert.ErrorContains(t, cfg.Validate(), test.expected)
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Auth.SecretKey = "secret"
+	cfg.DB.Password = "password"
+
+	dump := cfg.Dump()
+	assert.NotContains(t, dump, "secret_key: secret")
+	assert.NotContains(t, dump, "password: password")
+	assert.Contains(t, dump, redacted)
+	assert.Contains(t, dump, "token_ttl: 5h0m0s")
+
+	assert.Equal(t, "secret", cfg.Auth.SecretKey, "redaction must not modify the original config")
+}
@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := Load(files)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+
+	for i, migration := range migrations {
+		assert.Equal(t, int64(i+1), migration.Version, "migration versions must be sequential")
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0002_second.up.sql":   {Data: []byte("SELECT 2")},
+		"sql/0002_second.down.sql": {Data: []byte("SELECT -2")},
+		"sql/0001_first.up.sql":    {Data: []byte("SELECT 1")},
+		"sql/0001_first.down.sql":  {Data: []byte("SELECT -1")},
+	}
+
+	migrations, err := Load(fsys)
+	assert.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "first", Up: "SELECT 1", Down: "SELECT -1"},
+		{Version: 2, Name: "second", Up: "SELECT 2", Down: "SELECT -2"},
+	}, migrations)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	testTable := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "MissingDown",
+			fsys: fstest.MapFS{"sql/0001_first.up.sql": {Data: []byte("SELECT 1")}},
+		},
+		{
+			name: "InvalidVersion",
+			fsys: fstest.MapFS{
+				"sql/first_one.up.sql":   {Data: []byte("SELECT 1")},
+				"sql/first_one.down.sql": {Data: []byte("SELECT -1")},
+			},
+		},
+		{
+			name: "UnexpectedFile",
+			fsys: fstest.MapFS{"sql/0001_first.sql": {Data: []byte("SELECT 1")}},
+		},
+		{
+			name: "ConflictingNames",
+			fsys: fstest.MapFS{
+				"sql/0001_first.up.sql":   {Data: []byte("SELECT 1")},
+				"sql/0001_other.down.sql": {Data: []byte("SELECT -1")},
+			},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Load(test.fsys)
+			assert.Error(t, err)
+		})
+	}
+}
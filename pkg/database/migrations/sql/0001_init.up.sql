@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS users (
+    username   text PRIMARY KEY,
+    password   text NOT NULL,
+    balance    decimal(20, 8) DEFAULT 1000,
+    created_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS merches (
+    name  text PRIMARY KEY,
+    price decimal(20, 8) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS purchases (
+    guid       text PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    text NOT NULL,
+    merch_name text NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_purchases_user FOREIGN KEY (user_id) REFERENCES users (username),
+    CONSTRAINT fk_purchases_merch FOREIGN KEY (merch_name) REFERENCES merches (name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_merch ON purchases (user_id, merch_name);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    guid              text PRIMARY KEY,
+    created_at        timestamptz,
+    receiver_username text NOT NULL,
+    sender_username   text NOT NULL,
+    money_amount      decimal(20, 8) NOT NULL,
+    CONSTRAINT fk_transactions_receiver FOREIGN KEY (receiver_username) REFERENCES users (username),
+    CONSTRAINT fk_transactions_sender FOREIGN KEY (sender_username) REFERENCES users (username)
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_transactions_receiver;
+DROP INDEX IF EXISTS idx_transactions_sender;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_transactions_sender ON transactions (sender_username, created_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_receiver ON transactions (receiver_username, created_at);
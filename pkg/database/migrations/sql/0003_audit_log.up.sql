@@ -0,0 +1,31 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'user';
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL,
+    action     text NOT NULL,
+    actor      text NOT NULL,
+    subject    text NOT NULL DEFAULT '',
+    ip         text NOT NULL DEFAULT '',
+    user_agent text NOT NULL DEFAULT '',
+    request_id text NOT NULL DEFAULT '',
+    before     text NOT NULL DEFAULT '',
+    after      text NOT NULL DEFAULT '',
+    prev_hash  text NOT NULL,
+    hash       text NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_subject ON audit_log (subject, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log (action, created_at);
+
+-- Entries can only be appended, TRUNCATE is left to whoever owns the table.
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
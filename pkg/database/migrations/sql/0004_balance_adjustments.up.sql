@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS balance_adjustments (
+    guid       text PRIMARY KEY,
+    created_at timestamptz NOT NULL,
+    username   text NOT NULL,
+    amount     decimal(20, 8) NOT NULL,
+    reason     text NOT NULL,
+    actor      text NOT NULL,
+    batch_id   text NOT NULL DEFAULT '',
+    CONSTRAINT fk_balance_adjustments_user FOREIGN KEY (username) REFERENCES users (username)
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_adjustments_user ON balance_adjustments (username, created_at);
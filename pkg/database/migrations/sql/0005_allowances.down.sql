@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS allowance_runs;
+DROP TABLE IF EXISTS allowance_policies;
+ALTER TABLE users DROP COLUMN IF EXISTS active;
+ALTER TABLE users DROP COLUMN IF EXISTS department;
@@ -0,0 +1,27 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS department text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS active boolean NOT NULL DEFAULT true;
+
+CREATE TABLE IF NOT EXISTS allowance_policies (
+    id         bigserial PRIMARY KEY,
+    name       text NOT NULL UNIQUE,
+    schedule   text NOT NULL,
+    amount     decimal(20, 8) NOT NULL CHECK (amount > 0),
+    role       text NOT NULL DEFAULT '',
+    department text NOT NULL DEFAULT '',
+    paused     boolean NOT NULL DEFAULT false,
+    created_at timestamptz NOT NULL
+);
+
+-- A run is identified by its policy and scheduled time, the unique key makes
+-- sure that no occurrence is paid twice, even by several instances.
+CREATE TABLE IF NOT EXISTS allowance_runs (
+    guid          text PRIMARY KEY,
+    policy_id     bigint NOT NULL REFERENCES allowance_policies (id),
+    scheduled_for timestamptz NOT NULL,
+    trigger       text NOT NULL,
+    actor         text NOT NULL,
+    users_paid    integer NOT NULL,
+    total         decimal(20, 8) NOT NULL,
+    created_at    timestamptz NOT NULL,
+    CONSTRAINT uq_allowance_runs_occurrence UNIQUE (policy_id, scheduled_for)
+);
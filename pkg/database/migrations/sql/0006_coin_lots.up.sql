@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS coin_lots (
+    id         bigserial PRIMARY KEY,
+    username   text NOT NULL,
+    amount     decimal(20, 8) NOT NULL CHECK (amount > 0),
+    remaining  decimal(20, 8) NOT NULL CHECK (remaining >= 0),
+    granted_at timestamptz NOT NULL,
+    source     text NOT NULL,
+    CONSTRAINT fk_coin_lots_user FOREIGN KEY (username) REFERENCES users (username)
+);
+
+CREATE INDEX IF NOT EXISTS idx_coin_lots_open ON coin_lots (username, granted_at) WHERE remaining > 0;
+
+-- Coins held before lots were tracked count as granted by this migration.
+INSERT INTO coin_lots (username, amount, remaining, granted_at, source)
+SELECT username, balance, balance, now(), 'migration'
+FROM users
+WHERE balance > 0;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_transactions_feed;
+ALTER TABLE transactions DROP COLUMN IF EXISTS private;
+ALTER TABLE transactions DROP COLUMN IF EXISTS category;
+ALTER TABLE transactions DROP COLUMN IF EXISTS message;
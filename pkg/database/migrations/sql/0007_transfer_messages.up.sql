@@ -0,0 +1,6 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS message text NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS category text NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS private boolean NOT NULL DEFAULT false;
+
+-- The kudos feed lists the latest public transfers.
+CREATE INDEX IF NOT EXISTS idx_transactions_feed ON transactions (created_at DESC) WHERE NOT private;
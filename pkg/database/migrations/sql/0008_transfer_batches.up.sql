@@ -0,0 +1,2 @@
+-- Transfers sent to many recipients in one request share a batch ID.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS batch_id text NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS coin_requests (
+    id               bigserial PRIMARY KEY,
+    requester        text NOT NULL REFERENCES users (username),
+    payer            text NOT NULL REFERENCES users (username),
+    amount           decimal(20, 8) NOT NULL CHECK (amount > 0),
+    message          text NOT NULL DEFAULT '',
+    status           text NOT NULL,
+    created_at       timestamptz NOT NULL,
+    expires_at       timestamptz NOT NULL,
+    resolved_at      timestamptz,
+    transaction_guid text REFERENCES transactions (guid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_coin_requests_payer ON coin_requests (payer, created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_coin_requests_requester ON coin_requests (requester, created_at) WHERE status = 'pending';
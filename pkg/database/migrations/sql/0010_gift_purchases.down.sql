@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS notifications;
+DROP INDEX IF EXISTS idx_purchases_recipient;
+ALTER TABLE purchases DROP COLUMN IF EXISTS gift_message;
+ALTER TABLE purchases DROP COLUMN IF EXISTS recipient_id;
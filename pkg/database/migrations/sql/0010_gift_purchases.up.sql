@@ -0,0 +1,19 @@
+-- Purchases made before gifts were delivered to the buyer.
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS recipient_id text REFERENCES users (username);
+UPDATE purchases SET recipient_id = user_id WHERE recipient_id IS NULL;
+ALTER TABLE purchases ALTER COLUMN recipient_id SET NOT NULL;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS gift_message text NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_purchases_recipient ON purchases (recipient_id, merch_name);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id         bigserial PRIMARY KEY,
+    username   text NOT NULL REFERENCES users (username),
+    kind       text NOT NULL,
+    message    text NOT NULL,
+    reference  text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL,
+    read_at    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications (username, created_at);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id                bigserial PRIMARY KEY,
+    sender_username   text NOT NULL REFERENCES users (username),
+    receiver_username text NOT NULL REFERENCES users (username),
+    amount            decimal(20, 8) NOT NULL CHECK (amount > 0),
+    message           text NOT NULL DEFAULT '',
+    category          text NOT NULL DEFAULT '',
+    private           boolean NOT NULL DEFAULT false,
+    status            text NOT NULL,
+    execute_at        timestamptz NOT NULL,
+    created_at        timestamptz NOT NULL,
+    resolved_at       timestamptz,
+    transaction_guid  text REFERENCES transactions (guid),
+    failure_reason    text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_due ON scheduled_transfers (execute_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_sender ON scheduled_transfers (sender_username, execute_at);
@@ -0,0 +1,18 @@
+-- Coins reserved by active holds, the available balance is balance - held.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS held decimal(20, 8) NOT NULL DEFAULT 0 CHECK (held >= 0);
+
+CREATE TABLE IF NOT EXISTS holds (
+    id              bigserial PRIMARY KEY,
+    username        text NOT NULL REFERENCES users (username),
+    amount          decimal(20, 8) NOT NULL CHECK (amount > 0),
+    captured        decimal(20, 8) NOT NULL DEFAULT 0,
+    reason          text NOT NULL,
+    status          text NOT NULL,
+    created_at      timestamptz NOT NULL,
+    expires_at      timestamptz NOT NULL,
+    resolved_at     timestamptz,
+    adjustment_guid text REFERENCES balance_adjustments (guid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_holds_username ON holds (username) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_holds_expires_at ON holds (expires_at) WHERE status = 'active';
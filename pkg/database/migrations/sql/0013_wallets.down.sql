@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS wallet_approvals;
+DROP TABLE IF EXISTS wallet_purchases;
+ALTER TABLE purchases DROP COLUMN IF EXISTS wallet_id;
+DROP TABLE IF EXISTS wallet_contributions;
+DROP TABLE IF EXISTS wallet_members;
+DROP TABLE IF EXISTS wallets;
@@ -0,0 +1,51 @@
+CREATE TABLE IF NOT EXISTS wallets (
+    id         bigserial PRIMARY KEY,
+    name       text NOT NULL,
+    balance    decimal(20, 8) NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    quorum     integer NOT NULL DEFAULT 0 CHECK (quorum >= 0),
+    created_by text NOT NULL REFERENCES users (username),
+    created_at timestamptz NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS wallet_members (
+    wallet_id bigint NOT NULL REFERENCES wallets (id),
+    username  text NOT NULL REFERENCES users (username),
+    role      text NOT NULL,
+    joined_at timestamptz NOT NULL,
+    PRIMARY KEY (wallet_id, username)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_members_username ON wallet_members (username);
+
+CREATE TABLE IF NOT EXISTS wallet_contributions (
+    guid       text PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id  bigint NOT NULL REFERENCES wallets (id),
+    username   text NOT NULL REFERENCES users (username),
+    amount     decimal(20, 8) NOT NULL CHECK (amount > 0),
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_contributions_wallet ON wallet_contributions (wallet_id, created_at);
+
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS wallet_id bigint REFERENCES wallets (id);
+
+CREATE TABLE IF NOT EXISTS wallet_purchases (
+    id            bigserial PRIMARY KEY,
+    wallet_id     bigint NOT NULL REFERENCES wallets (id),
+    merch_name    text NOT NULL REFERENCES merches (name),
+    price         decimal(20, 8) NOT NULL,
+    proposed_by   text NOT NULL REFERENCES users (username),
+    status        text NOT NULL,
+    purchase_guid text,
+    created_at    timestamptz NOT NULL,
+    resolved_at   timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_purchases_wallet ON wallet_purchases (wallet_id, created_at);
+
+CREATE TABLE IF NOT EXISTS wallet_approvals (
+    wallet_purchase_id bigint NOT NULL REFERENCES wallet_purchases (id),
+    username           text NOT NULL REFERENCES users (username),
+    created_at         timestamptz NOT NULL,
+    PRIMARY KEY (wallet_purchase_id, username)
+);
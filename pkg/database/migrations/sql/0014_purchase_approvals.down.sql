@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS purchase_approvals;
+ALTER TABLE users DROP COLUMN IF EXISTS manager;
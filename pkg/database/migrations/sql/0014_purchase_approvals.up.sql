@@ -0,0 +1,23 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS manager text NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS purchase_approvals (
+    id            bigserial PRIMARY KEY,
+    username      text NOT NULL REFERENCES users (username),
+    recipient_id  text NOT NULL REFERENCES users (username),
+    gift_message  text NOT NULL DEFAULT '',
+    merch_name    text NOT NULL REFERENCES merches (name),
+    price         decimal(20, 8) NOT NULL,
+    approver      text NOT NULL DEFAULT '',
+    escalated     boolean NOT NULL DEFAULT false,
+    hold_id       bigint NOT NULL REFERENCES holds (id),
+    status        text NOT NULL,
+    created_at    timestamptz NOT NULL,
+    due_at        timestamptz NOT NULL,
+    resolved_at   timestamptz,
+    resolved_by   text NOT NULL DEFAULT '',
+    purchase_guid text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_approvals_approver ON purchase_approvals (approver) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_purchase_approvals_due ON purchase_approvals (due_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_purchase_approvals_username ON purchase_approvals (username, created_at);
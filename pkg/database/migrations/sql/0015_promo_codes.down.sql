@@ -0,0 +1,7 @@
+ALTER TABLE purchase_approvals DROP COLUMN IF EXISTS promo_codes;
+ALTER TABLE purchase_approvals DROP COLUMN IF EXISTS discount;
+ALTER TABLE purchases DROP COLUMN IF EXISTS promo_codes;
+ALTER TABLE purchases DROP COLUMN IF EXISTS discount;
+ALTER TABLE purchases DROP COLUMN IF EXISTS price;
+DROP TABLE IF EXISTS promo_redemptions;
+DROP TABLE IF EXISTS promo_codes;
@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS promo_codes (
+    code              text PRIMARY KEY,
+    kind              text NOT NULL,
+    amount            decimal(20, 8) NOT NULL,
+    items             jsonb,
+    starts_at         timestamptz,
+    ends_at           timestamptz,
+    max_uses          integer NOT NULL DEFAULT 0,
+    max_uses_per_user integer NOT NULL DEFAULT 0,
+    stackable         boolean NOT NULL DEFAULT false,
+    active            boolean NOT NULL DEFAULT true,
+    created_by        text NOT NULL DEFAULT '',
+    created_at        timestamptz NOT NULL
+);
+
+-- purchase_guid stays empty while the purchase waits for its approval.
+CREATE TABLE IF NOT EXISTS promo_redemptions (
+    id            bigserial PRIMARY KEY,
+    code          text NOT NULL REFERENCES promo_codes (code),
+    username      text NOT NULL REFERENCES users (username),
+    discount      decimal(20, 8) NOT NULL,
+    purchase_guid text NOT NULL DEFAULT '',
+    approval_id   bigint REFERENCES purchase_approvals (id),
+    created_at    timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_promo_redemptions_code ON promo_redemptions (code, username);
+CREATE INDEX IF NOT EXISTS idx_promo_redemptions_approval ON promo_redemptions (approval_id) WHERE approval_id IS NOT NULL;
+
+-- Purchases made before prices were recorded get the price of their wallet
+-- purchase, or the current price of the item.
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS price decimal(20, 8);
+UPDATE purchases SET price = wallet_purchases.price
+FROM wallet_purchases
+WHERE wallet_purchases.purchase_guid = purchases.guid AND purchases.price IS NULL;
+UPDATE purchases SET price = merches.price
+FROM merches
+WHERE merches.name = purchases.merch_name AND purchases.price IS NULL;
+ALTER TABLE purchases ALTER COLUMN price SET NOT NULL;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS discount decimal(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS promo_codes jsonb;
+
+ALTER TABLE purchase_approvals ADD COLUMN IF NOT EXISTS discount decimal(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE purchase_approvals ADD COLUMN IF NOT EXISTS promo_codes jsonb;
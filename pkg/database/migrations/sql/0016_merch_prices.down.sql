@@ -0,0 +1,3 @@
+ALTER TABLE purchases DROP COLUMN IF EXISTS unit_price;
+ALTER TABLE purchases RENAME COLUMN total TO price;
+DROP TABLE IF EXISTS merch_prices;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS merch_prices (
+    id             bigserial PRIMARY KEY,
+    merch_name     text NOT NULL REFERENCES merches (name),
+    price          decimal(20, 8) NOT NULL,
+    effective_from timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_merch_prices_merch ON merch_prices (merch_name, effective_from);
+
+-- The current prices are the only ones known, they are taken to have been in
+-- effect since the first purchase of the item.
+INSERT INTO merch_prices (merch_name, price, effective_from)
+SELECT merches.name, merches.price,
+       COALESCE((SELECT min(created_at) FROM purchases WHERE purchases.merch_name = merches.name), now())
+FROM merches
+WHERE NOT EXISTS (SELECT 1 FROM merch_prices WHERE merch_prices.merch_name = merches.name);
+
+ALTER TABLE purchases RENAME COLUMN price TO total;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS unit_price decimal(20, 8);
+UPDATE purchases SET unit_price = total + discount WHERE unit_price IS NULL;
+ALTER TABLE purchases ALTER COLUMN unit_price SET NOT NULL;
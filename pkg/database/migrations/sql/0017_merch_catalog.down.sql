@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS merch_images;
+
+DROP INDEX IF EXISTS idx_merches_tags;
+DROP INDEX IF EXISTS idx_merches_category;
+
+ALTER TABLE merches DROP COLUMN IF EXISTS tags;
+ALTER TABLE merches DROP COLUMN IF EXISTS category;
+ALTER TABLE merches DROP COLUMN IF EXISTS description;
+
+DROP TABLE IF EXISTS categories;
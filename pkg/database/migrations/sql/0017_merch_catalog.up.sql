@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS categories (
+    name  text PRIMARY KEY,
+    title text NOT NULL
+);
+
+ALTER TABLE merches ADD COLUMN IF NOT EXISTS description text NOT NULL DEFAULT '';
+ALTER TABLE merches ADD COLUMN IF NOT EXISTS category text REFERENCES categories (name);
+ALTER TABLE merches ADD COLUMN IF NOT EXISTS tags jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_merches_category ON merches (category);
+CREATE INDEX IF NOT EXISTS idx_merches_tags ON merches USING gin (tags);
+
+CREATE TABLE IF NOT EXISTS merch_images (
+    id           bigserial PRIMARY KEY,
+    merch_name   text NOT NULL REFERENCES merches (name),
+    key          text NOT NULL,
+    content_type text NOT NULL,
+    width        integer NOT NULL,
+    height       integer NOT NULL,
+    created_at   timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_merch_images_merch ON merch_images (merch_name);
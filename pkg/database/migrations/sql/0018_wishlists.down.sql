@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS wishlist_items;
+
+ALTER TABLE merches DROP COLUMN IF EXISTS stock;
@@ -0,0 +1,16 @@
+-- NULL means the stock of the item is not tracked.
+ALTER TABLE merches ADD COLUMN IF NOT EXISTS stock integer CHECK (stock >= 0);
+
+CREATE TABLE IF NOT EXISTS wishlist_items (
+    username          text NOT NULL REFERENCES users (username),
+    merch_name        text NOT NULL REFERENCES merches (name),
+    notify_restock    boolean NOT NULL DEFAULT false,
+    notify_price_drop boolean NOT NULL DEFAULT false,
+    seen_price        decimal(20, 8) NOT NULL,
+    seen_in_stock     boolean NOT NULL,
+    created_at        timestamptz NOT NULL,
+    PRIMARY KEY (username, merch_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wishlist_items_watched ON wishlist_items (merch_name)
+    WHERE notify_restock OR notify_price_drop;
@@ -0,0 +1,2 @@
+-- Holds captured by an approved purchase point at the purchase instead of an adjustment.
+ALTER TABLE holds ADD COLUMN IF NOT EXISTS purchase_guid text REFERENCES purchases (guid);
@@ -1,12 +1,12 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"time"
 
-	"shop/domain"
-	hash "shop/pkg"
+	"shop/pkg/config"
+	"shop/pkg/database/migrations"
 
 	log "github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
@@ -19,25 +19,19 @@ type Postgres struct {
 	MaxOpenConnections int
 }
 
-func InitializeDBPostgres(maxIdleConnections, maxOpenConnections int) *Postgres {
+func InitializeDBPostgres(cfg config.DB) *Postgres {
 	postgresDB := Postgres{
-		MaxIdleConnections: maxIdleConnections,
-		MaxOpenConnections: maxOpenConnections,
+		MaxIdleConnections: cfg.MaxIdleConnections,
+		MaxOpenConnections: cfg.MaxOpenConnections,
 	}
 
-	dbHost := os.Getenv("DB_HOST")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-	dbPort := os.Getenv("DB_PORT")
-
-	connectionDBUrl := fmt.Sprintf(`host=%s user=%s password=%s dbname=%s port=%s`, dbHost, dbUser, dbPassword, dbName, dbPort)
-	log.Infof(connectionDBUrl)
+	connectionDBUrl := fmt.Sprintf(`host=%s user=%s password=%s dbname=%s port=%s`, cfg.Host, cfg.User, cfg.Password, cfg.Name, cfg.Port)
+	log.WithFields(log.Fields{"host": cfg.Host, "port": cfg.Port, "user": cfg.User, "dbname": cfg.Name}).Info("connecting to Postgres DB")
 
 	var db *gorm.DB
 	var err error
-	maxRetries := 10
-	retryDelay := 5 * time.Second
+	maxRetries := cfg.ConnectRetries
+	retryDelay := cfg.RetryDelay
 
 	for i := 0; i < maxRetries; i++ {
 		db, err = gorm.Open(postgres.Open(connectionDBUrl), &gorm.Config{})
@@ -62,57 +56,39 @@ func InitializeDBPostgres(maxIdleConnections, maxOpenConnections int) *Postgres
 	postgresDB.db = db
 	log.Info("connected to Postgres DB")
 
-	postgresDB.Migrate()
 	return &postgresDB
 }
 
-func (postgresDB *Postgres) Migrate() {
-	err := postgresDB.db.AutoMigrate(&domain.Purchase{}, &domain.Transaction{}, &domain.User{}, &domain.Merch{})
+func (postgresDB *Postgres) Migrator() (*migrations.Migrator, error) {
+	sqlDB, err := postgresDB.db.DB()
 	if err != nil {
-		log.Fatalf("failed to migrate database: %v", err)
+		return nil, err
 	}
+	return migrations.NewMigrator(sqlDB)
 }
 
-func (postgresDB *Postgres) Seed() {
-	users := []domain.User{
-		{Username: "user1", Password: hash.HashPassword("user1")},
-		{Username: "user2", Password: hash.HashPassword("hashed_password")},
-	}
-	if err := postgresDB.db.CreateInBatches(users, len(users)).Error; err != nil {
-		log.Printf("failed to seed users: %v", err)
-	}
-
-	merchItems := []domain.Merch{
-		{Name: "t-shirt", Price: 80},
-		{Name: "cup", Price: 20},
-		{Name: "book", Price: 50},
-		{Name: "pen", Price: 10},
-		{Name: "powerbank", Price: 200},
-		{Name: "hoody", Price: 300},
-		{Name: "umbrella", Price: 200},
-		{Name: "socks", Price: 10},
-		{Name: "wallet", Price: 50},
-		{Name: "pink-hoody", Price: 500},
-	}
-	if err := postgresDB.db.CreateInBatches(merchItems, len(merchItems)).Error; err != nil {
-		log.Printf("failed to seed merchandise: %v", err)
+func (postgresDB *Postgres) Migrate(ctx context.Context) error {
+	migrator, err := postgresDB.Migrator()
+	if err != nil {
+		return err
 	}
+	return migrator.Up(ctx)
+}
 
-	purchases := []domain.Purchase{
-		{UserID: users[0].Username, MerchName: merchItems[0].Name, CreatedAt: time.Now()},
-	}
-	if err := postgresDB.db.CreateInBatches(purchases, len(purchases)).Error; err != nil {
-		log.Printf("failed to seed purchases: %v", err)
+func (postgresDB *Postgres) CheckSchema(ctx context.Context) error {
+	migrator, err := postgresDB.Migrator()
+	if err != nil {
+		return err
 	}
+	return migrator.CheckSchema(ctx)
+}
 
-	transactions := []domain.Transaction{
-		{ReceiverUsername: users[0].Username, SenderUsername: users[1].Username, MoneyAmount: 100, CreatedAt: time.Now()},
-	}
-	if err := postgresDB.db.CreateInBatches(transactions, len(transactions)).Error; err != nil {
-		log.Printf("failed to seed transactions: %v", err)
+func (postgresDB *Postgres) Close() error {
+	sqlDB, err := postgresDB.db.DB()
+	if err != nil {
+		return err
 	}
-
-	log.Infof("Database seeded successfully")
+	return sqlDB.Close()
 }
 
 func (postgresDB *Postgres) GetDB() *gorm.DB {
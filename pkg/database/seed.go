@@ -0,0 +1,229 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"shop/domain"
+	hash "shop/pkg"
+	"shop/pkg/audit"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrCredentialsInProduction = errors.New("refusing to seed user credentials in production mode")
+
+type Fixtures struct {
+	Users    []UserFixture    `json:"users" yaml:"users"`
+	Merch    []MerchFixture   `json:"merch" yaml:"merch"`
+	Balances []BalanceFixture `json:"balances" yaml:"balances"`
+}
+
+type UserFixture struct {
+	Username   string `json:"username" yaml:"username"`
+	Password   string `json:"password" yaml:"password"`
+	Role       string `json:"role" yaml:"role"`
+	Department string `json:"department" yaml:"department"`
+}
+
+type MerchFixture struct {
+	Name  string  `json:"name" yaml:"name"`
+	Price float64 `json:"price" yaml:"price"`
+}
+
+type BalanceFixture struct {
+	Username string  `json:"username" yaml:"username"`
+	Balance  float64 `json:"balance" yaml:"balance"`
+}
+
+// LoadFixtures reads fixtures from a .yaml, .yml or .json file.
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures Fixtures
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &fixtures)
+	case ".json":
+		err = json.Unmarshal(data, &fixtures)
+	default:
+		return nil, fmt.Errorf("unsupported fixture format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &fixtures, fixtures.Validate()
+}
+
+func (f *Fixtures) Validate() error {
+	for _, user := range f.Users {
+		if user.Username == "" || user.Password == "" {
+			return fmt.Errorf("user fixture %q must have a username and a password", user.Username)
+		}
+		if user.Role != "" && user.Role != domain.RoleUser && user.Role != domain.RoleAdmin {
+			return fmt.Errorf("user fixture %q has unknown role %q", user.Username, user.Role)
+		}
+	}
+	for _, merch := range f.Merch {
+		if merch.Name == "" || merch.Price <= 0 {
+			return fmt.Errorf("merch fixture %q must have a name and a positive price", merch.Name)
+		}
+	}
+	for _, balance := range f.Balances {
+		if balance.Username == "" || balance.Balance < 0 {
+			return fmt.Errorf("balance fixture %q must have a username and a non-negative balance", balance.Username)
+		}
+	}
+	return nil
+}
+
+// Adjuster applies balance adjustments in tx, recording them in the history
+// and the audit log like the admin credits and debits.
+type Adjuster interface {
+	ApplyAdjustments(ctx context.Context, tx *gorm.DB, adjustments []domain.BalanceAdjustment) ([]domain.BalanceAdjustment, error)
+}
+
+type SeedOptions struct {
+	Production      bool
+	StartingBalance float64
+	BcryptCost      int
+	// Adjuster is required to seed balances.
+	Adjuster Adjuster
+}
+
+// Seed upserts the fixtures in a single transaction, so it can be run any number of times.
+// Existing users keep their password and role, merch prices and balances are
+// overwritten. Price changes are recorded in the audit log and start a new
+// entry of the item's price history. A balance is changed by a credit or a
+// debit with the reason "seed", a balance below the user's held coins is
+// refused.
+func (postgresDB *Postgres) Seed(ctx context.Context, fixtures *Fixtures, options SeedOptions) error {
+	if options.Production && len(fixtures.Users) > 0 {
+		return ErrCredentialsInProduction
+	}
+	if len(fixtures.Balances) > 0 && options.Adjuster == nil {
+		return errors.New("seeding balances requires an adjuster")
+	}
+
+	if audit.ActorFromContext(ctx).Username == "" {
+		ctx = audit.WithUsername(ctx, "system:seed")
+	}
+
+	return postgresDB.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, fixture := range fixtures.Users {
+			role := fixture.Role
+			if role == "" {
+				role = domain.RoleUser
+			}
+			user := domain.User{
+				Username:   fixture.Username,
+				Password:   hash.HashPassword(fixture.Password, options.BcryptCost),
+				Balance:    options.StartingBalance,
+				Role:       role,
+				Department: fixture.Department,
+				Active:     true,
+			}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&user)
+			if result.Error != nil {
+				return fmt.Errorf("failed to seed user %s: %w", fixture.Username, result.Error)
+			}
+			if result.RowsAffected > 0 {
+				if err := resetLots(tx, user.Username, user.Balance); err != nil {
+					return fmt.Errorf("failed to seed user %s: %w", fixture.Username, err)
+				}
+			}
+		}
+
+		for _, fixture := range fixtures.Merch {
+			var previous []float64
+			if err := tx.Model(&domain.Merch{}).Where("name = ?", fixture.Name).Pluck("price", &previous).Error; err != nil {
+				return fmt.Errorf("failed to seed merch %s: %w", fixture.Name, err)
+			}
+			merch := domain.Merch{Name: fixture.Name, Price: fixture.Price}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "name"}},
+				DoUpdates: clause.AssignmentColumns([]string{"price"}),
+			}).Create(&merch).Error
+			if err != nil {
+				return fmt.Errorf("failed to seed merch %s: %w", fixture.Name, err)
+			}
+			if len(previous) == 0 || previous[0] != fixture.Price {
+				price := domain.MerchPrice{MerchName: fixture.Name, Price: fixture.Price, EffectiveFrom: time.Now()}
+				if err = tx.Create(&price).Error; err != nil {
+					return fmt.Errorf("failed to seed merch %s: %w", fixture.Name, err)
+				}
+			}
+			if len(previous) > 0 && previous[0] != fixture.Price {
+				before := map[string]float64{"price": previous[0]}
+				after := map[string]float64{"price": fixture.Price}
+				if err = appendAudit(ctx, tx, domain.AuditPriceChange, fixture.Name, before, after); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, fixture := range fixtures.Balances {
+			var user domain.User
+			result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("username = ?", fixture.Username).Limit(1).Find(&user)
+			if result.Error != nil {
+				return fmt.Errorf("failed to seed balance for %s: %w", fixture.Username, result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("failed to seed balance: no such user %s", fixture.Username)
+			}
+			if fixture.Balance < user.Held {
+				return fmt.Errorf("failed to seed balance for %s: %v is below the %v coins held", fixture.Username, fixture.Balance, user.Held)
+			}
+			if user.Balance == fixture.Balance {
+				continue
+			}
+			adjustment := domain.BalanceAdjustment{Username: fixture.Username, Amount: fixture.Balance - user.Balance, Reason: "seed"}
+			if _, err := options.Adjuster.ApplyAdjustments(ctx, tx, []domain.BalanceAdjustment{adjustment}); err != nil {
+				return fmt.Errorf("failed to seed balance for %s: %w", fixture.Username, err)
+			}
+		}
+
+		log.Infof("seeded %d users, %d merch items and %d balances",
+			len(fixtures.Users), len(fixtures.Merch), len(fixtures.Balances))
+		return nil
+	})
+}
+
+// resetLots replaces the user's coin lots with a single lot of balance
+// granted now, so that seeded users' coins expire like any other grant.
+func resetLots(tx *gorm.DB, username string, balance float64) error {
+	err := tx.Model(&domain.CoinLot{}).Where("username = ? AND remaining > 0", username).Update("remaining", 0).Error
+	if err != nil || balance <= 0 {
+		return err
+	}
+	return tx.Create(&domain.CoinLot{
+		Username:  username,
+		Amount:    balance,
+		Remaining: balance,
+		GrantedAt: time.Now(),
+		Source:    domain.LotSourceSeed,
+	}).Error
+}
+
+func appendAudit(ctx context.Context, tx *gorm.DB, action, subject string, before, after any) error {
+	entry, err := audit.NewEntry(ctx, action, subject, before, after)
+	if err != nil {
+		return err
+	}
+	if err = audit.Append(ctx, tx, entry); err != nil {
+		return fmt.Errorf("failed to audit %s of %s: %w", action, subject, err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFixture(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFixtures_YAML(t *testing.T) {
+	path := writeFixture(t, "fixtures.yaml", `
+users:
+  - {username: user1, password: secret}
+merch:
+  - {name: cup, price: 20}
+balances:
+  - {username: user1, balance: 500}
+`)
+
+	fixtures, err := LoadFixtures(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []UserFixture{{Username: "user1", Password: "secret"}}, fixtures.Users)
+	assert.Equal(t, []MerchFixture{{Name: "cup", Price: 20}}, fixtures.Merch)
+	assert.Equal(t, []BalanceFixture{{Username: "user1", Balance: 500}}, fixtures.Balances)
+}
+
+func TestLoadFixtures_JSON(t *testing.T) {
+	path := writeFixture(t, "fixtures.json", `{"merch": [{"name": "pen", "price": 10}]}`)
+
+	fixtures, err := LoadFixtures(path)
+	assert.NoError(t, err)
+	assert.Empty(t, fixtures.Users)
+	assert.Equal(t, []MerchFixture{{Name: "pen", Price: 10}}, fixtures.Merch)
+}
+
+func TestLoadFixtures_Invalid(t *testing.T) {
+	testTable := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "UnsupportedFormat", file: "fixtures.toml", content: ``},
+		{name: "Malformed", file: "fixtures.json", content: `{"merch": [`},
+		{name: "MissingPassword", file: "fixtures.yaml", content: `users: [{username: user1}]`},
+		{name: "NonPositivePrice", file: "fixtures.yaml", content: `merch: [{name: cup, price: 0}]`},
+		{name: "NegativeBalance", file: "fixtures.yaml", content: `balances: [{username: user1, balance: -1}]`},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadFixtures(writeFixture(t, test.file, test.content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadFixtures_RepositoryFiles(t *testing.T) {
+	for _, path := range []string{"../../fixtures/demo.yaml", "../../fixtures/catalog.json"} {
+		_, err := LoadFixtures(path)
+		assert.NoError(t, err, path)
+	}
+}
+
+func TestSeed_RefusesCredentialsInProduction(t *testing.T) {
+	postgresDB := &Postgres{}
+	fixtures := &Fixtures{Users: []UserFixture{{Username: "user1", Password: "user1"}}}
+
+	err := postgresDB.Seed(context.Background(), fixtures, SeedOptions{Production: true})
+	assert.ErrorIs(t, err, ErrCredentialsInProduction)
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"shop/domain"
+	"shop/pkg/audit"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetRole grants role to an existing user.
+func (postgresDB *Postgres) SetRole(ctx context.Context, username, role string) error {
+	if role != domain.RoleUser && role != domain.RoleAdmin {
+		return fmt.Errorf("unknown role %q", role)
+	}
+	if audit.ActorFromContext(ctx).Username == "" {
+		ctx = audit.WithUsername(ctx, "system:admin")
+	}
+
+	return postgresDB.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user domain.User
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("username = ?", username).Limit(1).Find(&user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("no such user %s", username)
+		}
+		if user.Role == role {
+			return nil
+		}
+		if err := tx.Model(&user).Update("role", role).Error; err != nil {
+			return err
+		}
+		return appendAudit(ctx, tx, domain.AuditRoleChange, username, map[string]string{"role": user.Role}, map[string]string{"role": role})
+	})
+}
+
+// VerifyAudit checks the hash chain of the whole audit log and returns the
+// number of entries checked.
+func (postgresDB *Postgres) VerifyAudit(ctx context.Context) (int, error) {
+	var entries []domain.AuditEntry
+	if err := postgresDB.db.WithContext(ctx).Order("id").Find(&entries).Error; err != nil {
+		return 0, err
+	}
+	return len(entries), audit.Verify(entries)
+}
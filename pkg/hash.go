@@ -2,8 +2,8 @@ package hash
 
 import "golang.org/x/crypto/bcrypt"
 
-func HashPassword(password string) string {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+func HashPassword(password string, cost int) string {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), cost)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,151 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"shop/pkg/database/migrations"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+var ErrDraining = errors.New("server is shutting down")
+
+type Component struct {
+	Status  string         `json:"status"`
+	Error   string         `json:"error,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+type Report struct {
+	Status     string               `json:"status"`
+	Components map[string]Component `json:"components"`
+}
+
+// CheckFunc reports the state of a dependency, an error means it is not usable.
+type CheckFunc func(ctx context.Context) (map[string]any, error)
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+type Checker struct {
+	timeout   time.Duration
+	startedAt time.Time
+	checks    []namedCheck
+	draining  atomic.Bool
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout, startedAt: time.Now()}
+}
+
+func (c *Checker) AddReadinessCheck(name string, check CheckFunc) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// SetDraining makes readiness fail, so that load balancers stop routing
+// new requests to the instance while it finishes the in-flight ones.
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Liveness only reports that the process is able to serve requests,
+// it must not depend on external services.
+func (c *Checker) Liveness(context.Context) Report {
+	return Report{
+		Status: StatusOK,
+		Components: map[string]Component{
+			"process": {Status: StatusOK, Details: map[string]any{"uptime": time.Since(c.startedAt).Round(time.Second).String()}},
+		},
+	}
+}
+
+func (c *Checker) Readiness(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Components: make(map[string]Component, len(c.checks)+1)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			details, err := check.check(ctx)
+			component := Component{Status: StatusOK, Details: details}
+			if err != nil {
+				component.Status = StatusFail
+				component.Error = err.Error()
+			}
+			mu.Lock()
+			report.Components[check.name] = component
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	shutdown := Component{Status: StatusOK}
+	if c.draining.Load() {
+		shutdown = Component{Status: StatusFail, Error: ErrDraining.Error()}
+	}
+	report.Components["shutdown"] = shutdown
+
+	for _, component := range report.Components {
+		if component.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func DatabaseCheck(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) (map[string]any, error) {
+		return nil, db.PingContext(ctx)
+	}
+}
+
+// MigrationsCheck fails until the embedded migrations are applied. It only
+// reads the schema version, probes never create the migrations table.
+func MigrationsCheck(migrator *migrations.Migrator) CheckFunc {
+	return func(ctx context.Context) (map[string]any, error) {
+		version, err := migrator.AppliedVersion(ctx)
+		if errors.Is(err, migrations.ErrNotMigrated) {
+			return map[string]any{"latest": migrator.Latest()}, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		details := map[string]any{"version": version, "latest": migrator.Latest()}
+		if version < migrator.Latest() {
+			return details, migrations.ErrSchemaBehind
+		}
+		return details, nil
+	}
+}
+
+// PoolCheck fails when the share of connections in use reaches threshold.
+func PoolCheck(db *sql.DB, threshold float64) CheckFunc {
+	return func(context.Context) (map[string]any, error) {
+		stats := db.Stats()
+		details := map[string]any{
+			"in_use":     stats.InUse,
+			"idle":       stats.Idle,
+			"max_open":   stats.MaxOpenConnections,
+			"wait_count": stats.WaitCount,
+		}
+		if stats.MaxOpenConnections > 0 && float64(stats.InUse) >= threshold*float64(stats.MaxOpenConnections) {
+			return details, fmt.Errorf("connection pool saturated: %d of %d connections in use", stats.InUse, stats.MaxOpenConnections)
+		}
+		return details, nil
+	}
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveness(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.AddReadinessCheck("database", func(context.Context) (map[string]any, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	report := checker.Liveness(context.Background())
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Equal(t, StatusOK, report.Components["process"].Status)
+}
+
+func TestReadiness(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.AddReadinessCheck("database", func(context.Context) (map[string]any, error) {
+		return nil, nil
+	})
+	checker.AddReadinessCheck("migrations", func(context.Context) (map[string]any, error) {
+		return map[string]any{"version": 2}, nil
+	})
+
+	report := checker.Readiness(context.Background())
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Equal(t, Component{Status: StatusOK}, report.Components["database"])
+	assert.Equal(t, Component{Status: StatusOK, Details: map[string]any{"version": 2}}, report.Components["migrations"])
+	assert.Equal(t, StatusOK, report.Components["shutdown"].Status)
+}
+
+func TestReadiness_FailingComponent(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.AddReadinessCheck("database", func(context.Context) (map[string]any, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	report := checker.Readiness(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, Component{Status: StatusFail, Error: "connection refused"}, report.Components["database"])
+}
+
+func TestReadiness_Timeout(t *testing.T) {
+	checker := NewChecker(10 * time.Millisecond)
+	checker.AddReadinessCheck("database", func(ctx context.Context) (map[string]any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	report := checker.Readiness(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Components["database"].Error)
+}
+
+func TestReadiness_Draining(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.SetDraining()
+
+	report := checker.Readiness(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, Component{Status: StatusFail, Error: ErrDraining.Error()}, report.Components["shutdown"])
+
+	assert.Equal(t, StatusOK, checker.Liveness(context.Background()).Status)
+}
@@ -0,0 +1,121 @@
+package images
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"slices"
+)
+
+// ThumbnailWidths are the widths thumbnails are made in, a closed set so that
+// the cached thumbnails of an image stay few.
+var ThumbnailWidths = []int{64, 128, 256, 512, 1024}
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooManyPixels     = errors.New("image has too many pixels")
+)
+
+// contentTypes maps the formats that can be uploaded to their content types.
+var contentTypes = map[string]string{
+	"png":  "image/png",
+	"jpeg": "image/jpeg",
+	"gif":  "image/gif",
+}
+
+// Info is what an upload tells about the image without decoding it whole.
+type Info struct {
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Inspect reads the format and the size of the image from its header. Images
+// of more than maxPixels pixels are rejected: a small file can still decode
+// into a huge bitmap when its thumbnails are made.
+func Inspect(r io.Reader, maxPixels int64) (*Info, error) {
+	cfg, format, err := image.DecodeConfig(r)
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, err)
+	}
+	contentType, ok := contentTypes[format]
+	if !ok || cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrUnsupportedFormat
+	}
+	if pixels := int64(cfg.Width) * int64(cfg.Height); pixels > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d, the limit is %d pixels", ErrTooManyPixels, cfg.Width, cfg.Height, maxPixels)
+	}
+	return &Info{ContentType: contentType, Width: cfg.Width, Height: cfg.Height}, nil
+}
+
+// Thumbnail writes the image scaled down to width, keeping its aspect ratio,
+// and returns the content type written. Images narrower than width keep
+// their size. JPEGs stay JPEGs, other formats are written as PNG.
+func Thumbnail(w io.Writer, r io.Reader, width int) (string, error) {
+	if !slices.Contains(ThumbnailWidths, width) {
+		return "", fmt.Errorf("thumbnail width must be one of %v", ThumbnailWidths)
+	}
+	src, format, err := image.Decode(r)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, err)
+	}
+
+	thumbnail := Resize(src, width)
+	contentType := ThumbnailContentType(contentTypes[format])
+	if contentType == contentTypes["jpeg"] {
+		return contentType, jpeg.Encode(w, thumbnail, &jpeg.Options{Quality: 85})
+	}
+	return contentType, png.Encode(w, thumbnail)
+}
+
+// ThumbnailContentType is the content type of the thumbnails of an image.
+func ThumbnailContentType(contentType string) string {
+	if contentType == contentTypes["jpeg"] {
+		return contentType
+	}
+	return contentTypes["png"]
+}
+
+// Resize scales src down to width with a box filter, each pixel of the result
+// is the average of the source pixels it covers.
+func Resize(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	if bounds.Dx() <= width {
+		return src
+	}
+	height := max(1, bounds.Dy()*width/bounds.Dx())
+
+	in := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(in, in.Bounds(), src, bounds.Min, draw.Src)
+	out := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0, y1 := y*in.Rect.Dy()/height, (y+1)*in.Rect.Dy()/height
+		for x := 0; x < width; x++ {
+			x0, x1 := x*in.Rect.Dx()/width, (x+1)*in.Rect.Dx()/width
+			var sum [4]int
+			for sy := y0; sy < max(y1, y0+1); sy++ {
+				for sx := x0; sx < max(x1, x0+1); sx++ {
+					i := in.PixOffset(sx, sy)
+					for c := range sum {
+						sum[c] += int(in.Pix[i+c])
+					}
+				}
+			}
+			n := max(y1-y0, 1) * max(x1-x0, 1)
+			i := out.PixOffset(x, y)
+			for c := range sum {
+				out.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return out
+}
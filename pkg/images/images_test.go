@@ -0,0 +1,66 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encode(img image.Image) []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func TestInspect(t *testing.T) {
+	data := encode(image.NewNRGBA(image.Rect(0, 0, 300, 200)))
+	info, err := Inspect(bytes.NewReader(data), 60000)
+	assert.NoError(t, err)
+	assert.Equal(t, &Info{ContentType: "image/png", Width: 300, Height: 200}, info)
+
+	_, err = Inspect(bytes.NewReader(data), 59999)
+	assert.ErrorIs(t, err, ErrTooManyPixels)
+
+	_, err = Inspect(strings.NewReader("<svg></svg>"), 60000)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestResize(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			if x%2 == 0 {
+				src.Set(x, y, color.NRGBA{R: 200, A: 255})
+			} else {
+				src.Set(x, y, color.NRGBA{B: 100, A: 255})
+			}
+		}
+	}
+
+	thumbnail := Resize(src, 2)
+	assert.Equal(t, image.Rect(0, 0, 2, 1), thumbnail.Bounds(), "aspect ratio is kept")
+	assert.Equal(t, color.NRGBA{R: 100, B: 50, A: 255}, thumbnail.At(0, 0), "pixels are averaged")
+
+	assert.Same(t, src, Resize(src, 8), "small images are not scaled up")
+}
+
+func TestThumbnail(t *testing.T) {
+	data := encode(image.NewNRGBA(image.Rect(0, 0, 1000, 500)))
+
+	var buf bytes.Buffer
+	contentType, err := Thumbnail(&buf, bytes.NewReader(data), 128)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	info, err := Inspect(&buf, 1<<20)
+	assert.NoError(t, err)
+	assert.Equal(t, 128, info.Width)
+	assert.Equal(t, 64, info.Height)
+
+	_, err = Thumbnail(&buf, bytes.NewReader(data), 100)
+	assert.EqualError(t, err, "thumbnail width must be one of [64 128 256 512 1024]")
+}
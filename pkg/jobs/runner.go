@@ -0,0 +1,71 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Func runs a job for the tick at now. Jobs must be idempotent: a tick can
+// be repeated on another instance or after a restart.
+type Func func(ctx context.Context, now time.Time) error
+
+type job struct {
+	name string
+	run  Func
+}
+
+// Runner calls every job on a fixed interval, one tick at a time.
+type Runner struct {
+	interval time.Duration
+	jobs     []job
+	done     chan struct{}
+	once     sync.Once
+}
+
+func NewRunner(interval time.Duration) *Runner {
+	return &Runner{interval: interval, done: make(chan struct{})}
+}
+
+func (r *Runner) Add(name string, run Func) {
+	r.jobs = append(r.jobs, job{name: name, run: run})
+}
+
+// Run ticks until ctx is cancelled. A tick in progress is not interrupted,
+// so that jobs never stop halfway through, Run returns once it is over.
+func (r *Runner) Run(ctx context.Context) {
+	defer r.once.Do(func() { close(r.done) })
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.tick(context.WithoutCancel(ctx), time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.tick(context.WithoutCancel(ctx), now)
+		}
+	}
+}
+
+// Done is closed when Run returns.
+func (r *Runner) Done() <-chan struct{} {
+	return r.done
+}
+
+func (r *Runner) tick(ctx context.Context, now time.Time) {
+	for _, job := range r.jobs {
+		start := time.Now()
+		err := job.run(ctx, now)
+		entry := log.WithFields(log.Fields{"job": job.name, "duration": time.Since(start).String()})
+		if err != nil {
+			entry.Errorf("job failed: %v", err)
+			continue
+		}
+		entry.Debug("job finished")
+	}
+}
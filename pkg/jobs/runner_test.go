@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunner(t *testing.T) {
+	runner := NewRunner(10 * time.Millisecond)
+	var ticks, failures atomic.Int32
+	runner.Add("count", func(context.Context, time.Time) error {
+		ticks.Add(1)
+		return nil
+	})
+	runner.Add("fail", func(context.Context, time.Time) error {
+		failures.Add(1)
+		return errors.New("boom")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go runner.Run(ctx)
+	assert.Eventually(t, func() bool { return ticks.Load() >= 3 }, time.Second, time.Millisecond)
+	cancel()
+
+	select {
+	case <-runner.Done():
+	case <-time.After(time.Second):
+		t.Fatal("runner did not stop")
+	}
+	assert.Equal(t, ticks.Load(), failures.Load(), "a failing job must not stop the others")
+}
+
+func TestRunner_FinishesTickOnCancel(t *testing.T) {
+	runner := NewRunner(time.Hour)
+	started := make(chan struct{})
+	var finished atomic.Bool
+	runner.Add("slow", func(ctx context.Context, _ time.Time) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(ctx.Err() == nil)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go runner.Run(ctx)
+	<-started
+	cancel()
+	<-runner.Done()
+	assert.True(t, finished.Load(), "the job context must outlive the cancelled runner context")
+}
@@ -1,8 +1,110 @@
 package logger
 
-import log "github.com/sirupsen/logrus"
+import (
+	"context"
+	"regexp"
 
-func InitLogger() {
-	log.SetFormatter(&log.JSONFormatter{})
-	log.SetLevel(log.InfoLevel)
+	"shop/pkg/config"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	sensitiveKey = regexp.MustCompile(`(?i)password|secret|token|authorization|cookie`)
+	// key=value and "key": "value" pairs with a sensitive key, and bare JWTs.
+	sensitiveValue = regexp.MustCompile(`(?i)((?:password|secret_key|secret|token|accesstoken)"?\s*[=:]\s*"?)([^"\s,}]+)`)
+	jwtValue       = regexp.MustCompile(`eyJ[\w-]+\.[\w-]+\.[\w-]+`)
+)
+
+type contextKey struct{}
+
+// Init configures the standard logger. It can be called again once the
+// configuration is loaded, the hooks are installed only once.
+func Init(cfg config.Log) error {
+	level, err := log.ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(level)
+
+	if cfg.Format == "text" {
+		log.SetFormatter(&log.TextFormatter{FullTimestamp: true})
+	} else {
+		log.SetFormatter(&log.JSONFormatter{})
+	}
+
+	hooks := log.LevelHooks{}
+	hooks.Add(traceHook{})
+	hooks.Add(redactHook{})
+	log.StandardLogger().ReplaceHooks(hooks)
+	return nil
+}
+
+// WithFields returns a context whose logger carries the given fields
+// in addition to the ones already there.
+func WithFields(ctx context.Context, fields log.Fields) context.Context {
+	return context.WithValue(ctx, contextKey{}, entryFromContext(ctx).WithFields(fields))
+}
+
+// FromContext returns the request-scoped logger, falling back to the
+// standard logger outside of a request.
+func FromContext(ctx context.Context) *log.Entry {
+	return entryFromContext(ctx).WithContext(ctx)
+}
+
+func entryFromContext(ctx context.Context) *log.Entry {
+	if entry, ok := ctx.Value(contextKey{}).(*log.Entry); ok {
+		return entry
+	}
+	return log.NewEntry(log.StandardLogger())
+}
+
+// Redact masks passwords, secrets and tokens in s.
+func Redact(s string) string {
+	s = sensitiveValue.ReplaceAllString(s, "${1}"+redacted)
+	return jwtValue.ReplaceAllString(s, redacted)
+}
+
+// traceHook adds the trace and span IDs to entries logged with a context.
+type traceHook struct{}
+
+func (traceHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (traceHook) Fire(entry *log.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	spanContext := trace.SpanContextFromContext(entry.Context)
+	if spanContext.IsValid() {
+		entry.Data["trace_id"] = spanContext.TraceID().String()
+		entry.Data["span_id"] = spanContext.SpanID().String()
+	}
+	return nil
+}
+
+// redactHook keeps secrets out of the logs, whether they are passed as
+// fields or formatted into the message.
+type redactHook struct{}
+
+func (redactHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (redactHook) Fire(entry *log.Entry) error {
+	entry.Message = Redact(entry.Message)
+	for key, value := range entry.Data {
+		if sensitiveKey.MatchString(key) {
+			entry.Data[key] = redacted
+			continue
+		}
+		if s, ok := value.(string); ok {
+			entry.Data[key] = Redact(s)
+		}
+	}
+	return nil
 }
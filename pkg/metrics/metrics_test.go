@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func scrape(t *testing.T) string {
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	return w.Body.String()
+}
+
+func TestMiddleware_UsesRouteTemplate(t *testing.T) {
+	router := gin.New()
+	router.Use(Middleware())
+	router.POST("/api/buy/:item", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, item := range []string{"socks", "cup", "pen"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/buy/"+item, nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown/user1", nil))
+
+	body := scrape(t)
+	assert.Contains(t, body, `shop_http_request_duration_seconds_count{method="POST",route="/api/buy/:item",status="200"} 3`)
+	assert.Contains(t, body, `shop_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, body, "socks")
+	assert.NotContains(t, body, "user1")
+}
+
+func TestGormPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, db.Use(GormPlugin{}))
+
+	type Item struct {
+		Name string `gorm:"primaryKey"`
+	}
+	assert.NoError(t, db.Exec("CREATE TABLE items (name text PRIMARY KEY)").Error)
+	assert.NoError(t, db.Create(&Item{Name: "cup"}).Error)
+	var items []Item
+	assert.NoError(t, db.Find(&items).Error)
+
+	body := scrape(t)
+	assert.Contains(t, body, `shop_db_query_duration_seconds_count{operation="create",table="items"} 1`)
+	assert.Contains(t, body, `shop_db_query_duration_seconds_count{operation="query",table="items"} 1`)
+}
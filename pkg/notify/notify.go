@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/config"
+	"shop/pkg/logger"
+)
+
+// Notifier delivers a notification to its user outside of the shop, the
+// notification is already in the user's inbox.
+type Notifier interface {
+	Notify(ctx context.Context, notification domain.Notification) error
+}
+
+// New returns the notifier configured by NOTIFIER.
+func New(cfg config.Notifier) (Notifier, error) {
+	switch cfg.Driver {
+	case "none":
+		return Nop{}, nil
+	case "log":
+		return Log{}, nil
+	case "webhook":
+		return NewWebhook(cfg.WebhookURL, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", cfg.Driver)
+	}
+}
+
+// Nop leaves notifications in the inbox only.
+type Nop struct{}
+
+func (Nop) Notify(context.Context, domain.Notification) error {
+	return nil
+}
+
+// Log writes notifications to the service log.
+type Log struct{}
+
+func (Log) Notify(ctx context.Context, notification domain.Notification) error {
+	logger.FromContext(ctx).WithField("username", notification.Username).
+		WithField("kind", notification.Kind).
+		WithField("reference", notification.Reference).
+		Info(notification.Message)
+	return nil
+}
+
+// Webhook posts notifications as JSON to a URL, any status other than 2xx
+// is a failed delivery.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhook(url string, timeout time.Duration) *Webhook {
+	return &Webhook{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+type webhookPayload struct {
+	Username  string    `json:"username"`
+	Kind      string    `json:"kind"`
+	Message   string    `json:"message"`
+	Reference string    `json:"reference,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (w *Webhook) Notify(ctx context.Context, notification domain.Notification) error {
+	body, err := json.Marshal(webhookPayload{
+		Username:  notification.Username,
+		Kind:      notification.Kind,
+		Message:   notification.Message,
+		Reference: notification.Reference,
+		CreatedAt: notification.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to deliver notification: webhook responded %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"shop/domain"
+	"shop/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook(t *testing.T) {
+	var received map[string]any
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	notifier, err := New(config.Notifier{Driver: "webhook", WebhookURL: server.URL, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notification := domain.Notification{Username: "user1", Kind: domain.NotificationPriceDrop, Message: "hoody is now 250 coins", Reference: "hoody"}
+
+	assert.NoError(t, notifier.Notify(context.Background(), notification))
+	assert.Equal(t, "user1", received["username"])
+	assert.Equal(t, "price_drop", received["kind"])
+	assert.Equal(t, "hoody", received["reference"])
+
+	status = http.StatusBadGateway
+	assert.EqualError(t, notifier.Notify(context.Background(), notification), "failed to deliver notification: webhook responded 502 Bad Gateway")
+}
+
+func TestNew(t *testing.T) {
+	notifier, err := New(config.Notifier{Driver: "none"})
+	assert.NoError(t, err)
+	assert.Equal(t, Nop{}, notifier)
+
+	_, err = New(config.Notifier{Driver: "sms"})
+	assert.EqualError(t, err, `unknown notifier "sms"`)
+}
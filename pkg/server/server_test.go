@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"shop/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testConfig() config.HTTP {
+	cfg := config.Default().HTTP
+	cfg.ShutdownTimeout = 5 * time.Second
+	cfg.DrainDelay = 0
+	return cfg
+}
+
+func TestServe_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte("done"))
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- New(testConfig(), handler).Serve(ctx, listener) }()
+
+	responses := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responses <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		responses <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case <-served:
+		t.Fatal("server stopped before the in-flight request finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	_, err = http.Get("http://" + listener.Addr().String())
+	assert.Error(t, err, "new connections must be refused while draining")
+
+	close(release)
+	assert.Equal(t, "done", <-responses)
+	assert.NoError(t, <-served)
+}
+
+func TestServe_ShutdownDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig()
+	cfg.ShutdownTimeout = 50 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- New(cfg, handler).Serve(ctx, listener) }()
+
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+	assert.ErrorIs(t, <-served, context.DeadlineExceeded)
+}
+
+func TestServe_OnDrain(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig()
+	cfg.DrainDelay = 200 * time.Millisecond
+
+	srv := New(cfg, http.NotFoundHandler())
+	drained := make(chan struct{})
+	srv.OnDrain(func() { close(drained) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx, listener) }()
+
+	cancel()
+	<-drained
+
+	resp, err := http.Get("http://" + listener.Addr().String())
+	assert.NoError(t, err, "requests must still be served during the drain delay")
+	if err == nil {
+		resp.Body.Close()
+	}
+	assert.NoError(t, <-served)
+}
@@ -0,0 +1,128 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"shop/pkg/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func attributeValue(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	router := gin.New()
+	router.Use(Middleware())
+	router.POST("/api/buy/:item", func(c *gin.Context) {
+		_, span := Start(c.Request.Context(), "usecase.CreatePurchase")
+		span.End()
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/buy/socks", nil)
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	child, server := spans[0], spans[1]
+	assert.Equal(t, "POST /api/buy/:item", server.Name())
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", server.SpanContext().TraceID().String())
+	assert.Equal(t, "/api/buy/:item", attributeValue(server, "http.route"))
+	assert.Equal(t, "500", attributeValue(server, "http.response.status_code"))
+	assert.Equal(t, codes.Error, server.Status().Code)
+	assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+}
+
+func TestGormPlugin(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, db.Use(GormPlugin{}))
+
+	type Item struct {
+		Name string `gorm:"primaryKey"`
+	}
+	assert.NoError(t, db.Exec("CREATE TABLE items (name text PRIMARY KEY)").Error)
+
+	ctx, parent := Start(context.Background(), "repository.CreateItem")
+	assert.NoError(t, db.WithContext(ctx).Create(&Item{Name: "secret-value"}).Error)
+	parent.End()
+
+	var insert sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "sql.create items" {
+			insert = span
+		}
+	}
+	if assert.NotNil(t, insert) {
+		assert.Equal(t, parent.SpanContext().SpanID(), insert.Parent().SpanID())
+		assert.Contains(t, attributeValue(insert, "db.query.text"), "INSERT INTO `items`")
+		assert.NotContains(t, attributeValue(insert, "db.query.text"), "secret-value")
+	}
+}
+
+func TestError(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	_, span := Start(context.Background(), "usecase.Auth")
+	assert.NoError(t, Error(span, nil))
+	err := errors.New("invalid password")
+	assert.Equal(t, err, Error(span, err))
+	span.End()
+
+	assert.Equal(t, codes.Error, recorder.Ended()[0].Status().Code)
+	assert.Equal(t, "invalid password", recorder.Ended()[0].Status().Description)
+}
+
+func TestInit_FileExporter(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	cfg := config.Default().Tracing
+	cfg.Exporter = "file"
+	cfg.File = t.TempDir() + "/traces.jsonl"
+
+	shutdown, err := Init(context.Background(), cfg)
+	assert.NoError(t, err)
+	_, span := Start(context.Background(), "usecase.Auth")
+	span.End()
+	assert.NoError(t, shutdown(context.Background()))
+
+	cfg.Exporter = "zipkin"
+	_, err = Init(context.Background(), cfg)
+	assert.Error(t, err)
+}
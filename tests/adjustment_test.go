@@ -0,0 +1,105 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"shop/internal/repository"
+	"shop/pkg/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalanceAdjustmentIntegration(t *testing.T) {
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	adminToken := performAuthRequest(t, router, "admin", "admin")
+	adminRequest := func(path, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: adminToken})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := adminRequest("/api/admin/users/user1/credit", "application/json", `{"amount": 200, "reason": "birthday"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = adminRequest("/api/admin/users/user1/debit", "application/json", `{"amount": 5000, "reason": "clawback"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, 1200.0, balanceOf(t, db, "user1"))
+
+	rec = adminRequest("/api/admin/issuance", "text/csv", "user1,10,hackathon\nnobody,10,hackathon\n")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, 1200.0, balanceOf(t, db, "user1"), "a failed issuance must not apply any row")
+
+	rec = adminRequest("/api/admin/issuance", "text/csv", "username,amount,reason\nuser1,10,hackathon\nuser2,20,hackathon\n")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1210.0, balanceOf(t, db, "user1"))
+	assert.Equal(t, 1020.0, balanceOf(t, db, "user2"))
+
+	token := performAuthRequest(t, router, "user1", "user1")
+	req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+	req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resBody struct {
+		Adjustments []struct {
+			Amount float64 `json:"amount"`
+			Reason string  `json:"reason"`
+			Actor  string  `json:"actor"`
+		} `json:"adjustments"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resBody); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, resBody.Adjustments, 2) {
+		assert.Equal(t, 200.0, resBody.Adjustments[0].Amount)
+		assert.Equal(t, "birthday", resBody.Adjustments[0].Reason)
+		assert.Equal(t, "admin", resBody.Adjustments[0].Actor)
+	}
+}
+
+func TestSeedBalanceIntegration(t *testing.T) {
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	adminToken := performAuthRequest(t, router, "admin", "admin")
+	rec := performRequest(router, adminToken, http.MethodPost, "/api/admin/holds", `{"username":"user1","amount":300,"reason":"order 7"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	seed := func(balance float64) error {
+		fixtures := &database.Fixtures{Balances: []database.BalanceFixture{{Username: "user1", Balance: balance}}}
+		options := database.SeedOptions{Adjuster: repository.NewRepository(db)}
+		return database.InitializeDBPostgres(cfg.DB).Seed(context.Background(), fixtures, options)
+	}
+
+	assert.Error(t, seed(200), "the balance cannot drop below the held coins")
+	assert.Equal(t, 1000.0, balanceOf(t, db, "user1"))
+
+	assert.NoError(t, seed(400))
+	assert.Equal(t, 400.0, balanceOf(t, db, "user1"))
+	var adjustment struct {
+		Amount float64
+		Actor  string
+	}
+	if err := db.Raw("SELECT amount, actor FROM balance_adjustments WHERE username = ? AND reason = 'seed'", "user1").Scan(&adjustment).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, -600.0, adjustment.Amount)
+	assert.Equal(t, "system:seed", adjustment.Actor)
+	var remaining float64
+	if err := db.Raw("SELECT COALESCE(SUM(remaining), 0) FROM coin_lots WHERE username = ?", "user1").Scan(&remaining).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 400.0, remaining, "the lots backing the hold are kept")
+}
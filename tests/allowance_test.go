@@ -0,0 +1,74 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"shop/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowanceIntegration(t *testing.T) {
+	router, usecase, db := setupTestDB()
+	defer clearDatabase(db)
+
+	adminToken := performAuthRequest(t, router, "admin", "admin")
+	adminRequest := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: adminToken})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := adminRequest(http.MethodPatch, "/api/admin/users/user1", `{"department": "sales"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = adminRequest(http.MethodPost, "/api/admin/allowances", `{"name": "sales", "schedule": "* * * * *", "amount": 100, "department": "sales"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var policy domain.AllowancePolicy
+	if err := json.Unmarshal(rec.Body.Bytes(), &policy); err != nil {
+		t.Fatal(err)
+	}
+	id := strconv.FormatInt(policy.ID, 10)
+
+	// Ticking again, like a restarted instance would, pays nothing more.
+	now := time.Now().Add(5 * time.Minute)
+	assert.NoError(t, usecase.RunDueAllowances(context.Background(), now))
+	assert.NoError(t, usecase.RunDueAllowances(context.Background(), now))
+	assert.Equal(t, 1100.0, balanceOf(t, db, "user1"))
+	assert.Equal(t, 1000.0, balanceOf(t, db, "user2"))
+
+	rec = adminRequest(http.MethodPost, "/api/admin/allowances/"+id+"/pause", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, usecase.RunDueAllowances(context.Background(), now.Add(time.Hour)))
+	assert.Equal(t, 1100.0, balanceOf(t, db, "user1"), "a paused policy must not run on schedule")
+
+	rec = adminRequest(http.MethodPost, "/api/admin/allowances/"+id+"/run", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1200.0, balanceOf(t, db, "user1"))
+
+	rec = adminRequest(http.MethodGet, "/api/admin/allowances/"+id+"/runs", "")
+	var runs struct {
+		Runs []domain.AllowanceRun `json:"runs"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &runs); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, runs.Runs, 2) {
+		assert.Equal(t, domain.RunTriggerManual, runs.Runs[0].Trigger)
+		assert.Equal(t, "admin", runs.Runs[0].Actor)
+		assert.Equal(t, 1, runs.Runs[1].UsersPaid)
+	}
+}
@@ -0,0 +1,102 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"shop/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurchaseApprovalsIntegration(t *testing.T) {
+	router, usecase, db := setupTestDB()
+	defer clearDatabase(db)
+
+	tokens := map[string]string{
+		"admin": performAuthRequest(t, router, "admin", "admin"),
+		"user1": performAuthRequest(t, router, "user1", "user1"),
+		"boss":  performAuthRequest(t, router, "boss", "boss"),
+	}
+	buy := func() domain.PurchaseApproval {
+		rec := performRequest(router, tokens["user1"], http.MethodPost, "/api/buy/pink-hoody", "")
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("expected a pending approval: %d %s", rec.Code, rec.Body.String())
+		}
+		var approval domain.PurchaseApproval
+		if err := json.Unmarshal(rec.Body.Bytes(), &approval); err != nil {
+			t.Fatal(err)
+		}
+		return approval
+	}
+
+	rec := performRequest(router, tokens["admin"], http.MethodPatch, "/api/admin/users/user1", `{"manager":"boss"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Cheap items are bought at once.
+	rec = performRequest(router, tokens["user1"], http.MethodPost, "/api/buy/cup", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 980.0, balanceOf(t, db, "user1"))
+
+	approval := buy()
+	assert.Equal(t, "boss", approval.Approver)
+	assert.Equal(t, 980.0, balanceOf(t, db, "user1"))
+
+	var pending domain.PendingApprovals
+	rec = performRequest(router, tokens["boss"], http.MethodGet, "/api/approvals", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &pending); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, pending.Assigned, 1)
+
+	rec = performRequest(router, tokens["user1"], http.MethodPost, fmt.Sprintf("/api/approvals/%d/approve", approval.ID), "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = performRequest(router, tokens["boss"], http.MethodPost, fmt.Sprintf("/api/approvals/%d/approve", approval.ID), "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 480.0, balanceOf(t, db, "user1"))
+
+	var count int64
+	db.Raw("SELECT count(*) FROM purchases WHERE user_id = 'user1' AND merch_name = 'pink-hoody'").Scan(&count)
+	assert.Equal(t, int64(1), count)
+
+	// Not enough coins are left for another one.
+	rec = performRequest(router, tokens["user1"], http.MethodPost, "/api/buy/pink-hoody", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = performRequest(router, tokens["admin"], http.MethodPost, "/api/admin/users/user1/credit", `{"amount":520,"reason":"bonus"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	approval = buy()
+	rec = performRequest(router, tokens["admin"], http.MethodPost, fmt.Sprintf("/api/approvals/%d/reject", approval.ID), "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = performRequest(router, tokens["boss"], http.MethodPost, fmt.Sprintf("/api/approvals/%d/approve", approval.ID), "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// Unhandled approvals go to admins and then expire.
+	approval = buy()
+	assert.NoError(t, usecase.EscalatePurchaseApprovals(context.Background(), approval.DueAt))
+	rec = performRequest(router, tokens["admin"], http.MethodGet, "/api/approvals", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &pending); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, pending.Assigned, 1)
+	assert.True(t, pending.Assigned[0].Escalated)
+
+	assert.NoError(t, usecase.EscalatePurchaseApprovals(context.Background(), pending.Assigned[0].DueAt))
+	var status string
+	if err := db.Raw("SELECT status FROM purchase_approvals WHERE id = ?", approval.ID).Scan(&status).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, domain.ApprovalExpired, status)
+
+	var held float64
+	if err := db.Raw("SELECT held FROM users WHERE username = 'user1'").Scan(&held).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0.0, held)
+}
@@ -0,0 +1,62 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"shop/domain"
+	"shop/pkg/audit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLogIntegration(t *testing.T) {
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	token := performAuthRequest(t, router, "user1", "user1")
+	reqBodyJSON, _ := json.Marshal(map[string]interface{}{"receiver_username": "user2", "amount": 30.0})
+	req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", bytes.NewBuffer(reqBodyJSON))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "audit-test")
+	req.Header.Set("User-Agent", "integration-test")
+	req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	adminToken := performAuthRequest(t, router, "admin", "admin")
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/audit?actor=user1&action=coins.transfer", nil)
+	req.AddCookie(&http.Cookie{Name: "accessToken", Value: adminToken})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resBody struct {
+		Entries []domain.AuditEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resBody); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, resBody.Entries, 1) {
+		entry := resBody.Entries[0]
+		assert.Equal(t, "audit-test", entry.RequestID)
+		assert.Equal(t, "integration-test", entry.UserAgent)
+		assert.JSONEq(t, `{"receiver_balance":1000,"sender_balance":1000}`, entry.Before)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/audit", nil)
+	req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	var entries []domain.AuditEntry
+	db.Order("id").Find(&entries)
+	assert.NoError(t, audit.Verify(entries))
+	assert.Error(t, db.Exec("UPDATE audit_log SET actor = 'someone else'").Error, "audit log must be append-only")
+}
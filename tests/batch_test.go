@@ -0,0 +1,73 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendCoinBatchIntegration(t *testing.T) {
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	token := performAuthRequest(t, router, "user1", "user1")
+	adminBalance := balanceOf(t, db, "admin")
+	send := func(body map[string]any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/api/sendCoin/batch", bytes.NewBuffer(data))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The total exceeds the balance, so neither transfer is made.
+	rec := send(map[string]any{"transfers": []map[string]any{
+		{"receiver_username": "user2", "amount": 600},
+		{"receiver_username": "admin", "amount": 600},
+	}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, 1000.0, balanceOf(t, db, "user1"))
+	assert.Equal(t, 1000.0, balanceOf(t, db, "user2"))
+
+	rec = send(map[string]any{"transfers": []map[string]any{
+		{"receiver_username": "user2", "amount": 50},
+		{"receiver_username": "admin", "amount": 50},
+	}, "message": "Great sprint", "category": "teamwork"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var batch struct {
+		BatchID      string  `json:"batch_id"`
+		Total        float64 `json:"total"`
+		Transactions []struct {
+			BatchID string `json:"batch_id"`
+			Message string `json:"message"`
+		} `json:"transactions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &batch); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, batch.BatchID)
+	assert.Equal(t, 100.0, batch.Total)
+	if assert.Len(t, batch.Transactions, 2) {
+		assert.Equal(t, batch.BatchID, batch.Transactions[0].BatchID)
+		assert.Equal(t, "Great sprint", batch.Transactions[1].Message)
+	}
+	assert.Equal(t, 900.0, balanceOf(t, db, "user1"))
+	assert.Equal(t, 1050.0, balanceOf(t, db, "user2"))
+	assert.Equal(t, adminBalance+50, balanceOf(t, db, "admin"))
+
+	var grouped int64
+	if err := db.Raw("SELECT count(*) FROM transactions WHERE batch_id = ?", batch.BatchID).Scan(&grouped).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(2), grouped)
+}
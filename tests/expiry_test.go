@@ -0,0 +1,72 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"shop/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoinExpiryIntegration(t *testing.T) {
+	t.Setenv("COIN_EXPIRY_MONTHS", "12")
+	router, usecase, db := setupTestDB()
+	defer clearDatabase(db)
+
+	// user1's seeded coins were granted 13 months ago, then they receive 30 more.
+	granted := time.Now().AddDate(0, -13, 0)
+	db.Model(&domain.CoinLot{}).Where("username = ?", "user1").Update("granted_at", granted)
+	token := performAuthRequest(t, router, "user2", "user2")
+	sendCoins(t, router, token, "user1", 30)
+
+	// Spending takes the oldest coins first, so the 100 spent are among the expired ones.
+	token = performAuthRequest(t, router, "user1", "user1")
+	sendCoins(t, router, token, "user2", 100)
+	assert.Equal(t, 930.0, balanceOf(t, db, "user1"))
+
+	assert.NoError(t, usecase.ExpireCoins(context.Background(), time.Now()))
+	assert.Equal(t, 30.0, balanceOf(t, db, "user1"))
+	// Coins passed to user2 keep their grant date and expire as well.
+	assert.Equal(t, 1000.0-30, balanceOf(t, db, "user2"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+	req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resBody struct {
+		Adjustments []domain.BalanceAdjustment `json:"adjustments"`
+		Expirations []domain.CoinExpiration    `json:"expirations"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resBody); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, resBody.Adjustments, 1) {
+		assert.Equal(t, -900.0, resBody.Adjustments[0].Amount)
+		assert.Contains(t, resBody.Adjustments[0].Reason, "expired")
+	}
+	if assert.Len(t, resBody.Expirations, 1) {
+		assert.Equal(t, 30.0, resBody.Expirations[0].Amount)
+	}
+}
+
+func sendCoins(t *testing.T, router http.Handler, token, receiver string, amount float64) {
+	body, _ := json.Marshal(map[string]any{"receiver_username": receiver, "amount": amount})
+	req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("failed to send coins: %d %s", rec.Code, rec.Body.String())
+	}
+}
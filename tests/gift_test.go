@@ -0,0 +1,67 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGiftPurchaseIntegration(t *testing.T) {
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	buyer := performAuthRequest(t, router, "user1", "user1")
+	recipient := performAuthRequest(t, router, "user2", "user2")
+
+	rec := performRequest(router, buyer, http.MethodPost, "/api/buy/socks", map[string]string{"recipient": "user2", "message": "Happy birthday"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("failed to buy a gift: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = performRequest(router, buyer, http.MethodPost, "/api/buy/socks", map[string]string{"recipient": "nobody"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var info struct {
+		Inventory []struct {
+			Type     string `json:"type"`
+			Quantity int    `json:"quantity"`
+		} `json:"inventory"`
+	}
+	rec = performRequest(router, buyer, http.MethodGet, "/api/info", nil)
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, info.Inventory)
+
+	rec = performRequest(router, recipient, http.MethodGet, "/api/info", nil)
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, info.Inventory, 1) {
+		assert.Equal(t, "socks", info.Inventory[0].Type)
+		assert.Equal(t, 1, info.Inventory[0].Quantity)
+	}
+
+	var inbox struct {
+		Notifications []struct {
+			Kind    string `json:"kind"`
+			Message string `json:"message"`
+		} `json:"notifications"`
+	}
+	rec = performRequest(router, recipient, http.MethodGet, "/api/notifications", nil)
+	if err := json.Unmarshal(rec.Body.Bytes(), &inbox); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, inbox.Notifications, 1) {
+		assert.Equal(t, "gift", inbox.Notifications[0].Kind)
+		assert.Contains(t, inbox.Notifications[0].Message, "Happy birthday")
+	}
+
+	rec = performRequest(router, recipient, http.MethodPost, "/api/notifications/read", nil)
+	assert.JSONEq(t, `{"marked":1}`, rec.Body.String())
+}
@@ -0,0 +1,79 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"shop/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoldsIntegration(t *testing.T) {
+	router, usecase, db := setupTestDB()
+	defer clearDatabase(db)
+
+	adminToken := performAuthRequest(t, router, "admin", "admin")
+	userToken := performAuthRequest(t, router, "user1", "user1")
+	performAuthRequest(t, router, "user2", "user2")
+	hold := func(body string) domain.Hold {
+		rec := performRequest(router, adminToken, http.MethodPost, "/api/admin/holds", body)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("failed to create a hold: %d %s", rec.Code, rec.Body.String())
+		}
+		var hold domain.Hold
+		if err := json.Unmarshal(rec.Body.Bytes(), &hold); err != nil {
+			t.Fatal(err)
+		}
+		return hold
+	}
+
+	order := hold(`{"username":"user1","amount":900,"reason":"order 7"}`)
+
+	// Only 100 coins are available now.
+	rec := performRequest(router, userToken, http.MethodPost, "/api/sendCoin", `{"receiver_username":"user2","amount":200}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = performRequest(router, adminToken, http.MethodPost, "/api/admin/holds", `{"username":"user1","amount":200,"reason":"order 8"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var info struct {
+		Balance domain.Balance `json:"balance"`
+	}
+	rec = performRequest(router, userToken, http.MethodGet, "/api/info", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1000.0, info.Balance.Balance)
+	assert.Equal(t, 100.0, info.Balance.Available)
+	assert.Len(t, info.Balance.Holds, 1)
+
+	rec = performRequest(router, adminToken, http.MethodPost, fmt.Sprintf("/api/admin/holds/%d/capture", order.ID), `{"amount":300}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 700.0, balanceOf(t, db, "user1"))
+	rec = performRequest(router, adminToken, http.MethodPost, fmt.Sprintf("/api/admin/holds/%d/release", order.ID), "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	pool := hold(`{"username":"user1","amount":600,"reason":"team pool"}`)
+	assert.NoError(t, usecase.ExpireHolds(context.Background(), time.Now()))
+	assert.NoError(t, usecase.ExpireHolds(context.Background(), pool.ExpiresAt))
+
+	rec = performRequest(router, adminToken, http.MethodGet, "/api/admin/users/user1/balance", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &info.Balance); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 700.0, info.Balance.Available)
+	assert.Empty(t, info.Balance.Holds)
+
+	var status string
+	if err := db.Raw("SELECT status FROM holds WHERE id = ?", pool.ID).Scan(&status).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, domain.HoldExpired, status)
+}
@@ -0,0 +1,55 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKudosFeedIntegration(t *testing.T) {
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	token := performAuthRequest(t, router, "user1", "user1")
+	send := func(body map[string]any) {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", bytes.NewBuffer(data))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("failed to send coins: %d %s", rec.Code, rec.Body.String())
+		}
+	}
+	send(map[string]any{"receiver_username": "user2", "amount": 10, "message": "<i>Thanks</i> for the help", "category": "help"})
+	send(map[string]any{"receiver_username": "user2", "amount": 20, "message": "Just between us", "private": true})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/kudos", nil)
+	req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resBody struct {
+		Kudos []struct {
+			Amount   float64 `json:"money_amount"`
+			Message  string  `json:"message"`
+			Category string  `json:"category"`
+		} `json:"kudos"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resBody); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, resBody.Kudos, 1) {
+		assert.Equal(t, "Thanks for the help", resBody.Kudos[0].Message)
+		assert.Equal(t, "help", resBody.Kudos[0].Category)
+	}
+}
@@ -0,0 +1,55 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferLimitsIntegration(t *testing.T) {
+	t.Setenv("TRANSFER_MAX_AMOUNT", "100")
+	t.Setenv("TRANSFER_DAILY_AMOUNT", "150")
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	token := performAuthRequest(t, router, "user1", "user1")
+	send := func(receiver string, amount float64) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]any{"receiver_username": receiver, "amount": amount})
+		req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send("user2", 101)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"transfer_amount_limit"`)
+
+	assert.Equal(t, http.StatusOK, send("user2", 100).Code)
+	rec = send("user2", 60)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resBody struct {
+		Code      string  `json:"code"`
+		Remaining float64 `json:"remaining"`
+		ResetsAt  string  `json:"resets_at"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resBody); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "daily_amount_limit", resBody.Code)
+	assert.Equal(t, 50.0, resBody.Remaining)
+	assert.NotEmpty(t, resBody.ResetsAt)
+
+	assert.Equal(t, http.StatusOK, send("user2", 50).Code)
+	assert.Equal(t, 850.0, balanceOf(t, db, "user1"))
+}
@@ -5,17 +5,23 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"shop/domain"
 	"shop/internal/controller"
 	"shop/internal/controller/middleware"
 	"shop/internal/repository"
 	"shop/internal/usecase"
 	hash "shop/pkg"
+	"shop/pkg/blob"
+	"shop/pkg/config"
 	"shop/pkg/database"
+	"shop/pkg/health"
 	"shop/pkg/logger"
+	"shop/pkg/notify"
 	"testing"
 
 	"github.com/joho/godotenv"
@@ -25,19 +31,48 @@ import (
 	"gorm.io/gorm"
 )
 
+var cfg *config.Config
+
 func setupTestDB() (http.Handler, usecase.Usecase, *gorm.DB) {
 	if err := godotenv.Load("../.env"); err != nil {
 		log.Fatalf("error loading .env file")
 	}
+	var err error
+	cfg, err = config.Load("tests", nil)
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	// Approvals are off by default, the tests buy pink-hoody with an approval.
+	cfg.Purchases.ApprovalThreshold = 500
+	if err = logger.Init(cfg.Log); err != nil {
+		log.Fatalf("failed to initialize logger: %v", err)
+	}
 
-	db := database.InitializeDBPostgres(3, 10)
+	db := database.InitializeDBPostgres(cfg.DB)
+	if err = db.Migrate(context.Background()); err != nil {
+		log.Fatalf("failed to migrate database: %v", err)
+	}
 	clearDatabase(db.GetDB())
-	db.Seed()
-	logger.InitLogger()
-
+	fixtures, err := database.LoadFixtures("../fixtures/demo.yaml")
+	if err != nil {
+		log.Fatalf("failed to load fixtures: %v", err)
+	}
 	repository := repository.NewRepository(db.GetDB())
-	usecase := usecase.NewUsecase(repository)
-	handler := controller.NewHandler(usecase)
+	options := database.SeedOptions{StartingBalance: cfg.Users.StartingBalance, BcryptCost: cfg.Auth.BcryptCost, Adjuster: repository}
+	if err = db.Seed(context.Background(), fixtures, options); err != nil {
+		log.Fatalf("failed to seed database: %v", err)
+	}
+
+	blobDir, err := os.MkdirTemp("", "shop-blobs")
+	if err != nil {
+		log.Fatalf("failed to create blob directory: %v", err)
+	}
+	blobs, err := blob.NewLocal(blobDir)
+	if err != nil {
+		log.Fatalf("failed to open blob store: %v", err)
+	}
+	usecase := usecase.NewUsecase(repository, blobs, notify.Nop{}, cfg)
+	handler := controller.NewHandler(usecase, cfg.Auth, health.NewChecker(cfg.Health.CheckTimeout))
 	router := handler.Handle()
 
 	return router, usecase, db.GetDB()
@@ -69,15 +104,36 @@ func performAuthRequest(t *testing.T, router http.Handler, username, password st
 	return token
 }
 
+// performRequest sends a request with the token's cookie. A string body is
+// sent as is, any other non-nil body is encoded as JSON.
+func performRequest(router http.Handler, token, method, path string, body any) *httptest.ResponseRecorder {
+	var data []byte
+	switch body := body.(type) {
+	case nil:
+	case string:
+		data = []byte(body)
+	default:
+		data, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
 func TestAuthHandlerIntegration(t *testing.T) {
 	router, _, db := setupTestDB()
 	defer clearDatabase(db)
 
-	user := domain.User{Username: "testuser", Password: hash.HashPassword("user1"), Balance: 100}
+	user := domain.User{Username: "testuser", Password: hash.HashPassword("user1", cfg.Auth.BcryptCost), Balance: 100}
 	db.Create(&user)
 
 	token := performAuthRequest(t, router, "testuser", "user1")
-	expectedToken, err := middleware.JWT{}.GenerateToken(&user)
+	expectedToken, err := middleware.JWT{SecretKey: cfg.Auth.SecretKey, TokenTTL: cfg.Auth.TokenTTL}.GenerateToken(&user)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedToken, token)
@@ -155,13 +211,35 @@ func TestInfoHandlerIntegration(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	assert.Contains(t, resBody, "inventory")
 	assert.Contains(t, resBody, "purchases")
 	assert.Contains(t, resBody, "transactions")
 }
 
 func clearDatabase(db *gorm.DB) {
+	db.Exec("TRUNCATE audit_log")
+	db.Exec("DELETE FROM scheduled_transfers")
+	db.Exec("DELETE FROM coin_requests")
 	db.Exec("DELETE FROM transactions")
+	db.Exec("DELETE FROM promo_redemptions")
+	db.Exec("DELETE FROM promo_codes")
+	db.Exec("DELETE FROM purchase_approvals")
+	db.Exec("DELETE FROM holds")
+	db.Exec("DELETE FROM balance_adjustments")
+	db.Exec("DELETE FROM allowance_runs")
+	db.Exec("DELETE FROM allowance_policies")
+	db.Exec("DELETE FROM coin_lots")
+	db.Exec("DELETE FROM notifications")
+	db.Exec("DELETE FROM wishlist_items")
+	db.Exec("DELETE FROM wallet_approvals")
+	db.Exec("DELETE FROM wallet_purchases")
+	db.Exec("DELETE FROM wallet_contributions")
+	db.Exec("DELETE FROM wallet_members")
 	db.Exec("DELETE FROM purchases")
+	db.Exec("DELETE FROM wallets")
+	db.Exec("DELETE FROM merch_prices")
+	db.Exec("DELETE FROM merch_images")
 	db.Exec("DELETE FROM merches")
+	db.Exec("DELETE FROM categories")
 	db.Exec("DELETE FROM users")
 }
@@ -0,0 +1,148 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"shop/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceHistoryIntegration(t *testing.T) {
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	token := performAuthRequest(t, router, "user1", "user1")
+
+	rec := performRequest(router, token, http.MethodPost, "/api/buy/cup", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// The cup gets more expensive, the purchase keeps the price it was paid.
+	changedAt := time.Now().UTC().Truncate(time.Microsecond)
+	db.Exec("UPDATE merches SET price = 25 WHERE name = 'cup'")
+	db.Create(&domain.MerchPrice{MerchName: "cup", Price: 25, EffectiveFrom: changedAt})
+
+	rec = performRequest(router, token, http.MethodPost, "/api/buy/cup", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var purchase domain.Purchase
+	if err := json.Unmarshal(rec.Body.Bytes(), &purchase); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 25.0, purchase.UnitPrice)
+	assert.Equal(t, 25.0, purchase.Total)
+
+	var totals []float64
+	db.Raw("SELECT total FROM purchases WHERE user_id = 'user1' ORDER BY created_at").Scan(&totals)
+	assert.Equal(t, []float64{20, 25}, totals)
+	assert.Equal(t, 955.0, balanceOf(t, db, "user1"))
+
+	rec = performRequest(router, token, http.MethodGet, "/api/merch/cup/prices", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var timeline domain.PriceTimeline
+	if err := json.Unmarshal(rec.Body.Bytes(), &timeline); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 25.0, timeline.Price)
+	if assert.Len(t, timeline.Prices, 2) {
+		assert.Equal(t, 20.0, timeline.Prices[0].Price)
+		assert.True(t, changedAt.Equal(*timeline.Prices[0].EffectiveTo))
+		assert.Equal(t, 25.0, timeline.Prices[1].Price)
+		assert.Nil(t, timeline.Prices[1].EffectiveTo)
+	}
+
+	rec = performRequest(router, token, http.MethodGet, "/api/merch/ghost/prices", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCatalogIntegration(t *testing.T) {
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	adminToken := performAuthRequest(t, router, "admin", "admin")
+	userToken := performAuthRequest(t, router, "user1", "user1")
+	catalog := func(query string) []string {
+		rec := performRequest(router, userToken, http.MethodGet, "/api/merch"+query, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var resp struct {
+			Merch []domain.Merch `json:"merch"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, merch := range resp.Merch {
+			names = append(names, merch.Name)
+		}
+		return names
+	}
+
+	rec := performRequest(router, adminToken, http.MethodPost, "/api/admin/categories", `{"name":"apparel","title":"Apparel"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	rec = performRequest(router, adminToken, http.MethodPatch, "/api/admin/merch/hoody", `{"category":"apparel","tags":["Warm","cotton"],"description":"A warm hoody"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = performRequest(router, adminToken, http.MethodPatch, "/api/admin/merch/t-shirt", `{"category":"apparel","tags":["cotton"]}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = performRequest(router, adminToken, http.MethodPatch, "/api/admin/merch/cup", `{"category":"kitchen"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	assert.Equal(t, []string{"hoody", "t-shirt"}, catalog("?category=apparel"))
+	assert.Equal(t, []string{"hoody"}, catalog("?category=apparel&tag=warm"))
+	assert.Equal(t, []string{"hoody", "t-shirt"}, catalog("?tag=COTTON"))
+
+	rec = performRequest(router, adminToken, http.MethodDelete, "/api/admin/categories/apparel", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// A new price made by an admin starts a new entry of the price history.
+	rec = performRequest(router, adminToken, http.MethodPatch, "/api/admin/merch/hoody", `{"price":250}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = performRequest(router, userToken, http.MethodPost, "/api/buy/hoody", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 750.0, balanceOf(t, db, "user1"))
+	var prices int64
+	db.Model(&domain.MerchPrice{}).Where("merch_name = 'hoody'").Count(&prices)
+	assert.Equal(t, int64(2), prices)
+
+	var upload bytes.Buffer
+	_ = png.Encode(&upload, image.NewNRGBA(image.Rect(0, 0, 600, 400)))
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, _ := form.CreateFormFile("image", "hoody.png")
+	_, _ = part.Write(upload.Bytes())
+	_ = form.Close()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/merch/hoody/images", body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.AddCookie(&http.Cookie{Name: "accessToken", Value: adminToken})
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var merchImage domain.MerchImage
+	if err := json.Unmarshal(rec.Body.Bytes(), &merchImage); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 600, merchImage.Width)
+
+	rec = performRequest(router, userToken, http.MethodGet, fmt.Sprintf("/api/merch/hoody/images/%d/thumbnail?width=128", merchImage.ID), "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+	thumbnail, err := png.DecodeConfig(rec.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, 128, thumbnail.Width)
+	assert.Equal(t, 85, thumbnail.Height)
+
+	rec = performRequest(router, adminToken, http.MethodDelete, fmt.Sprintf("/api/admin/merch/hoody/images/%d", merchImage.ID), "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = performRequest(router, userToken, http.MethodGet, fmt.Sprintf("/api/merch/hoody/images/%d/thumbnail", merchImage.ID), "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
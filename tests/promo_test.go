@@ -0,0 +1,78 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"shop/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromoCodesIntegration(t *testing.T) {
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	tokens := map[string]string{
+		"admin": performAuthRequest(t, router, "admin", "admin"),
+		"user1": performAuthRequest(t, router, "user1", "user1"),
+	}
+
+	rec := performRequest(router, tokens["admin"], http.MethodPost, "/api/admin/promos", `{"code":"onboard20","kind":"percent","amount":20,"items":["hoody","pink-hoody"],"max_uses_per_user":1,"stackable":true}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	rec = performRequest(router, tokens["admin"], http.MethodPost, "/api/admin/promos", `{"code":"ONBOARD20","kind":"fixed","amount":5}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = performRequest(router, tokens["admin"], http.MethodPost, "/api/admin/promos", `{"code":"WELCOME","kind":"fixed","amount":50,"stackable":true}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	rec = performRequest(router, tokens["admin"], http.MethodPost, "/api/admin/promos", `{"code":"SOLO","kind":"fixed","amount":10}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	// The code does not apply to cups.
+	rec = performRequest(router, tokens["user1"], http.MethodPost, "/api/buy/cup", `{"promo_code":"onboard20"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, 1000.0, balanceOf(t, db, "user1"))
+
+	// 20% off 300 is 240, then 50 off is 190.
+	rec = performRequest(router, tokens["user1"], http.MethodPost, "/api/buy/hoody", `{"promo_codes":["ONBOARD20","WELCOME"]}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var purchase domain.Purchase
+	if err := json.Unmarshal(rec.Body.Bytes(), &purchase); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 300.0, purchase.UnitPrice)
+	assert.Equal(t, 190.0, purchase.Total)
+	assert.Equal(t, 110.0, purchase.Discount)
+	assert.Equal(t, []string{"ONBOARD20", "WELCOME"}, purchase.PromoCodes)
+	assert.Equal(t, 810.0, balanceOf(t, db, "user1"))
+
+	// Each user can use ONBOARD20 once.
+	rec = performRequest(router, tokens["user1"], http.MethodPost, "/api/buy/hoody", `{"promo_code":"ONBOARD20"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// SOLO cannot be combined with other codes.
+	rec = performRequest(router, tokens["user1"], http.MethodPost, "/api/buy/cup", `{"promo_codes":["SOLO","WELCOME"]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = performRequest(router, tokens["admin"], http.MethodPost, "/api/admin/promos/solo/disable", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = performRequest(router, tokens["user1"], http.MethodPost, "/api/buy/cup", `{"promo_code":"SOLO"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, 810.0, balanceOf(t, db, "user1"))
+
+	var listed struct {
+		PromoCodes []domain.PromoCode `json:"promo_codes"`
+	}
+	rec = performRequest(router, tokens["admin"], http.MethodGet, "/api/admin/promos", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatal(err)
+	}
+	uses := map[string]int{}
+	for _, code := range listed.PromoCodes {
+		uses[code.Code] = code.Uses
+	}
+	assert.Equal(t, map[string]int{"ONBOARD20": 1, "WELCOME": 1, "SOLO": 0}, uses)
+}
@@ -0,0 +1,92 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoinRequestsIntegration(t *testing.T) {
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	requester := performAuthRequest(t, router, "user1", "user1")
+	payer := performAuthRequest(t, router, "user2", "user2")
+	create := func(amount float64) int64 {
+		rec := performRequest(router, requester, http.MethodPost, "/api/requests", map[string]any{"payer_username": "user2", "amount": amount, "message": "lunch"})
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("failed to create a coin request: %d %s", rec.Code, rec.Body.String())
+		}
+		var request struct {
+			ID int64 `json:"id"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &request); err != nil {
+			t.Fatal(err)
+		}
+		return request.ID
+	}
+
+	accepted, declined, cancelled := create(30), create(40), create(50)
+
+	var pending struct {
+		Incoming []struct {
+			ID int64 `json:"id"`
+		} `json:"incoming"`
+	}
+	rec := performRequest(router, payer, http.MethodGet, "/api/requests", nil)
+	if err := json.Unmarshal(rec.Body.Bytes(), &pending); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, pending.Incoming, 3)
+
+	// Only the payer can accept.
+	rec = performRequest(router, requester, http.MethodPost, fmt.Sprintf("/api/requests/%d/accept", accepted), nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = performRequest(router, payer, http.MethodPost, fmt.Sprintf("/api/requests/%d/accept", accepted), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"accepted"`)
+	assert.Equal(t, 1030.0, balanceOf(t, db, "user1"))
+	assert.Equal(t, 970.0, balanceOf(t, db, "user2"))
+
+	rec = performRequest(router, payer, http.MethodPost, fmt.Sprintf("/api/requests/%d/accept", accepted), nil)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = performRequest(router, payer, http.MethodPost, fmt.Sprintf("/api/requests/%d/decline", declined), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = performRequest(router, requester, http.MethodPost, fmt.Sprintf("/api/requests/%d/cancel", cancelled), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = performRequest(router, payer, http.MethodGet, "/api/requests", nil)
+	assert.JSONEq(t, `{"incoming":[],"outgoing":[]}`, rec.Body.String())
+	assert.Equal(t, 970.0, balanceOf(t, db, "user2"))
+}
+
+func TestCoinRequestExpiryIntegration(t *testing.T) {
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	requester := performAuthRequest(t, router, "user1", "user1")
+	payer := performAuthRequest(t, router, "user2", "user2")
+	rec := performRequest(router, requester, http.MethodPost, "/api/requests", map[string]any{"payer_username": "user2", "amount": 10})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	db.Exec("UPDATE coin_requests SET expires_at = now() - interval '1 minute'")
+
+	var id int64
+	db.Raw("SELECT id FROM coin_requests").Scan(&id)
+	rec = performRequest(router, payer, http.MethodPost, fmt.Sprintf("/api/requests/%d/accept", id), nil)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "expired")
+
+	var status string
+	db.Raw("SELECT status FROM coin_requests WHERE id = ?", id).Scan(&status)
+	assert.Equal(t, "expired", status)
+	assert.Equal(t, 1000.0, balanceOf(t, db, "user2"))
+}
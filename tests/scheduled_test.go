@@ -0,0 +1,81 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduledTransfersIntegration(t *testing.T) {
+	router, usecase, db := setupTestDB()
+	defer clearDatabase(db)
+
+	token := performAuthRequest(t, router, "user1", "user1")
+	performAuthRequest(t, router, "user2", "user2")
+	schedule := func(amount float64, executeAt time.Time) int64 {
+		rec := performRequest(router, token, http.MethodPost, "/api/sendCoin/scheduled", map[string]any{
+			"receiver_username": "user2", "amount": amount, "execute_at": executeAt.Format(time.RFC3339), "message": "happy birthday",
+		})
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("failed to schedule a transfer: %d %s", rec.Code, rec.Body.String())
+		}
+		var scheduled struct {
+			ID int64 `json:"id"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &scheduled); err != nil {
+			t.Fatal(err)
+		}
+		return scheduled.ID
+	}
+
+	tomorrow := time.Now().Add(24 * time.Hour)
+	schedule(30, tomorrow)
+	schedule(5000, tomorrow)
+	cancelled := schedule(40, tomorrow)
+	later := schedule(50, tomorrow.Add(7*24*time.Hour))
+
+	rec := performRequest(router, token, http.MethodPost, fmt.Sprintf("/api/sendCoin/scheduled/%d/cancel", cancelled), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Nothing is due yet.
+	assert.NoError(t, usecase.RunScheduledTransfers(context.Background(), time.Now()))
+	assert.Equal(t, 1000.0, balanceOf(t, db, "user1"))
+
+	assert.NoError(t, usecase.RunScheduledTransfers(context.Background(), tomorrow.Add(time.Minute)))
+	assert.Equal(t, 970.0, balanceOf(t, db, "user1"))
+	assert.Equal(t, 1030.0, balanceOf(t, db, "user2"))
+
+	// Failed transfers are not retried.
+	assert.NoError(t, usecase.RunScheduledTransfers(context.Background(), tomorrow.Add(time.Hour)))
+	assert.Equal(t, 970.0, balanceOf(t, db, "user1"))
+
+	var list struct {
+		Scheduled []struct {
+			ID            int64   `json:"id"`
+			Amount        float64 `json:"amount"`
+			Status        string  `json:"status"`
+			FailureReason string  `json:"failure_reason"`
+		} `json:"scheduled"`
+	}
+	rec = performRequest(router, token, http.MethodGet, "/api/sendCoin/scheduled", nil)
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[float64]string{}
+	for _, scheduled := range list.Scheduled {
+		statuses[scheduled.Amount] = scheduled.Status
+		if scheduled.Status == "failed" {
+			assert.Equal(t, "insufficient money", scheduled.FailureReason)
+		}
+	}
+	assert.Equal(t, map[float64]string{30: "executed", 5000: "failed", 40: "cancelled", 50: "pending"}, statuses)
+	assert.Equal(t, later, list.Scheduled[0].ID)
+}
@@ -0,0 +1,93 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"shop/pkg/server"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func balanceOf(t *testing.T, db *gorm.DB, username string) float64 {
+	var balance float64
+	if err := db.Raw("SELECT balance FROM users WHERE username = ?", username).Scan(&balance).Error; err != nil {
+		t.Fatal(err)
+	}
+	return balance
+}
+
+func TestGracefulShutdownLeavesNoHalfAppliedTransfers(t *testing.T) {
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	tokens := map[string]string{
+		"user1": performAuthRequest(t, router, "user1", "user1"),
+		"user2": performAuthRequest(t, router, "user2", "hashed_password"),
+	}
+	initial := map[string]float64{"user1": balanceOf(t, db, "user1"), "user2": balanceOf(t, db, "user2")}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpCfg := cfg.HTTP
+	httpCfg.ShutdownTimeout = 10 * time.Second
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- server.New(httpCfg, router).Serve(ctx, listener) }()
+
+	var succeeded atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		sender, receiver := "user1", "user2"
+		if i%2 == 1 {
+			sender, receiver = receiver, sender
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body, _ := json.Marshal(map[string]interface{}{"receiver_username": receiver, "amount": 1.0})
+			req, _ := http.NewRequest(http.MethodPost, "http://"+listener.Addr().String()+"/api/sendCoin", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{Name: "accessToken", Value: tokens[sender]})
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				succeeded.Add(1)
+			}
+		}()
+		if i == 50 {
+			cancel()
+		}
+	}
+	wg.Wait()
+	cancel()
+	assert.NoError(t, <-served)
+
+	var transactionsCount int64
+	db.Raw("SELECT COUNT(*) FROM transactions").Scan(&transactionsCount)
+	assert.Equal(t, succeeded.Load(), transactionsCount, "every acknowledged transfer must be stored, and only those")
+
+	for username, balance := range initial {
+		var received, sent float64
+		db.Raw("SELECT COALESCE(SUM(money_amount), 0) FROM transactions WHERE receiver_username = ?", username).Scan(&received)
+		db.Raw("SELECT COALESCE(SUM(money_amount), 0) FROM transactions WHERE sender_username = ?", username).Scan(&sent)
+		assert.Equal(t, balance+received-sent, balanceOf(t, db, username), "balance of %s must match its transfers", username)
+	}
+	assert.Equal(t, initial["user1"]+initial["user2"], balanceOf(t, db, "user1")+balanceOf(t, db, "user2"))
+}
@@ -0,0 +1,79 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"shop/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletsIntegration(t *testing.T) {
+	router, _, db := setupTestDB()
+	defer clearDatabase(db)
+
+	tokens := map[string]string{}
+	for _, username := range []string{"user1", "user2", "user3"} {
+		tokens[username] = performAuthRequest(t, router, username, username)
+	}
+	decode := func(rec *httptest.ResponseRecorder, v any) {
+		if rec.Code >= http.StatusBadRequest {
+			t.Fatalf("unexpected response: %d %s", rec.Code, rec.Body.String())
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wallet domain.Wallet
+	decode(performRequest(router, tokens["user1"], http.MethodPost, "/api/wallets", `{"name":"team","quorum":2,"members":["user2","user3"]}`), &wallet)
+	path := fmt.Sprintf("/api/wallets/%d", wallet.ID)
+
+	for _, username := range []string{"user2", "user3"} {
+		rec := performRequest(router, tokens[username], http.MethodPost, path+"/contribute", `{"amount":300}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, 700.0, balanceOf(t, db, username))
+	}
+
+	// A member's proposal waits for a second approval.
+	var purchase domain.WalletPurchase
+	decode(performRequest(router, tokens["user2"], http.MethodPost, path+"/purchases", `{"item":"pink-hoody"}`), &purchase)
+	assert.Equal(t, domain.WalletPurchasePending, purchase.Status)
+	decode(performRequest(router, tokens["user3"], http.MethodPost, fmt.Sprintf("%s/purchases/%d/approve", path, purchase.ID), ""), &purchase)
+	assert.Equal(t, domain.WalletPurchaseCompleted, purchase.Status)
+	assert.Equal(t, 700.0, balanceOf(t, db, "user2"))
+
+	var count int64
+	db.Raw("SELECT count(*) FROM purchases WHERE user_id = 'user2' AND merch_name = 'pink-hoody' AND wallet_id = ?", wallet.ID).Scan(&count)
+	assert.Equal(t, int64(1), count)
+
+	// The owner's approval is enough, but the wallet has only 100 coins left.
+	decode(performRequest(router, tokens["user3"], http.MethodPost, path+"/purchases", `{"item":"hoody"}`), &purchase)
+	rec := performRequest(router, tokens["user1"], http.MethodPost, fmt.Sprintf("%s/purchases/%d/approve", path, purchase.ID), "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = performRequest(router, tokens["user2"], http.MethodPost, fmt.Sprintf("%s/purchases/%d/reject", path, purchase.ID), "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	decode(performRequest(router, tokens["user3"], http.MethodPost, fmt.Sprintf("%s/purchases/%d/reject", path, purchase.ID), ""), &purchase)
+	assert.Equal(t, domain.WalletPurchaseRejected, purchase.Status)
+
+	var info domain.WalletInfo
+	decode(performRequest(router, tokens["user2"], http.MethodGet, path+"/info", ""), &info)
+	assert.Equal(t, 100.0, info.Wallet.Balance)
+	assert.Len(t, info.Members, 3)
+	assert.Len(t, info.Contributions, 2)
+	assert.Len(t, info.Purchases, 2)
+
+	rec = performRequest(router, tokens["user1"], http.MethodDelete, path+"/members/user1", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = performRequest(router, tokens["user3"], http.MethodDelete, path+"/members/user3", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = performRequest(router, tokens["user3"], http.MethodGet, path+"/info", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
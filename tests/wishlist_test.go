@@ -0,0 +1,105 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"shop/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWishlistIntegration(t *testing.T) {
+	router, usecase, db := setupTestDB()
+	defer clearDatabase(db)
+
+	adminToken := performAuthRequest(t, router, "admin", "admin")
+	userToken := performAuthRequest(t, router, "user1", "user1")
+	performAuthRequest(t, router, "user2", "user2")
+	inbox := func() []domain.Notification {
+		rec := performRequest(router, userToken, http.MethodGet, "/api/notifications", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var body struct {
+			Notifications []domain.Notification `json:"notifications"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return body.Notifications
+	}
+
+	// The hoody sells out, user1 waits for it and for the t-shirt to get
+	// cheaper, and saves the pink hoody for later.
+	rec := performRequest(router, adminToken, http.MethodPatch, "/api/admin/merch/hoody", `{"stock":0}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = performRequest(router, userToken, http.MethodPost, "/api/buy/hoody", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = performRequest(router, userToken, http.MethodPut, "/api/wishlist/hoody", `{"notify_restock":true}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = performRequest(router, userToken, http.MethodPut, "/api/wishlist/t-shirt", `{"notify_price_drop":true}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = performRequest(router, userToken, http.MethodPut, "/api/wishlist/pink-hoody", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = performRequest(router, userToken, http.MethodPut, "/api/wishlist/ghost", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = performRequest(router, userToken, http.MethodPost, "/api/sendCoin", `{"receiver_username":"user2","amount":520}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = performRequest(router, userToken, http.MethodGet, "/api/wishlist", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var wishlist domain.Wishlist
+	if err := json.Unmarshal(rec.Body.Bytes(), &wishlist); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 480.0, wishlist.Available)
+	assert.Equal(t, 400.0, wishlist.CoinsToGo)
+	if assert.Len(t, wishlist.Items, 3) {
+		assert.Equal(t, "hoody", wishlist.Items[0].Item)
+		assert.False(t, wishlist.Items[0].InStock)
+		assert.False(t, wishlist.Items[0].Affordable)
+		assert.Equal(t, "t-shirt", wishlist.Items[1].Item)
+		assert.True(t, wishlist.Items[1].Affordable)
+		assert.Equal(t, "pink-hoody", wishlist.Items[2].Item)
+		assert.Equal(t, 20.0, wishlist.Items[2].CoinsToGo)
+	}
+
+	// Nothing changed yet, so nothing is alerted.
+	now := time.Now()
+	assert.NoError(t, usecase.SendWishlistAlerts(context.Background(), now))
+	assert.Empty(t, inbox())
+
+	rec = performRequest(router, adminToken, http.MethodPatch, "/api/admin/merch/hoody", `{"stock":3}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = performRequest(router, adminToken, http.MethodPatch, "/api/admin/merch/t-shirt", `{"price":60}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, usecase.SendWishlistAlerts(context.Background(), now))
+	notifications := inbox()
+	if assert.Len(t, notifications, 2) {
+		kinds := []string{notifications[0].Kind, notifications[1].Kind}
+		assert.ElementsMatch(t, []string{domain.NotificationRestock, domain.NotificationPriceDrop}, kinds)
+	}
+
+	// Each change is alerted once and a price going up is not alerted.
+	rec = performRequest(router, adminToken, http.MethodPatch, "/api/admin/merch/t-shirt", `{"price":90}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, usecase.SendWishlistAlerts(context.Background(), now))
+	assert.NoError(t, usecase.SendWishlistAlerts(context.Background(), now))
+	assert.Len(t, inbox(), 2)
+
+	rec = performRequest(router, userToken, http.MethodPost, "/api/buy/hoody", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var stock int
+	db.Raw("SELECT stock FROM merches WHERE name = 'hoody'").Scan(&stock)
+	assert.Equal(t, 2, stock)
+
+	rec = performRequest(router, userToken, http.MethodDelete, "/api/wishlist/t-shirt", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = performRequest(router, userToken, http.MethodDelete, "/api/wishlist/t-shirt", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}